@@ -2,15 +2,18 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"regexp"
 	"syscall"
 	"time"
 
 	"github.com/kasbench/globeco-confirmation-service/internal/api"
 	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/middleware"
 	"github.com/kasbench/globeco-confirmation-service/internal/service"
 	"github.com/kasbench/globeco-confirmation-service/internal/utils"
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
@@ -20,8 +23,8 @@ import (
 )
 
 func main() {
-	// Load configuration
-	cfg, err := config.LoadFromEnvironment()
+	// Load configuration, from CONFIG_FILE when set; environment variables override it
+	cfg, err := loadConfig(os.Getenv("CONFIG_FILE"))
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
@@ -32,15 +35,30 @@ func main() {
 		Format:      cfg.Logging.Format,
 		Output:      cfg.Logging.Output,
 		ServiceName: cfg.Tracing.ServiceName,
+
+		SamplingInitial:    cfg.Logging.SamplingInitial,
+		SamplingThereafter: cfg.Logging.SamplingThereafter,
 	})
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
+	// Flush buffered entries, including the shutdown logs, on the way out
+	defer func() {
+		if err := appLogger.Close(); err != nil {
+			log.Printf("Failed to flush logger: %v", err)
+		}
+	}()
 
 	// Initialize metrics
 	appMetrics := metrics.New(metrics.Config{
 		Namespace: cfg.Metrics.Namespace,
 		Enabled:   cfg.Metrics.Enabled,
+		ConstLabels: map[string]string{
+			"environment":     cfg.Metrics.Environment,
+			"service_version": cfg.Tracing.ServiceVersion,
+		},
+		HighCardinalityMetricsEnabled: cfg.Metrics.HighCardinalityMetricsEnabled,
+		MessageSizeClasses:            cfg.Metrics.MessageSizeClasses,
 	})
 
 	// Initialize OpenTelemetry metrics (additional metrics for OTLP export)
@@ -94,19 +112,26 @@ func main() {
 		zap.String("environment", config.GetEnvironment()),
 		zap.String("http_address", cfg.GetHTTPAddress()),
 		zap.Strings("kafka_brokers", cfg.Kafka.Brokers),
-		zap.String("kafka_topic", cfg.Kafka.Topic),
+		zap.Strings("kafka_topics", cfg.Kafka.GetTopics()),
 		zap.String("kafka_consumer_group", cfg.Kafka.ConsumerGroup),
 		zap.String("execution_service_url", cfg.ExecutionService.BaseURL),
 	)
 
-	// Log configuration details in debug mode
+	// Log configuration details in debug mode, with credentials masked
 	if cfg.Logging.Level == "debug" {
+		redacted := cfg.Redacted()
 		appLogger.WithContext(ctx).Debug("Configuration loaded",
-			zap.Any("http", cfg.HTTP),
-			zap.Any("kafka", cfg.Kafka),
-			zap.Any("execution_service", cfg.ExecutionService),
-			zap.Any("performance", cfg.Performance),
-			zap.Any("health", cfg.Health),
+			zap.Any("http", redacted["http"]),
+			zap.Any("kafka", redacted["kafka"]),
+			zap.Any("execution_service", redacted["execution_service"]),
+			zap.Any("performance", redacted["performance"]),
+			zap.Any("health", redacted["health"]),
+		)
+	}
+
+	if cfg.Logging.DebugPayloadLogging {
+		appLogger.WithContext(ctx).Warn("Debug payload logging is enabled; logs may contain sensitive fill and execution data",
+			zap.Int("max_length", cfg.Logging.DebugPayloadMaxLength),
 		)
 	}
 
@@ -121,56 +146,190 @@ func main() {
 	}()
 
 	// Initialize resilience manager
-	resilienceManager := utils.NewResilienceManager(utils.ResilienceConfig{
-		RetryConfig: utils.RetryConfig{
-			InitialDelay:  cfg.ExecutionService.RetryBackoff,
-			MaxDelay:      5 * time.Second,
-			BackoffFactor: 2.0,
-		},
-		CircuitBreakerConfig: utils.CircuitBreakerConfig{
-			FailureThreshold: cfg.ExecutionService.CircuitBreaker.FailureThreshold,
-			Timeout:          cfg.ExecutionService.CircuitBreaker.Timeout,
-		},
-		DeadLetterQueueConfig: utils.DeadLetterQueueConfig{
-			MaxSize: 1000,
-		},
-		TimeoutConfig: utils.TimeoutConfig{
-			ExecutionServiceTimeout: cfg.ExecutionService.Timeout,
-			KafkaConsumerTimeout:    cfg.Kafka.ConsumerTimeout,
-			DefaultOperationTimeout: 5 * time.Second,
-		},
-	}, appLogger, appMetrics)
+	resilienceManager := utils.NewResilienceManager(newResilienceConfig(cfg), appLogger, appMetrics)
+
+	// Initialize Execution Service client; tenant clients share the token provider
+	executionTokenProvider := service.NewTokenProviderFromConfig(cfg.ExecutionService.Auth)
+	newExecutionClient := func(tenantID string, executionService config.ExecutionServiceConfig) *service.ExecutionServiceClient {
+		return service.NewExecutionServiceClient(service.ExecutionServiceClientConfig{
+			ExecutionService:  executionService,
+			Logger:            appLogger,
+			Metrics:           appMetrics,
+			ResilienceManager: resilienceManager,
+			TracingProvider:   nil, // Using global OpenTelemetry tracer now
+			Concurrency: utils.NewAdaptiveConcurrencyLimiter(utils.AdaptiveConcurrencyConfig{
+				MinLimit:      cfg.Performance.AdaptiveConcurrencyMin,
+				MaxLimit:      cfg.Performance.AdaptiveConcurrencyMax,
+				LatencyTarget: cfg.Performance.AdaptiveConcurrencyLatencyTarget,
+			}),
+			TokenProvider: executionTokenProvider,
+			Logging:       cfg.Logging,
+			TenantID:      tenantID,
+		})
+	}
+	executionClient := newExecutionClient("", cfg.ExecutionService)
+
+	// Initialize Allocation Service client; left nil when disabled so completed trades are not posted.
+	// Its circuit breaker defers posts while the service is down instead of dead-lettering them.
+	newAllocationClient := func(allocationService config.AllocationServiceConfig) *service.AllocationServiceClient {
+		return service.NewAllocationServiceClient(service.AllocationServiceClientConfig{
+			AllocationService: allocationService,
+			Logger:            appLogger,
+			Metrics:           appMetrics,
+			ResilienceManager: resilienceManager,
+			TracingProvider:   nil, // Using global OpenTelemetry tracer now
+		})
+	}
+	var allocationClient service.AllocationServiceClientInterface
+	var healthCheckers []api.HealthChecker
+	newAllocationCircuitBreaker := func(tenantID string, allocationService config.AllocationServiceConfig) *utils.CircuitBreaker {
+		return utils.NewCircuitBreaker(utils.CircuitBreakerConfig{
+			Name:                 service.AllocationCircuitBreakerName(tenantID),
+			FailureThreshold:     allocationService.CircuitBreaker.FailureThreshold,
+			Timeout:              allocationService.CircuitBreaker.Timeout,
+			WindowSize:           allocationService.CircuitBreaker.WindowSize,
+			FailureRateThreshold: allocationService.CircuitBreaker.FailureRateThreshold,
+		}, appLogger, appMetrics)
+	}
+	var allocationCircuitBreaker *utils.CircuitBreaker
+	if cfg.AllocationService.Enabled {
+		allocationCircuitBreaker = newAllocationCircuitBreaker("", cfg.AllocationService)
+
+		client := newAllocationClient(cfg.AllocationService)
+		allocationClient = client
+
+		// Unless allocation posts are required, the Allocation Service is not critical:
+		// readiness reports DEGRADED while it is down
+		healthCheckers = append(healthCheckers, api.NewAllocationServiceHealthChecker(client, cfg.AllocationService.Required))
+	} else {
+		appLogger.WithContext(ctx).Info("Allocation Service client disabled")
+	}
 
-	// Initialize Execution Service client
-	executionClient := service.NewExecutionServiceClient(service.ExecutionServiceClientConfig{
-		ExecutionService:  cfg.ExecutionService,
-		Logger:            appLogger,
-		Metrics:           appMetrics,
-		ResilienceManager: resilienceManager,
-		TracingProvider:   nil, // Using global OpenTelemetry tracer now
-	})
+	// Initialize validation service
+	// Format patterns were checked by config validation; empty patterns use the defaults
+	compilePattern := func(pattern string) *regexp.Regexp {
+		if pattern == "" {
+			return nil
+		}
+		return regexp.MustCompile(pattern)
+	}
+	newValidationService := func(validation config.ValidationConfig) *service.ValidationService {
+		return service.NewValidationService(service.ValidationConfig{
+			Logger:                       appLogger,
+			Metrics:                      appMetrics,
+			SentBeforeReceivedSeverity:   service.ValidationSeverity(validation.SentBeforeReceivedSeverity),
+			LastFilledBeforeSentSeverity: service.ValidationSeverity(validation.LastFilledBeforeSentSeverity),
+			LatestVersionSentinel:        validation.LatestVersionSentinel,
+			MissingFieldMode:             service.MissingFieldMode(validation.MissingFieldMode),
+			MaxFillsPerFilledShare:       validation.MaxFillsPerFilledShare,
+			ExcessiveFillCountSeverity:   service.ValidationSeverity(validation.ExcessiveFillCountSeverity),
+			TotalAmountTolerancePercent:  validation.TotalAmountTolerancePercent,
+			FutureToleranceSeconds:       validation.FutureToleranceSeconds,
+			MaxTimestampAge:              validation.MaxTimestampAge,
+			PriceAnomalyWindow:           validation.PriceAnomalyWindow,
+			PriceAnomalyTolerancePercent: validation.PriceAnomalyTolerancePercent,
+			PriceAnomalyMaxSecurities:    validation.PriceAnomalyMaxSecurities,
+			TickerPattern:                compilePattern(validation.TickerPattern),
+			SecurityIDPattern:            compilePattern(validation.SecurityIDPattern),
+			DestinationPattern:           compilePattern(validation.DestinationPattern),
+		})
+	}
+	validationService := newValidationService(cfg.Validation)
 
-	// Initialize Allocation Service client
-	allocationClient := service.NewAllocationServiceClient(service.AllocationServiceClientConfig{
-		AllocationService: cfg.AllocationService,
-		Logger:            appLogger,
-		Metrics:           appMetrics,
-		ResilienceManager: resilienceManager,
-		TracingProvider:   nil, // Using global OpenTelemetry tracer now
+	// Build per-tenant clients and validation for the settings each tenant overrides
+	tenants := make(map[string]service.TenantProfile, len(cfg.Tenants))
+	tenantValidation := make(map[string]*service.ValidationService)
+	for _, tenantID := range cfg.TenantIDs() {
+		tenant := cfg.Tenants[tenantID]
+
+		var profile service.TenantProfile
+		if tenant.ExecutionServiceURL != "" {
+			profile.ExecutionClient = newExecutionClient(tenantID, cfg.ExecutionServiceFor(tenantID))
+		}
+		if tenant.AllocationServiceURL != "" {
+			profile.AllocationClient = newAllocationClient(cfg.AllocationServiceFor(tenantID))
+			profile.AllocationCircuitBreaker = newAllocationCircuitBreaker(tenantID, cfg.AllocationServiceFor(tenantID))
+		}
+		if tenant.Validation != (config.TenantValidationConfig{}) {
+			profile.ValidationService = newValidationService(cfg.ValidationFor(tenantID))
+			tenantValidation[tenantID] = profile.ValidationService
+		}
+		tenants[tenantID] = profile
+
+		appLogger.WithContext(ctx).Info("Tenant overrides configured",
+			zap.String("tenant_id", tenantID),
+			zap.String("execution_service_url", cfg.ExecutionServiceFor(tenantID).BaseURL),
+			zap.String("allocation_service_url", cfg.AllocationServiceFor(tenantID).BaseURL),
+		)
+	}
+
+	// Validation has already checked the trusted proxy ranges
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.HTTP.RateLimit.TrustedProxies)
+	if err != nil {
+		appLogger.WithContext(ctx).Fatal("Invalid trusted proxies", zap.Error(err))
+	}
+
+	// The limiter is kept even when disabled so a reload can turn it on
+	rateLimiter := middleware.NewClientRateLimiter(middleware.RateLimitConfig{
+		RequestsPerSecond: cfg.HTTP.RateLimit.RequestsPerSecond,
+		Burst:             cfg.HTTP.RateLimit.Burst,
+		TTL:               cfg.HTTP.RateLimit.TTL,
+		Done:              ctx.Done(),
+		TrustedProxies:    trustedProxies,
 	})
 
-	// Initialize validation service
-	validationService := service.NewValidationService(service.ValidationConfig{
-		Logger: appLogger,
+	// Reload the log level, validation thresholds and rate limit on SIGHUP
+	configReloader := service.NewConfigReloader(service.ConfigReloaderConfig{
+		Load:              func() (*config.Config, error) { return loadConfig(os.Getenv("CONFIG_FILE")) },
+		Logger:            appLogger,
+		ValidationService: validationService,
+		TenantValidation:  tenantValidation,
+		RateLimiter:       rateLimiter,
 	})
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reloadChan:
+				if err := configReloader.Reload(ctx); err != nil {
+					appLogger.WithContext(ctx).Error("Failed to reload configuration, keeping current settings", zap.Error(err))
+				}
+			}
+		}
+	}()
 
 	// Initialize duplicate detection service
+	duplicateRetention := 24 * time.Hour
 	duplicateDetection := service.NewDuplicateDetectionService(service.DuplicateDetectionConfig{
 		Logger:          appLogger,
-		RetentionPeriod: 24 * time.Hour,
-		MaxEntries:      10000,
+		RetentionPeriod: duplicateRetention,
+		MaxEntries:      cfg.Performance.DuplicateDetectionMaxEntries,
+
+		PriceChangeThresholdPercent: cfg.Validation.DuplicatePriceChangeThresholdPercent,
+		QuantityChangeTriggers:      &cfg.Validation.DuplicateQuantityChangeTriggers,
+
+		Redis: service.RedisDuplicateStoreConfig{
+			Address:     cfg.Redis.Address,
+			Password:    cfg.Redis.Password,
+			DB:          cfg.Redis.DB,
+			KeyPrefix:   cfg.Redis.KeyPrefix,
+			DialTimeout: cfg.Redis.DialTimeout,
+		},
 	})
 
+	// Optional fill fields sent in Execution Service updates
+	var updateMapper service.ExecutionUpdateMapper
+	if len(cfg.ExecutionService.UpdateFields) > 0 {
+		fieldMapper, err := service.NewExecutionUpdateFieldMapper(cfg.ExecutionService.UpdateFields)
+		if err != nil {
+			appLogger.WithContext(ctx).Fatal("Invalid execution update fields", zap.Error(err))
+		}
+		updateMapper = fieldMapper
+	}
+
 	// Initialize confirmation service (message handler)
 	confirmationService := service.NewConfirmationService(service.ConfirmationServiceConfig{
 		ExecutionClient:    executionClient,
@@ -182,6 +341,24 @@ func main() {
 		ValidationService:  validationService,
 		DuplicateDetection: duplicateDetection,
 		Config:             cfg,
+
+		ExecutionIDAllowlist: cfg.Canary.ExecutionIDAllowlist,
+		ExecutionIDDenylist:  cfg.Canary.ExecutionIDDenylist,
+
+		ErrorRateWindowSize:      cfg.Performance.ErrorRateWindowSize,
+		ProcessingTimeWindowSize: cfg.Performance.ProcessingTimeWindowSize,
+		Mode:                     service.ProcessingMode(cfg.Canary.Mode),
+
+		AllocationCircuitBreaker:        allocationCircuitBreaker,
+		PendingAllocationBufferSize:     cfg.AllocationService.PendingBufferSize,
+		PendingAllocationReplayInterval: cfg.AllocationService.ReplayInterval,
+		AllocationRequired:              cfg.AllocationService.Required,
+		AllocationTrigger:               service.AllocationTrigger(cfg.AllocationService.Trigger),
+		PostedAllocationRetention:       duplicateRetention,
+		PostedAllocationMaxEntries:      cfg.Performance.DuplicateDetectionMaxEntries,
+
+		Tenants:      tenants,
+		UpdateMapper: updateMapper,
 	})
 
 	// TEMP LOG: Check allocationClient wiring
@@ -201,20 +378,38 @@ func main() {
 		ResilienceManager: resilienceManager,
 		TracingProvider:   nil, // Using global OpenTelemetry tracer now
 		MessageHandler:    confirmationService,
+		Logging:           cfg.Logging,
+		WorkerPoolSize:    cfg.Performance.WorkerPoolSize,
+		WorkerConcurrency: newWorkerConcurrency(cfg),
 	})
 
 	// Initialize HTTP server for health checks and metrics
 	httpHandler := api.NewHandlers(api.HandlerConfig{
 		ConfirmationService: confirmationService,
 		KafkaConsumer:       kafkaConsumer,
+		DuplicateDetection:  duplicateDetection,
+		DeadLetterQueue:     resilienceManager,
 		Logger:              appLogger,
 		Metrics:             appMetrics,
+		StartupGracePeriod:  cfg.Health.StartupGracePeriod,
+		Limits:              cfg.GetRuntimeLimits(),
+		Config:              cfg,
+		HealthCheckers:      healthCheckers,
 	})
 
 	router := api.NewRouter(api.RouterConfig{
-		Handlers: httpHandler,
-		Logger:   appLogger,
-		Metrics:  appMetrics,
+		Handlers:              httpHandler,
+		Logger:                appLogger,
+		Metrics:               appMetrics,
+		MaxConcurrentRequests: cfg.Performance.MaxConcurrentRequests,
+		AdminEndpointsEnabled: cfg.HTTP.AdminEndpointsEnabled,
+		MaxBodyBytes:          cfg.HTTP.MaxBodyBytes,
+		CORS: middleware.CORSConfig{
+			AllowedOrigins: cfg.HTTP.CORS.AllowedOrigins,
+			AllowedMethods: cfg.HTTP.CORS.AllowedMethods,
+			AllowedHeaders: cfg.HTTP.CORS.AllowedHeaders,
+		},
+		RateLimiter: rateLimiter,
 	})
 	httpServer := &http.Server{
 		Addr:         cfg.GetHTTPAddress(),
@@ -251,23 +446,30 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
-	// Stop Kafka consumer
-	if err := kafkaConsumer.Stop(shutdownCtx); err != nil {
-		appLogger.WithContext(shutdownCtx).Error("Error stopping Kafka consumer", zap.Error(err))
-	}
-
-	// Stop HTTP server
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		appLogger.WithContext(shutdownCtx).Error("Error stopping HTTP server", zap.Error(err))
-	}
+	// Components stop in stage order: stop fetching and drain the in-flight message,
+	// stop the confirmation service and persist the dead letter queue, then the HTTP
+	// server and finally telemetry so the shutdown itself is still traced and measured
+	shutdown := utils.NewShutdownSequence(appLogger)
+	shutdown.Register(utils.ShutdownStageConsumer, "kafka_consumer", kafkaConsumer.Stop)
+	shutdown.Register(utils.ShutdownStageDeadLetterQueue, "confirmation_service", func(ctx context.Context) error {
+		// Dead-letters posts still waiting for replay before the queue is persisted, and
+		// stops duplicate detection, closing the Redis connection if used
+		confirmationService.Stop(ctx)
+		return nil
+	})
+	shutdown.Register(utils.ShutdownStageDeadLetterQueue, "resilience_manager", func(ctx context.Context) error {
+		// Flushes the dead letter queue to disk when persistence is enabled
+		resilienceManager.Stop(ctx)
+		return nil
+	})
+	shutdown.Register(utils.ShutdownStageHTTPServer, "http_server", httpServer.Shutdown)
+	// Tracing provider shutdown is handled by the OpenTelemetry shutdown
+	shutdown.Register(utils.ShutdownStageTelemetry, "opentelemetry", otelShutdown)
 
-	// Shutdown OpenTelemetry
-	if err := otelShutdown(shutdownCtx); err != nil {
-		appLogger.WithContext(shutdownCtx).Error("Error shutting down OpenTelemetry", zap.Error(err))
+	if err := shutdown.Run(shutdownCtx); err != nil {
+		appLogger.WithContext(shutdownCtx).Error("Error during graceful shutdown", zap.Error(err))
 	}
 
-	// Note: Tracing provider shutdown is now handled by OpenTelemetry shutdown above
-
 	select {
 	case <-shutdownCtx.Done():
 		appLogger.WithContext(ctx).Error("Shutdown timeout exceeded")
@@ -275,3 +477,62 @@ func main() {
 		appLogger.WithContext(ctx).Info("Service shutdown completed")
 	}
 }
+
+// newResilienceConfig builds the resilience manager settings from the service configuration
+func newResilienceConfig(cfg *config.Config) utils.ResilienceConfig {
+	return utils.ResilienceConfig{
+		RetryConfig: utils.RetryConfig{
+			InitialDelay:  cfg.ExecutionService.RetryBackoff,
+			MaxDelay:      5 * time.Second,
+			BackoffFactor: 2.0,
+		},
+		CircuitBreakerConfig: utils.CircuitBreakerConfig{
+			FailureThreshold:     cfg.ExecutionService.CircuitBreaker.FailureThreshold,
+			Timeout:              cfg.ExecutionService.CircuitBreaker.Timeout,
+			WindowSize:           cfg.ExecutionService.CircuitBreaker.WindowSize,
+			FailureRateThreshold: cfg.ExecutionService.CircuitBreaker.FailureRateThreshold,
+		},
+		DeadLetterQueueConfig: utils.DeadLetterQueueConfig{
+			Enabled:            cfg.Performance.DeadLetterQueueEnabled,
+			MaxSize:            cfg.Performance.DeadLetterQueueMaxSize,
+			PersistToDisk:      cfg.Performance.DeadLetterQueuePersistToDisk,
+			FilePath:           cfg.Performance.DeadLetterQueueFilePath,
+			CompactionInterval: cfg.Performance.DeadLetterQueueCompactionInterval,
+		},
+		TimeoutConfig: utils.TimeoutConfig{
+			ExecutionServiceTimeout: cfg.ExecutionService.Timeout,
+			KafkaConsumerTimeout:    cfg.Kafka.ConsumerTimeout,
+			DefaultOperationTimeout: 5 * time.Second,
+		},
+		RetryBudget: utils.RetryBudgetConfig{
+			Tokens:     cfg.Performance.RetryBudgetTokens,
+			RefillRate: cfg.Performance.RetryBudgetRefillRate,
+		},
+	}
+}
+
+// newWorkerConcurrency builds the adaptive limit on active Kafka consumer workers,
+// which backs off between the adaptive concurrency min and the worker pool size
+func newWorkerConcurrency(cfg *config.Config) *utils.AdaptiveConcurrencyLimiter {
+	if cfg.Performance.WorkerPoolSize <= 1 {
+		return nil
+	}
+
+	return utils.NewAdaptiveConcurrencyLimiter(utils.AdaptiveConcurrencyConfig{
+		MinLimit:      cfg.Performance.AdaptiveConcurrencyMin,
+		MaxLimit:      cfg.Performance.WorkerPoolSize,
+		LatencyTarget: cfg.Performance.AdaptiveConcurrencyLatencyTarget,
+	})
+}
+
+// loadConfig loads the configuration file at path, which must exist, or only the
+// defaults and environment variables when path is empty
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		return config.LoadFromEnvironment()
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("CONFIG_FILE: %w", err)
+	}
+	return config.LoadFromFile(path)
+}
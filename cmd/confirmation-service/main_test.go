@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResilienceConfig_DeadLetterQueueStoresMessages(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+
+	resilienceManager := utils.NewResilienceManager(newResilienceConfig(config.GetDefaults()), appLogger, nil)
+	t.Cleanup(func() { resilienceManager.Stop(context.Background()) })
+
+	err = resilienceManager.AddToDeadLetterQueue(context.Background(), "fill", "processing failed", []error{errors.New("boom")}, 1, nil)
+	require.NoError(t, err)
+
+	assert.Len(t, resilienceManager.GetDeadLetterMessages(), 1)
+}
+
+func TestNewResilienceConfig_DeadLetterQueueCanBeDisabled(t *testing.T) {
+	cfg := config.GetDefaults()
+	cfg.Performance.DeadLetterQueueEnabled = false
+
+	assert.False(t, newResilienceConfig(cfg).DeadLetterQueueConfig.Enabled)
+}
+
+func TestNewResilienceConfig_DeadLetterQueuePersistsToDisk(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+
+	cfg := config.GetDefaults()
+	cfg.Performance.DeadLetterQueuePersistToDisk = true
+	cfg.Performance.DeadLetterQueueFilePath = filepath.Join(t.TempDir(), "dlq.jsonl")
+
+	first := utils.NewResilienceManager(newResilienceConfig(cfg), appLogger, nil)
+	err = first.AddToDeadLetterQueue(context.Background(), "fill", "processing failed", []error{errors.New("boom")}, 1, nil)
+	require.NoError(t, err)
+	first.Stop(context.Background())
+
+	// A restarted service reloads the persisted message
+	second := utils.NewResilienceManager(newResilienceConfig(cfg), appLogger, nil)
+	t.Cleanup(func() { second.Stop(context.Background()) })
+
+	assert.Len(t, second.GetDeadLetterMessages(), 1)
+}
@@ -3,12 +3,19 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/kasbench/globeco-confirmation-service/internal/buildinfo"
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
 	"github.com/kasbench/globeco-confirmation-service/internal/service"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
 	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
@@ -19,21 +26,50 @@ type ConfirmationServiceInterface interface {
 	GetStats() map[string]interface{}
 }
 
+// DuplicateDetectionInterface defines what the handlers need from duplicate detection
+type DuplicateDetectionInterface interface {
+	ListProcessedMessages(ctx context.Context, query service.ProcessedMessageQuery) (*service.ProcessedMessagePage, error)
+	ClearProcessedMessages(ctx context.Context) (int, error)
+}
+
+// DeadLetterQueueInterface defines what the handlers need from the dead letter queue
+type DeadLetterQueueInterface interface {
+	GetDeadLetterMessagesFiltered(reason string, since, until time.Time) []utils.DeadLetterMessage
+}
+
+// Default and maximum page sizes for the duplicates endpoint
+const (
+	defaultDuplicatesPageSize = 50
+	maxDuplicatesPageSize     = 500
+)
+
 // Handlers contains all HTTP handlers for the confirmation service
 type Handlers struct {
 	confirmationService ConfirmationServiceInterface
 	kafkaConsumer       service.KafkaConsumerInterface
+	duplicateDetection  DuplicateDetectionInterface
+	deadLetterQueue     DeadLetterQueueInterface
 	logger              *logger.Logger
 	metrics             *metrics.Metrics
 	startTime           time.Time
+	startupGracePeriod  time.Duration
+	limits              config.RuntimeLimits
+	config              *config.Config
+	healthCheckers      *HealthCheckRegistry
 }
 
 // HandlerConfig represents the configuration for API handlers
 type HandlerConfig struct {
 	ConfirmationService ConfirmationServiceInterface
 	KafkaConsumer       service.KafkaConsumerInterface
+	DuplicateDetection  DuplicateDetectionInterface // Optional; /duplicates and /admin/dedupe/clear return 503 without it
+	DeadLetterQueue     DeadLetterQueueInterface    // Optional; /admin/dlq returns 503 without it
 	Logger              *logger.Logger
 	Metrics             *metrics.Metrics
+	StartupGracePeriod  time.Duration
+	Limits              config.RuntimeLimits
+	Config              *config.Config  // Optional; /admin/config returns 503 without it
+	HealthCheckers      []HealthChecker // Readiness checks run after the Kafka and Execution Service checks
 }
 
 // HealthResponse represents the response structure for health endpoints
@@ -45,12 +81,43 @@ type HealthResponse struct {
 	Uptime    string                 `json:"uptime"`
 	Checks    map[string]HealthCheck `json:"checks,omitempty"`
 	Message   string                 `json:"message,omitempty"`
+	Paused    bool                   `json:"paused,omitempty"` // Ready, but Kafka consumption is paused
 	RequestID string                 `json:"requestId,omitempty"`
 }
 
+// ConsumerStateResponse represents the response structure for the consumer admin endpoints
+type ConsumerStateResponse struct {
+	Paused    bool      `json:"paused"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+// DedupeClearResponse represents the response structure for the duplicate detection clear endpoint
+type DedupeClearResponse struct {
+	Cleared   int       `json:"cleared"`
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+// StartupResponse represents the response structure for the startup endpoint
+type StartupResponse struct {
+	Status               string    `json:"status"`
+	Timestamp            time.Time `json:"timestamp"`
+	Service              string    `json:"service"`
+	Uptime               string    `json:"uptime"`
+	GracePeriod          string    `json:"gracePeriod"`
+	GracePeriodRemaining string    `json:"gracePeriodRemaining"`
+	KafkaConsumerRunning bool      `json:"kafkaConsumerRunning"`
+	Message              string    `json:"message,omitempty"`
+	RequestID            string    `json:"requestId,omitempty"`
+}
+
 // HealthCheck represents an individual health check result
 type HealthCheck struct {
 	Status    string        `json:"status"`
+	Critical  bool          `json:"critical"`
 	Message   string        `json:"message,omitempty"`
 	Duration  time.Duration `json:"duration"`
 	Timestamp time.Time     `json:"timestamp"`
@@ -67,26 +134,78 @@ type StatsResponse struct {
 	RequestID   string                 `json:"requestId,omitempty"`
 }
 
+// LimitsResponse represents the response structure for the limits endpoint
+type LimitsResponse struct {
+	Service   string               `json:"service"`
+	Timestamp time.Time            `json:"timestamp"`
+	Limits    config.RuntimeLimits `json:"limits"`
+	RequestID string               `json:"requestId,omitempty"`
+}
+
+// DuplicatesResponse represents the response structure for the duplicates endpoint
+type DuplicatesResponse struct {
+	Service     string                     `json:"service"`
+	Timestamp   time.Time                  `json:"timestamp"`
+	ExecutionID int64                      `json:"executionId,omitempty"`
+	Offset      int                        `json:"offset"`
+	Limit       int                        `json:"limit"`
+	Total       int                        `json:"total"`
+	Entries     []service.ProcessedMessage `json:"entries"`
+	RequestID   string                     `json:"requestId,omitempty"`
+}
+
+// DeadLetterQueueResponse represents the response structure for the dead letter queue endpoint
+type DeadLetterQueueResponse struct {
+	Service   string                    `json:"service"`
+	Timestamp time.Time                 `json:"timestamp"`
+	Reason    string                    `json:"reason,omitempty"`
+	Since     *time.Time                `json:"since,omitempty"`
+	Until     *time.Time                `json:"until,omitempty"`
+	Total     int                       `json:"total"`
+	Messages  []utils.DeadLetterMessage `json:"messages"`
+	RequestID string                    `json:"requestId,omitempty"`
+}
+
 // ErrorResponse represents the standard error response structure
 type ErrorResponse struct {
-	Error     string    `json:"error"`
-	Message   string    `json:"message"`
-	Timestamp time.Time `json:"timestamp"`
-	RequestID string    `json:"requestId,omitempty"`
-	Code      int       `json:"code"`
+	Error     string              `json:"error"`
+	Message   string              `json:"message"`
+	Timestamp time.Time           `json:"timestamp"`
+	RequestID string              `json:"requestId,omitempty"`
+	Code      int                 `json:"code"`
+	Errors    []domain.FieldError `json:"errors,omitempty"`
 }
 
 // NewHandlers creates a new handlers instance
 func NewHandlers(config HandlerConfig) *Handlers {
+	healthCheckers := NewHealthCheckRegistry(
+		kafkaHealthChecker{consumer: config.KafkaConsumer},
+		executionServiceHealthChecker{confirmationService: config.ConfirmationService},
+	)
+	for _, checker := range config.HealthCheckers {
+		healthCheckers.Register(checker)
+	}
+
 	return &Handlers{
 		confirmationService: config.ConfirmationService,
 		kafkaConsumer:       config.KafkaConsumer,
+		duplicateDetection:  config.DuplicateDetection,
+		deadLetterQueue:     config.DeadLetterQueue,
 		logger:              config.Logger,
 		metrics:             config.Metrics,
 		startTime:           time.Now(),
+		startupGracePeriod:  config.StartupGracePeriod,
+		limits:              config.Limits,
+		config:              config.Config,
+		healthCheckers:      healthCheckers,
 	}
 }
 
+// RegisterHealthChecker adds a readiness check, replacing any with the same name
+func (h *Handlers) RegisterHealthChecker(checker HealthChecker) {
+	h.healthCheckers.Register(checker)
+}
+
 // LivenessHandler implements the /health/live endpoint
 // Returns 200 OK if the service is running (basic liveness check)
 func (h *Handlers) LivenessHandler(w http.ResponseWriter, r *http.Request) {
@@ -99,7 +218,7 @@ func (h *Handlers) LivenessHandler(w http.ResponseWriter, r *http.Request) {
 		Status:    "UP",
 		Timestamp: time.Now(),
 		Service:   "globeco-confirmation-service",
-		Version:   "1.0.0", // TODO: Get from build info
+		Version:   buildinfo.Version,
 		Uptime:    time.Since(h.startTime).String(),
 		Message:   "Service is alive and running",
 		RequestID: correlationID,
@@ -113,16 +232,79 @@ func (h *Handlers) LivenessHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
 		h.logger.WithContext(ctx).Error("Failed to encode liveness response", zap.Error(err))
 	}
 
 	h.logger.WithContext(ctx).Debug("Liveness check completed successfully")
 }
 
+// StartupHandler implements the /health/startup endpoint
+// Returns 200 OK once the startup grace period has elapsed and the Kafka consumer is running
+// Returns 503 Service Unavailable while the service is still starting up
+func (h *Handlers) StartupHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := logger.GetCorrelationID(ctx)
+
+	h.logger.WithContext(ctx).Debug("Startup check requested")
+
+	checkStart := time.Now()
+	uptime := time.Since(h.startTime)
+	remaining := h.startupGracePeriod - uptime
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	kafkaRunning := h.kafkaConsumer != nil && h.kafkaConsumer.IsRunning()
+	started := remaining == 0 && kafkaRunning
+
+	response := StartupResponse{
+		Status:               getStatusString(started),
+		Timestamp:            time.Now(),
+		Service:              "globeco-confirmation-service",
+		Uptime:               uptime.String(),
+		GracePeriod:          h.startupGracePeriod.String(),
+		GracePeriodRemaining: remaining.String(),
+		KafkaConsumerRunning: kafkaRunning,
+		RequestID:            correlationID,
+	}
+
+	statusCode := http.StatusOK
+	switch {
+	case started:
+		response.Message = "Service has started"
+	case remaining > 0:
+		statusCode = http.StatusServiceUnavailable
+		response.Message = "Service is starting - grace period has not elapsed"
+	default:
+		statusCode = http.StatusServiceUnavailable
+		response.Message = "Service is starting - Kafka consumer is not running"
+	}
+
+	// Record health check metrics
+	if h.metrics != nil {
+		h.metrics.RecordHealthCheck("startup", started, time.Since(checkStart))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
+		h.logger.WithContext(ctx).Error("Failed to encode startup response", zap.Error(err))
+	}
+
+	h.logger.WithContext(ctx).Debug("Startup check completed",
+		zap.Bool("started", started),
+		zap.Bool("kafka_consumer_running", kafkaRunning),
+		zap.Duration("grace_period_remaining", remaining),
+	)
+}
+
 // ReadinessHandler implements the /health/ready endpoint
-// Returns 200 OK if service can connect to dependencies (Kafka and Execution Service)
-// Returns 503 Service Unavailable if dependencies are unreachable
+// Returns 200 OK if every registered dependency check (Kafka and Execution Service by default) is UP
+// Returns 200 OK with status DEGRADED if only non-critical dependencies are unreachable
+// Returns 503 Service Unavailable if critical dependencies are unreachable
+// A paused consumer stays ready; the response reports paused and a PAUSED consumption check
 func (h *Handlers) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	correlationID := logger.GetCorrelationID(ctx)
@@ -137,68 +319,63 @@ func (h *Handlers) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
 	overallStatus := "UP"
 	statusCode := http.StatusOK
 
-	// Check Kafka connectivity
-	kafkaStart := time.Now()
-	kafkaHealthy := false
-	kafkaMessage := "Kafka connection failed"
-
-	if h.kafkaConsumer != nil {
-		kafkaHealthy = h.kafkaConsumer.IsHealthy(checkCtx)
-		if kafkaHealthy {
-			kafkaMessage = "Kafka connection healthy"
+	// Run the registered dependency checks. A failed critical check makes the service
+	// not ready; failed non-critical checks leave it serving but DEGRADED.
+	var failedChecks []string
+	criticalFailed := false
+	for _, checker := range h.healthCheckers.Checkers() {
+		start := time.Now()
+		check := checker.Check(checkCtx)
+		check.Duration = time.Since(start)
+		check.Timestamp = time.Now()
+		check.Critical = checker.Critical()
+
+		checks[checker.Name()] = check
+		if check.Status != "UP" {
+			failedChecks = append(failedChecks, checker.Name())
+			criticalFailed = criticalFailed || check.Critical
 		}
-	} else {
-		kafkaMessage = "Kafka consumer not initialized"
 	}
 
-	checks["kafka"] = HealthCheck{
-		Status:    getStatusString(kafkaHealthy),
-		Message:   kafkaMessage,
-		Duration:  time.Since(kafkaStart),
-		Timestamp: time.Now(),
-	}
-
-	// Check Execution Service connectivity
-	executionStart := time.Now()
-	executionHealthy := false
-	executionMessage := "Execution Service connection failed"
-
-	if h.confirmationService != nil {
-		executionHealthy = h.confirmationService.IsHealthy(checkCtx)
-		if executionHealthy {
-			executionMessage = "Execution Service connection healthy"
+	// A paused consumer is still ready; report the pause so it can be told apart
+	paused := h.kafkaConsumer != nil && h.kafkaConsumer.IsPaused()
+	if paused {
+		checks["consumption"] = HealthCheck{
+			Status:    "PAUSED",
+			Message:   "Kafka consumption is paused",
+			Timestamp: time.Now(),
 		}
-	} else {
-		executionMessage = "Confirmation service not initialized"
-	}
-
-	checks["execution_service"] = HealthCheck{
-		Status:    getStatusString(executionHealthy),
-		Message:   executionMessage,
-		Duration:  time.Since(executionStart),
-		Timestamp: time.Now(),
 	}
 
 	// Determine overall status
-	if !kafkaHealthy || !executionHealthy {
+	switch {
+	case criticalFailed:
 		overallStatus = "DOWN"
 		statusCode = http.StatusServiceUnavailable
+	case len(failedChecks) > 0:
+		overallStatus = "DEGRADED"
 	}
 
 	response := HealthResponse{
 		Status:    overallStatus,
 		Timestamp: time.Now(),
 		Service:   "globeco-confirmation-service",
-		Version:   "1.0.0", // TODO: Get from build info
+		Version:   buildinfo.Version,
 		Uptime:    time.Since(h.startTime).String(),
 		Checks:    checks,
+		Paused:    paused,
 		RequestID: correlationID,
 	}
 
-	if overallStatus == "UP" {
-		response.Message = "Service is ready to accept traffic"
-	} else {
+	switch {
+	case overallStatus == "DOWN":
 		response.Message = "Service is not ready - dependency checks failed"
+	case overallStatus == "DEGRADED":
+		response.Message = "Service is ready but degraded - non-critical dependency checks failed"
+	case paused:
+		response.Message = "Service is ready but Kafka consumption is paused"
+	default:
+		response.Message = "Service is ready to accept traffic"
 	}
 
 	// Record health check metrics
@@ -209,20 +386,76 @@ func (h *Handlers) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
 		h.logger.WithContext(ctx).Error("Failed to encode readiness response", zap.Error(err))
 	}
 
 	h.logger.WithContext(ctx).Info("Readiness check completed",
 		zap.String("overall_status", overallStatus),
-		zap.Bool("kafka_healthy", kafkaHealthy),
-		zap.Bool("execution_service_healthy", executionHealthy),
+		zap.Strings("failed_checks", failedChecks),
+		zap.Bool("paused", paused),
 	)
 }
 
+// ConsumerPauseHandler implements the POST /admin/consumer/pause endpoint, which stops
+// the Kafka consumer fetching messages until it is resumed
+func (h *Handlers) ConsumerPauseHandler(w http.ResponseWriter, r *http.Request) {
+	if h.kafkaConsumer == nil {
+		h.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Kafka consumer not initialized", nil)
+		return
+	}
+
+	h.kafkaConsumer.Pause(r.Context())
+	h.writeConsumerState(w, r, "Kafka consumption paused")
+}
+
+// ConsumerResumeHandler implements the POST /admin/consumer/resume endpoint, which lifts
+// an operator pause. Consumption stays paused while dead letter queue backpressure applies.
+func (h *Handlers) ConsumerResumeHandler(w http.ResponseWriter, r *http.Request) {
+	if h.kafkaConsumer == nil {
+		h.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Kafka consumer not initialized", nil)
+		return
+	}
+
+	h.kafkaConsumer.Resume(r.Context())
+
+	message := "Kafka consumption resumed"
+	if h.kafkaConsumer.IsPaused() {
+		message = "Operator pause lifted; Kafka consumption is still paused by dead letter queue backpressure"
+	}
+	h.writeConsumerState(w, r, message)
+}
+
+// writeConsumerState writes the consumer's paused state after an admin action
+func (h *Handlers) writeConsumerState(w http.ResponseWriter, r *http.Request, message string) {
+	ctx := r.Context()
+
+	response := ConsumerStateResponse{
+		Paused:    h.kafkaConsumer.IsPaused(),
+		Timestamp: time.Now(),
+		Message:   message,
+		RequestID: logger.GetCorrelationID(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
+		h.logger.WithContext(ctx).Error("Failed to encode consumer state response", zap.Error(err))
+	}
+}
+
 // MetricsHandler serves Prometheus metrics at /metrics endpoint
 func (h *Handlers) MetricsHandler() http.Handler {
-	return promhttp.Handler()
+	if h.metrics == nil {
+		return promhttp.Handler()
+	}
+
+	// Serve the Go runtime metrics alongside the application registry
+	return promhttp.HandlerFor(prometheus.Gatherers{
+		prometheus.DefaultGatherer,
+		h.metrics.Gatherer(),
+	}, promhttp.HandlerOpts{})
 }
 
 // StatsHandler implements the /stats endpoint for operational statistics
@@ -255,7 +488,7 @@ func (h *Handlers) StatsHandler(w http.ResponseWriter, r *http.Request) {
 		Service:     "globeco-confirmation-service",
 		Timestamp:   time.Now(),
 		Uptime:      time.Since(h.startTime).String(),
-		Version:     "1.0.0", // TODO: Get from build info
+		Version:     buildinfo.Version,
 		Environment: getEnvironment(),
 		Stats:       stats,
 		RequestID:   correlationID,
@@ -264,7 +497,7 @@ func (h *Handlers) StatsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
 		h.logger.WithContext(ctx).Error("Failed to encode stats response", zap.Error(err))
 		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to encode response", err)
 		return
@@ -273,6 +506,210 @@ func (h *Handlers) StatsHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.WithContext(ctx).Debug("Stats request completed successfully")
 }
 
+// LimitsHandler implements the /limits endpoint with the effective runtime limits
+func (h *Handlers) LimitsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := logger.GetCorrelationID(ctx)
+
+	response := LimitsResponse{
+		Service:   "globeco-confirmation-service",
+		Timestamp: time.Now(),
+		Limits:    h.limits,
+		RequestID: correlationID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
+		h.logger.WithContext(ctx).Error("Failed to encode limits response", zap.Error(err))
+	}
+}
+
+// DuplicatesHandler implements the /duplicates endpoint, returning a page of the
+// duplicate detection records, optionally filtered by ?executionId=
+func (h *Handlers) DuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := logger.GetCorrelationID(ctx)
+
+	if h.duplicateDetection == nil {
+		h.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Duplicate detection is not available", nil)
+		return
+	}
+
+	query := service.ProcessedMessageQuery{Limit: defaultDuplicatesPageSize}
+	params := r.URL.Query()
+
+	if value := params.Get("executionId"); value != "" {
+		executionID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || executionID < 1 {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, "executionId must be a positive integer", nil)
+			return
+		}
+		query.ExecutionServiceID = executionID
+	}
+
+	if value := params.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, "offset must be a non-negative integer", nil)
+			return
+		}
+		query.Offset = offset
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDuplicatesPageSize {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxDuplicatesPageSize), nil)
+			return
+		}
+		query.Limit = limit
+	}
+
+	page, err := h.duplicateDetection.ListProcessedMessages(ctx, query)
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list duplicate detection records", err)
+		return
+	}
+
+	response := DuplicatesResponse{
+		Service:     "globeco-confirmation-service",
+		Timestamp:   time.Now(),
+		ExecutionID: query.ExecutionServiceID,
+		Offset:      query.Offset,
+		Limit:       query.Limit,
+		Total:       page.Total,
+		Entries:     page.Messages,
+		RequestID:   correlationID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
+		h.logger.WithContext(ctx).Error("Failed to encode duplicates response", zap.Error(err))
+	}
+}
+
+// DeadLetterQueueHandler implements the /admin/dlq endpoint, returning the dead letter queue
+// messages, optionally filtered by ?reason= and an RFC 3339 ?since= and ?until= range
+func (h *Handlers) DeadLetterQueueHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	correlationID := logger.GetCorrelationID(ctx)
+
+	if h.deadLetterQueue == nil {
+		h.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Dead letter queue is not available", nil)
+		return
+	}
+
+	params := r.URL.Query()
+	response := DeadLetterQueueResponse{
+		Service:   "globeco-confirmation-service",
+		Reason:    params.Get("reason"),
+		RequestID: correlationID,
+	}
+
+	var since, until time.Time
+	if value := params.Get("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, "since must be an RFC 3339 timestamp", nil)
+			return
+		}
+		since = parsed
+		response.Since = &since
+	}
+
+	if value := params.Get("until"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, "until must be an RFC 3339 timestamp", nil)
+			return
+		}
+		until = parsed
+		response.Until = &until
+	}
+
+	if !since.IsZero() && !until.IsZero() && until.Before(since) {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "until must not be before since", nil)
+		return
+	}
+
+	response.Messages = h.deadLetterQueue.GetDeadLetterMessagesFiltered(response.Reason, since, until)
+	response.Total = len(response.Messages)
+	response.Timestamp = time.Now()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
+		h.logger.WithContext(ctx).Error("Failed to encode dead letter queue response", zap.Error(err))
+	}
+}
+
+// DedupeClearHandler implements the POST /admin/dedupe/clear endpoint, which removes
+// every duplicate detection record so previously seen fills are processed again
+func (h *Handlers) DedupeClearHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.duplicateDetection == nil {
+		h.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Duplicate detection is not available", nil)
+		return
+	}
+
+	cleared, err := h.duplicateDetection.ClearProcessedMessages(ctx)
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to clear duplicate detection records", err)
+		return
+	}
+
+	response := DedupeClearResponse{
+		Cleared:   cleared,
+		Timestamp: time.Now(),
+		Message:   "Duplicate detection records cleared",
+		RequestID: logger.GetCorrelationID(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
+		h.logger.WithContext(ctx).Error("Failed to encode dedupe clear response", zap.Error(err))
+	}
+}
+
+// ConfigResponse represents the response structure for the configuration endpoint
+type ConfigResponse struct {
+	Config    map[string]interface{} `json:"config"`
+	Timestamp time.Time              `json:"timestamp"`
+	RequestID string                 `json:"requestId,omitempty"`
+}
+
+// ConfigHandler implements the GET /admin/config endpoint, which returns the loaded
+// configuration with secrets such as passwords and tokens redacted
+func (h *Handlers) ConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if h.config == nil {
+		h.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Configuration is not available", nil)
+		return
+	}
+
+	response := ConfigResponse{
+		Config:    h.config.Redacted(),
+		Timestamp: time.Now(),
+		RequestID: logger.GetCorrelationID(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
+		h.logger.WithContext(ctx).Error("Failed to encode config response", zap.Error(err))
+	}
+}
+
 // VersionHandler implements the /version endpoint
 func (h *Handlers) VersionHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -280,10 +717,10 @@ func (h *Handlers) VersionHandler(w http.ResponseWriter, r *http.Request) {
 
 	response := map[string]interface{}{
 		"service":    "globeco-confirmation-service",
-		"version":    "1.0.0",                // TODO: Get from build info
-		"build_time": "2025-01-27T00:00:00Z", // TODO: Get from build info
-		"git_commit": "unknown",              // TODO: Get from build info
-		"go_version": "1.23.4",
+		"version":    buildinfo.Version,
+		"build_time": buildinfo.BuildTime,
+		"git_commit": buildinfo.GitCommit,
+		"go_version": buildinfo.GoVersion(),
 		"timestamp":  time.Now(),
 		"uptime":     time.Since(h.startTime).String(),
 		"request_id": correlationID,
@@ -292,7 +729,7 @@ func (h *Handlers) VersionHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
 		h.logger.WithContext(ctx).Error("Failed to encode version response", zap.Error(err))
 	}
 }
@@ -305,16 +742,19 @@ func (h *Handlers) RootHandler(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"service":     "GlobeCo Confirmation Service",
 		"description": "Microservice for processing fill messages from Kafka and updating the Execution Service",
-		"version":     "1.0.0",
+		"version":     buildinfo.Version,
 		"status":      "running",
 		"timestamp":   time.Now(),
 		"uptime":      time.Since(h.startTime).String(),
 		"endpoints": map[string]string{
-			"health_live":  "/health/live",
-			"health_ready": "/health/ready",
-			"metrics":      "/metrics",
-			"stats":        "/stats",
-			"version":      "/version",
+			"health_live":    "/health/live",
+			"health_ready":   "/health/ready",
+			"health_startup": "/health/startup",
+			"metrics":        "/metrics",
+			"stats":          "/stats",
+			"limits":         "/limits",
+			"duplicates":     "/duplicates",
+			"version":        "/version",
 		},
 		"request_id": correlationID,
 	}
@@ -322,7 +762,7 @@ func (h *Handlers) RootHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := newJSONEncoder(w, r).Encode(response); err != nil {
 		h.logger.WithContext(ctx).Error("Failed to encode root response", zap.Error(err))
 	}
 }
@@ -340,6 +780,12 @@ func (h *Handlers) writeErrorResponse(w http.ResponseWriter, r *http.Request, st
 		Code:      statusCode,
 	}
 
+	// Render structured validation failures when the error carries them
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		errorResponse.Errors = domainErr.FieldErrors
+	}
+
 	if err != nil {
 		h.logger.WithContext(ctx).Error("API error occurred",
 			zap.Int("status_code", statusCode),
@@ -351,13 +797,23 @@ func (h *Handlers) writeErrorResponse(w http.ResponseWriter, r *http.Request, st
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
-	if encodeErr := json.NewEncoder(w).Encode(errorResponse); encodeErr != nil {
+	if encodeErr := newJSONEncoder(w, r).Encode(errorResponse); encodeErr != nil {
 		h.logger.WithContext(ctx).Error("Failed to encode error response", zap.Error(encodeErr))
 	}
 }
 
 // Helper functions
 
+// newJSONEncoder returns an encoder for the response, indenting the output when the
+// request asks for ?pretty=true and writing compact JSON otherwise
+func newJSONEncoder(w http.ResponseWriter, r *http.Request) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	if pretty, err := strconv.ParseBool(r.URL.Query().Get("pretty")); err == nil && pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder
+}
+
 func getStatusString(healthy bool) string {
 	if healthy {
 		return "UP"
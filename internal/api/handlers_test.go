@@ -3,10 +3,22 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"strings"
 	"testing"
-
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kasbench/globeco-confirmation-service/internal/buildinfo"
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	custommiddleware "github.com/kasbench/globeco-confirmation-service/internal/middleware"
+	"github.com/kasbench/globeco-confirmation-service/internal/service"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
 	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
 	"github.com/stretchr/testify/assert"
@@ -31,6 +43,7 @@ func (m *MockConfirmationService) GetStats() map[string]interface{} {
 
 type MockKafkaConsumer struct {
 	mock.Mock
+	paused bool // Pause state is recorded rather than mocked so every test need not expect IsPaused
 }
 
 func (m *MockKafkaConsumer) Start(ctx context.Context) error {
@@ -48,11 +61,28 @@ func (m *MockKafkaConsumer) IsHealthy(ctx context.Context) bool {
 	return args.Bool(0)
 }
 
+func (m *MockKafkaConsumer) IsRunning() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
 func (m *MockKafkaConsumer) GetStats() map[string]interface{} {
 	args := m.Called()
 	return args.Get(0).(map[string]interface{})
 }
 
+func (m *MockKafkaConsumer) Pause(ctx context.Context) {
+	m.paused = true
+}
+
+func (m *MockKafkaConsumer) Resume(ctx context.Context) {
+	m.paused = false
+}
+
+func (m *MockKafkaConsumer) IsPaused() bool {
+	return m.paused
+}
+
 func setupTestHandlers(t *testing.T) (*Handlers, *MockConfirmationService, *MockKafkaConsumer) {
 	appLogger, err := logger.New(logger.Config{
 		Level:       "info",
@@ -107,13 +137,59 @@ func TestLivenessHandler(t *testing.T) {
 
 	assert.Equal(t, "UP", response.Status)
 	assert.Equal(t, "globeco-confirmation-service", response.Service)
-	assert.Equal(t, "1.0.0", response.Version)
+	assert.Equal(t, buildinfo.Version, response.Version)
 	assert.Equal(t, "Service is alive and running", response.Message)
 	assert.Equal(t, "test-correlation-id", response.RequestID)
 	assert.NotZero(t, response.Timestamp)
 	assert.NotEmpty(t, response.Uptime)
 }
 
+func TestStartupHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		gracePeriod    time.Duration
+		kafkaRunning   bool
+		expectedStatus int
+		expectedState  string
+	}{
+		{"started", 0, true, http.StatusOK, "UP"},
+		{"within grace period", time.Hour, true, http.StatusServiceUnavailable, "DOWN"},
+		{"kafka consumer not running", 0, false, http.StatusServiceUnavailable, "DOWN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers, _, mockKafkaConsumer := setupTestHandlers(t)
+			handlers.startupGracePeriod = tt.gracePeriod
+			mockKafkaConsumer.On("IsRunning").Return(tt.kafkaRunning)
+
+			req := httptest.NewRequest("GET", "/health/startup", nil)
+			w := httptest.NewRecorder()
+
+			handlers.StartupHandler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response StartupResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedState, response.Status)
+			assert.Equal(t, tt.kafkaRunning, response.KafkaConsumerRunning)
+			assert.Equal(t, tt.gracePeriod.String(), response.GracePeriod)
+
+			remaining, err := time.ParseDuration(response.GracePeriodRemaining)
+			require.NoError(t, err)
+			if tt.gracePeriod > 0 {
+				assert.Greater(t, remaining, time.Duration(0))
+				assert.LessOrEqual(t, remaining, tt.gracePeriod)
+			} else {
+				assert.Equal(t, time.Duration(0), remaining)
+			}
+		})
+	}
+}
+
 func TestReadinessHandler_Healthy(t *testing.T) {
 	handlers, mockConfirmationService, mockKafkaConsumer := setupTestHandlers(t)
 
@@ -201,6 +277,273 @@ func TestReadinessHandler_PartiallyHealthy(t *testing.T) {
 	mockConfirmationService.AssertExpectations(t)
 }
 
+// staticHealthChecker reports a fixed status
+type staticHealthChecker struct {
+	name     string
+	status   string
+	critical bool
+}
+
+func (c staticHealthChecker) Name() string {
+	return c.name
+}
+
+func (c staticHealthChecker) Critical() bool {
+	return c.critical
+}
+
+func (c staticHealthChecker) Check(ctx context.Context) HealthCheck {
+	return HealthCheck{Status: c.status, Message: c.name + " is " + c.status}
+}
+
+func TestReadinessHandler_RegisteredCheckerFails(t *testing.T) {
+	handlers, mockConfirmationService, mockKafkaConsumer := setupTestHandlers(t)
+
+	mockKafkaConsumer.On("IsHealthy", mock.AnythingOfType("*context.timerCtx")).Return(true)
+	mockConfirmationService.On("IsHealthy", mock.AnythingOfType("*context.timerCtx")).Return(true)
+	handlers.RegisterHealthChecker(staticHealthChecker{name: "redis", status: "DOWN", critical: true})
+
+	req := httptest.NewRequest("GET", "/health/ready", nil)
+	w := httptest.NewRecorder()
+
+	handlers.ReadinessHandler(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var response HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.Equal(t, "DOWN", response.Status)
+	assert.Equal(t, "UP", response.Checks["kafka"].Status)
+	assert.Equal(t, "UP", response.Checks["execution_service"].Status)
+	assert.Equal(t, "DOWN", response.Checks["redis"].Status)
+	assert.Equal(t, "redis is DOWN", response.Checks["redis"].Message)
+	assert.False(t, response.Checks["redis"].Timestamp.IsZero())
+}
+
+func TestReadinessHandler_CriticalAndNonCriticalChecks(t *testing.T) {
+	tests := []struct {
+		name               string
+		criticalHealthy    bool
+		nonCriticalHealthy bool
+		expectedStatus     string
+		expectedCode       int
+		expectedMessage    string
+	}{
+		{
+			name:               "all up",
+			criticalHealthy:    true,
+			nonCriticalHealthy: true,
+			expectedStatus:     "UP",
+			expectedCode:       http.StatusOK,
+			expectedMessage:    "Service is ready to accept traffic",
+		},
+		{
+			name:               "non-critical down",
+			criticalHealthy:    true,
+			nonCriticalHealthy: false,
+			expectedStatus:     "DEGRADED",
+			expectedCode:       http.StatusOK,
+			expectedMessage:    "Service is ready but degraded - non-critical dependency checks failed",
+		},
+		{
+			name:               "critical down",
+			criticalHealthy:    false,
+			nonCriticalHealthy: true,
+			expectedStatus:     "DOWN",
+			expectedCode:       http.StatusServiceUnavailable,
+			expectedMessage:    "Service is not ready - dependency checks failed",
+		},
+		{
+			name:               "both down",
+			criticalHealthy:    false,
+			nonCriticalHealthy: false,
+			expectedStatus:     "DOWN",
+			expectedCode:       http.StatusServiceUnavailable,
+			expectedMessage:    "Service is not ready - dependency checks failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers, mockConfirmationService, mockKafkaConsumer := setupTestHandlers(t)
+
+			// Kafka stays up; the Execution Service is the critical check under test
+			mockKafkaConsumer.On("IsHealthy", mock.AnythingOfType("*context.timerCtx")).Return(true)
+			mockConfirmationService.On("IsHealthy", mock.AnythingOfType("*context.timerCtx")).Return(tt.criticalHealthy)
+			handlers.RegisterHealthChecker(NewAllocationServiceHealthChecker(healthReporterFunc(func(ctx context.Context) bool {
+				return tt.nonCriticalHealthy
+			}), false))
+
+			req := httptest.NewRequest("GET", "/health/ready", nil)
+			w := httptest.NewRecorder()
+
+			handlers.ReadinessHandler(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			var response HealthResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			assert.Equal(t, tt.expectedMessage, response.Message)
+			assert.True(t, response.Checks["execution_service"].Critical)
+			assert.False(t, response.Checks["allocation_service"].Critical)
+			assert.Equal(t, getStatusString(tt.nonCriticalHealthy), response.Checks["allocation_service"].Status)
+		})
+	}
+}
+
+func TestHandlers_ReadinessHandler_RequiredAllocationServiceIsCritical(t *testing.T) {
+	handlers, mockConfirmationService, mockKafkaConsumer := setupTestHandlers(t)
+
+	mockKafkaConsumer.On("IsHealthy", mock.AnythingOfType("*context.timerCtx")).Return(true)
+	mockConfirmationService.On("IsHealthy", mock.AnythingOfType("*context.timerCtx")).Return(true)
+	handlers.RegisterHealthChecker(NewAllocationServiceHealthChecker(healthReporterFunc(func(ctx context.Context) bool {
+		return false
+	}), true))
+
+	req := httptest.NewRequest("GET", "/health/ready", nil)
+	w := httptest.NewRecorder()
+
+	handlers.ReadinessHandler(w, req)
+
+	// Fills cannot be committed while required allocation posts fail
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var response HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "DOWN", response.Status)
+	assert.True(t, response.Checks["allocation_service"].Critical)
+}
+
+// healthReporterFunc adapts a function to HealthReporter
+type healthReporterFunc func(ctx context.Context) bool
+
+func (f healthReporterFunc) IsHealthy(ctx context.Context) bool {
+	return f(ctx)
+}
+
+func TestHealthCheckRegistry_Register(t *testing.T) {
+	registry := NewHealthCheckRegistry(
+		staticHealthChecker{name: "kafka", status: "UP"},
+		staticHealthChecker{name: "redis", status: "DOWN"},
+	)
+
+	// Registering a name again replaces the checker in place
+	registry.Register(staticHealthChecker{name: "kafka", status: "DOWN"})
+	registry.Register(staticHealthChecker{name: "allocation_service", status: "UP"})
+
+	checkers := registry.Checkers()
+	require.Len(t, checkers, 3)
+	assert.Equal(t, "kafka", checkers[0].Name())
+	assert.Equal(t, "DOWN", checkers[0].Check(context.Background()).Status)
+	assert.Equal(t, "redis", checkers[1].Name())
+	assert.Equal(t, "allocation_service", checkers[2].Name())
+}
+
+func TestReadinessHandler_Paused(t *testing.T) {
+	handlers, mockConfirmationService, mockKafkaConsumer := setupTestHandlers(t)
+
+	mockKafkaConsumer.On("IsHealthy", mock.AnythingOfType("*context.timerCtx")).Return(true)
+	mockConfirmationService.On("IsHealthy", mock.AnythingOfType("*context.timerCtx")).Return(true)
+	mockKafkaConsumer.Pause(context.Background())
+
+	req := httptest.NewRequest("GET", "/health/ready", nil)
+	w := httptest.NewRecorder()
+
+	handlers.ReadinessHandler(w, req)
+
+	// Ready, but distinguishable from an active consumer
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.Equal(t, "UP", response.Status)
+	assert.True(t, response.Paused)
+	assert.Equal(t, "Service is ready but Kafka consumption is paused", response.Message)
+	assert.Equal(t, "PAUSED", response.Checks["consumption"].Status)
+}
+
+func TestConsumerPauseAndResumeHandlers(t *testing.T) {
+	handlers, _, mockKafkaConsumer := setupTestHandlers(t)
+	router := NewRouter(RouterConfig{Handlers: handlers, AdminEndpointsEnabled: true})
+
+	post := func(path string) ConsumerStateResponse {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response ConsumerStateResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	response := post("/admin/consumer/pause")
+	assert.True(t, response.Paused)
+	assert.Equal(t, "Kafka consumption paused", response.Message)
+	assert.True(t, mockKafkaConsumer.IsPaused())
+
+	response = post("/admin/consumer/resume")
+	assert.False(t, response.Paused)
+	assert.Equal(t, "Kafka consumption resumed", response.Message)
+	assert.False(t, mockKafkaConsumer.IsPaused())
+
+	// The admin endpoints only accept POST
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/consumer/pause", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestRouter_RecoversHandlerPanics(t *testing.T) {
+	handlers, _, _ := setupTestHandlers(t)
+	router := NewRouter(RouterConfig{Handlers: handlers})
+	router.(chi.Router).Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+
+	// Later requests are still served
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRouter_LimitsWriteEndpointBodies(t *testing.T) {
+	handlers, _, mockKafkaConsumer := setupTestHandlers(t)
+	router := NewRouter(RouterConfig{Handlers: handlers, AdminEndpointsEnabled: true, MaxBodyBytes: 64})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer/pause", strings.NewReader(strings.Repeat("x", 65))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.False(t, mockKafkaConsumer.IsPaused())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer/pause", strings.NewReader("{}")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, mockKafkaConsumer.IsPaused())
+}
+
+func TestConsumerAdminEndpoints_Disabled(t *testing.T) {
+	handlers, _, mockKafkaConsumer := setupTestHandlers(t)
+	router := NewRouter(RouterConfig{Handlers: handlers})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer/pause", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.False(t, mockKafkaConsumer.IsPaused())
+}
+
 func TestStatsHandler(t *testing.T) {
 	handlers, mockConfirmationService, mockKafkaConsumer := setupTestHandlers(t)
 
@@ -231,7 +574,7 @@ func TestStatsHandler(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, "globeco-confirmation-service", response.Service)
-	assert.Equal(t, "1.0.0", response.Version)
+	assert.Equal(t, buildinfo.Version, response.Version)
 	assert.Equal(t, "development", response.Environment)
 	assert.Equal(t, "test-correlation-id", response.RequestID)
 	assert.Contains(t, response.Stats, "globeco-confirmation_service")
@@ -259,13 +602,279 @@ func TestVersionHandler(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, "globeco-confirmation-service", response["service"])
-	assert.Equal(t, "1.0.0", response["version"])
-	assert.Equal(t, "1.23.4", response["go_version"])
+	assert.Equal(t, buildinfo.Version, response["version"])
+	assert.Equal(t, runtime.Version(), response["go_version"])
 	assert.Equal(t, "test-correlation-id", response["request_id"])
 	assert.NotNil(t, response["timestamp"])
 	assert.NotNil(t, response["uptime"])
 }
 
+func TestVersionHandler_ReflectsBuildInfo(t *testing.T) {
+	originalVersion, originalCommit, originalBuildTime := buildinfo.Version, buildinfo.GitCommit, buildinfo.BuildTime
+	buildinfo.Version, buildinfo.GitCommit, buildinfo.BuildTime = "2.3.4", "abc1234", "2025-06-01T12:00:00Z"
+	t.Cleanup(func() {
+		buildinfo.Version, buildinfo.GitCommit, buildinfo.BuildTime = originalVersion, originalCommit, originalBuildTime
+	})
+
+	handlers, _, _ := setupTestHandlers(t)
+
+	req := httptest.NewRequest("GET", "/version", nil)
+	w := httptest.NewRecorder()
+
+	handlers.VersionHandler(w, req)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2.3.4", response["version"])
+	assert.Equal(t, "abc1234", response["git_commit"])
+	assert.Equal(t, "2025-06-01T12:00:00Z", response["build_time"])
+
+	// Other handlers report the same version
+	w = httptest.NewRecorder()
+	handlers.LivenessHandler(w, httptest.NewRequest("GET", "/health/live", nil))
+
+	var health HealthResponse
+	err = json.Unmarshal(w.Body.Bytes(), &health)
+	require.NoError(t, err)
+	assert.Equal(t, "2.3.4", health.Version)
+}
+
+func TestLimitsHandler(t *testing.T) {
+	handlers, _, _ := setupTestHandlers(t)
+
+	cfg := config.GetDefaults()
+	cfg.Performance.MaxConcurrentRequests = 25
+	cfg.Performance.DeadLetterQueueMaxSize = 250
+	cfg.Performance.DuplicateDetectionMaxEntries = 5000
+	cfg.ExecutionService.Timeout = 4 * time.Second
+	cfg.ExecutionService.UpdateTimeout = 7 * time.Second
+	cfg.ExecutionService.MaxRetries = 6
+	cfg.ExecutionService.MaxConflictRetries = 2
+	handlers.limits = cfg.GetRuntimeLimits()
+
+	req := httptest.NewRequest("GET", "/limits", nil)
+	req = req.WithContext(logger.WithCorrelationIDContext(context.Background(), "test-correlation-id"))
+	w := httptest.NewRecorder()
+
+	handlers.LimitsHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response LimitsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "test-correlation-id", response.RequestID)
+	assert.Equal(t, 25, response.Limits.Concurrency.MaxConcurrentRequests)
+	assert.Equal(t, 250, response.Limits.Capacity.DeadLetterQueueMaxSize)
+	assert.Equal(t, 5000, response.Limits.Capacity.DuplicateDetectionMaxEntries)
+	assert.Equal(t, "4s", response.Limits.Timeouts.ExecutionServiceGet)
+	assert.Equal(t, "7s", response.Limits.Timeouts.ExecutionServiceUpdate)
+	assert.Equal(t, 6, response.Limits.Retries.ExecutionService)
+	assert.Equal(t, 2, response.Limits.Retries.MaxConflictRetries)
+}
+
+func TestHandlers_PrettyJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		expectPretty bool
+	}{
+		{"compact by default", "/version", false},
+		{"pretty requested", "/version?pretty=true", true},
+		{"pretty disabled", "/version?pretty=false", false},
+		{"pretty malformed", "/version?pretty=yes please", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers, _, _ := setupTestHandlers(t)
+
+			req := httptest.NewRequest("GET", strings.ReplaceAll(tt.target, " ", "%20"), nil)
+			w := httptest.NewRecorder()
+
+			handlers.VersionHandler(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectPretty, strings.Contains(w.Body.String(), "\n  \""))
+			assert.True(t, json.Valid(w.Body.Bytes()))
+		})
+	}
+}
+
+func TestDuplicatesHandler(t *testing.T) {
+	handlers, _, _ := setupTestHandlers(t)
+
+	duplicateDetection := service.NewDuplicateDetectionService(service.DuplicateDetectionConfig{Logger: handlers.logger})
+	defer duplicateDetection.Stop()
+	handlers.duplicateDetection = duplicateDetection
+
+	ctx := context.Background()
+	for i := int64(1); i <= 3; i++ {
+		duplicateDetection.RecordProcessedMessage(ctx, &domain.Fill{ID: i, ExecutionServiceID: 10 + i%2}, true, time.Millisecond, "")
+	}
+
+	t.Run("filters by execution ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/duplicates?executionId=11", nil)
+		w := httptest.NewRecorder()
+
+		handlers.DuplicatesHandler(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response DuplicatesResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(11), response.ExecutionID)
+		assert.Equal(t, 2, response.Total)
+		assert.Equal(t, defaultDuplicatesPageSize, response.Limit)
+		require.Len(t, response.Entries, 2)
+		for _, entry := range response.Entries {
+			assert.Equal(t, int64(11), entry.ExecutionServiceID)
+			assert.True(t, entry.Success)
+		}
+	})
+
+	t.Run("paginates", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/duplicates?offset=1&limit=1", nil)
+		w := httptest.NewRecorder()
+
+		handlers.DuplicatesHandler(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response DuplicatesResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.Total)
+		assert.Equal(t, 1, response.Offset)
+		assert.Len(t, response.Entries, 1)
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, target := range []string{"/duplicates?executionId=abc", "/duplicates?offset=-1", "/duplicates?limit=0", "/duplicates?limit=501"} {
+			req := httptest.NewRequest("GET", target, nil)
+			w := httptest.NewRecorder()
+
+			handlers.DuplicatesHandler(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, target)
+		}
+	})
+
+	t.Run("unavailable without duplicate detection", func(t *testing.T) {
+		handlers, _, _ := setupTestHandlers(t)
+
+		req := httptest.NewRequest("GET", "/duplicates", nil)
+		w := httptest.NewRecorder()
+
+		handlers.DuplicatesHandler(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+// stubDeadLetterQueue records the filter it was called with
+type stubDeadLetterQueue struct {
+	messages     []utils.DeadLetterMessage
+	reason       string
+	since, until time.Time
+}
+
+func (s *stubDeadLetterQueue) GetDeadLetterMessagesFiltered(reason string, since, until time.Time) []utils.DeadLetterMessage {
+	s.reason, s.since, s.until = reason, since, until
+	return s.messages
+}
+
+func TestDeadLetterQueueHandler(t *testing.T) {
+	handlers, _, _ := setupTestHandlers(t)
+	router := NewRouter(RouterConfig{Handlers: handlers, AdminEndpointsEnabled: true})
+
+	t.Run("unavailable without a dead letter queue", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/dlq", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	dlq := &stubDeadLetterQueue{messages: []utils.DeadLetterMessage{{ID: "dlq-1", FailureReason: "poison message"}}}
+	handlers.deadLetterQueue = dlq
+
+	t.Run("passes filters through", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/dlq?reason=poison%20message&since=2026-01-02T09:00:00Z&until=2026-01-02T10:00:00Z", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		assert.Equal(t, "poison message", dlq.reason)
+		assert.Equal(t, time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC), dlq.since)
+		assert.Equal(t, time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC), dlq.until)
+
+		var response DeadLetterQueueResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "poison message", response.Reason)
+		assert.Equal(t, 1, response.Total)
+		require.Len(t, response.Messages, 1)
+		assert.Equal(t, "dlq-1", response.Messages[0].ID)
+	})
+
+	t.Run("unfiltered", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/dlq", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		assert.Empty(t, dlq.reason)
+		assert.True(t, dlq.since.IsZero())
+		assert.True(t, dlq.until.IsZero())
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, target := range []string{"/admin/dlq?since=yesterday", "/admin/dlq?until=2026-01-02", "/admin/dlq?since=2026-01-02T10:00:00Z&until=2026-01-02T09:00:00Z"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, target)
+		}
+	})
+
+	t.Run("disabled along with the other admin endpoints", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		NewRouter(RouterConfig{Handlers: handlers}).ServeHTTP(w, httptest.NewRequest("GET", "/admin/dlq", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDedupeClearHandler(t *testing.T) {
+	handlers, _, _ := setupTestHandlers(t)
+	router := NewRouter(RouterConfig{Handlers: handlers, AdminEndpointsEnabled: true})
+
+	// Without duplicate detection the endpoint is unavailable
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/dedupe/clear", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	duplicateDetection := service.NewDuplicateDetectionService(service.DuplicateDetectionConfig{Logger: handlers.logger})
+	defer duplicateDetection.Stop()
+	handlers.duplicateDetection = duplicateDetection
+
+	ctx := context.Background()
+	for i := int64(1); i <= 3; i++ {
+		duplicateDetection.RecordProcessedMessage(ctx, &domain.Fill{ID: i, ExecutionServiceID: 10}, true, time.Millisecond, "")
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/dedupe/clear", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response DedupeClearResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Cleared)
+	assert.Equal(t, 0, duplicateDetection.GetProcessedMessageStats()["total_messages"])
+
+	// Disabled along with the other admin endpoints
+	w = httptest.NewRecorder()
+	NewRouter(RouterConfig{Handlers: handlers}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/dedupe/clear", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestRootHandler(t *testing.T) {
 	handlers, _, _ := setupTestHandlers(t)
 
@@ -283,7 +892,7 @@ func TestRootHandler(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, "GlobeCo Confirmation Service", response["service"])
-	assert.Equal(t, "1.0.0", response["version"])
+	assert.Equal(t, buildinfo.Version, response["version"])
 	assert.Equal(t, "running", response["status"])
 	assert.Equal(t, "test-correlation-id", response["request_id"])
 	assert.Contains(t, response, "endpoints")
@@ -312,6 +921,27 @@ func TestMetricsHandler(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "# HELP")
 }
 
+func TestMetricsHandler_ExportsApplicationMetricsWithConstLabels(t *testing.T) {
+	appMetrics := metrics.New(metrics.Config{
+		Namespace: "test",
+		Enabled:   true,
+		ConstLabels: map[string]string{
+			"environment":     "production",
+			"service_version": "2.0.0",
+		},
+	})
+	appMetrics.RecordMessageProcessed()
+
+	handlers := NewHandlers(HandlerConfig{Metrics: appMetrics})
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handlers.MetricsHandler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `test_messages_processed_total{environment="production",service_version="2.0.0"} 1`)
+}
+
 func TestWriteErrorResponse(t *testing.T) {
 	handlers, _, _ := setupTestHandlers(t)
 
@@ -335,6 +965,46 @@ func TestWriteErrorResponse(t *testing.T) {
 	assert.NotZero(t, response.Timestamp)
 }
 
+func TestWriteErrorResponse_FieldErrors(t *testing.T) {
+	handlers, _, _ := setupTestHandlers(t)
+
+	req := httptest.NewRequest("POST", "/test", nil)
+	w := httptest.NewRecorder()
+
+	validationErr := domain.NewValidationError("comprehensive_validation_failed", "ticker: ticker is required").
+		WithFieldErrors([]domain.FieldError{
+			{Field: "ticker", Code: "REQUIRED_FIELD", Message: "ticker is required"},
+			{Field: "quantity", Code: "INVALID_TYPE", Message: "quantity must be positive"},
+		})
+
+	handlers.writeErrorResponse(w, req, http.StatusBadRequest, validationErr.Error(), fmt.Errorf("wrapped: %w", validationErr))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+	// The summary string remains the top-level message
+	assert.Equal(t, validationErr.Error(), body["message"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "ticker", "code": "REQUIRED_FIELD", "message": "ticker is required"},
+		map[string]interface{}{"field": "quantity", "code": "INVALID_TYPE", "message": "quantity must be positive"},
+	}, body["errors"])
+}
+
+func TestWriteErrorResponse_NoFieldErrors(t *testing.T) {
+	handlers, _, _ := setupTestHandlers(t)
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+
+	handlers.writeErrorResponse(w, req, http.StatusInternalServerError, "Internal error", errors.New("boom"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.NotContains(t, body, "errors")
+}
+
 func TestGetStatusString(t *testing.T) {
 	assert.Equal(t, "UP", getStatusString(true))
 	assert.Equal(t, "DOWN", getStatusString(false))
@@ -344,3 +1014,71 @@ func TestGetEnvironment(t *testing.T) {
 	env := getEnvironment()
 	assert.Equal(t, "development", env)
 }
+
+func TestConfigHandler(t *testing.T) {
+	handlers, _, _ := setupTestHandlers(t)
+	router := NewRouter(RouterConfig{Handlers: handlers, AdminEndpointsEnabled: true})
+
+	// Without a configuration the endpoint is unavailable
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	cfg := config.GetDefaults()
+	cfg.Redis.Password = "hunter2"
+	handlers.config = cfg
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "hunter2")
+
+	var response ConfigResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	redis := response.Config["redis"].(map[string]interface{})
+	assert.Equal(t, logger.RedactedValue, redis["password"])
+	assert.Equal(t, cfg.Redis.KeyPrefix, redis["key_prefix"])
+
+	// Disabled along with the other admin endpoints
+	w = httptest.NewRecorder()
+	NewRouter(RouterConfig{Handlers: handlers}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRouter_RateLimitsByDirectPeerUnlessTrustedProxy(t *testing.T) {
+	handlers, _, _ := setupTestHandlers(t)
+	trustedProxies, err := custommiddleware.ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	router := NewRouter(RouterConfig{Handlers: handlers, RateLimiter: custommiddleware.NewClientRateLimiter(custommiddleware.RateLimitConfig{
+		RequestsPerSecond: 1,
+		Burst:             1,
+		Done:              done,
+		TrustedProxies:    trustedProxies,
+	})})
+
+	get := func(path, remoteAddr, forwardedFor string) int {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// An untrusted peer cannot dodge its limit by spoofing X-Forwarded-For
+	assert.Equal(t, http.StatusOK, get("/version", "203.0.113.7:4000", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, get("/version", "203.0.113.7:4001", "198.51.100.2"))
+
+	// Behind a trusted proxy each forwarded client has its own bucket
+	assert.Equal(t, http.StatusOK, get("/version", "10.0.0.5:5000", "198.51.100.3"))
+	assert.Equal(t, http.StatusOK, get("/version", "10.0.0.5:5001", "198.51.100.4"))
+	assert.Equal(t, http.StatusTooManyRequests, get("/version", "10.0.0.5:5002", "198.51.100.4"))
+
+	// Health probes are not rate limited
+	assert.Equal(t, http.StatusOK, get("/health/live", "203.0.113.7:4002", ""))
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/service"
+)
+
+// HealthChecker checks one dependency for the readiness endpoint. The handler fills in
+// the check's Duration, Timestamp and Critical flag. A critical check not UP makes the
+// service not ready; a non-critical one leaves it ready but DEGRADED.
+type HealthChecker interface {
+	Name() string
+	Critical() bool
+	Check(ctx context.Context) HealthCheck
+}
+
+// HealthReporter is a dependency client that can tell whether its dependency is healthy
+type HealthReporter interface {
+	IsHealthy(ctx context.Context) bool
+}
+
+// HealthCheckRegistry holds the readiness checks in registration order
+type HealthCheckRegistry struct {
+	mutex    sync.RWMutex
+	checkers []HealthChecker
+}
+
+// NewHealthCheckRegistry creates a registry holding the given checkers
+func NewHealthCheckRegistry(checkers ...HealthChecker) *HealthCheckRegistry {
+	registry := &HealthCheckRegistry{}
+	for _, checker := range checkers {
+		registry.Register(checker)
+	}
+	return registry
+}
+
+// Register adds a checker, replacing any registered under the same name
+func (r *HealthCheckRegistry) Register(checker HealthChecker) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, registered := range r.checkers {
+		if registered.Name() == checker.Name() {
+			r.checkers[i] = checker
+			return
+		}
+	}
+	r.checkers = append(r.checkers, checker)
+}
+
+// Checkers returns the registered checkers
+func (r *HealthCheckRegistry) Checkers() []HealthChecker {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return append([]HealthChecker(nil), r.checkers...)
+}
+
+// healthyCheck returns an UP check with the message, or a DOWN check with failedMessage
+func healthyCheck(healthy bool, message, failedMessage string) HealthCheck {
+	if !healthy {
+		message = failedMessage
+	}
+	return HealthCheck{Status: getStatusString(healthy), Message: message}
+}
+
+// kafkaHealthChecker checks the Kafka consumer's connection
+type kafkaHealthChecker struct {
+	consumer service.KafkaConsumerInterface
+}
+
+func (c kafkaHealthChecker) Name() string {
+	return "kafka"
+}
+
+func (c kafkaHealthChecker) Critical() bool {
+	return true
+}
+
+func (c kafkaHealthChecker) Check(ctx context.Context) HealthCheck {
+	if c.consumer == nil {
+		return HealthCheck{Status: "DOWN", Message: "Kafka consumer not initialized"}
+	}
+	return healthyCheck(c.consumer.IsHealthy(ctx), "Kafka connection healthy", "Kafka connection failed")
+}
+
+// executionServiceHealthChecker checks the Execution Service through the confirmation service
+type executionServiceHealthChecker struct {
+	confirmationService ConfirmationServiceInterface
+}
+
+func (c executionServiceHealthChecker) Name() string {
+	return "execution_service"
+}
+
+func (c executionServiceHealthChecker) Critical() bool {
+	return true
+}
+
+func (c executionServiceHealthChecker) Check(ctx context.Context) HealthCheck {
+	if c.confirmationService == nil {
+		return HealthCheck{Status: "DOWN", Message: "Confirmation service not initialized"}
+	}
+	return healthyCheck(c.confirmationService.IsHealthy(ctx), "Execution Service connection healthy", "Execution Service connection failed")
+}
+
+// allocationServiceHealthChecker checks the Allocation Service. It is only critical when
+// allocation posts are required: otherwise completed trades are retried while it is
+// down, so fills keep being confirmed.
+type allocationServiceHealthChecker struct {
+	client   HealthReporter
+	required bool
+}
+
+// NewAllocationServiceHealthChecker creates the Allocation Service readiness check,
+// critical when allocation posts are required
+func NewAllocationServiceHealthChecker(client HealthReporter, required bool) HealthChecker {
+	return allocationServiceHealthChecker{client: client, required: required}
+}
+
+func (c allocationServiceHealthChecker) Name() string {
+	return "allocation_service"
+}
+
+func (c allocationServiceHealthChecker) Critical() bool {
+	return c.required
+}
+
+func (c allocationServiceHealthChecker) Check(ctx context.Context) HealthCheck {
+	return healthyCheck(c.client.IsHealthy(ctx), "Allocation Service connection healthy", "Allocation Service connection failed")
+}
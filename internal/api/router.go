@@ -14,19 +14,27 @@ import (
 
 // RouterConfig represents the configuration for the HTTP router
 type RouterConfig struct {
-	Handlers *Handlers
-	Logger   *logger.Logger
-	Metrics  *metrics.Metrics
+	Handlers              *Handlers
+	Logger                *logger.Logger
+	Metrics               *metrics.Metrics
+	MaxConcurrentRequests int   // Limit on in-flight requests to operational endpoints; 0 disables it
+	AdminEndpointsEnabled bool  // Serve the /admin endpoints, which inspect or change the running service
+	MaxBodyBytes          int64 // Limit on request bodies sent to write endpoints; 0 disables it
+	CORS                  custommiddleware.CORSConfig
+	RateLimiter           *custommiddleware.ClientRateLimiter // Per-client-IP limit on operational endpoints; nil disables it
 }
 
 // NewRouter creates a new HTTP router with all endpoints and middleware configured
 func NewRouter(config RouterConfig) http.Handler {
 	r := chi.NewRouter()
 
+	// Recover panics first so the recovery wraps every other middleware
+	r.Use(custommiddleware.Recoverer(config.Logger, config.Metrics))
+
 	// Add built-in middleware
+	// RemoteAddr is left as the direct peer; the rate limiter only trusts forwarding
+	// headers from configured proxies
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(30 * time.Second))
 
 	// Add OpenTelemetry HTTP instrumentation middleware
@@ -44,24 +52,53 @@ func NewRouter(config RouterConfig) http.Handler {
 		r.Use(custommiddleware.MetricsMiddleware(config.Metrics))
 	}
 
-	// Add CORS middleware for development
-	r.Use(custommiddleware.CORS())
+	// Add CORS middleware; any origin is allowed unless an allowlist is configured
+	r.Use(custommiddleware.CORS(config.CORS))
 
 	// Health check endpoints (required by Kubernetes)
 	r.Route("/health", func(r chi.Router) {
 		r.Get("/live", config.Handlers.LivenessHandler)
 		r.Get("/ready", config.Handlers.ReadinessHandler)
+		r.Get("/startup", config.Handlers.StartupHandler)
 	})
 
 	// Metrics endpoint for Prometheus
 	r.Handle("/metrics", config.Handlers.MetricsHandler())
 
-	// Operational endpoints
-	r.Get("/stats", config.Handlers.StatsHandler)
-	r.Get("/version", config.Handlers.VersionHandler)
-
-	// Root endpoint
-	r.Get("/", config.Handlers.RootHandler)
+	// Operational endpoints are concurrency limited; health and metrics are not
+	// so probes and scrapes keep working while the service is busy
+	r.Group(func(r chi.Router) {
+		if config.RateLimiter != nil {
+			r.Use(config.RateLimiter.Middleware)
+		}
+		r.Use(custommiddleware.ConcurrencyLimiter(config.MaxConcurrentRequests))
+
+		r.Get("/stats", config.Handlers.StatsHandler)
+		r.Get("/limits", config.Handlers.LimitsHandler)
+		r.Get("/duplicates", config.Handlers.DuplicatesHandler)
+		r.Get("/version", config.Handlers.VersionHandler)
+
+		if config.AdminEndpointsEnabled {
+			r.Get("/admin/config", config.Handlers.ConfigHandler)
+
+			// Dead-lettered messages carry whole fill payloads
+			r.Get("/admin/dlq", config.Handlers.DeadLetterQueueHandler)
+
+			// Write endpoints accept bodies, so they are size limited
+			r.Group(func(r chi.Router) {
+				r.Use(custommiddleware.MaxBodyBytes(config.MaxBodyBytes))
+
+				r.Route("/admin/consumer", func(r chi.Router) {
+					r.Post("/pause", config.Handlers.ConsumerPauseHandler)
+					r.Post("/resume", config.Handlers.ConsumerResumeHandler)
+				})
+				r.Post("/admin/dedupe/clear", config.Handlers.DedupeClearHandler)
+			})
+		}
+
+		// Root endpoint
+		r.Get("/", config.Handlers.RootHandler)
+	})
 
 	// Add a catch-all for undefined routes
 	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
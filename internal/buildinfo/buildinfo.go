@@ -0,0 +1,26 @@
+// Package buildinfo holds version information stamped into the binary at build time.
+//
+// The variables are set with linker flags, for example:
+//
+//	go build -ldflags "-X github.com/kasbench/globeco-confirmation-service/internal/buildinfo.Version=1.2.3 \
+//	  -X github.com/kasbench/globeco-confirmation-service/internal/buildinfo.GitCommit=$(git rev-parse --short HEAD) \
+//	  -X github.com/kasbench/globeco-confirmation-service/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import "runtime"
+
+var (
+	// Version is the service version
+	Version = "dev"
+
+	// GitCommit is the commit the binary was built from
+	GitCommit = "unknown"
+
+	// BuildTime is when the binary was built, in RFC 3339 format
+	BuildTime = "unknown"
+)
+
+// GoVersion returns the Go version the binary was built with
+func GoVersion() string {
+	return runtime.Version()
+}
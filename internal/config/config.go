@@ -2,7 +2,15 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
 )
 
 // Config represents the application configuration
@@ -17,6 +25,11 @@ type Config struct {
 	Performance       PerformanceConfig       `mapstructure:"performance"`
 	Health            HealthConfig            `mapstructure:"health"`
 	Validation        ValidationConfig        `mapstructure:"validation"`
+	Canary            CanaryConfig            `mapstructure:"canary"`
+	Redis             RedisConfig             `mapstructure:"redis"`
+
+	// Per-tenant overrides keyed by tenant ID, matched case-insensitively
+	Tenants map[string]TenantConfig `mapstructure:"tenants"`
 }
 
 // HTTPConfig represents HTTP server configuration
@@ -26,56 +39,211 @@ type HTTPConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout" validate:"required"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout" validate:"required"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout" validate:"required"`
+
+	// Serve the /admin endpoints that change the running service, such as pausing consumption
+	AdminEndpointsEnabled bool `mapstructure:"admin_endpoints_enabled"`
+
+	// Limit on request bodies sent to write endpoints; larger requests get 413
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes" validate:"min=0"`
+
+	CORS CORSConfig `mapstructure:"cors"`
+
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+}
+
+// RateLimitConfig represents the per-client-IP limit on operational endpoints. Behind
+// trusted proxies the client IP is read from X-Forwarded-For or X-Real-IP.
+type RateLimitConfig struct {
+	RequestsPerSecond float64       `mapstructure:"requests_per_second" validate:"min=0"` // 0 disables the limit
+	Burst             int           `mapstructure:"burst" validate:"min=0"`               // 0 defaults to RequestsPerSecond rounded up
+	TTL               time.Duration `mapstructure:"ttl" validate:"min=0"`                 // Idle time after which a client's bucket is evicted
+	TrustedProxies    []string      `mapstructure:"trusted_proxies"`                      // CIDRs or IPs of proxies in front of the service
+}
+
+// CORSConfig represents the Cross-Origin Resource Sharing policy. An empty origin list
+// allows any origin; empty method and header lists use the middleware defaults.
+type CORSConfig struct {
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+	AllowedMethods []string `mapstructure:"allowed_methods"`
+	AllowedHeaders []string `mapstructure:"allowed_headers"`
 }
 
 // KafkaConfig represents Kafka configuration
 type KafkaConfig struct {
 	Brokers           []string      `mapstructure:"brokers" validate:"required,min=1"`
-	Topic             string        `mapstructure:"topic" validate:"required"`
+	Topic             string        `mapstructure:"topic"`  // Single topic, used when Topics is empty
+	Topics            []string      `mapstructure:"topics"` // Topics consumed by the one consumer group
 	ConsumerGroup     string        `mapstructure:"consumer_group" validate:"required"`
 	ConsumerTimeout   time.Duration `mapstructure:"consumer_timeout" validate:"required"`
 	ConnectionTimeout time.Duration `mapstructure:"connection_timeout" validate:"required"`
 	FetchTimeout      time.Duration `mapstructure:"fetch_timeout" validate:"required"`
 	MaxRetries        int           `mapstructure:"max_retries" validate:"required,min=0"`
 	RetryBackoff      time.Duration `mapstructure:"retry_backoff" validate:"required"`
+	DrainTimeout      time.Duration `mapstructure:"drain_timeout"`
+	LagPollInterval   time.Duration `mapstructure:"lag_poll_interval"`                             // How often consumer lag is measured; 0 disables it
+	MessageFormat     string        `mapstructure:"message_format" validate:"oneof=json protobuf"` // Encoding of fill message values
+
+	// JSON fills from producers with different field names are decoded by renaming each
+	// producer field (key) to a fill field (value), e.g. exec_service_id: executionServiceId
+	FieldMapping map[string]string `mapstructure:"field_mapping"`
+
+	// Message header carrying the producer's correlation ID, reused for the fill's logs
+	// and downstream requests; a new ID is generated when the header is absent
+	CorrelationIDHeader string `mapstructure:"correlation_id_header" validate:"required"`
+
+	// Messages larger than this are dead-lettered and committed without being decoded (0 disables)
+	MaxMessageSizeBytes int `mapstructure:"max_message_size_bytes" validate:"min=0"`
+
+	// A message failing with a non-transient error is retried in place until it has
+	// failed this many times, then dead-lettered and committed so it stops blocking
+	// its partition (0 disables)
+	MaxProcessingAttempts int `mapstructure:"max_processing_attempts" validate:"min=0"`
+
+	// Consumption pauses while the dead letter queue is at or above the high-water
+	// mark and resumes once it drops below the low-water mark (0 disables)
+	DLQPauseHighWaterMark int `mapstructure:"dlq_pause_high_water_mark" validate:"min=0"`
+	DLQResumeLowWaterMark int `mapstructure:"dlq_resume_low_water_mark" validate:"min=0"`
+
+	// Where a consumer group without committed offsets starts on each partition:
+	// earliest or latest. When StartTimestamp (RFC 3339) is set, such partitions start
+	// at the first message at or after it instead. Committed offsets always win.
+	StartOffset    string `mapstructure:"start_offset" validate:"oneof=earliest latest"`
+	StartTimestamp string `mapstructure:"start_timestamp"`
+
+	// Handled messages are committed in batches of CommitBatchSize, or every
+	// CommitInterval if sooner, and on shutdown (1 commits every message)
+	CommitBatchSize int           `mapstructure:"commit_batch_size" validate:"min=0"`
+	CommitInterval  time.Duration `mapstructure:"commit_interval"`
+}
+
+// GetTopics returns the topics to consume, falling back to the single Topic
+func (k KafkaConfig) GetTopics() []string {
+	if len(k.Topics) > 0 {
+		return k.Topics
+	}
+	if k.Topic != "" {
+		return []string{k.Topic}
+	}
+	return nil
+}
+
+// GetStartTimestamp returns the parsed start timestamp, or the zero time when none is set
+func (k KafkaConfig) GetStartTimestamp() (time.Time, error) {
+	if k.StartTimestamp == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, k.StartTimestamp)
 }
 
 // ExecutionServiceConfig represents Execution Service configuration
 type ExecutionServiceConfig struct {
 	BaseURL        string               `mapstructure:"base_url" validate:"required,url"`
 	Timeout        time.Duration        `mapstructure:"timeout" validate:"required"`
+	GetTimeout     time.Duration        `mapstructure:"get_timeout"`    // Defaults to Timeout when unset
+	UpdateTimeout  time.Duration        `mapstructure:"update_timeout"` // Defaults to Timeout when unset
+	HealthTimeout  time.Duration        `mapstructure:"health_timeout"` // Defaults to 5s when unset
+	HealthPath     string               `mapstructure:"health_path"`    // Defaults to /actuator/health/liveness when unset
 	MaxRetries     int                  `mapstructure:"max_retries" validate:"required,min=0"`
 	RetryBackoff   time.Duration        `mapstructure:"retry_backoff" validate:"required"`
 	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+
+	// Times an update rejected with a version conflict is retried with a refreshed version
+	MaxConflictRetries int `mapstructure:"max_conflict_retries" validate:"min=0"`
+
+	// Health check responses with a status in this range count as healthy; zero
+	// values default to 200-299
+	HealthyStatusMin int `mapstructure:"healthy_status_min" validate:"min=0"`
+	HealthyStatusMax int `mapstructure:"healthy_status_max" validate:"min=0"`
+
+	// Bearer token authentication; requests are unauthenticated when unset
+	Auth AuthConfig `mapstructure:"auth"`
+
+	// GetExecution responses are cached for this long, so a burst of fills for one
+	// execution reads it once; updates invalidate the execution. 0 disables the cache
+	CacheTTL time.Duration `mapstructure:"cache_ttl" validate:"min=0"`
+
+	// Requests, including retries, are paced to RateLimit per second with bursts of up
+	// to RateLimitBurst; calls over the rate wait for their turn. 0 disables the limit
+	RateLimit      float64 `mapstructure:"rate_limit" validate:"min=0"`
+	RateLimitBurst int     `mapstructure:"rate_limit_burst" validate:"min=0"`
+
+	// HTTP connection pool; zero values use the client defaults (10 idle connections,
+	// 10 per host, 30s idle timeout). MaxConnsPerHost 0 leaves connections unlimited
+	MaxIdleConns        int           `mapstructure:"max_idle_conns" validate:"min=0"`
+	MaxIdleConnsPerHost int           `mapstructure:"max_idle_conns_per_host" validate:"min=0"`
+	MaxConnsPerHost     int           `mapstructure:"max_conns_per_host" validate:"min=0"`
+	IdleConnTimeout     time.Duration `mapstructure:"idle_conn_timeout" validate:"min=0"`
+	DisableCompression  bool          `mapstructure:"disable_compression"`
+
+	// Optional fill fields also sent in update requests: total_amount, number_of_fills.
+	// Empty sends only the filled quantity, average price and version
+	UpdateFields []string `mapstructure:"update_fields"`
 }
 
 // AllocationServiceConfig represents Allocation Service configuration
 type AllocationServiceConfig struct {
+	Enabled        bool                 `mapstructure:"enabled"`
 	BaseURL        string               `mapstructure:"base_url" validate:"required,url"`
 	Timeout        time.Duration        `mapstructure:"timeout" validate:"required"`
 	MaxRetries     int                  `mapstructure:"max_retries" validate:"required,min=0"`
 	RetryBackoff   time.Duration        `mapstructure:"retry_backoff" validate:"required"`
 	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+
+	// Posts skipped while the circuit is open are buffered (up to PendingBufferSize)
+	// and replayed every ReplayInterval
+	PendingBufferSize int           `mapstructure:"pending_buffer_size" validate:"min=1"`
+	ReplayInterval    time.Duration `mapstructure:"replay_interval"`
+
+	// When true, a completed fill's offset is only committed once its allocation post
+	// succeeds; the consumer retries the fill in place instead of dead-lettering the post
+	Required bool `mapstructure:"required"`
+
+	// Which fills are posted: closed (no longer open), full (fully filled executions)
+	// or terminal (filled, cancelled or deleted executions)
+	Trigger string `mapstructure:"trigger" validate:"oneof=closed full terminal"`
 }
 
 // CircuitBreakerConfig represents circuit breaker configuration
 type CircuitBreakerConfig struct {
-	FailureThreshold int           `mapstructure:"failure_threshold" validate:"required,min=1"`
-	Timeout          time.Duration `mapstructure:"timeout" validate:"required"`
+	FailureThreshold     int           `mapstructure:"failure_threshold" validate:"required,min=1"`
+	Timeout              time.Duration `mapstructure:"timeout" validate:"required"`
+	WindowSize           int           `mapstructure:"window_size" validate:"min=0"`                  // 0 trips on consecutive failures
+	FailureRateThreshold float64       `mapstructure:"failure_rate_threshold" validate:"min=0,max=1"` // Used when window_size > 0
 }
 
 // LoggingConfig represents logging configuration
 type LoggingConfig struct {
 	Level  string `mapstructure:"level" validate:"required,oneof=debug info warn error"`
-	Format string `mapstructure:"format" validate:"required,oneof=json console"`
+	Format string `mapstructure:"format" validate:"required,oneof=json console logfmt"`
 	Output string `mapstructure:"output" validate:"required,oneof=stdout stderr file"`
+
+	// Logs raw Kafka messages and Execution Service bodies at debug level. They may
+	// contain sensitive data, so this is off by default.
+	DebugPayloadLogging   bool `mapstructure:"debug_payload_logging"`
+	DebugPayloadMaxLength int  `mapstructure:"debug_payload_max_length"` // Logged payloads are truncated to this many bytes
+
+	// JSON names of fields masked when whole objects such as fills, or debug payloads, are logged
+	RedactFields []string `mapstructure:"redact_fields"`
+
+	// Sampling of repeated debug and info logs: per second, the first sampling_initial
+	// entries with the same message are logged, then every sampling_thereafter-th.
+	// Warnings and errors are always logged. 0 disables sampling.
+	SamplingInitial    int `mapstructure:"sampling_initial" validate:"min=0"`
+	SamplingThereafter int `mapstructure:"sampling_thereafter" validate:"min=0"`
 }
 
 // MetricsConfig represents metrics configuration
 type MetricsConfig struct {
-	Enabled   bool   `mapstructure:"enabled"`
-	Path      string `mapstructure:"path" validate:"required"`
-	Namespace string `mapstructure:"namespace" validate:"required"`
+	Enabled     bool   `mapstructure:"enabled"`
+	Path        string `mapstructure:"path" validate:"required"`
+	Namespace   string `mapstructure:"namespace" validate:"required"`
+	Environment string `mapstructure:"environment"` // Added to every metric as the environment label
+
+	// Enables metrics labeled by ticker; each traded security adds a time series
+	HighCardinalityMetricsEnabled bool `mapstructure:"high_cardinality_metrics_enabled"`
+
+	// Ascending byte thresholds for the size classes processing time is broken down by
+	MessageSizeClasses []int `mapstructure:"message_size_classes"`
 }
 
 // TracingConfig represents tracing configuration
@@ -91,7 +259,39 @@ type TracingConfig struct {
 type PerformanceConfig struct {
 	MaxConcurrentRequests int `mapstructure:"max_concurrent_requests" validate:"required,min=1"`
 	MessageBufferSize     int `mapstructure:"message_buffer_size" validate:"required,min=1"`
-	WorkerPoolSize        int `mapstructure:"worker_pool_size" validate:"required,min=1"`
+
+	// Kafka consumer workers handling messages concurrently, each partition on one
+	// worker; the adaptive concurrency latency target and min scale how many are active
+	WorkerPoolSize int `mapstructure:"worker_pool_size" validate:"required,min=1"`
+
+	DeadLetterQueueEnabled       bool `mapstructure:"dead_letter_queue_enabled"`
+	DeadLetterQueueMaxSize       int  `mapstructure:"dead_letter_queue_max_size" validate:"min=1"`
+	DuplicateDetectionMaxEntries int  `mapstructure:"duplicate_detection_max_entries" validate:"min=1"`
+
+	// Dead letter queue persistence: messages are appended to DeadLetterQueueFilePath,
+	// reloaded on startup, and the file is rewritten with only live messages every
+	// DeadLetterQueueCompactionInterval
+	DeadLetterQueuePersistToDisk      bool          `mapstructure:"dead_letter_queue_persist_to_disk"`
+	DeadLetterQueueFilePath           string        `mapstructure:"dead_letter_queue_file_path"`
+	DeadLetterQueueCompactionInterval time.Duration `mapstructure:"dead_letter_queue_compaction_interval"`
+
+	// Number of recent messages the rolling error rate is computed over
+	ErrorRateWindowSize int `mapstructure:"error_rate_window_size" validate:"min=1"`
+
+	// Number of recent processing durations the /stats percentiles are computed over
+	ProcessingTimeWindowSize int `mapstructure:"processing_time_window_size" validate:"min=1"`
+
+	// Global retry budget: each retry spends a token from a bucket of RetryBudgetTokens
+	// refilled at RetryBudgetRefillRate tokens per second (0 tokens = unlimited retries)
+	RetryBudgetTokens     int     `mapstructure:"retry_budget_tokens" validate:"min=0"`
+	RetryBudgetRefillRate float64 `mapstructure:"retry_budget_refill_rate" validate:"min=0"`
+
+	// Adaptive limit on concurrent Execution Service requests: grows towards the max while
+	// requests stay under the latency target and backs off towards the min when they do not
+	// (0 max = unlimited)
+	AdaptiveConcurrencyMin           int           `mapstructure:"adaptive_concurrency_min" validate:"min=0"`
+	AdaptiveConcurrencyMax           int           `mapstructure:"adaptive_concurrency_max" validate:"min=0"`
+	AdaptiveConcurrencyLatencyTarget time.Duration `mapstructure:"adaptive_concurrency_latency_target"`
 }
 
 // HealthConfig represents health check configuration
@@ -105,6 +305,94 @@ type ValidationConfig struct {
 	SkipExecutionIDValidation bool `mapstructure:"skip_execution_id_validation"`
 	MaxMessageAgeMinutes      int  `mapstructure:"max_message_age_minutes" validate:"min=0"`
 	WarnOnValidationFailures  bool `mapstructure:"warn_on_validation_failures"`
+
+	// Severity of timestamp ordering violations (error or warning)
+	SentBeforeReceivedSeverity   string `mapstructure:"sent_before_received_severity" validate:"oneof=error warning"`
+	LastFilledBeforeSentSeverity string `mapstructure:"last_filled_before_sent_severity" validate:"oneof=error warning"`
+
+	// Duplicate fills are reprocessed as corrections when the price moves by more than
+	// this percentage or, if enabled, when only the filled quantity changes
+	DuplicatePriceChangeThresholdPercent float64 `mapstructure:"duplicate_price_change_threshold_percent" validate:"gt=0"`
+	DuplicateQuantityChangeTriggers      bool    `mapstructure:"duplicate_quantity_change_triggers"`
+
+	// Negative fill version meaning "use the current execution version" (0 disables)
+	LatestVersionSentinel int `mapstructure:"latest_version_sentinel" validate:"max=0"`
+
+	// Handling of omitted optional fields: strict warns, lenient fills in defaults
+	MissingFieldMode string `mapstructure:"missing_field_mode" validate:"oneof=strict lenient"`
+
+	// Fills reporting more than this many sub-fills per filled share are flagged with
+	// the given severity (error or warning); 0 disables the check
+	MaxFillsPerFilledShare     float64 `mapstructure:"max_fills_per_filled_share" validate:"min=0"`
+	ExcessiveFillCountSeverity string  `mapstructure:"excessive_fill_count_severity" validate:"oneof=error warning"`
+
+	// totalAmount may differ from quantityFilled * averagePrice by this percentage
+	// before a calculation mismatch warning is reported
+	TotalAmountTolerancePercent float64 `mapstructure:"total_amount_tolerance_percent" validate:"gt=0"`
+
+	// Timestamps more than this many seconds ahead of now are flagged as in the future,
+	// and receivedTimestamps older than the max age as old
+	FutureToleranceSeconds int64         `mapstructure:"future_tolerance_seconds" validate:"gt=0"`
+	MaxTimestampAge        time.Duration `mapstructure:"max_timestamp_age" validate:"gt=0"`
+
+	// Warn on fills priced more than price_anomaly_tolerance_percent away from the
+	// moving average of the last price_anomaly_window prices for the security
+	// (0 disables), remembering prices for up to price_anomaly_max_securities securities
+	PriceAnomalyWindow           int     `mapstructure:"price_anomaly_window" validate:"min=0"`
+	PriceAnomalyTolerancePercent float64 `mapstructure:"price_anomaly_tolerance_percent" validate:"min=0"`
+	PriceAnomalyMaxSecurities    int     `mapstructure:"price_anomaly_max_securities" validate:"min=0"`
+
+	// Regular expressions fill tickers, security IDs and destinations are expected to
+	// match; fills that do not match get a format warning
+	TickerPattern      string `mapstructure:"ticker_pattern"`
+	SecurityIDPattern  string `mapstructure:"security_id_pattern"`
+	DestinationPattern string `mapstructure:"destination_pattern"`
+}
+
+// CanaryConfig restricts processing to a subset of executions during a canary rollout.
+// Fills for executions that are not allowlisted (when the allowlist is set) or that are
+// denylisted are skipped and committed. In shadow mode fills are validated and
+// duplicate-checked but the Execution and Allocation Services are never called.
+type CanaryConfig struct {
+	ExecutionIDAllowlist []int64 `mapstructure:"execution_id_allowlist"`
+	ExecutionIDDenylist  []int64 `mapstructure:"execution_id_denylist"`
+	Mode                 string  `mapstructure:"mode" validate:"oneof=live shadow"`
+}
+
+// AuthConfig represents how requests to a downstream service are authenticated: with a
+// static bearer token, or with tokens from an OAuth2 client credentials token endpoint.
+// Requests are unauthenticated when neither is set.
+type AuthConfig struct {
+	Token        string   `mapstructure:"token" sensitive:"true"`
+	TokenURL     string   `mapstructure:"token_url"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret" sensitive:"true"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+// TenantConfig overrides global settings for fills from one tenant. Empty values fall
+// back to the global configuration.
+type TenantConfig struct {
+	ExecutionServiceURL  string                 `mapstructure:"execution_service_url"`
+	AllocationServiceURL string                 `mapstructure:"allocation_service_url"`
+	Validation           TenantValidationConfig `mapstructure:"validation"`
+}
+
+// TenantValidationConfig overrides validation thresholds for one tenant
+type TenantValidationConfig struct {
+	MaxFillsPerFilledShare     *float64 `mapstructure:"max_fills_per_filled_share"` // Set to 0 to disable the check for the tenant
+	ExcessiveFillCountSeverity string   `mapstructure:"excessive_fill_count_severity"`
+	MissingFieldMode           string   `mapstructure:"missing_field_mode"`
+}
+
+// RedisConfig represents the Redis connection used to share duplicate detection
+// records between instances. Duplicate detection stays in memory when Address is empty.
+type RedisConfig struct {
+	Address     string        `mapstructure:"address"`
+	Password    string        `mapstructure:"password" sensitive:"true"`
+	DB          int           `mapstructure:"db" validate:"min=0"`
+	KeyPrefix   string        `mapstructure:"key_prefix"`
+	DialTimeout time.Duration `mapstructure:"dial_timeout" validate:"min=0"`
 }
 
 // GetDefaults returns a Config with default values
@@ -116,16 +404,29 @@ func GetDefaults() *Config {
 			ReadTimeout:  30 * time.Second,
 			WriteTimeout: 30 * time.Second,
 			IdleTimeout:  60 * time.Second,
+
+			AdminEndpointsEnabled: true,
+			MaxBodyBytes:          1 << 20,
+			RateLimit: RateLimitConfig{
+				TTL: time.Minute,
+			},
 		},
 		Kafka: KafkaConfig{
-			Brokers:           []string{"globeco-execution-service-kafka:9092"},
-			Topic:             "fills",
-			ConsumerGroup:     "globeco-confirmation-service",
-			ConsumerTimeout:   30 * time.Second,
-			ConnectionTimeout: 10 * time.Second,
-			FetchTimeout:      5 * time.Second,
-			MaxRetries:        3,
-			RetryBackoff:      100 * time.Millisecond,
+			Brokers:             []string{"globeco-execution-service-kafka:9092"},
+			Topic:               "fills",
+			ConsumerGroup:       "globeco-confirmation-service",
+			ConsumerTimeout:     30 * time.Second,
+			ConnectionTimeout:   10 * time.Second,
+			FetchTimeout:        5 * time.Second,
+			MaxRetries:          3,
+			RetryBackoff:        100 * time.Millisecond,
+			DrainTimeout:        10 * time.Second,
+			LagPollInterval:     30 * time.Second,
+			MessageFormat:       "json",
+			CorrelationIDHeader: "X-Correlation-ID",
+			StartOffset:         "latest",
+			CommitBatchSize:     1,
+			CommitInterval:      time.Second,
 		},
 		ExecutionService: ExecutionServiceConfig{
 			BaseURL:      "http://globeco-execution-service:8084",
@@ -133,11 +434,22 @@ func GetDefaults() *Config {
 			MaxRetries:   3,
 			RetryBackoff: 100 * time.Millisecond,
 			CircuitBreaker: CircuitBreakerConfig{
-				FailureThreshold: 5,
-				Timeout:          30 * time.Second,
+				FailureThreshold:     5,
+				Timeout:              30 * time.Second,
+				FailureRateThreshold: 0.5,
 			},
+			MaxConflictRetries: 3,
+			HealthPath:         "/actuator/health/liveness",
+			RateLimitBurst:     10,
+			HealthyStatusMin:   200,
+			HealthyStatusMax:   299,
+
+			MaxIdleConns:        10,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     30 * time.Second,
 		},
 		AllocationService: AllocationServiceConfig{
+			Enabled:      true,
 			BaseURL:      "http://globeco-allocation-service:8089",
 			Timeout:      10 * time.Second,
 			MaxRetries:   3,
@@ -146,16 +458,31 @@ func GetDefaults() *Config {
 				FailureThreshold: 5,
 				Timeout:          30 * time.Second,
 			},
+			PendingBufferSize: 1000,
+			ReplayInterval:    10 * time.Second,
+			Required:          false,
+			Trigger:           "closed",
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
 			Format: "json",
 			Output: "stdout",
+
+			DebugPayloadLogging:   false,
+			DebugPayloadMaxLength: 4096,
+
+			RedactFields: []string{"securityId"},
+
+			SamplingInitial:    0,
+			SamplingThereafter: 100,
 		},
 		Metrics: MetricsConfig{
-			Enabled:   true,
-			Path:      "/metrics",
-			Namespace: "confirmation",
+			Enabled:     true,
+			Path:        "/metrics",
+			Namespace:   "confirmation",
+			Environment: "development",
+
+			MessageSizeClasses: []int{1024, 16384},
 		},
 		Tracing: TracingConfig{
 			Enabled:        true,
@@ -168,6 +495,24 @@ func GetDefaults() *Config {
 			MaxConcurrentRequests: 10,
 			MessageBufferSize:     1000,
 			WorkerPoolSize:        5,
+
+			DeadLetterQueueEnabled:       true,
+			DeadLetterQueueMaxSize:       1000,
+			DuplicateDetectionMaxEntries: 10000,
+
+			DeadLetterQueuePersistToDisk:      false,
+			DeadLetterQueueFilePath:           "",
+			DeadLetterQueueCompactionInterval: 10 * time.Minute,
+
+			ErrorRateWindowSize:      100,
+			ProcessingTimeWindowSize: 1000,
+
+			RetryBudgetTokens:     100,
+			RetryBudgetRefillRate: 10,
+
+			AdaptiveConcurrencyMin:           1,
+			AdaptiveConcurrencyMax:           0,
+			AdaptiveConcurrencyLatencyTarget: 500 * time.Millisecond,
 		},
 		Health: HealthConfig{
 			StartupGracePeriod: 30 * time.Second,
@@ -177,6 +522,38 @@ func GetDefaults() *Config {
 			SkipExecutionIDValidation: false,
 			MaxMessageAgeMinutes:      60,
 			WarnOnValidationFailures:  true,
+
+			SentBeforeReceivedSeverity:   "error",
+			LastFilledBeforeSentSeverity: "error",
+
+			DuplicatePriceChangeThresholdPercent: 0.1,
+			DuplicateQuantityChangeTriggers:      true,
+
+			LatestVersionSentinel: -1,
+			MissingFieldMode:      "strict",
+
+			MaxFillsPerFilledShare:     1,
+			ExcessiveFillCountSeverity: "warning",
+
+			TotalAmountTolerancePercent: 1.0,
+
+			FutureToleranceSeconds: 3600,
+			MaxTimestampAge:        365 * 24 * time.Hour,
+
+			PriceAnomalyWindow:           0,
+			PriceAnomalyTolerancePercent: 20,
+			PriceAnomalyMaxSecurities:    10000,
+
+			TickerPattern:      `^[A-Z]{1,5}$`,
+			SecurityIDPattern:  `^[A-Za-z0-9]+$`,
+			DestinationPattern: `^[A-Z]{2,4}$`,
+		},
+		Canary: CanaryConfig{
+			Mode: "live",
+		},
+		Redis: RedisConfig{
+			KeyPrefix:   "confirmation:processed:",
+			DialTimeout: 5 * time.Second,
 		},
 	}
 }
@@ -192,35 +569,262 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("http.host is required")
 	}
 
+	if _, _, err := net.SplitHostPort(c.GetHTTPAddress()); err != nil || strings.ContainsAny(c.HTTP.Host, " \t") {
+		return fmt.Errorf("http.host %q does not form a listen address; use a hostname or IPv4 address such as 0.0.0.0", c.HTTP.Host)
+	}
+
+	if c.HTTP.ReadTimeout <= 0 || c.HTTP.WriteTimeout <= 0 || c.HTTP.IdleTimeout <= 0 {
+		return fmt.Errorf("http.read_timeout, http.write_timeout and http.idle_timeout must be positive")
+	}
+
+	if c.HTTP.MaxBodyBytes < 0 {
+		return fmt.Errorf("http.max_body_bytes must not be negative")
+	}
+
+	if c.HTTP.RateLimit.RequestsPerSecond < 0 || c.HTTP.RateLimit.Burst < 0 {
+		return fmt.Errorf("http.rate_limit.requests_per_second and http.rate_limit.burst must not be negative")
+	}
+
+	if c.HTTP.RateLimit.TTL < 0 {
+		return fmt.Errorf("http.rate_limit.ttl must not be negative")
+	}
+
+	for _, proxy := range c.HTTP.RateLimit.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				return fmt.Errorf("http.rate_limit.trusted_proxies entry %q must be a CIDR or IP address", proxy)
+			}
+		}
+	}
+
 	// Validate Kafka configuration
 	if len(c.Kafka.Brokers) == 0 {
 		return fmt.Errorf("kafka.brokers is required")
 	}
 
-	if c.Kafka.Topic == "" {
+	for _, broker := range c.Kafka.Brokers {
+		if host, port, err := net.SplitHostPort(broker); err != nil || host == "" || port == "" {
+			return fmt.Errorf("kafka.brokers entry %q must be a host:port address", broker)
+		}
+	}
+
+	if c.Kafka.ConsumerTimeout <= 0 || c.Kafka.ConnectionTimeout <= 0 || c.Kafka.FetchTimeout <= 0 {
+		return fmt.Errorf("kafka.consumer_timeout, kafka.connection_timeout and kafka.fetch_timeout must be positive")
+	}
+
+	if c.Kafka.MaxRetries < 0 {
+		return fmt.Errorf("kafka.max_retries must not be negative")
+	}
+
+	if c.Kafka.MaxRetries > 0 && c.Kafka.RetryBackoff <= 0 {
+		return fmt.Errorf("kafka.retry_backoff must be positive when kafka.max_retries is set")
+	}
+
+	if len(c.Kafka.GetTopics()) == 0 {
 		return fmt.Errorf("kafka.topic is required")
 	}
 
+	for _, topic := range c.Kafka.Topics {
+		if topic == "" {
+			return fmt.Errorf("kafka.topics must not contain empty topic names")
+		}
+	}
+
 	if c.Kafka.ConsumerGroup == "" {
 		return fmt.Errorf("kafka.consumer_group is required")
 	}
 
+	if c.Kafka.DLQPauseHighWaterMark < 0 || c.Kafka.DLQResumeLowWaterMark < 0 {
+		return fmt.Errorf("kafka.dlq_pause_high_water_mark and kafka.dlq_resume_low_water_mark must not be negative")
+	}
+
+	if c.Kafka.DLQPauseHighWaterMark > 0 {
+		if c.Kafka.DLQResumeLowWaterMark < 1 {
+			return fmt.Errorf("kafka.dlq_resume_low_water_mark must be at least 1 when kafka.dlq_pause_high_water_mark is set")
+		}
+
+		if c.Kafka.DLQResumeLowWaterMark >= c.Kafka.DLQPauseHighWaterMark {
+			return fmt.Errorf("kafka.dlq_resume_low_water_mark must be less than kafka.dlq_pause_high_water_mark")
+		}
+
+		if c.Kafka.DLQPauseHighWaterMark > c.Performance.DeadLetterQueueMaxSize {
+			return fmt.Errorf("kafka.dlq_pause_high_water_mark must not exceed performance.dead_letter_queue_max_size")
+		}
+	}
+
+	if c.Kafka.MessageFormat != "json" && c.Kafka.MessageFormat != "protobuf" {
+		return fmt.Errorf("kafka.message_format must be one of: json, protobuf")
+	}
+
+	if c.Kafka.MaxMessageSizeBytes < 0 {
+		return fmt.Errorf("kafka.max_message_size_bytes must not be negative")
+	}
+
+	if c.Kafka.CorrelationIDHeader == "" {
+		return fmt.Errorf("kafka.correlation_id_header is required")
+	}
+
+	if c.Kafka.MaxProcessingAttempts < 0 {
+		return fmt.Errorf("kafka.max_processing_attempts must not be negative")
+	}
+
+	if len(c.Kafka.FieldMapping) > 0 && c.Kafka.MessageFormat != "json" {
+		return fmt.Errorf("kafka.field_mapping requires kafka.message_format json")
+	}
+	fillFields := domain.FillJSONFields()
+	for from, to := range c.Kafka.FieldMapping {
+		if from == "" || to == "" {
+			return fmt.Errorf("kafka.field_mapping must not contain empty field names")
+		}
+		if !fillFields[to] {
+			return fmt.Errorf("kafka.field_mapping maps %s to unknown fill field %s", from, to)
+		}
+	}
+
+	if c.Kafka.LagPollInterval < 0 {
+		return fmt.Errorf("kafka.lag_poll_interval must not be negative")
+	}
+
+	if c.Kafka.CommitBatchSize < 0 {
+		return fmt.Errorf("kafka.commit_batch_size must not be negative")
+	}
+
+	if c.Kafka.CommitBatchSize > 1 && c.Kafka.CommitInterval <= 0 {
+		return fmt.Errorf("kafka.commit_interval must be positive when kafka.commit_batch_size is greater than 1")
+	}
+
+	if c.Kafka.StartOffset != "earliest" && c.Kafka.StartOffset != "latest" {
+		return fmt.Errorf("kafka.start_offset must be one of: earliest, latest")
+	}
+
+	if _, err := c.Kafka.GetStartTimestamp(); err != nil {
+		return fmt.Errorf("kafka.start_timestamp must be an RFC 3339 timestamp: %w", err)
+	}
+
 	// Validate Execution Service configuration
 	if c.ExecutionService.BaseURL == "" {
 		return fmt.Errorf("execution_service.base_url is required")
 	}
 
+	if !isAbsoluteURL(c.ExecutionService.BaseURL) {
+		return fmt.Errorf("execution_service.base_url %q must be an absolute URL such as http://host:port", c.ExecutionService.BaseURL)
+	}
+
+	if err := validateServiceCalls(c.ExecutionService.Timeout, c.ExecutionService.MaxRetries, c.ExecutionService.RetryBackoff, c.ExecutionService.CircuitBreaker); err != nil {
+		return fmt.Errorf("execution_service.%s", err)
+	}
+
+	if c.ExecutionService.GetTimeout < 0 {
+		return fmt.Errorf("execution_service.get_timeout must not be negative")
+	}
+
+	if c.ExecutionService.UpdateTimeout < 0 {
+		return fmt.Errorf("execution_service.update_timeout must not be negative")
+	}
+
+	if c.ExecutionService.HealthTimeout < 0 {
+		return fmt.Errorf("execution_service.health_timeout must not be negative")
+	}
+
+	if path := c.ExecutionService.HealthPath; path != "" && !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("execution_service.health_path must start with /")
+	}
+
+	if err := validateStatusRange(c.ExecutionService.HealthyStatusMin, c.ExecutionService.HealthyStatusMax); err != nil {
+		return fmt.Errorf("execution_service.healthy_status_min and healthy_status_max %s", err)
+	}
+
+	if auth := c.ExecutionService.Auth; auth.TokenURL != "" {
+		if auth.Token != "" {
+			return fmt.Errorf("execution_service.auth.token and execution_service.auth.token_url are mutually exclusive")
+		}
+		if !isAbsoluteURL(auth.TokenURL) {
+			return fmt.Errorf("execution_service.auth.token_url must be an absolute URL")
+		}
+		if auth.ClientID == "" {
+			return fmt.Errorf("execution_service.auth.client_id is required with execution_service.auth.token_url")
+		}
+	}
+
+	if c.ExecutionService.MaxConflictRetries < 0 {
+		return fmt.Errorf("execution_service.max_conflict_retries must not be negative")
+	}
+
+	if c.ExecutionService.CacheTTL < 0 {
+		return fmt.Errorf("execution_service.cache_ttl must not be negative")
+	}
+
+	if c.ExecutionService.RateLimit < 0 || c.ExecutionService.RateLimitBurst < 0 {
+		return fmt.Errorf("execution_service.rate_limit and execution_service.rate_limit_burst must not be negative")
+	}
+
+	if c.ExecutionService.MaxIdleConns < 0 {
+		return fmt.Errorf("execution_service.max_idle_conns must not be negative")
+	}
+
+	if c.ExecutionService.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("execution_service.max_idle_conns_per_host must not be negative")
+	}
+
+	if c.ExecutionService.MaxConnsPerHost < 0 {
+		return fmt.Errorf("execution_service.max_conns_per_host must not be negative")
+	}
+
+	if c.ExecutionService.IdleConnTimeout < 0 {
+		return fmt.Errorf("execution_service.idle_conn_timeout must not be negative")
+	}
+
+	validUpdateFields := map[string]bool{"total_amount": true, "number_of_fills": true}
+	for _, field := range c.ExecutionService.UpdateFields {
+		if !validUpdateFields[field] {
+			return fmt.Errorf("execution_service.update_fields must contain only: total_amount, number_of_fills")
+		}
+	}
+
 	if c.ExecutionService.CircuitBreaker.FailureThreshold < 1 {
 		return fmt.Errorf("execution_service.circuit_breaker.failure_threshold must be at least 1")
 	}
 
+	if c.ExecutionService.CircuitBreaker.WindowSize < 0 {
+		return fmt.Errorf("execution_service.circuit_breaker.window_size must not be negative")
+	}
+
+	if c.ExecutionService.CircuitBreaker.FailureRateThreshold < 0 || c.ExecutionService.CircuitBreaker.FailureRateThreshold > 1 {
+		return fmt.Errorf("execution_service.circuit_breaker.failure_rate_threshold must be between 0 and 1")
+	}
+
 	// Validate Allocation Service configuration
-	if c.AllocationService.BaseURL == "" {
-		return fmt.Errorf("allocation_service.base_url is required")
+	if c.AllocationService.Enabled {
+		if c.AllocationService.BaseURL == "" {
+			return fmt.Errorf("allocation_service.base_url is required")
+		}
+
+		if !isAbsoluteURL(c.AllocationService.BaseURL) {
+			return fmt.Errorf("allocation_service.base_url %q must be an absolute URL such as http://host:port", c.AllocationService.BaseURL)
+		}
+
+		if err := validateServiceCalls(c.AllocationService.Timeout, c.AllocationService.MaxRetries, c.AllocationService.RetryBackoff, c.AllocationService.CircuitBreaker); err != nil {
+			return fmt.Errorf("allocation_service.%s", err)
+		}
+
+		if c.AllocationService.CircuitBreaker.FailureThreshold < 1 {
+			return fmt.Errorf("allocation_service.circuit_breaker.failure_threshold must be at least 1")
+		}
+
+		if c.AllocationService.PendingBufferSize < 1 {
+			return fmt.Errorf("allocation_service.pending_buffer_size must be at least 1")
+		}
+
+		if c.AllocationService.ReplayInterval <= 0 {
+			return fmt.Errorf("allocation_service.replay_interval must be positive")
+		}
+	} else if c.AllocationService.Required {
+		return fmt.Errorf("allocation_service.required requires allocation_service.enabled")
 	}
 
-	if c.AllocationService.CircuitBreaker.FailureThreshold < 1 {
-		return fmt.Errorf("allocation_service.circuit_breaker.failure_threshold must be at least 1")
+	validAllocationTriggers := map[string]bool{"closed": true, "full": true, "terminal": true}
+	if !validAllocationTriggers[c.AllocationService.Trigger] {
+		return fmt.Errorf("allocation_service.trigger must be one of: closed, full, terminal")
 	}
 
 	// Validate Logging configuration
@@ -229,9 +833,9 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("logging.level must be one of: debug, info, warn, error")
 	}
 
-	validLogFormats := map[string]bool{"json": true, "console": true}
+	validLogFormats := map[string]bool{"json": true, "console": true, "logfmt": true}
 	if !validLogFormats[c.Logging.Format] {
-		return fmt.Errorf("logging.format must be one of: json, console")
+		return fmt.Errorf("logging.format must be one of: json, console, logfmt")
 	}
 
 	validLogOutputs := map[string]bool{"stdout": true, "stderr": true, "file": true}
@@ -239,6 +843,30 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("logging.output must be one of: stdout, stderr, file")
 	}
 
+	if c.Logging.DebugPayloadLogging && c.Logging.DebugPayloadMaxLength <= 0 {
+		return fmt.Errorf("logging.debug_payload_max_length must be positive when logging.debug_payload_logging is enabled")
+	}
+
+	if c.Logging.SamplingInitial < 0 {
+		return fmt.Errorf("logging.sampling_initial must not be negative")
+	}
+	if c.Logging.SamplingInitial > 0 && c.Logging.SamplingThereafter <= 0 {
+		return fmt.Errorf("logging.sampling_thereafter must be positive when logging.sampling_initial is set")
+	}
+
+	for _, field := range c.Logging.RedactFields {
+		if strings.TrimSpace(field) == "" {
+			return fmt.Errorf("logging.redact_fields must not contain empty field names")
+		}
+	}
+
+	// Validate Metrics configuration
+	for i, threshold := range c.Metrics.MessageSizeClasses {
+		if threshold < 1 || (i > 0 && threshold <= c.Metrics.MessageSizeClasses[i-1]) {
+			return fmt.Errorf("metrics.message_size_classes must be positive and strictly ascending")
+		}
+	}
+
 	// Validate Tracing configuration
 	validTracingExporters := map[string]bool{"stdout": true, "jaeger": true, "otlp": true}
 	if !validTracingExporters[c.Tracing.Exporter] {
@@ -258,10 +886,381 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("performance.worker_pool_size must be at least 1")
 	}
 
+	if c.Performance.DeadLetterQueueMaxSize < 1 {
+		return fmt.Errorf("performance.dead_letter_queue_max_size must be at least 1")
+	}
+
+	if c.Performance.DeadLetterQueuePersistToDisk && c.Performance.DeadLetterQueueFilePath == "" {
+		return fmt.Errorf("performance.dead_letter_queue_file_path is required when performance.dead_letter_queue_persist_to_disk is enabled")
+	}
+
+	if c.Performance.DeadLetterQueueCompactionInterval < 0 {
+		return fmt.Errorf("performance.dead_letter_queue_compaction_interval must not be negative")
+	}
+
+	if c.Performance.DuplicateDetectionMaxEntries < 1 {
+		return fmt.Errorf("performance.duplicate_detection_max_entries must be at least 1")
+	}
+
+	if c.Performance.ErrorRateWindowSize < 1 {
+		return fmt.Errorf("performance.error_rate_window_size must be at least 1")
+	}
+
+	if c.Performance.ProcessingTimeWindowSize < 1 {
+		return fmt.Errorf("performance.processing_time_window_size must be at least 1")
+	}
+
+	if c.Performance.RetryBudgetTokens < 0 || c.Performance.RetryBudgetRefillRate < 0 {
+		return fmt.Errorf("performance.retry_budget_tokens and performance.retry_budget_refill_rate must not be negative")
+	}
+
+	if c.Performance.RetryBudgetTokens > 0 && c.Performance.RetryBudgetRefillRate == 0 {
+		return fmt.Errorf("performance.retry_budget_refill_rate must be positive when performance.retry_budget_tokens is set")
+	}
+
+	if c.Performance.AdaptiveConcurrencyMin < 0 || c.Performance.AdaptiveConcurrencyMax < 0 {
+		return fmt.Errorf("performance.adaptive_concurrency_min and performance.adaptive_concurrency_max must not be negative")
+	}
+
+	if c.Performance.AdaptiveConcurrencyMax > 0 {
+		if c.Performance.AdaptiveConcurrencyMin < 1 || c.Performance.AdaptiveConcurrencyMin > c.Performance.AdaptiveConcurrencyMax {
+			return fmt.Errorf("performance.adaptive_concurrency_min must be between 1 and performance.adaptive_concurrency_max")
+		}
+
+		if c.Performance.AdaptiveConcurrencyLatencyTarget <= 0 {
+			return fmt.Errorf("performance.adaptive_concurrency_latency_target must be positive when performance.adaptive_concurrency_max is set")
+		}
+	}
+
+	// Validate Validation configuration
+	validSeverities := map[string]bool{"error": true, "warning": true}
+	if !validSeverities[c.Validation.SentBeforeReceivedSeverity] {
+		return fmt.Errorf("validation.sent_before_received_severity must be one of: error, warning")
+	}
+
+	if !validSeverities[c.Validation.LastFilledBeforeSentSeverity] {
+		return fmt.Errorf("validation.last_filled_before_sent_severity must be one of: error, warning")
+	}
+
+	if c.Validation.DuplicatePriceChangeThresholdPercent <= 0 {
+		return fmt.Errorf("validation.duplicate_price_change_threshold_percent must be positive")
+	}
+
+	if c.Validation.LatestVersionSentinel > 0 {
+		return fmt.Errorf("validation.latest_version_sentinel must be negative, or 0 to disable")
+	}
+
+	if c.Validation.MissingFieldMode != "strict" && c.Validation.MissingFieldMode != "lenient" {
+		return fmt.Errorf("validation.missing_field_mode must be one of: strict, lenient")
+	}
+
+	if c.Validation.MaxFillsPerFilledShare < 0 {
+		return fmt.Errorf("validation.max_fills_per_filled_share must not be negative")
+	}
+
+	if c.Validation.TotalAmountTolerancePercent <= 0 {
+		return fmt.Errorf("validation.total_amount_tolerance_percent must be greater than 0")
+	}
+
+	if c.Validation.FutureToleranceSeconds <= 0 {
+		return fmt.Errorf("validation.future_tolerance_seconds must be greater than 0")
+	}
+
+	if c.Validation.MaxTimestampAge <= 0 {
+		return fmt.Errorf("validation.max_timestamp_age must be greater than 0")
+	}
+
+	if !validSeverities[c.Validation.ExcessiveFillCountSeverity] {
+		return fmt.Errorf("validation.excessive_fill_count_severity must be one of: error, warning")
+	}
+
+	if c.Validation.PriceAnomalyWindow < 0 {
+		return fmt.Errorf("validation.price_anomaly_window must not be negative")
+	}
+	if c.Validation.PriceAnomalyWindow > 0 {
+		if c.Validation.PriceAnomalyTolerancePercent <= 0 {
+			return fmt.Errorf("validation.price_anomaly_tolerance_percent must be positive when validation.price_anomaly_window is set")
+		}
+		if c.Validation.PriceAnomalyMaxSecurities < 1 {
+			return fmt.Errorf("validation.price_anomaly_max_securities must be at least 1 when validation.price_anomaly_window is set")
+		}
+	}
+
+	formatPatterns := []struct {
+		key     string
+		pattern string
+	}{
+		{"ticker_pattern", c.Validation.TickerPattern},
+		{"security_id_pattern", c.Validation.SecurityIDPattern},
+		{"destination_pattern", c.Validation.DestinationPattern},
+	}
+	for _, format := range formatPatterns {
+		if _, err := regexp.Compile(format.pattern); err != nil {
+			return fmt.Errorf("validation.%s is not a valid regular expression: %w", format.key, err)
+		}
+	}
+
+	// Validate Canary configuration
+	allowlisted := make(map[int64]bool, len(c.Canary.ExecutionIDAllowlist))
+	for _, id := range c.Canary.ExecutionIDAllowlist {
+		allowlisted[id] = true
+	}
+	for _, id := range c.Canary.ExecutionIDDenylist {
+		if allowlisted[id] {
+			return fmt.Errorf("canary execution ID %d cannot be both allowlisted and denylisted", id)
+		}
+	}
+
+	if c.Canary.Mode != "live" && c.Canary.Mode != "shadow" {
+		return fmt.Errorf("canary.mode must be one of: live, shadow")
+	}
+
+	// Validate Redis configuration
+	if c.Redis.DB < 0 {
+		return fmt.Errorf("redis.db must not be negative")
+	}
+
+	if c.Redis.DialTimeout < 0 {
+		return fmt.Errorf("redis.dial_timeout must not be negative")
+	}
+
+	// Validate tenant overrides
+	for _, tenantID := range c.TenantIDs() {
+		tenant := c.Tenants[tenantID]
+		if tenant.ExecutionServiceURL != "" && !isAbsoluteURL(tenant.ExecutionServiceURL) {
+			return fmt.Errorf("tenants.%s.execution_service_url must be an absolute URL", tenantID)
+		}
+
+		if tenant.AllocationServiceURL != "" {
+			if !c.AllocationService.Enabled {
+				return fmt.Errorf("tenants.%s.allocation_service_url requires allocation_service.enabled", tenantID)
+			}
+			if !isAbsoluteURL(tenant.AllocationServiceURL) {
+				return fmt.Errorf("tenants.%s.allocation_service_url must be an absolute URL", tenantID)
+			}
+		}
+
+		if maxFills := tenant.Validation.MaxFillsPerFilledShare; maxFills != nil && *maxFills < 0 {
+			return fmt.Errorf("tenants.%s.validation.max_fills_per_filled_share must not be negative", tenantID)
+		}
+
+		if severity := tenant.Validation.ExcessiveFillCountSeverity; severity != "" && !validSeverities[severity] {
+			return fmt.Errorf("tenants.%s.validation.excessive_fill_count_severity must be one of: error, warning", tenantID)
+		}
+
+		if mode := tenant.Validation.MissingFieldMode; mode != "" && mode != "strict" && mode != "lenient" {
+			return fmt.Errorf("tenants.%s.validation.missing_field_mode must be one of: strict, lenient", tenantID)
+		}
+	}
+
 	return nil
 }
 
+// validateStatusRange checks an optional HTTP status code range, where zero leaves an
+// end at its default
+func validateStatusRange(min, max int) error {
+	for _, code := range []int{min, max} {
+		if code != 0 && (code < 100 || code > 599) {
+			return fmt.Errorf("must be HTTP status codes between 100 and 599")
+		}
+	}
+	// Unset bounds default to 200-299, so compare against the range in effect
+	if min == 0 {
+		min = 200
+	}
+	if max == 0 {
+		max = 299
+	}
+	if min > max {
+		return fmt.Errorf("must form a range with min not above max (unset values default to 200-299)")
+	}
+	return nil
+}
+
+// validateServiceCalls checks the timeout, retry and circuit breaker settings of a
+// downstream service; errors name the keys relative to the service's section
+func validateServiceCalls(timeout time.Duration, maxRetries int, retryBackoff time.Duration, breaker CircuitBreakerConfig) error {
+	if timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+	if maxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+	if maxRetries > 0 && retryBackoff <= 0 {
+		return fmt.Errorf("retry_backoff must be positive when max_retries is set")
+	}
+	if breaker.Timeout <= 0 {
+		return fmt.Errorf("circuit_breaker.timeout must be positive")
+	}
+	return nil
+}
+
+func isAbsoluteURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// TenantIDs returns the IDs of tenants with overrides, sorted
+func (c *Config) TenantIDs() []string {
+	ids := make([]string, 0, len(c.Tenants))
+	for id := range c.Tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// tenant returns a tenant's overrides, if any
+func (c *Config) tenant(tenantID string) (TenantConfig, bool) {
+	for id, tenant := range c.Tenants {
+		if strings.EqualFold(id, tenantID) {
+			return tenant, true
+		}
+	}
+	return TenantConfig{}, false
+}
+
+// ExecutionServiceFor returns the Execution Service configuration used for a tenant's fills
+func (c *Config) ExecutionServiceFor(tenantID string) ExecutionServiceConfig {
+	executionService := c.ExecutionService
+	if tenant, ok := c.tenant(tenantID); ok && tenant.ExecutionServiceURL != "" {
+		executionService.BaseURL = tenant.ExecutionServiceURL
+	}
+	return executionService
+}
+
+// AllocationServiceFor returns the Allocation Service configuration used for a tenant's fills
+func (c *Config) AllocationServiceFor(tenantID string) AllocationServiceConfig {
+	allocationService := c.AllocationService
+	if tenant, ok := c.tenant(tenantID); ok && tenant.AllocationServiceURL != "" {
+		allocationService.BaseURL = tenant.AllocationServiceURL
+	}
+	return allocationService
+}
+
+// ValidationFor returns the validation configuration used for a tenant's fills
+func (c *Config) ValidationFor(tenantID string) ValidationConfig {
+	validation := c.Validation
+	tenant, ok := c.tenant(tenantID)
+	if !ok {
+		return validation
+	}
+
+	if tenant.Validation.MaxFillsPerFilledShare != nil {
+		validation.MaxFillsPerFilledShare = *tenant.Validation.MaxFillsPerFilledShare
+	}
+	if tenant.Validation.ExcessiveFillCountSeverity != "" {
+		validation.ExcessiveFillCountSeverity = tenant.Validation.ExcessiveFillCountSeverity
+	}
+	if tenant.Validation.MissingFieldMode != "" {
+		validation.MissingFieldMode = tenant.Validation.MissingFieldMode
+	}
+	return validation
+}
+
 // GetHTTPAddress returns the HTTP server address
 func (c *Config) GetHTTPAddress() string {
 	return fmt.Sprintf("%s:%d", c.HTTP.Host, c.HTTP.Port)
 }
+
+// RuntimeLimits represents the effective limits derived from the configuration
+type RuntimeLimits struct {
+	Concurrency ConcurrencyLimits `json:"concurrency"`
+	Timeouts    TimeoutLimits     `json:"timeouts"`
+	Retries     RetryLimits       `json:"retries"`
+	Capacity    CapacityLimits    `json:"capacity"`
+}
+
+// ConcurrencyLimits represents the concurrency related limits
+type ConcurrencyLimits struct {
+	MaxConcurrentRequests int `json:"max_concurrent_requests"`
+	WorkerPoolSize        int `json:"worker_pool_size"`
+	MessageBufferSize     int `json:"message_buffer_size"`
+	AdaptiveMin           int `json:"adaptive_min"`
+	AdaptiveMax           int `json:"adaptive_max"` // 0 when the adaptive limit is disabled
+}
+
+// TimeoutLimits represents the timeouts, rendered as duration strings
+type TimeoutLimits struct {
+	HTTPRead                string `json:"http_read"`
+	HTTPWrite               string `json:"http_write"`
+	HTTPIdle                string `json:"http_idle"`
+	KafkaConsumer           string `json:"kafka_consumer"`
+	KafkaDrain              string `json:"kafka_drain"`
+	ExecutionServiceGet     string `json:"execution_service_get"`
+	ExecutionServiceUpdate  string `json:"execution_service_update"`
+	ExecutionServiceHealth  string `json:"execution_service_health"`
+	AllocationService       string `json:"allocation_service"`
+	CircuitBreakerOpenState string `json:"circuit_breaker_open_state"`
+}
+
+// RetryLimits represents the retry attempt limits
+type RetryLimits struct {
+	Kafka              int `json:"kafka"`
+	ExecutionService   int `json:"execution_service"`
+	AllocationService  int `json:"allocation_service"`
+	MaxConflictRetries int `json:"max_conflict_retries"`
+
+	BudgetTokens     int     `json:"budget_tokens"`
+	BudgetRefillRate float64 `json:"budget_refill_rate"`
+}
+
+// CapacityLimits represents the in-memory capacity limits
+type CapacityLimits struct {
+	DeadLetterQueueMaxSize       int `json:"dead_letter_queue_max_size"`
+	DuplicateDetectionMaxEntries int `json:"duplicate_detection_max_entries"`
+}
+
+// GetRuntimeLimits returns the effective limits, resolving optional values to
+// the fallbacks the service applies when they are unset
+func (c *Config) GetRuntimeLimits() RuntimeLimits {
+	getTimeout := c.ExecutionService.GetTimeout
+	if getTimeout <= 0 {
+		getTimeout = c.ExecutionService.Timeout
+	}
+
+	updateTimeout := c.ExecutionService.UpdateTimeout
+	if updateTimeout <= 0 {
+		updateTimeout = c.ExecutionService.Timeout
+	}
+
+	healthTimeout := c.ExecutionService.HealthTimeout
+	if healthTimeout <= 0 {
+		healthTimeout = 5 * time.Second
+	}
+
+	return RuntimeLimits{
+		Concurrency: ConcurrencyLimits{
+			MaxConcurrentRequests: c.Performance.MaxConcurrentRequests,
+			WorkerPoolSize:        c.Performance.WorkerPoolSize,
+			MessageBufferSize:     c.Performance.MessageBufferSize,
+			AdaptiveMin:           c.Performance.AdaptiveConcurrencyMin,
+			AdaptiveMax:           c.Performance.AdaptiveConcurrencyMax,
+		},
+		Timeouts: TimeoutLimits{
+			HTTPRead:                c.HTTP.ReadTimeout.String(),
+			HTTPWrite:               c.HTTP.WriteTimeout.String(),
+			HTTPIdle:                c.HTTP.IdleTimeout.String(),
+			KafkaConsumer:           c.Kafka.ConsumerTimeout.String(),
+			KafkaDrain:              c.Kafka.DrainTimeout.String(),
+			ExecutionServiceGet:     getTimeout.String(),
+			ExecutionServiceUpdate:  updateTimeout.String(),
+			ExecutionServiceHealth:  healthTimeout.String(),
+			AllocationService:       c.AllocationService.Timeout.String(),
+			CircuitBreakerOpenState: c.ExecutionService.CircuitBreaker.Timeout.String(),
+		},
+		Retries: RetryLimits{
+			Kafka:              c.Kafka.MaxRetries,
+			ExecutionService:   c.ExecutionService.MaxRetries,
+			AllocationService:  c.AllocationService.MaxRetries,
+			MaxConflictRetries: c.ExecutionService.MaxConflictRetries,
+
+			BudgetTokens:     c.Performance.RetryBudgetTokens,
+			BudgetRefillRate: c.Performance.RetryBudgetRefillRate,
+		},
+		Capacity: CapacityLimits{
+			DeadLetterQueueMaxSize:       c.Performance.DeadLetterQueueMaxSize,
+			DuplicateDetectionMaxEntries: c.Performance.DuplicateDetectionMaxEntries,
+		},
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetDefaults(t *testing.T) {
@@ -32,6 +33,10 @@ func TestGetDefaults(t *testing.T) {
 	assert.Equal(t, 100*time.Millisecond, config.ExecutionService.RetryBackoff)
 	assert.Equal(t, 5, config.ExecutionService.CircuitBreaker.FailureThreshold)
 	assert.Equal(t, 30*time.Second, config.ExecutionService.CircuitBreaker.Timeout)
+	assert.Equal(t, 3, config.ExecutionService.MaxConflictRetries)
+	assert.Equal(t, -1, config.Validation.LatestVersionSentinel)
+	assert.Equal(t, 1000, config.Performance.DeadLetterQueueMaxSize)
+	assert.Equal(t, 10000, config.Performance.DuplicateDetectionMaxEntries)
 
 	// Test Logging defaults
 	assert.Equal(t, "info", config.Logging.Level)
@@ -102,6 +107,689 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "http.host is required",
 		},
+		{
+			name: "canary execution ID both allowlisted and denylisted",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Canary.ExecutionIDAllowlist = []int64{1, 2}
+				c.Canary.ExecutionIDDenylist = []int64{2}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "canary execution ID 2 cannot be both allowlisted and denylisted",
+		},
+		{
+			name: "invalid Kafka message format",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.MessageFormat = "avro"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.message_format must be one of: json, protobuf",
+		},
+		{
+			name: "allocation replay interval not positive",
+			config: func() *Config {
+				c := GetDefaults()
+				c.AllocationService.ReplayInterval = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "allocation_service.replay_interval must be positive",
+		},
+		{
+			name: "allocation required while disabled",
+			config: func() *Config {
+				c := GetDefaults()
+				c.AllocationService.Enabled = false
+				c.AllocationService.Required = true
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "allocation_service.required requires allocation_service.enabled",
+		},
+		{
+			name: "tenant execution service URL not absolute",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Tenants = map[string]TenantConfig{"acme": {ExecutionServiceURL: "acme-execution-service"}}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "tenants.acme.execution_service_url must be an absolute URL",
+		},
+		{
+			name: "invalid tenant excessive fill count severity",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Tenants = map[string]TenantConfig{"acme": {Validation: TenantValidationConfig{ExcessiveFillCountSeverity: "fatal"}}}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "tenants.acme.validation.excessive_fill_count_severity must be one of: error, warning",
+		},
+		{
+			name: "empty Kafka correlation ID header",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.CorrelationIDHeader = ""
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.correlation_id_header is required",
+		},
+		{
+			name: "negative Kafka max processing attempts",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.MaxProcessingAttempts = -1
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.max_processing_attempts must not be negative",
+		},
+		{
+			name: "negative Kafka max message size",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.MaxMessageSizeBytes = -1
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.max_message_size_bytes must not be negative",
+		},
+		{
+			name: "negative lag poll interval",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.LagPollInterval = -time.Second
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.lag_poll_interval must not be negative",
+		},
+		{
+			name: "negative execution service rate limit",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.RateLimit = -1
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.rate_limit and execution_service.rate_limit_burst must not be negative",
+		},
+		{
+			name: "negative kafka commit batch size",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.CommitBatchSize = -1
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.commit_batch_size must not be negative",
+		},
+		{
+			name: "batched kafka commits without interval",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.CommitBatchSize = 100
+				c.Kafka.CommitInterval = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.commit_interval must be positive when kafka.commit_batch_size is greater than 1",
+		},
+		{
+			name: "invalid kafka start offset",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.StartOffset = "middle"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.start_offset must be one of: earliest, latest",
+		},
+		{
+			name: "invalid kafka start timestamp",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.StartTimestamp = "2024-01-02"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.start_timestamp must be an RFC 3339 timestamp",
+		},
+		{
+			name: "valid kafka start timestamp",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.StartOffset = "earliest"
+				c.Kafka.StartTimestamp = "2024-01-02T15:04:05Z"
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "http host that does not form a listen address",
+			config: func() *Config {
+				c := GetDefaults()
+				c.HTTP.Host = "::"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "http.host \"::\" does not form a listen address; use a hostname or IPv4 address such as 0.0.0.0",
+		},
+		{
+			name: "zero http read timeout",
+			config: func() *Config {
+				c := GetDefaults()
+				c.HTTP.ReadTimeout = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "http.read_timeout, http.write_timeout and http.idle_timeout must be positive",
+		},
+		{
+			name: "kafka broker without port",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.Brokers = []string{"kafka:9092", "kafka-2"}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.brokers entry \"kafka-2\" must be a host:port address",
+		},
+		{
+			name: "empty kafka broker",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.Brokers = []string{""}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.brokers entry \"\" must be a host:port address",
+		},
+		{
+			name: "zero kafka fetch timeout",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.FetchTimeout = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.consumer_timeout, kafka.connection_timeout and kafka.fetch_timeout must be positive",
+		},
+		{
+			name: "kafka retries without backoff",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.RetryBackoff = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.retry_backoff must be positive when kafka.max_retries is set",
+		},
+		{
+			name: "relative execution service base url",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.BaseURL = "globeco-execution-service:8084"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.base_url \"globeco-execution-service:8084\" must be an absolute URL such as http://host:port",
+		},
+		{
+			name: "zero execution service timeout",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.Timeout = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.timeout must be positive",
+		},
+		{
+			name: "negative execution service retries",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.MaxRetries = -1
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.max_retries must not be negative",
+		},
+		{
+			name: "execution service retries without backoff",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.RetryBackoff = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.retry_backoff must be positive when max_retries is set",
+		},
+		{
+			name: "zero execution service circuit breaker timeout",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.CircuitBreaker.Timeout = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.circuit_breaker.timeout must be positive",
+		},
+		{
+			name: "relative allocation service base url",
+			config: func() *Config {
+				c := GetDefaults()
+				c.AllocationService.BaseURL = "/allocations"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "allocation_service.base_url \"/allocations\" must be an absolute URL such as http://host:port",
+		},
+		{
+			name: "zero allocation service timeout",
+			config: func() *Config {
+				c := GetDefaults()
+				c.AllocationService.Timeout = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "allocation_service.timeout must be positive",
+		},
+		{
+			name: "execution service without retries needs no backoff",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.MaxRetries = 0
+				c.ExecutionService.RetryBackoff = 0
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "disabled allocation service is not checked",
+			config: func() *Config {
+				c := GetDefaults()
+				c.AllocationService.Enabled = false
+				c.AllocationService.BaseURL = "not a url"
+				c.AllocationService.Timeout = 0
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "zero duplicate price change threshold",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Validation.DuplicatePriceChangeThresholdPercent = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "validation.duplicate_price_change_threshold_percent must be positive",
+		},
+		{
+			name: "unknown execution update field",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.UpdateFields = []string{"total_amount", "ticker"}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.update_fields must contain only: total_amount, number_of_fills",
+		},
+		{
+			name: "invalid allocation trigger",
+			config: func() *Config {
+				c := GetDefaults()
+				c.AllocationService.Trigger = "partial"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "allocation_service.trigger must be one of: closed, full, terminal",
+		},
+		{
+			name: "empty redact field name",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Logging.RedactFields = []string{"securityId", " "}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "logging.redact_fields must not contain empty field names",
+		},
+		{
+			name: "negative log sampling initial",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Logging.SamplingInitial = -1
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "logging.sampling_initial must not be negative",
+		},
+		{
+			name: "log sampling without thereafter",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Logging.SamplingInitial = 10
+				c.Logging.SamplingThereafter = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "logging.sampling_thereafter must be positive when logging.sampling_initial is set",
+		},
+		{
+			name: "debug payload logging without max length",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Logging.DebugPayloadLogging = true
+				c.Logging.DebugPayloadMaxLength = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "logging.debug_payload_max_length must be positive when logging.debug_payload_logging is enabled",
+		},
+		{
+			name: "negative HTTP max body bytes",
+			config: func() *Config {
+				c := GetDefaults()
+				c.HTTP.MaxBodyBytes = -1
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "http.max_body_bytes must not be negative",
+		},
+		{
+			name: "negative HTTP rate limit TTL",
+			config: func() *Config {
+				c := GetDefaults()
+				c.HTTP.RateLimit.TTL = -time.Second
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "http.rate_limit.ttl must not be negative",
+		},
+		{
+			name: "invalid trusted proxy",
+			config: func() *Config {
+				c := GetDefaults()
+				c.HTTP.RateLimit.TrustedProxies = []string{"10.0.0.0/8", "ingress"}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "http.rate_limit.trusted_proxies entry \"ingress\" must be a CIDR or IP address",
+		},
+		{
+			name: "negative execution service cache TTL",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.CacheTTL = -time.Second
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.cache_ttl must not be negative",
+		},
+		{
+			name: "execution service auth token URL without client ID",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.Auth.TokenURL = "https://auth.example.com/token"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.auth.client_id is required with execution_service.auth.token_url",
+		},
+		{
+			name: "execution service auth token and token URL",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.Auth = AuthConfig{Token: "secret", TokenURL: "https://auth.example.com/token", ClientID: "confirmation"}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.auth.token and execution_service.auth.token_url are mutually exclusive",
+		},
+		{
+			name: "execution service health path without leading slash",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.HealthPath = "healthz"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.health_path must start with /",
+		},
+		{
+			name: "execution service healthy status range inverted",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.HealthyStatusMin = 300
+				c.ExecutionService.HealthyStatusMax = 200
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.healthy_status_min and healthy_status_max must form a range with min not above max",
+		},
+		{
+			name: "execution service healthy status min above the default max",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.HealthyStatusMin = 300
+				c.ExecutionService.HealthyStatusMax = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.healthy_status_min and healthy_status_max must form a range with min not above max",
+		},
+		{
+			name: "execution service healthy status max below the default min",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.HealthyStatusMin = 0
+				c.ExecutionService.HealthyStatusMax = 199
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.healthy_status_min and healthy_status_max must form a range with min not above max",
+		},
+		{
+			name: "negative execution service max connections per host",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.MaxConnsPerHost = -1
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.max_conns_per_host must not be negative",
+		},
+		{
+			name: "negative execution service idle connection timeout",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.IdleConnTimeout = -time.Second
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.idle_conn_timeout must not be negative",
+		},
+		{
+			name: "zero total amount tolerance",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Validation.TotalAmountTolerancePercent = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "validation.total_amount_tolerance_percent must be greater than 0",
+		},
+		{
+			name: "zero future timestamp tolerance",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Validation.FutureToleranceSeconds = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "validation.future_tolerance_seconds must be greater than 0",
+		},
+		{
+			name: "zero max timestamp age",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Validation.MaxTimestampAge = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "validation.max_timestamp_age must be greater than 0",
+		},
+		{
+			name: "field mapping with protobuf messages",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.MessageFormat = "protobuf"
+				c.Kafka.FieldMapping = map[string]string{"avg_price": "averagePrice"}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.field_mapping requires kafka.message_format json",
+		},
+		{
+			name: "field mapping to unknown fill field",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.FieldMapping = map[string]string{"avg_price": "avgPrice"}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.field_mapping maps avg_price to unknown fill field avgPrice",
+		},
+		{
+			name: "message size classes not ascending",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Metrics.MessageSizeClasses = []int{4096, 1024}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "metrics.message_size_classes must be positive and strictly ascending",
+		},
+		{
+			name: "invalid canary mode",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Canary.Mode = "dry-run"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "canary.mode must be one of: live, shadow",
+		},
+		{
+			name: "disabled allocation service without base URL",
+			config: func() *Config {
+				c := GetDefaults()
+				c.AllocationService.Enabled = false
+				c.AllocationService.BaseURL = ""
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "retry budget without refill rate",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Performance.RetryBudgetRefillRate = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "performance.retry_budget_refill_rate must be positive when performance.retry_budget_tokens is set",
+		},
+		{
+			name: "adaptive concurrency min above max",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Performance.AdaptiveConcurrencyMin = 5
+				c.Performance.AdaptiveConcurrencyMax = 4
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "performance.adaptive_concurrency_min must be between 1 and performance.adaptive_concurrency_max",
+		},
+		{
+			name: "invalid missing field mode",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Validation.MissingFieldMode = "relaxed"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "validation.missing_field_mode must be one of: strict, lenient",
+		},
+		{
+			name: "invalid excessive fill count severity",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Validation.ExcessiveFillCountSeverity = "fatal"
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "validation.excessive_fill_count_severity must be one of: error, warning",
+		},
+		{
+			name: "negative Redis DB",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Redis.DB = -1
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "redis.db must not be negative",
+		},
+		{
+			name: "DLQ resume low-water mark missing",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.DLQPauseHighWaterMark = 500
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.dlq_resume_low_water_mark must be at least 1",
+		},
+		{
+			name: "DLQ resume low-water mark not below high-water mark",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.DLQPauseHighWaterMark = 500
+				c.Kafka.DLQResumeLowWaterMark = 500
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.dlq_resume_low_water_mark must be less than kafka.dlq_pause_high_water_mark",
+		},
+		{
+			name: "DLQ pause high-water mark above DLQ capacity",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.DLQPauseHighWaterMark = 2000
+				c.Kafka.DLQResumeLowWaterMark = 100
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.dlq_pause_high_water_mark must not exceed performance.dead_letter_queue_max_size",
+		},
+		{
+			name: "DLQ persistence without a file path",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Performance.DeadLetterQueuePersistToDisk = true
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "performance.dead_letter_queue_file_path is required when performance.dead_letter_queue_persist_to_disk is enabled",
+		},
 		{
 			name: "empty Kafka brokers",
 			config: func() *Config {
@@ -122,6 +810,26 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "kafka.topic is required",
 		},
+		{
+			name: "Kafka topics without single topic",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.Topic = ""
+				c.Kafka.Topics = []string{"fills", "fills-replay"}
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "empty name in Kafka topics",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Kafka.Topics = []string{"fills", ""}
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "kafka.topics must not contain empty topic names",
+		},
 		{
 			name: "empty Kafka consumer group",
 			config: func() *Config {
@@ -142,6 +850,16 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "execution_service.base_url is required",
 		},
+		{
+			name: "negative execution service get timeout",
+			config: func() *Config {
+				c := GetDefaults()
+				c.ExecutionService.GetTimeout = -time.Second
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "execution_service.get_timeout must not be negative",
+		},
 		{
 			name: "invalid circuit breaker failure threshold",
 			config: func() *Config {
@@ -170,7 +888,7 @@ func TestConfig_Validate(t *testing.T) {
 				return c
 			}(),
 			wantErr: true,
-			errMsg:  "logging.format must be one of: json, console",
+			errMsg:  "logging.format must be one of: json, console, logfmt",
 		},
 		{
 			name: "invalid logging output",
@@ -222,6 +940,37 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "performance.worker_pool_size must be at least 1",
 		},
+		{
+			name: "invalid processing time window size",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Performance.ProcessingTimeWindowSize = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "performance.processing_time_window_size must be at least 1",
+		},
+		{
+			name: "price anomaly check without tolerance",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Validation.PriceAnomalyWindow = 5
+				c.Validation.PriceAnomalyTolerancePercent = 0
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "validation.price_anomaly_tolerance_percent must be positive when validation.price_anomaly_window is set",
+		},
+		{
+			name: "invalid ticker pattern",
+			config: func() *Config {
+				c := GetDefaults()
+				c.Validation.TickerPattern = `^[A-Z{1,6}$`
+				return c
+			}(),
+			wantErr: true,
+			errMsg:  "validation.ticker_pattern is not a valid regular expression",
+		},
 	}
 
 	for _, tt := range tests {
@@ -237,6 +986,39 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestConfig_TenantOverrides(t *testing.T) {
+	noFillCountCheck := 0.0
+	config := GetDefaults()
+	config.Tenants = map[string]TenantConfig{
+		"acme": {
+			ExecutionServiceURL: "http://acme-execution-service:8084",
+			Validation: TenantValidationConfig{
+				MaxFillsPerFilledShare: &noFillCountCheck,
+				MissingFieldMode:       "lenient",
+			},
+		},
+		"globex": {AllocationServiceURL: "http://globex-allocation-service:8089"},
+	}
+	require.NoError(t, config.Validate())
+
+	assert.Equal(t, []string{"acme", "globex"}, config.TenantIDs())
+
+	// Tenant IDs match case-insensitively
+	assert.Equal(t, "http://acme-execution-service:8084", config.ExecutionServiceFor("ACME").BaseURL)
+	assert.Equal(t, config.ExecutionService.Timeout, config.ExecutionServiceFor("acme").Timeout)
+	assert.Equal(t, config.AllocationService.BaseURL, config.AllocationServiceFor("acme").BaseURL)
+	assert.Equal(t, "http://globex-allocation-service:8089", config.AllocationServiceFor("globex").BaseURL)
+
+	acmeValidation := config.ValidationFor("acme")
+	assert.Equal(t, 0.0, acmeValidation.MaxFillsPerFilledShare)
+	assert.Equal(t, "lenient", acmeValidation.MissingFieldMode)
+	assert.Equal(t, config.Validation.ExcessiveFillCountSeverity, acmeValidation.ExcessiveFillCountSeverity)
+
+	// Unknown tenants use the global settings
+	assert.Equal(t, config.ExecutionService, config.ExecutionServiceFor("initech"))
+	assert.Equal(t, config.Validation, config.ValidationFor(""))
+}
+
 func TestConfig_GetHTTPAddress(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -276,6 +1058,32 @@ func TestConfig_GetHTTPAddress(t *testing.T) {
 	}
 }
 
+func TestConfig_GetRuntimeLimits(t *testing.T) {
+	config := GetDefaults()
+
+	limits := config.GetRuntimeLimits()
+	assert.Equal(t, "10s", limits.Timeouts.ExecutionServiceGet)
+	assert.Equal(t, "10s", limits.Timeouts.ExecutionServiceUpdate)
+	assert.Equal(t, "5s", limits.Timeouts.ExecutionServiceHealth)
+	assert.Equal(t, 10, limits.Concurrency.MaxConcurrentRequests)
+	assert.Equal(t, 1000, limits.Capacity.DeadLetterQueueMaxSize)
+
+	config.ExecutionService.GetTimeout = 2 * time.Second
+	limits = config.GetRuntimeLimits()
+	assert.Equal(t, "2s", limits.Timeouts.ExecutionServiceGet)
+	assert.Equal(t, "10s", limits.Timeouts.ExecutionServiceUpdate)
+}
+
+func TestKafkaConfig_GetTopics(t *testing.T) {
+	kafka := KafkaConfig{Topic: "fills"}
+	assert.Equal(t, []string{"fills"}, kafka.GetTopics())
+
+	kafka.Topics = []string{"fills", "fills-replay"}
+	assert.Equal(t, []string{"fills", "fills-replay"}, kafka.GetTopics())
+
+	assert.Nil(t, KafkaConfig{}.GetTopics())
+}
+
 func TestValidLogLevels(t *testing.T) {
 	validLevels := []string{"debug", "info", "warn", "error"}
 
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 type Loader struct {
 	configName string
 	configPath string
+	configFile string // Exact file to read instead of searching for configName
 	envPrefix  string
 }
 
@@ -32,6 +36,14 @@ func (l *Loader) WithConfigFile(name, path string) *Loader {
 	return l
 }
 
+// WithConfigFilePath reads exactly the given file instead of searching the config
+// paths. The format (YAML or JSON) follows the file extension; a missing file leaves
+// the defaults and environment variables in effect.
+func (l *Loader) WithConfigFilePath(path string) *Loader {
+	l.configFile = path
+	return l
+}
+
 // WithEnvPrefix sets the environment variable prefix
 func (l *Loader) WithEnvPrefix(prefix string) *Loader {
 	l.envPrefix = prefix
@@ -47,11 +59,18 @@ func (l *Loader) Load() (*Config, error) {
 	v := viper.New()
 
 	// Set config file settings
-	v.SetConfigName(l.configName)
-	v.SetConfigType("yaml")
-	v.AddConfigPath(l.configPath)
-	v.AddConfigPath("/etc/confirmation-service/")
-	v.AddConfigPath("$HOME/.confirmation-service")
+	if l.configFile != "" {
+		v.SetConfigFile(l.configFile)
+		if filepath.Ext(l.configFile) == "" {
+			v.SetConfigType("yaml")
+		}
+	} else {
+		v.SetConfigName(l.configName)
+		v.SetConfigType("yaml")
+		v.AddConfigPath(l.configPath)
+		v.AddConfigPath("/etc/confirmation-service/")
+		v.AddConfigPath("$HOME/.confirmation-service")
+	}
 
 	// Setup environment variable handling
 	v.SetEnvPrefix(l.envPrefix)
@@ -61,9 +80,10 @@ func (l *Loader) Load() (*Config, error) {
 	// Bind specific environment variables for backward compatibility
 	l.bindEnvironmentVariables(v)
 
-	// Try to read config file (optional)
+	// Try to read config file (optional); environment variables take precedence over it
 	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) && !errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("failed to read config file: %w", err)
 		}
 		// Config file not found is OK, we'll use defaults + env vars
@@ -92,30 +112,78 @@ func (l *Loader) bindEnvironmentVariables(v *viper.Viper) {
 	// HTTP configuration
 	v.BindEnv("http.port", "HTTP_PORT", "PORT")
 	v.BindEnv("http.host", "HTTP_HOST", "HOST")
+	v.BindEnv("http.admin_endpoints_enabled", "HTTP_ADMIN_ENDPOINTS_ENABLED")
+	v.BindEnv("http.cors.allowed_origins", "HTTP_CORS_ALLOWED_ORIGINS")
+	v.BindEnv("http.max_body_bytes", "HTTP_MAX_BODY_BYTES")
+	v.BindEnv("http.rate_limit.requests_per_second", "HTTP_RATE_LIMIT")
+	v.BindEnv("http.rate_limit.burst", "HTTP_RATE_LIMIT_BURST")
+	v.BindEnv("http.rate_limit.ttl", "HTTP_RATE_LIMIT_TTL")
+	v.BindEnv("http.rate_limit.trusted_proxies", "HTTP_TRUSTED_PROXIES")
 
 	// Kafka configuration
 	v.BindEnv("kafka.brokers", "KAFKA_BROKERS")
 	v.BindEnv("kafka.topic", "KAFKA_TOPIC")
+	v.BindEnv("kafka.topics", "KAFKA_TOPICS")
 	v.BindEnv("kafka.consumer_group", "KAFKA_CONSUMER_GROUP")
+	v.BindEnv("kafka.message_format", "KAFKA_MESSAGE_FORMAT")
+	v.BindEnv("kafka.correlation_id_header", "KAFKA_CORRELATION_ID_HEADER")
+	v.BindEnv("kafka.max_message_size_bytes", "KAFKA_MAX_MESSAGE_SIZE_BYTES")
+	v.BindEnv("kafka.max_processing_attempts", "KAFKA_MAX_PROCESSING_ATTEMPTS")
+	v.BindEnv("kafka.lag_poll_interval", "KAFKA_LAG_POLL_INTERVAL")
+	v.BindEnv("kafka.start_offset", "KAFKA_START_OFFSET")
+	v.BindEnv("kafka.start_timestamp", "KAFKA_START_TIMESTAMP")
+	v.BindEnv("kafka.commit_batch_size", "KAFKA_COMMIT_BATCH_SIZE")
+	v.BindEnv("kafka.commit_interval", "KAFKA_COMMIT_INTERVAL")
 
 	// Execution Service configuration
 	v.BindEnv("execution_service.base_url", "EXECUTION_SERVICE_URL")
 	v.BindEnv("execution_service.timeout", "EXECUTION_SERVICE_TIMEOUT")
+	v.BindEnv("execution_service.cache_ttl", "EXECUTION_SERVICE_CACHE_TTL")
+	v.BindEnv("execution_service.rate_limit", "EXECUTION_SERVICE_RATE_LIMIT")
+	v.BindEnv("execution_service.rate_limit_burst", "EXECUTION_SERVICE_RATE_LIMIT_BURST")
+	v.BindEnv("execution_service.auth.token", "EXECUTION_SERVICE_AUTH_TOKEN")
+	v.BindEnv("execution_service.auth.client_secret", "EXECUTION_SERVICE_AUTH_CLIENT_SECRET")
+	v.BindEnv("execution_service.update_fields", "EXECUTION_SERVICE_UPDATE_FIELDS")
+
+	// Allocation Service configuration
+	v.BindEnv("allocation_service.enabled", "ALLOCATION_SERVICE_ENABLED")
+	v.BindEnv("allocation_service.base_url", "ALLOCATION_SERVICE_URL")
+	v.BindEnv("allocation_service.required", "ALLOCATION_SERVICE_REQUIRED")
+	v.BindEnv("allocation_service.trigger", "ALLOCATION_SERVICE_TRIGGER")
+
+	// Performance configuration
+	v.BindEnv("performance.dead_letter_queue_enabled", "DLQ_ENABLED")
+	v.BindEnv("performance.dead_letter_queue_persist_to_disk", "DLQ_PERSIST_TO_DISK")
+	v.BindEnv("performance.dead_letter_queue_file_path", "DLQ_FILE_PATH")
+	v.BindEnv("performance.dead_letter_queue_compaction_interval", "DLQ_COMPACTION_INTERVAL")
 
 	// Logging configuration
 	v.BindEnv("logging.level", "LOG_LEVEL")
 	v.BindEnv("logging.format", "LOG_FORMAT")
 	v.BindEnv("logging.output", "LOG_OUTPUT")
+	v.BindEnv("logging.debug_payload_logging", "LOG_DEBUG_PAYLOADS")
+	v.BindEnv("logging.redact_fields", "LOG_REDACT_FIELDS")
+	v.BindEnv("logging.sampling_initial", "LOG_SAMPLING_INITIAL")
+	v.BindEnv("logging.sampling_thereafter", "LOG_SAMPLING_THEREAFTER")
 
 	// Metrics configuration
 	v.BindEnv("metrics.enabled", "METRICS_ENABLED")
 	v.BindEnv("metrics.path", "METRICS_PATH")
+	v.BindEnv("metrics.environment", "ENVIRONMENT")
+	v.BindEnv("metrics.high_cardinality_metrics_enabled", "METRICS_HIGH_CARDINALITY_ENABLED")
 
 	// Tracing configuration
 	v.BindEnv("tracing.enabled", "TRACING_ENABLED")
 	v.BindEnv("tracing.service_name", "TRACING_SERVICE_NAME")
 	v.BindEnv("tracing.service_version", "TRACING_SERVICE_VERSION")
 	v.BindEnv("tracing.exporter", "TRACING_EXPORTER")
+
+	// Canary configuration
+	v.BindEnv("canary.mode", "CANARY_MODE")
+
+	// Redis configuration
+	v.BindEnv("redis.address", "REDIS_ADDRESS")
+	v.BindEnv("redis.password", "REDIS_PASSWORD")
 }
 
 // parseDurations handles duration parsing from string environment variables
@@ -124,13 +192,28 @@ func (l *Loader) parseDurations(v *viper.Viper, config *Config) error {
 		"http.read_timeout":                         &config.HTTP.ReadTimeout,
 		"http.write_timeout":                        &config.HTTP.WriteTimeout,
 		"http.idle_timeout":                         &config.HTTP.IdleTimeout,
+		"http.rate_limit.ttl":                       &config.HTTP.RateLimit.TTL,
 		"kafka.consumer_timeout":                    &config.Kafka.ConsumerTimeout,
 		"kafka.retry_backoff":                       &config.Kafka.RetryBackoff,
+		"kafka.drain_timeout":                       &config.Kafka.DrainTimeout,
+		"kafka.lag_poll_interval":                   &config.Kafka.LagPollInterval,
+		"kafka.commit_interval":                     &config.Kafka.CommitInterval,
 		"execution_service.timeout":                 &config.ExecutionService.Timeout,
+		"execution_service.get_timeout":             &config.ExecutionService.GetTimeout,
+		"execution_service.update_timeout":          &config.ExecutionService.UpdateTimeout,
+		"execution_service.health_timeout":          &config.ExecutionService.HealthTimeout,
 		"execution_service.retry_backoff":           &config.ExecutionService.RetryBackoff,
+		"execution_service.cache_ttl":               &config.ExecutionService.CacheTTL,
+		"execution_service.idle_conn_timeout":       &config.ExecutionService.IdleConnTimeout,
 		"execution_service.circuit_breaker.timeout": &config.ExecutionService.CircuitBreaker.Timeout,
 		"health.startup_grace_period":               &config.Health.StartupGracePeriod,
 		"health.check_interval":                     &config.Health.CheckInterval,
+		"redis.dial_timeout":                        &config.Redis.DialTimeout,
+		"allocation_service.replay_interval":        &config.AllocationService.ReplayInterval,
+		"validation.max_timestamp_age":              &config.Validation.MaxTimestampAge,
+
+		"performance.adaptive_concurrency_latency_target":   &config.Performance.AdaptiveConcurrencyLatencyTarget,
+		"performance.dead_letter_queue_compaction_interval": &config.Performance.DeadLetterQueueCompactionInterval,
 	}
 
 	for key, field := range durationFields {
@@ -154,27 +237,14 @@ func LoadFromEnvironment() (*Config, error) {
 	return loader.Load()
 }
 
-// LoadFromFile loads configuration from a specific file
+// LoadFromFile loads configuration from a YAML or JSON file, with environment
+// variables overriding the values it sets
 func LoadFromFile(filePath string) (*Config, error) {
-	// Extract directory and filename
-	dir := "."
-	name := "config"
-
-	if filePath != "" {
-		if lastSlash := strings.LastIndex(filePath, "/"); lastSlash != -1 {
-			dir = filePath[:lastSlash]
-			name = filePath[lastSlash+1:]
-		} else {
-			name = filePath
-		}
-
-		// Remove file extension if present
-		if lastDot := strings.LastIndex(name, "."); lastDot != -1 {
-			name = name[:lastDot]
-		}
+	if filePath == "" {
+		return LoadFromEnvironment()
 	}
 
-	loader := NewLoader().WithConfigFile(name, dir)
+	loader := NewLoader().WithConfigFilePath(filePath)
 	return loader.Load()
 }
 
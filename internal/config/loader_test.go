@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -79,6 +80,92 @@ func TestLoadFromFile_NonExistentFile(t *testing.T) {
 	assert.Equal(t, "fills", config.Kafka.Topic)
 }
 
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadFromFile_YAML(t *testing.T) {
+	path := writeConfigFile(t, "service.yaml", `
+http:
+  port: 9191
+kafka:
+  brokers: ["kafka-a:9092"]
+execution_service:
+  base_url: "http://execution:8084"
+  timeout: "7s"
+`)
+
+	config, err := LoadFromFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 9191, config.HTTP.Port)
+	assert.Equal(t, []string{"kafka-a:9092"}, config.Kafka.Brokers)
+	assert.Equal(t, "http://execution:8084", config.ExecutionService.BaseURL)
+	assert.Equal(t, 7*time.Second, config.ExecutionService.Timeout)
+	// Unset values keep their defaults
+	assert.Equal(t, "fills", config.Kafka.Topic)
+}
+
+func TestLoadFromFile_JSON(t *testing.T) {
+	path := writeConfigFile(t, "service.json", `{
+  "http": {"port": 9292},
+  "logging": {"level": "warn"},
+  "execution_service": {"retry_backoff": "250ms"}
+}`)
+
+	config, err := LoadFromFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 9292, config.HTTP.Port)
+	assert.Equal(t, "warn", config.Logging.Level)
+	assert.Equal(t, 250*time.Millisecond, config.ExecutionService.RetryBackoff)
+}
+
+func TestLoadFromFile_EnvironmentOnly(t *testing.T) {
+	t.Setenv("HTTP_PORT", "9393")
+	t.Setenv("LOG_LEVEL", "debug")
+
+	config, err := LoadFromFile("")
+	require.NoError(t, err)
+
+	assert.Equal(t, 9393, config.HTTP.Port)
+	assert.Equal(t, "debug", config.Logging.Level)
+}
+
+func TestLoadFromFile_EnvironmentOverridesFile(t *testing.T) {
+	path := writeConfigFile(t, "service.yaml", `
+http:
+  port: 9191
+logging:
+  level: "warn"
+execution_service:
+  base_url: "http://execution:8084"
+  timeout: "7s"
+`)
+	t.Setenv("HTTP_PORT", "9494")
+	t.Setenv("EXECUTION_SERVICE_TIMEOUT", "3s")
+
+	config, err := LoadFromFile(path)
+	require.NoError(t, err)
+
+	// Set in both: the environment wins
+	assert.Equal(t, 9494, config.HTTP.Port)
+	assert.Equal(t, 3*time.Second, config.ExecutionService.Timeout)
+	// Set only in the file
+	assert.Equal(t, "warn", config.Logging.Level)
+	assert.Equal(t, "http://execution:8084", config.ExecutionService.BaseURL)
+}
+
+func TestLoadFromFile_InvalidFile(t *testing.T) {
+	path := writeConfigFile(t, "service.json", `{"http": {"port": `)
+
+	_, err := LoadFromFile(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read config file")
+}
+
 func TestGetEnvironment(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -286,3 +373,37 @@ func TestConfigValidationFailure(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "configuration validation failed")
 }
+
+func TestLoadFromFile_DeadLetterQueuePersistenceFromEnvironment(t *testing.T) {
+	t.Setenv("DLQ_PERSIST_TO_DISK", "true")
+	t.Setenv("DLQ_FILE_PATH", "/var/lib/confirmation-service/dlq.jsonl")
+	t.Setenv("DLQ_COMPACTION_INTERVAL", "5m")
+
+	config, err := LoadFromFile("")
+	require.NoError(t, err)
+
+	assert.True(t, config.Performance.DeadLetterQueuePersistToDisk)
+	assert.Equal(t, "/var/lib/confirmation-service/dlq.jsonl", config.Performance.DeadLetterQueueFilePath)
+	assert.Equal(t, 5*time.Minute, config.Performance.DeadLetterQueueCompactionInterval)
+}
+
+func TestLoadFromFile_RateLimitTTL(t *testing.T) {
+	config, err := LoadFromFile("")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, config.HTTP.RateLimit.TTL)
+
+	path := writeConfigFile(t, "service.yaml", `
+http:
+  rate_limit:
+    requests_per_second: 5
+    ttl: "10m"
+`)
+	config, err = LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, config.HTTP.RateLimit.TTL)
+
+	t.Setenv("HTTP_RATE_LIMIT_TTL", "30s")
+	config, err = LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, config.HTTP.RateLimit.TTL)
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+)
+
+// Redacted returns the configuration keyed by its configuration file names, with the
+// values of fields tagged sensitive:"true" masked. Unset sensitive fields stay empty
+// so it is still visible whether they are configured.
+func (c *Config) Redacted() map[string]interface{} {
+	return redactStruct(reflect.ValueOf(*c))
+}
+
+func redactStruct(v reflect.Value) map[string]interface{} {
+	result := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		value := v.Field(i)
+		if field.Tag.Get("sensitive") == "true" && !value.IsZero() {
+			result[name] = logger.RedactedValue
+			continue
+		}
+		result[name] = redactValue(value)
+	}
+	return result
+}
+
+func redactValue(v reflect.Value) interface{} {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return redactStruct(v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem())
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		result := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			result[fmt.Sprint(iter.Key().Interface())] = redactValue(iter.Value())
+		}
+		return result
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		result := make([]interface{}, v.Len())
+		for i := range result {
+			result[i] = redactValue(v.Index(i))
+		}
+		return result
+	default:
+		return v.Interface()
+	}
+}
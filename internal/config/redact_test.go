@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Redacted(t *testing.T) {
+	c := GetDefaults()
+	c.Redis.Address = "redis:6379"
+	c.Redis.Password = "hunter2"
+	c.ExecutionService.Auth.ClientID = "confirmation-service"
+	c.ExecutionService.Auth.ClientSecret = "s3cret"
+
+	redacted := c.Redacted()
+
+	redis, ok := redacted["redis"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, logger.RedactedValue, redis["password"])
+	assert.Equal(t, "redis:6379", redis["address"])
+
+	executionService, ok := redacted["execution_service"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, c.ExecutionService.BaseURL, executionService["base_url"])
+	assert.Equal(t, c.ExecutionService.Timeout.String(), executionService["timeout"])
+
+	auth, ok := executionService["auth"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, logger.RedactedValue, auth["client_secret"])
+	assert.Equal(t, "confirmation-service", auth["client_id"])
+	// Unset secrets stay empty so it is visible that they are not configured
+	assert.Equal(t, "", auth["token"])
+
+	// The original configuration is unchanged
+	assert.Equal(t, "hunter2", c.Redis.Password)
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -14,14 +15,24 @@ type AllocationServiceExecutionDTO struct {
 	Destination        string   `json:"destination" validate:"required"`
 	SecurityID         string   `json:"securityId" validate:"required"`
 	Ticker             string   `json:"ticker" validate:"required"`
-	Quantity           int64    `json:"quantity" validate:"required,min=1"`
+	Quantity           float64  `json:"quantity" validate:"required,gt=0"`
 	LimitPrice         *float64 `json:"limitPrice"` // Always null
 	ReceivedTimestamp  string   `json:"receivedTimestamp" validate:"required,datetime"`
 	SentTimestamp      string   `json:"sentTimestamp" validate:"required,datetime"`
 	LastFillTimestamp  string   `json:"lastFillTimestamp,omitempty"` // nullable in OpenAPI
-	QuantityFilled     int64    `json:"quantityFilled" validate:"required,min=0"`
+	QuantityFilled     float64  `json:"quantityFilled" validate:"required,min=0"`
 	TotalAmount        float64  `json:"totalAmount" validate:"required,min=0"`
 	AveragePrice       float64  `json:"averagePrice" validate:"required,min=0"`
+
+	// Sent as the Idempotency-Key header rather than in the body, so the Allocation
+	// Service can recognize a post repeated for a reprocessed fill
+	IdempotencyKey string `json:"-"`
+}
+
+// AllocationIdempotencyKey derives the idempotency key for posting a fill's
+// execution; it is the same every time the same fill version is processed
+func AllocationIdempotencyKey(fill *Fill) string {
+	return fmt.Sprintf("fill-%d-exec-%d-v%d", fill.ID, fill.ExecutionServiceID, fill.Version)
 }
 
 // NewAllocationServiceExecutionDTO maps a Fill to AllocationServiceExecutionDTO
@@ -46,5 +57,6 @@ func NewAllocationServiceExecutionDTO(fill *Fill) *AllocationServiceExecutionDTO
 		QuantityFilled:     fill.QuantityFilled,
 		TotalAmount:        fill.TotalAmount,
 		AveragePrice:       fill.AveragePrice,
+		IdempotencyKey:     AllocationIdempotencyKey(fill),
 	}
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAllocationServiceExecutionDTO_IdempotencyKey(t *testing.T) {
+	fill := &Fill{ID: 5, ExecutionServiceID: 9, Version: 2, ReceivedTimestamp: 1, SentTimestamp: 2}
+
+	dto := NewAllocationServiceExecutionDTO(fill)
+	assert.Equal(t, "fill-5-exec-9-v2", dto.IdempotencyKey)
+	assert.Equal(t, dto.IdempotencyKey, NewAllocationServiceExecutionDTO(fill).IdempotencyKey)
+
+	fill.Version = 3
+	assert.NotEqual(t, dto.IdempotencyKey, AllocationIdempotencyKey(fill))
+
+	// The key is sent as a header, not in the body
+	body, err := json.Marshal(dto)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "fill-5-exec-9-v2")
+}
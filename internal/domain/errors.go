@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
+	"time"
 )
 
 // ErrorType represents the type of error
@@ -16,6 +18,8 @@ const (
 	ErrorTypeConflict ErrorType = "CONFLICT"
 	// ErrorTypeExternal represents external service errors
 	ErrorTypeExternal ErrorType = "EXTERNAL"
+	// ErrorTypeAllocation represents Allocation Service errors
+	ErrorTypeAllocation ErrorType = "ALLOCATION"
 	// ErrorTypeInternal represents internal service errors
 	ErrorTypeInternal ErrorType = "INTERNAL"
 	// ErrorTypeTimeout represents timeout errors
@@ -33,6 +37,28 @@ type DomainError struct {
 	Cause         error     `json:"-"`
 	Retryable     bool      `json:"retryable"`
 	CorrelationID string    `json:"correlationId,omitempty"`
+
+	// FieldErrors optionally carries the individual failures behind a validation error
+	FieldErrors []FieldError `json:"fieldErrors,omitempty"`
+
+	// RetryAfter is how long the remote service asked callers to wait before trying
+	// again (its Retry-After header); zero when it gave no hint
+	RetryAfter time.Duration `json:"-"`
+
+	// RequestRejected is set when the remote service turned the request away without
+	// acting on it, so even a non-idempotent request is safe to send again
+	RequestRejected bool `json:"-"`
+
+	// NotSent is set when a client-side limit held the request back and it never
+	// reached the remote service, so it says nothing about the service's health
+	NotSent bool `json:"-"`
+}
+
+// FieldError describes a validation failure for a single field
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 // Error implements the error interface
@@ -97,6 +123,21 @@ func NewExternalError(service, message string, cause error, retryable bool) *Dom
 	}
 }
 
+// NewAllocationError creates a new Allocation Service error
+func NewAllocationError(message string, cause error, retryable bool) *DomainError {
+	return &DomainError{
+		Type:      ErrorTypeAllocation,
+		Code:      "ALLOCATION_SERVICE_ERROR",
+		Message:   fmt.Sprintf("allocation service error: %s", message),
+		Cause:     cause,
+		Retryable: retryable,
+	}
+}
+
+// AllocationBadRequestCode marks an Allocation Service error caused by the
+// service rejecting the request itself (HTTP 400)
+const AllocationBadRequestCode = "ALLOCATION_BAD_REQUEST"
+
 // NewInternalError creates a new internal service error
 func NewInternalError(message string, cause error) *DomainError {
 	return &DomainError{
@@ -129,6 +170,60 @@ func NewCircuitBreakerError(service string) *DomainError {
 	}
 }
 
+// WithFieldErrors attaches structured per-field validation failures to the error
+func (e *DomainError) WithFieldErrors(fieldErrors []FieldError) *DomainError {
+	e.FieldErrors = fieldErrors
+	return e
+}
+
+// WithRetryAfter records how long the remote service asked callers to wait before retrying
+func (e *DomainError) WithRetryAfter(retryAfter time.Duration) *DomainError {
+	e.RetryAfter = retryAfter
+	return e
+}
+
+// WithRequestRejected marks the error as a request the remote service did not act on
+func (e *DomainError) WithRequestRejected() *DomainError {
+	e.RequestRejected = true
+	return e
+}
+
+// IsRequestRejected reports whether err, or any error it wraps, is a request the remote
+// service turned away without acting on it
+func IsRequestRejected(err error) bool {
+	var domainErr *DomainError
+	return errors.As(err, &domainErr) && domainErr.RequestRejected
+}
+
+// WithNotSent marks the error as a request that was never sent to the remote service
+func (e *DomainError) WithNotSent() *DomainError {
+	e.NotSent = true
+	return e
+}
+
+// IsNotSent reports whether err, or any error it wraps, is a request that was never
+// sent to the remote service
+func IsNotSent(err error) bool {
+	var domainErr *DomainError
+	return errors.As(err, &domainErr) && domainErr.NotSent
+}
+
+// RetryAfterOf returns the retry delay requested through err, or any error it wraps,
+// or zero when there is none
+func RetryAfterOf(err error) time.Duration {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.RetryAfter
+	}
+	return 0
+}
+
+// IsErrorType reports whether err, or any error it wraps, is a DomainError of the given type
+func IsErrorType(err error, errorType ErrorType) bool {
+	var domainErr *DomainError
+	return errors.As(err, &domainErr) && domainErr.Type == errorType
+}
+
 // WithCorrelationID adds a correlation ID to the error
 func (e *DomainError) WithCorrelationID(correlationID string) *DomainError {
 	e.CorrelationID = correlationID
@@ -2,7 +2,9 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -158,3 +160,45 @@ func TestErrorTypes(t *testing.T) {
 	assert.Equal(t, "TIMEOUT", string(ErrorTypeTimeout))
 	assert.Equal(t, "CIRCUIT_BREAKER", string(ErrorTypeCircuitBreaker))
 }
+
+func TestIsErrorType(t *testing.T) {
+	conflict := NewConflictError("execution", "version conflict")
+
+	assert.True(t, IsErrorType(conflict, ErrorTypeConflict))
+	assert.True(t, IsErrorType(fmt.Errorf("update failed: %w", conflict), ErrorTypeConflict))
+	assert.False(t, IsErrorType(conflict, ErrorTypeValidation))
+	assert.False(t, IsErrorType(errors.New("plain error"), ErrorTypeConflict))
+	assert.False(t, IsErrorType(nil, ErrorTypeConflict))
+}
+
+func TestDomainError_WithFieldErrors(t *testing.T) {
+	fieldErrors := []FieldError{{Field: "ticker", Code: "REQUIRED_FIELD", Message: "ticker is required"}}
+
+	err := NewValidationError("invalid fill", "ticker: ticker is required").WithFieldErrors(fieldErrors)
+
+	assert.Equal(t, fieldErrors, err.FieldErrors)
+	assert.Equal(t, "VALIDATION_FAILED: invalid fill - ticker: ticker is required", err.Error())
+}
+
+func TestRetryAfterOf(t *testing.T) {
+	rateLimited := NewExternalError("execution-service", "rate limit exceeded", nil, true).WithRetryAfter(2 * time.Second)
+
+	assert.Equal(t, 2*time.Second, RetryAfterOf(rateLimited))
+	assert.Equal(t, 2*time.Second, RetryAfterOf(fmt.Errorf("get failed: %w", rateLimited)))
+	assert.Zero(t, RetryAfterOf(NewExternalError("execution-service", "server error: 500", nil, true)))
+	assert.Zero(t, RetryAfterOf(errors.New("plain error")))
+}
+
+func TestNewAllocationError(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	retryable := NewAllocationError("request failed", cause, true).WithCorrelationID("test-correlation-id")
+	assert.Equal(t, ErrorTypeAllocation, retryable.Type)
+	assert.Equal(t, "ALLOCATION_SERVICE_ERROR", retryable.Code)
+	assert.Equal(t, "allocation service error: request failed", retryable.Message)
+	assert.Equal(t, "test-correlation-id", retryable.CorrelationID)
+	assert.True(t, retryable.IsRetryable())
+	assert.ErrorIs(t, retryable, cause)
+
+	assert.False(t, NewAllocationError("authentication/authorization failed", nil, false).IsRetryable())
+}
@@ -13,12 +13,12 @@ type ExecutionResponse struct {
 	TradeType               string    `json:"tradeType"`
 	Destination             string    `json:"destination"`
 	SecurityID              string    `json:"securityId"`
-	Quantity                int64     `json:"quantity"`
+	Quantity                float64   `json:"quantity"`
 	LimitPrice              float64   `json:"limitPrice"`
 	ReceivedTimestamp       time.Time `json:"receivedTimestamp"`
 	SentTimestamp           time.Time `json:"sentTimestamp"`
 	TradeServiceExecutionID int64     `json:"tradeServiceExecutionId"`
-	QuantityFilled          int64     `json:"quantityFilled"`
+	QuantityFilled          float64   `json:"quantityFilled"`
 	AveragePrice            *float64  `json:"averagePrice"`
 	Version                 int       `json:"version"`
 }
@@ -43,7 +43,7 @@ func (e *ExecutionResponse) UnmarshalJSON(data []byte) error {
 
 	// Parse Quantity (handle both int, float, and scientific notation)
 	if aux.Quantity != nil {
-		if val, err := parseToInt64(aux.Quantity); err == nil {
+		if val, err := parseToFloat64(aux.Quantity); err == nil {
 			e.Quantity = val
 		}
 	}
@@ -57,7 +57,7 @@ func (e *ExecutionResponse) UnmarshalJSON(data []byte) error {
 
 	// Parse QuantityFilled (handle scientific notation)
 	if aux.QuantityFilled != nil {
-		if val, err := parseToInt64(aux.QuantityFilled); err == nil {
+		if val, err := parseToFloat64(aux.QuantityFilled); err == nil {
 			e.QuantityFilled = val
 		}
 	}
@@ -117,9 +117,13 @@ func parseToFloat64(value interface{}) (float64, error) {
 
 // ExecutionUpdateRequest represents the request payload for updating an execution
 type ExecutionUpdateRequest struct {
-	QuantityFilled int64   `json:"quantityFilled" validate:"required,min=0"`
+	QuantityFilled float64 `json:"quantityFilled" validate:"required,min=0"`
 	AveragePrice   float64 `json:"averagePrice" validate:"required,min=0"`
 	Version        int     `json:"version" validate:"required,min=0"`
+
+	// Optional fill totals, sent only to Execution Service variants that accept them
+	TotalAmount   *float64 `json:"totalAmount,omitempty"`
+	NumberOfFills *int     `json:"numberOfFills,omitempty"`
 }
 
 // ExecutionUpdateResponse represents the response from the Execution Service PUT API
@@ -129,14 +133,24 @@ type ExecutionUpdateResponse struct {
 	TradeType               string    `json:"tradeType"`
 	Destination             string    `json:"destination"`
 	SecurityID              string    `json:"securityId"`
-	Quantity                int64     `json:"quantity"`
+	Quantity                float64   `json:"quantity"`
 	LimitPrice              float64   `json:"limitPrice"`
 	ReceivedTimestamp       time.Time `json:"receivedTimestamp"`
 	SentTimestamp           time.Time `json:"sentTimestamp"`
 	TradeServiceExecutionID int64     `json:"tradeServiceExecutionId"`
-	QuantityFilled          int64     `json:"quantityFilled"`
+	QuantityFilled          float64   `json:"quantityFilled"`
 	AveragePrice            *float64  `json:"averagePrice"`
 	Version                 int       `json:"version"`
+
+	// Changed reports whether the update modified the execution. The API does not
+	// say so directly; it is inferred from the version (see InferChanged).
+	Changed bool `json:"-"`
+}
+
+// InferChanged sets Changed by comparing the response version with the version
+// sent in the update request; the Execution Service only increments it on change
+func (e *ExecutionUpdateResponse) InferChanged(requestVersion int) {
+	e.Changed = e.Version != requestVersion
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling for ExecutionUpdateResponse
@@ -159,7 +173,7 @@ func (e *ExecutionUpdateResponse) UnmarshalJSON(data []byte) error {
 
 	// Parse Quantity (handle both int, float, and scientific notation)
 	if aux.Quantity != nil {
-		if val, err := parseToInt64(aux.Quantity); err == nil {
+		if val, err := parseToFloat64(aux.Quantity); err == nil {
 			e.Quantity = val
 		}
 	}
@@ -173,7 +187,7 @@ func (e *ExecutionUpdateResponse) UnmarshalJSON(data []byte) error {
 
 	// Parse QuantityFilled (handle scientific notation)
 	if aux.QuantityFilled != nil {
-		if val, err := parseToInt64(aux.QuantityFilled); err == nil {
+		if val, err := parseToFloat64(aux.QuantityFilled); err == nil {
 			e.QuantityFilled = val
 		}
 	}
@@ -203,12 +217,12 @@ type Execution struct {
 	TradeType               string
 	Destination             string
 	SecurityID              string
-	Quantity                int64
+	Quantity                float64
 	LimitPrice              float64
 	ReceivedTimestamp       time.Time
 	SentTimestamp           time.Time
 	TradeServiceExecutionID int64
-	QuantityFilled          int64
+	QuantityFilled          float64
 	AveragePrice            float64
 	Version                 int
 }
@@ -36,11 +36,11 @@ func TestExecutionResponse_UnmarshalJSON_ScientificNotation(t *testing.T) {
 	assert.Equal(t, "BUY", response.TradeType)
 	assert.Equal(t, "ML", response.Destination)
 	assert.Equal(t, "68336002fe95851f0a2aeda9", response.SecurityID)
-	assert.Equal(t, int64(1000), response.Quantity)  // Should convert 1000.00000000 to 1000
-	assert.Equal(t, float64(0), response.LimitPrice) // Should convert 0E-8 to 0
+	assert.Equal(t, float64(1000), response.Quantity) // Should convert 1000.00000000 to 1000
+	assert.Equal(t, float64(0), response.LimitPrice)  // Should convert 0E-8 to 0
 	assert.Equal(t, int64(5), response.TradeServiceExecutionID)
-	assert.Equal(t, int64(0), response.QuantityFilled) // Should convert 0E-8 to 0
-	assert.Nil(t, response.AveragePrice)               // Should handle null
+	assert.Equal(t, float64(0), response.QuantityFilled) // Should convert 0E-8 to 0
+	assert.Nil(t, response.AveragePrice)                 // Should handle null
 	assert.Equal(t, 1, response.Version)
 
 	// Test GetAveragePrice method
@@ -71,9 +71,9 @@ func TestExecutionResponse_UnmarshalJSON_WithAveragePrice(t *testing.T) {
 
 	assert.Equal(t, int64(5), response.ID)
 	assert.Equal(t, "PARTIAL", response.ExecutionStatus)
-	assert.Equal(t, int64(1000), response.Quantity)
+	assert.Equal(t, float64(1000), response.Quantity)
 	assert.Equal(t, float64(100.50), response.LimitPrice)
-	assert.Equal(t, int64(500), response.QuantityFilled)
+	assert.Equal(t, float64(500), response.QuantityFilled)
 	assert.NotNil(t, response.AveragePrice)
 	assert.Equal(t, float64(99.75), *response.AveragePrice)
 	assert.Equal(t, 2, response.Version)
@@ -82,6 +82,25 @@ func TestExecutionResponse_UnmarshalJSON_WithAveragePrice(t *testing.T) {
 	assert.Equal(t, float64(99.75), response.GetAveragePrice())
 }
 
+func TestExecutionResponse_UnmarshalJSON_FractionalQuantities(t *testing.T) {
+	jsonData := `{
+		"id": 5,
+		"executionStatus": "PART",
+		"quantity": 1.50000000,
+		"quantityFilled": "5E-1",
+		"averagePrice": 10,
+		"version": 1
+	}`
+
+	var response ExecutionResponse
+	err := json.Unmarshal([]byte(jsonData), &response)
+	require.NoError(t, err)
+
+	// Quantities are no longer truncated to whole shares
+	assert.Equal(t, 1.5, response.Quantity)
+	assert.Equal(t, 0.5, response.QuantityFilled)
+}
+
 func TestExecutionResponse_UnmarshalJSON_ScientificNotationStrings(t *testing.T) {
 	// Test with scientific notation as strings
 	jsonData := `{
@@ -104,9 +123,9 @@ func TestExecutionResponse_UnmarshalJSON_ScientificNotationStrings(t *testing.T)
 	err := json.Unmarshal([]byte(jsonData), &response)
 	require.NoError(t, err)
 
-	assert.Equal(t, int64(1500), response.Quantity)      // 1.5E+3 = 1500
-	assert.Equal(t, float64(125), response.LimitPrice)   // 1.25E+2 = 125
-	assert.Equal(t, int64(250), response.QuantityFilled) // 2.5E+2 = 250
+	assert.Equal(t, float64(1500), response.Quantity)      // 1.5E+3 = 1500
+	assert.Equal(t, float64(125), response.LimitPrice)     // 1.25E+2 = 125
+	assert.Equal(t, float64(250), response.QuantityFilled) // 2.5E+2 = 250
 	assert.NotNil(t, response.AveragePrice)
 	assert.Equal(t, float64(99.75), *response.AveragePrice) // 9.975E+1 = 99.75
 }
@@ -192,10 +211,10 @@ func TestFromExecutionResponse(t *testing.T) {
 	assert.Equal(t, "BUY", execution.TradeType)
 	assert.Equal(t, "ML", execution.Destination)
 	assert.Equal(t, "68336002fe95851f0a2aeda9", execution.SecurityID)
-	assert.Equal(t, int64(1000), execution.Quantity)
+	assert.Equal(t, float64(1000), execution.Quantity)
 	assert.Equal(t, float64(100.50), execution.LimitPrice)
 	assert.Equal(t, int64(5), execution.TradeServiceExecutionID)
-	assert.Equal(t, int64(500), execution.QuantityFilled)
+	assert.Equal(t, float64(500), execution.QuantityFilled)
 	assert.Equal(t, float64(0), execution.AveragePrice) // Should be 0 for null
 	assert.Equal(t, 2, execution.Version)
 }
@@ -228,10 +247,10 @@ func TestExecutionUpdateResponse_UnmarshalJSON_ScientificNotation(t *testing.T)
 	assert.Equal(t, "BUY", response.TradeType)
 	assert.Equal(t, "ML", response.Destination)
 	assert.Equal(t, "68336002fe95851f0a2aeda9", response.SecurityID)
-	assert.Equal(t, int64(1000), response.Quantity)
+	assert.Equal(t, float64(1000), response.Quantity)
 	assert.Equal(t, float64(0), response.LimitPrice)
 	assert.Equal(t, int64(8), response.TradeServiceExecutionID)
-	assert.Equal(t, int64(3700), response.QuantityFilled)
+	assert.Equal(t, float64(3700), response.QuantityFilled)
 	assert.NotNil(t, response.AveragePrice)
 	assert.Equal(t, float64(10), *response.AveragePrice)
 	assert.Equal(t, 8, response.Version)
@@ -264,10 +283,10 @@ func TestExecutionUpdateResponse_UnmarshalJSON_WithNullAveragePrice(t *testing.T
 
 	assert.Equal(t, int64(7), response.ID)
 	assert.Equal(t, "PARTIAL", response.ExecutionStatus)
-	assert.Equal(t, int64(1000), response.Quantity)
-	assert.Equal(t, float64(0), response.LimitPrice)   // Should convert 0E-8 to 0
-	assert.Equal(t, int64(0), response.QuantityFilled) // Should convert 0E-8 to 0
-	assert.Nil(t, response.AveragePrice)               // Should handle null
+	assert.Equal(t, float64(1000), response.Quantity)
+	assert.Equal(t, float64(0), response.LimitPrice)     // Should convert 0E-8 to 0
+	assert.Equal(t, float64(0), response.QuantityFilled) // Should convert 0E-8 to 0
+	assert.Nil(t, response.AveragePrice)                 // Should handle null
 	assert.Equal(t, 1, response.Version)
 
 	// Test GetAveragePrice method
@@ -296,9 +315,19 @@ func TestExecutionUpdateResponse_UnmarshalJSON_ScientificNotationStrings(t *test
 	err := json.Unmarshal([]byte(jsonData), &response)
 	require.NoError(t, err)
 
-	assert.Equal(t, int64(1500), response.Quantity)      // 1.5E+3 = 1500
-	assert.Equal(t, float64(125), response.LimitPrice)   // 1.25E+2 = 125
-	assert.Equal(t, int64(250), response.QuantityFilled) // 2.5E+2 = 250
+	assert.Equal(t, float64(1500), response.Quantity)      // 1.5E+3 = 1500
+	assert.Equal(t, float64(125), response.LimitPrice)     // 1.25E+2 = 125
+	assert.Equal(t, float64(250), response.QuantityFilled) // 2.5E+2 = 250
 	assert.NotNil(t, response.AveragePrice)
 	assert.Equal(t, float64(99.75), *response.AveragePrice) // 9.975E+1 = 99.75
 }
+
+func TestExecutionUpdateResponse_InferChanged(t *testing.T) {
+	response := &ExecutionUpdateResponse{Version: 4}
+	response.InferChanged(3)
+	assert.True(t, response.Changed)
+
+	response = &ExecutionUpdateResponse{Version: 3}
+	response.InferChanged(3)
+	assert.False(t, response.Changed)
+}
@@ -3,10 +3,19 @@ package domain
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// Fill represents a trade fill message received from Kafka
+// Fill represents a trade fill message received from Kafka.
+//
+// Quantities are decimal so fractional shares and sub-unit crypto amounts can be
+// traded. They are carried as float64, which holds the 15 significant digits any
+// quantity below a billion with six decimal places needs; they are only compared and
+// multiplied by prices, never accumulated, so no rounding error builds up. Whole-number
+// quantities encode as JSON integers, as they did when the fields were int64.
 type Fill struct {
 	ID                  int64   `json:"id" validate:"required"`
 	ExecutionServiceID  int64   `json:"executionServiceId" validate:"required,min=1"`
@@ -16,22 +25,38 @@ type Fill struct {
 	Destination         string  `json:"destination" validate:"required"`
 	SecurityID          string  `json:"securityId" validate:"required"`
 	Ticker              string  `json:"ticker" validate:"required"`
-	Quantity            int64   `json:"quantity" validate:"required,min=1"`
+	Quantity            float64 `json:"quantity" validate:"required,gt=0"`
 	ReceivedTimestamp   float64 `json:"receivedTimestamp" validate:"required"`
 	SentTimestamp       float64 `json:"sentTimestamp" validate:"required"`
 	LastFilledTimestamp float64 `json:"lastFilledTimestamp" validate:"required"`
-	QuantityFilled      int64   `json:"quantityFilled" validate:"required,min=0"`
+	QuantityFilled      float64 `json:"quantityFilled" validate:"required,min=0"`
 	AveragePrice        float64 `json:"averagePrice" validate:"required,min=0"`
 	NumberOfFills       int     `json:"numberOfFills" validate:"required,min=0"`
 	TotalAmount         float64 `json:"totalAmount" validate:"required,min=0"`
 	Version             int     `json:"version" validate:"required,min=0"`
+	TenantID            string  `json:"tenantId,omitempty"` // Optional; the X-Tenant-ID header takes precedence
+}
+
+// FillJSONFields returns the JSON field names of a fill
+func FillJSONFields() map[string]bool {
+	fillType := reflect.TypeOf(Fill{})
+
+	fields := make(map[string]bool, fillType.NumField())
+	for i := 0; i < fillType.NumField(); i++ {
+		name, _, _ := strings.Cut(fillType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
 }
 
 // Validate performs business rule validation on the Fill
 func (f *Fill) Validate() error {
 	// Validate that quantity filled doesn't exceed original quantity
 	if f.QuantityFilled > f.Quantity {
-		return fmt.Errorf("quantityFilled (%d) cannot exceed original quantity (%d)", f.QuantityFilled, f.Quantity)
+		return fmt.Errorf("quantityFilled (%s) cannot exceed original quantity (%s)",
+			FormatQuantity(f.QuantityFilled), FormatQuantity(f.Quantity))
 	}
 
 	// Validate that average price is reasonable (between 0 and 10000)
@@ -39,18 +64,24 @@ func (f *Fill) Validate() error {
 		return fmt.Errorf("averagePrice (%.2f) must be between 0 and 10000", f.AveragePrice)
 	}
 
-	// Validate timestamp ordering
-	if f.SentTimestamp < f.ReceivedTimestamp {
-		return fmt.Errorf("sentTimestamp cannot be before receivedTimestamp")
-	}
-
-	if f.LastFilledTimestamp < f.SentTimestamp {
-		return fmt.Errorf("lastFilledTimestamp cannot be before sentTimestamp")
-	}
+	// Timestamp ordering is left to the ValidationService, whose severity is configurable
 
 	return nil
 }
 
+// FormatQuantity formats a quantity without an exponent or trailing zeros, so whole
+// numbers read as they did when quantities were integers
+func FormatQuantity(quantity float64) string {
+	return strconv.FormatFloat(quantity, 'f', -1, 64)
+}
+
+// IsLatestVersionSentinel reports whether the fill's version is the configured
+// negative sentinel meaning "use the current execution version". A non-negative
+// sentinel disables the check.
+func (f *Fill) IsLatestVersionSentinel(sentinel int) bool {
+	return sentinel < 0 && f.Version == sentinel
+}
+
 // GetReceivedTime converts the received timestamp to time.Time
 func (f *Fill) GetReceivedTime() time.Time {
 	return time.Unix(int64(f.ReceivedTimestamp), int64((f.ReceivedTimestamp-float64(int64(f.ReceivedTimestamp)))*1e9))
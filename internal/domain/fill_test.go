@@ -51,6 +51,19 @@ func TestFill_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "quantityFilled (1500) cannot exceed original quantity (1000)",
 		},
+		{
+			name: "fractional quantity filled exceeds original quantity",
+			fill: Fill{
+				Quantity:            0.5,
+				QuantityFilled:      0.75,
+				AveragePrice:        100.0,
+				ReceivedTimestamp:   1748354367.509362,
+				SentTimestamp:       1748354367.512467,
+				LastFilledTimestamp: 1748354504.1602714,
+			},
+			wantErr: true,
+			errMsg:  "quantityFilled (0.75) cannot exceed original quantity (0.5)",
+		},
 		{
 			name: "average price too low",
 			fill: Fill{
@@ -87,8 +100,7 @@ func TestFill_Validate(t *testing.T) {
 				SentTimestamp:       1748354367.509362,
 				LastFilledTimestamp: 1748354504.1602714,
 			},
-			wantErr: true,
-			errMsg:  "sentTimestamp cannot be before receivedTimestamp",
+			wantErr: false, // Ordering is checked by the ValidationService
 		},
 		{
 			name: "last filled timestamp before sent timestamp",
@@ -100,8 +112,7 @@ func TestFill_Validate(t *testing.T) {
 				SentTimestamp:       1748354367.512467,
 				LastFilledTimestamp: 1748354367.510000,
 			},
-			wantErr: true,
-			errMsg:  "lastFilledTimestamp cannot be before sentTimestamp",
+			wantErr: false, // Ordering is checked by the ValidationService
 		},
 	}
 
@@ -195,6 +206,26 @@ func TestFill_JSONSerialization(t *testing.T) {
 	assert.Equal(t, fill.Version, unmarshaled.Version)
 }
 
+func TestFill_JSONFractionalQuantities(t *testing.T) {
+	var fill Fill
+	require.NoError(t, json.Unmarshal([]byte(`{"quantity": 1.5, "quantityFilled": 0.5}`), &fill))
+	assert.Equal(t, 1.5, fill.Quantity)
+	assert.Equal(t, 0.5, fill.QuantityFilled)
+
+	// Whole-number quantities still encode as JSON integers
+	data, err := json.Marshal(Fill{Quantity: 1000, QuantityFilled: 250})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"quantity":1000,`)
+	assert.Contains(t, string(data), `"quantityFilled":250,`)
+}
+
+func TestFormatQuantity(t *testing.T) {
+	assert.Equal(t, "1000", FormatQuantity(1000))
+	assert.Equal(t, "0.5", FormatQuantity(0.5))
+	assert.Equal(t, "0.00012345", FormatQuantity(0.00012345))
+	assert.Equal(t, "1000000000", FormatQuantity(1e9))
+}
+
 func TestFill_String(t *testing.T) {
 	fill := Fill{
 		ID:                 11,
@@ -229,3 +260,10 @@ func TestFill_ToUpdateRequest(t *testing.T) {
 	assert.Equal(t, fill.AveragePrice, updateReq.AveragePrice)
 	assert.Equal(t, currentVersion, updateReq.Version)
 }
+
+func TestFill_IsLatestVersionSentinel(t *testing.T) {
+	assert.True(t, (&Fill{Version: -1}).IsLatestVersionSentinel(-1))
+	assert.False(t, (&Fill{Version: -5}).IsLatestVersionSentinel(-1))
+	assert.False(t, (&Fill{Version: 0}).IsLatestVersionSentinel(0))
+	assert.False(t, (&Fill{Version: -1}).IsLatestVersionSentinel(0))
+}
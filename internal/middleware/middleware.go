@@ -3,8 +3,10 @@ package middleware
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
 	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
 	"go.uber.org/zap"
@@ -87,22 +89,84 @@ func MetricsMiddleware(appMetrics *metrics.Metrics) func(next http.Handler) http
 			duration := time.Since(start)
 			statusCode := strconv.Itoa(ww.statusCode)
 			appMetrics.RecordAPICall(r.Method, r.URL.Path, statusCode, duration)
+			appMetrics.RecordHTTPRequest(r.Method, routePattern(r), statusCode, duration)
 		})
 	}
 }
 
-// CORS creates a middleware that handles Cross-Origin Resource Sharing
-func CORS() func(next http.Handler) http.Handler {
+// Default CORS methods and headers, used when the configuration leaves them empty
+var (
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	defaultCORSHeaders = []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "X-Correlation-ID"}
+)
+
+// CORSConfig represents the Cross-Origin Resource Sharing policy
+type CORSConfig struct {
+	AllowedOrigins []string // Origins echoed back to browsers; empty allows any origin with *
+	AllowedMethods []string // Defaults to GET, POST, PUT, DELETE and OPTIONS
+	AllowedHeaders []string // Defaults to the common request headers and X-Correlation-ID
+}
+
+// routePattern returns the route template chi matched for the request, e.g.
+// /api/v1/duplicates, so metrics are not labeled with raw paths. It is only known once
+// the router has served the request.
+func routePattern(r *http.Request) string {
+	if routeContext := chi.RouteContext(r.Context()); routeContext != nil {
+		if pattern := routeContext.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+// CORS creates a middleware that handles Cross-Origin Resource Sharing. With an
+// allowlist, only a matching request Origin is echoed back, and preflight requests from
+// other origins are rejected.
+func CORS(config CORSConfig) func(next http.Handler) http.Handler {
+	allowedOrigins := make(map[string]bool, len(config.AllowedOrigins))
+	for _, origin := range config.AllowedOrigins {
+		allowedOrigins[origin] = true
+	}
+
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Set CORS headers
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Correlation-ID")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Correlation-ID")
+			allowed := true
+			if len(allowedOrigins) == 0 {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				// The response depends on the Origin, so caches must key on it
+				w.Header().Add("Vary", "Origin")
+
+				origin := r.Header.Get("Origin")
+				allowed = allowedOrigins[origin]
+				if allowed {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+			}
+
+			if allowed {
+				w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+				w.Header().Set("Access-Control-Expose-Headers", "X-Correlation-ID")
+			}
 
 			// Handle preflight requests
 			if r.Method == "OPTIONS" {
+				if !allowed {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
 				w.WriteHeader(http.StatusOK)
 				return
 			}
@@ -128,36 +192,66 @@ func SecurityHeaders() func(next http.Handler) http.Handler {
 	}
 }
 
-// RateLimiter creates a simple rate limiting middleware
-func RateLimiter(requestsPerSecond int) func(next http.Handler) http.Handler {
-	// Simple in-memory rate limiter (for production, use Redis or similar)
-	limiter := make(map[string][]time.Time)
+// RateLimiter creates a per-client-IP rate limiting middleware. Each IP gets a token
+// bucket; buckets idle for the TTL are purged by a background sweeper, so the limiter's
+// memory stays bounded by the IPs seen within one TTL.
+func RateLimiter(config RateLimitConfig) func(next http.Handler) http.Handler {
+	return NewClientRateLimiter(config).Middleware
+}
+
+// Middleware rejects requests from clients over their limit with 429
+func (l *ClientRateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.limiter.allow(l.limiter.clientIP(r)) {
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
 
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MaxBodyBytes creates a middleware that limits request bodies to limit bytes. Requests
+// declaring a larger Content-Length are rejected with 413 up front; bodies without one
+// are cut off at the limit, and handlers reading past it get an *http.MaxBytesError to
+// answer 413 with. A non-positive limit disables it.
+func MaxBodyBytes(limit int64) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			clientIP := r.RemoteAddr
-			now := time.Now()
-
-			// Clean old entries
-			if requests, exists := limiter[clientIP]; exists {
-				var validRequests []time.Time
-				for _, reqTime := range requests {
-					if now.Sub(reqTime) < time.Second {
-						validRequests = append(validRequests, reqTime)
-					}
-				}
-				limiter[clientIP] = validRequests
+			if r.ContentLength > limit {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
 			}
 
-			// Check rate limit
-			if len(limiter[clientIP]) >= requestsPerSecond {
-				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ConcurrencyLimiter creates a middleware that rejects requests with 503 once
+// maxInFlight requests are already being served. A non-positive limit disables it.
+func ConcurrencyLimiter(maxInFlight int) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxInFlight <= 0 {
+			return next
+		}
+
+		slots := make(chan struct{}, maxInFlight)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			default:
+				http.Error(w, "Too many concurrent requests", http.StatusServiceUnavailable)
 				return
 			}
 
-			// Add current request
-			limiter[clientIP] = append(limiter[clientIP], now)
-
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -1,13 +1,19 @@
 package middleware
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
 	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -120,8 +126,27 @@ func TestMetricsMiddleware(t *testing.T) {
 	// In a real scenario, you might want to use a metrics interface that can be mocked
 }
 
+func TestMetricsMiddleware_RecordsRouteTemplate(t *testing.T) {
+	appMetrics := metrics.New(metrics.Config{Namespace: "test_route_template", Enabled: true})
+
+	router := chi.NewRouter()
+	router.Use(MetricsMiddleware(appMetrics))
+	router.Get("/api/v1/executions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/v1/executions/1", "/api/v1/executions/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	// Both executions share the route template's series
+	assert.Equal(t, float64(2), testutil.ToFloat64(appMetrics.HTTPRequestsTotal.WithLabelValues("GET", "/api/v1/executions/{id}", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(appMetrics.HTTPRequestsTotal.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(&appMetrics.HTTPRequestDuration))
+}
+
 func TestCORS(t *testing.T) {
-	middleware := CORS()
+	middleware := CORS(CORSConfig{})
 
 	tests := []struct {
 		name           string
@@ -182,6 +207,69 @@ func TestCORS(t *testing.T) {
 	}
 }
 
+func TestCORS_AllowedOrigins(t *testing.T) {
+	middleware := CORS(CORSConfig{
+		AllowedOrigins: []string{"https://ops.example.com"},
+		AllowedMethods: []string{"GET", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type"},
+	})
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("allowed origin", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", "https://ops.example.com")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://ops.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		// The request is served, but browsers block the response without the header
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("preflight from allowed origin", func(t *testing.T) {
+		req := httptest.NewRequest("OPTIONS", "/test", nil)
+		req.Header.Set("Origin", "https://ops.example.com")
+		req.Header.Set("Access-Control-Request-Method", "GET")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://ops.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("preflight from disallowed origin", func(t *testing.T) {
+		req := httptest.NewRequest("OPTIONS", "/test", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		req.Header.Set("Access-Control-Request-Method", "GET")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
 func TestSecurityHeaders(t *testing.T) {
 	middleware := SecurityHeaders()
 
@@ -207,7 +295,9 @@ func TestSecurityHeaders(t *testing.T) {
 }
 
 func TestRateLimiter(t *testing.T) {
-	middleware := RateLimiter(2) // Allow 2 requests per second
+	done := make(chan struct{})
+	defer close(done)
+	middleware := RateLimiter(RateLimitConfig{RequestsPerSecond: 2, Done: done}) // Allow 2 requests per second
 
 	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -244,6 +334,102 @@ func TestRateLimiter(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w4.Code)
 }
 
+func TestMaxBodyBytes(t *testing.T) {
+	handler := MaxBodyBytes(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+
+	t.Run("under limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/test", strings.NewReader("small body")))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "small body", w.Body.String())
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/test", strings.NewReader(strings.Repeat("x", 17))))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("over limit without content length", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/test", strings.NewReader(strings.Repeat("x", 17)))
+		req.ContentLength = -1
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	const maxInFlight = 2
+
+	entered := make(chan struct{}, maxInFlight)
+	release := make(chan struct{})
+
+	handler := ConcurrencyLimiter(maxInFlight)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Hold maxInFlight requests inside the handler
+	var wg sync.WaitGroup
+	held := make([]*httptest.ResponseRecorder, maxInFlight)
+	for i := 0; i < maxInFlight; i++ {
+		held[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/stats", nil))
+		}(held[i])
+	}
+	for i := 0; i < maxInFlight; i++ {
+		select {
+		case <-entered:
+		case <-time.After(time.Second):
+			t.Fatal("held request did not reach the handler")
+		}
+	}
+
+	// The next concurrent request is rejected
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/stats", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "Too many concurrent requests")
+
+	// Once the held requests complete, capacity is available again
+	close(release)
+	wg.Wait()
+	for _, hw := range held {
+		assert.Equal(t, http.StatusOK, hw.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/stats", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestConcurrencyLimiter_Disabled(t *testing.T) {
+	handler := ConcurrencyLimiter(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/stats", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
 func TestResponseWriter(t *testing.T) {
 	originalWriter := httptest.NewRecorder()
 	wrapper := &responseWriter{
@@ -289,7 +475,7 @@ func TestMiddlewareChaining(t *testing.T) {
 
 	// Apply middleware in reverse order (like chi does)
 	chainedHandler := SecurityHeaders()(
-		CORS()(
+		CORS(CORSConfig{})(
 			MetricsMiddleware(appMetrics)(
 				CorrelationID()(
 					RequestLogger(appLogger)(handler),
@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+)
+
+// RateLimitConfig represents the configuration for the per-IP rate limiter
+type RateLimitConfig struct {
+	RequestsPerSecond float64         // Sustained rate each client IP may send at
+	Burst             int             // Requests a client may send at once; defaults to RequestsPerSecond rounded up
+	TTL               time.Duration   // Buckets idle this long are evicted; defaults to 1 minute
+	Done              <-chan struct{} // Stops the background sweeper when closed; nil runs it for the process lifetime
+	Clock             utils.Clock     // Defaults to the system clock
+
+	// Peers in these ranges are proxies whose X-Forwarded-For and X-Real-IP headers are
+	// trusted to name the client; see ParseTrustedProxies. Empty keys clients by RemoteAddr.
+	TrustedProxies []netip.Prefix
+}
+
+// ParseTrustedProxies parses trusted proxy CIDRs; a bare IP is a single-address range
+func ParseTrustedProxies(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// tokenBucket holds one client's remaining tokens as of lastSeen
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// ClientRateLimiter is the per-client-IP limiter behind the RateLimiter middleware, for
+// callers that change its limit while it serves requests
+type ClientRateLimiter struct {
+	limiter *rateLimiter
+}
+
+// NewClientRateLimiter creates a per-client-IP rate limiter and starts its background
+// sweeper. A rate of 0 lets every request through until Update sets one.
+func NewClientRateLimiter(config RateLimitConfig) *ClientRateLimiter {
+	limiter := newRateLimiter(config)
+	go limiter.sweepLoop(config.Done)
+
+	return &ClientRateLimiter{limiter: limiter}
+}
+
+// Update changes the rate and burst; a burst of 0 defaults as in RateLimitConfig and a
+// rate of 0 disables the limit. Clients keep their remaining tokens, capped at the new burst.
+func (l *ClientRateLimiter) Update(requestsPerSecond float64, burst int) {
+	l.limiter.update(requestsPerSecond, burst)
+}
+
+// rateLimiter keeps a token bucket per client IP
+type rateLimiter struct {
+	config  RateLimitConfig
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	if config.Burst <= 0 {
+		config.Burst = defaultBurst(config.RequestsPerSecond)
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if config.Clock == nil {
+		config.Clock = utils.RealClock{}
+	}
+
+	return &rateLimiter{
+		config:  config,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// defaultBurst is the burst used when none is configured: one second's worth of requests
+func defaultBurst(requestsPerSecond float64) int {
+	return int(math.Max(1, math.Ceil(requestsPerSecond)))
+}
+
+// update changes the rate and burst applied from the next request on
+func (l *rateLimiter) update(requestsPerSecond float64, burst int) {
+	if burst <= 0 {
+		burst = defaultBurst(requestsPerSecond)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.config.RequestsPerSecond = requestsPerSecond
+	l.config.Burst = burst
+}
+
+// allow takes a token from the client's bucket, refilled at the configured rate since
+// its last request, and reports whether one was available. A rate of 0 allows everything.
+func (l *rateLimiter) allow(key string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.config.RequestsPerSecond <= 0 {
+		return true
+	}
+
+	now := l.config.Clock.Now()
+	burst := float64(l.config.Burst)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst}
+		l.buckets[key] = bucket
+	} else {
+		elapsed := now.Sub(bucket.lastSeen).Seconds()
+		bucket.tokens = math.Min(burst, bucket.tokens+elapsed*l.config.RequestsPerSecond)
+	}
+	bucket.lastSeen = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// clientIP returns the IP a request is limited by. It is the direct peer's IP without
+// the port, unless the peer is a trusted proxy: then it is the nearest untrusted address
+// in X-Forwarded-For, or X-Real-IP when there is no forwarding chain.
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+
+	if !l.isTrustedProxy(peer) {
+		return peer
+	}
+
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		// Proxies append the address they received the request from, so walk back from
+		// the nearest hop; entries before the first untrusted hop may be forged
+		hops := strings.Split(forwardedFor, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if _, err := netip.ParseAddr(hop); err != nil {
+				break // A malformed hop ends the chain we can trust
+			}
+			if !l.isTrustedProxy(hop) || i == 0 {
+				return hop
+			}
+		}
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		if _, err := netip.ParseAddr(realIP); err == nil {
+			return realIP
+		}
+	}
+
+	return peer
+}
+
+// isTrustedProxy reports whether an address is in a trusted proxy range
+func (l *rateLimiter) isTrustedProxy(ip string) bool {
+	if len(l.config.TrustedProxies) == 0 {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range l.config.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// sweep evicts buckets idle for at least the TTL and returns how many it evicted. An
+// evicted client starts again with a full bucket, which it has normally refilled to by then.
+func (l *rateLimiter) sweep() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.config.Clock.Now()
+	evicted := 0
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) >= l.config.TTL {
+			delete(l.buckets, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// size returns the number of tracked client IPs
+func (l *rateLimiter) size() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return len(l.buckets)
+}
+
+// sweepLoop sweeps idle buckets once per TTL until done is closed
+func (l *rateLimiter) sweepLoop(done <-chan struct{}) {
+	ticker := time.NewTicker(l.config.TTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			l.sweep()
+		}
+	}
+}
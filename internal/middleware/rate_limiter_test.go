@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Burst(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := newRateLimiter(RateLimitConfig{RequestsPerSecond: 1, Burst: 3, Clock: clock})
+
+	// A full bucket allows a burst, then the client is limited
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.allow("10.0.0.1"), "request %d is within the burst", i+1)
+	}
+	assert.False(t, limiter.allow("10.0.0.1"))
+
+	// Tokens refill at the sustained rate
+	clock.Advance(time.Second)
+	assert.True(t, limiter.allow("10.0.0.1"))
+	assert.False(t, limiter.allow("10.0.0.1"))
+
+	// The bucket never holds more than the burst
+	clock.Advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.allow("10.0.0.1"))
+	}
+	assert.False(t, limiter.allow("10.0.0.1"))
+
+	// Other clients have their own buckets
+	assert.True(t, limiter.allow("10.0.0.2"))
+}
+
+func TestClientRateLimiter_Update(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := &ClientRateLimiter{limiter: newRateLimiter(RateLimitConfig{RequestsPerSecond: 1, Burst: 1, Clock: clock})}
+
+	assert.True(t, limiter.limiter.allow("10.0.0.1"))
+	assert.False(t, limiter.limiter.allow("10.0.0.1"))
+
+	// A higher rate refills faster from the next request on
+	limiter.Update(10, 5)
+	clock.Advance(200 * time.Millisecond)
+	assert.True(t, limiter.limiter.allow("10.0.0.1"))
+	assert.True(t, limiter.limiter.allow("10.0.0.1"))
+	assert.False(t, limiter.limiter.allow("10.0.0.1"))
+
+	// A lower burst caps the tokens a client kept
+	clock.Advance(time.Hour)
+	limiter.Update(1, 2)
+	assert.True(t, limiter.limiter.allow("10.0.0.1"))
+	assert.True(t, limiter.limiter.allow("10.0.0.1"))
+	assert.False(t, limiter.limiter.allow("10.0.0.1"))
+
+	// A rate of 0 disables the limit
+	limiter.Update(0, 0)
+	assert.True(t, limiter.limiter.allow("10.0.0.1"))
+}
+
+func TestRateLimiter_EvictsIdleEntries(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := newRateLimiter(RateLimitConfig{RequestsPerSecond: 1, TTL: time.Minute, Clock: clock})
+
+	limiter.allow("10.0.0.1")
+	clock.Advance(30 * time.Second)
+	limiter.allow("10.0.0.2")
+	assert.Equal(t, 2, limiter.size())
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, 1, limiter.sweep(), "only the entry idle for the TTL is evicted")
+	assert.Equal(t, 1, limiter.size())
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, 1, limiter.sweep())
+	assert.Zero(t, limiter.size())
+}
+
+func TestRateLimiter_SweeperPurgesIdleEntries(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	limiter := newRateLimiter(RateLimitConfig{RequestsPerSecond: 1, TTL: 20 * time.Millisecond})
+	go limiter.sweepLoop(done)
+
+	limiter.allow("10.0.0.1")
+	assert.Eventually(t, func() bool { return limiter.size() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestRateLimiter_KeysByIPWithoutPort(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	handler := RateLimiter(RateLimitConfig{RequestsPerSecond: 1, Done: done})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// New connections from the same client do not get a fresh bucket
+	codes := make([]int, 0, 2)
+	for _, remoteAddr := range []string{"10.0.0.1:1000", "10.0.0.1:1001"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes)
+}
+
+func TestRateLimiter_ClientIP(t *testing.T) {
+	trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.5"})
+	require.NoError(t, err)
+	limiter := newRateLimiter(RateLimitConfig{RequestsPerSecond: 1, TrustedProxies: trustedProxies})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{
+			name:       "no forwarding headers",
+			remoteAddr: "10.1.2.3:4000",
+			expected:   "10.1.2.3",
+		},
+		{
+			name:       "spoofed X-Forwarded-For from untrusted peer",
+			remoteAddr: "203.0.113.9:4000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			expected:   "203.0.113.9",
+		},
+		{
+			name:       "spoofed X-Real-IP from untrusted peer",
+			remoteAddr: "203.0.113.9:4000",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.1"},
+			expected:   "203.0.113.9",
+		},
+		{
+			name:       "X-Forwarded-For from trusted peer",
+			remoteAddr: "10.1.2.3:4000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "spoofed entry before the client in X-Forwarded-For",
+			remoteAddr: "10.1.2.3:4000",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.1, 10.4.5.6"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "only trusted hops in X-Forwarded-For",
+			remoteAddr: "192.168.1.5:4000",
+			headers:    map[string]string{"X-Forwarded-For": "10.9.9.9, 10.4.5.6"},
+			expected:   "10.9.9.9",
+		},
+		{
+			name:       "X-Real-IP from trusted peer",
+			remoteAddr: "192.168.1.5:4000",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.7"},
+			expected:   "198.51.100.7",
+		},
+		{
+			name:       "malformed X-Forwarded-For from trusted peer",
+			remoteAddr: "10.1.2.3:4000",
+			headers:    map[string]string{"X-Forwarded-For": "not-an-ip"},
+			expected:   "10.1.2.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			assert.Equal(t, tt.expected, limiter.clientIP(req))
+		})
+	}
+}
+
+func TestRateLimiter_TrustedProxyClientsAreLimitedSeparately(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	handler := RateLimiter(RateLimitConfig{RequestsPerSecond: 1, TrustedProxies: trustedProxies, Done: done})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Two clients behind the same load balancer get separate buckets
+	for _, client := range []string{"198.51.100.1", "198.51.100.2"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "10.0.0.1:4000"
+		req.Header.Set("X-Forwarded-For", client)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.168.1.5 ", "fd00::/8"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.5/32", "fd00::/8"}, []string{prefixes[0].String(), prefixes[1].String(), prefixes[2].String()})
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.ErrorContains(t, err, `invalid trusted proxy "10.0.0.0/33"`)
+}
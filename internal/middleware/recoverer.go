@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// panicResponse is the 500 body written after a recovered panic; it has the same shape
+// as the API's ErrorResponse
+type panicResponse struct {
+	Error     string    `json:"error"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	RequestID string    `json:"requestId,omitempty"`
+	Code      int       `json:"code"`
+}
+
+// headerTrackingWriter records whether the response has started, since a 500 can only
+// be written before that
+type headerTrackingWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *headerTrackingWriter) WriteHeader(code int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *headerTrackingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Recoverer creates a middleware that recovers panics in the handlers it wraps, logs
+// them with their stack trace and answers 500, so one failing request does not take
+// the server down. It belongs first in the chain so it wraps every other middleware.
+// http.ErrAbortHandler is re-panicked, since net/http uses it to abort a response.
+func Recoverer(appLogger *logger.Logger, appMetrics *metrics.Metrics) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tw := &headerTrackingWriter{ResponseWriter: w}
+
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				// The correlation ID middleware runs inside this one, so the ID is only
+				// on the response headers by now
+				correlationID := w.Header().Get("X-Correlation-ID")
+				if correlationID == "" {
+					correlationID = r.Header.Get("X-Correlation-ID")
+				}
+
+				if appLogger != nil {
+					panicLogger := appLogger
+					if correlationID != "" {
+						panicLogger = appLogger.WithCorrelationID(correlationID)
+					}
+					panicLogger.Error("Recovered from panic in HTTP handler",
+						zap.String("panic", fmt.Sprint(recovered)),
+						zap.String("method", r.Method),
+						zap.String("path", r.URL.Path),
+						zap.ByteString("stack", debug.Stack()),
+					)
+				}
+				if appMetrics != nil {
+					appMetrics.RecordHTTPPanic()
+				}
+
+				if tw.wroteHeader {
+					return // Too late to change the response
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(panicResponse{
+					Error:     http.StatusText(http.StatusInternalServerError),
+					Message:   "An unexpected error occurred",
+					Timestamp: time.Now(),
+					RequestID: correlationID,
+					Code:      http.StatusInternalServerError,
+				})
+			}()
+
+			next.ServeHTTP(tw, r)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverer(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Namespace: "test_recoverer", Enabled: true})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(Recoverer(appLogger, appMetrics)(CorrelationID()(mux)))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequest("GET", server.URL+"/panic", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Correlation-ID", "panic-correlation-id")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var body panicResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "Internal Server Error", body.Error)
+	assert.Equal(t, http.StatusInternalServerError, body.Code)
+	assert.Equal(t, "panic-correlation-id", body.RequestID)
+	assert.Equal(t, float64(1), testutil.ToFloat64(appMetrics.HTTPPanicsTotal))
+
+	// The server keeps serving
+	resp, err = http.Get(server.URL + "/ok")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRecoverer_AfterResponseStarted(t *testing.T) {
+	appMetrics := metrics.New(metrics.Config{Namespace: "test_recoverer_started", Enabled: true})
+
+	handler := Recoverer(nil, appMetrics)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("handler failed")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+	// The status already sent is kept; the panic is still counted
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, float64(1), testutil.ToFloat64(appMetrics.HTTPPanicsTotal))
+}
+
+func TestRecoverer_RepanicsAbortHandler(t *testing.T) {
+	handler := Recoverer(nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+	})
+}
@@ -98,25 +98,28 @@ func (asc *AllocationServiceClient) PostExecution(ctx context.Context, dto *doma
 		// Create HTTP request
 		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 		if err != nil {
-			return domain.NewExternalError("allocation-service", "failed to create request", err, true).WithCorrelationID(correlationID)
+			return domain.NewAllocationError("failed to create request", err, true).WithCorrelationID(correlationID)
 		}
 
 		// Set headers
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Accept", "application/json")
 		req.Header.Set("X-Correlation-ID", correlationID)
+		if dto.IdempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", dto.IdempotencyKey)
+		}
 
 		// Make the request
 		resp, err := asc.httpClient.Do(req)
 		if err != nil {
-			return domain.NewExternalError("allocation-service", "request failed", err, true).WithCorrelationID(correlationID)
+			return domain.NewAllocationError("request failed", err, true).WithCorrelationID(correlationID)
 		}
 		defer resp.Body.Close()
 
 		// Read response body
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return domain.NewExternalError("allocation-service", "failed to read response body", err, true).WithCorrelationID(correlationID)
+			return domain.NewAllocationError("failed to read response body", err, true).WithCorrelationID(correlationID)
 		}
 
 		// Check status code
@@ -125,7 +128,7 @@ func (asc *AllocationServiceClient) PostExecution(ctx context.Context, dto *doma
 				zap.Int("status_code", resp.StatusCode),
 				zap.String("body", string(body)),
 			)
-			return domain.NewExternalError("allocation-service", fmt.Sprintf("unexpected status code: %d", resp.StatusCode), nil, true).WithCorrelationID(correlationID)
+			return asc.handleErrorResponse(resp.StatusCode, body, correlationID)
 		}
 
 		asc.logger.WithContext(ctx).Info("Successfully posted execution to Allocation Service",
@@ -135,3 +138,80 @@ func (asc *AllocationServiceClient) PostExecution(ctx context.Context, dto *doma
 		return nil
 	})
 }
+
+// IsHealthy checks if the Allocation Service is healthy
+func (asc *AllocationServiceClient) IsHealthy(ctx context.Context) bool {
+	// Create a health check context with shorter timeout
+	healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/healthz", asc.config.BaseURL)
+
+	req, err := http.NewRequestWithContext(healthCtx, "GET", url, nil)
+	if err != nil {
+		asc.logger.WithContext(ctx).Warn("Failed to create health check request", zap.Error(err))
+		return false
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Correlation-ID", logger.GetCorrelationID(ctx))
+
+	resp, err := asc.httpClient.Do(req)
+	if err != nil {
+		asc.logger.WithContext(ctx).Warn("Allocation Service health check failed", zap.Error(err))
+		return false
+	}
+	defer resp.Body.Close()
+
+	healthy := resp.StatusCode >= 200 && resp.StatusCode < 300
+
+	if !healthy {
+		asc.logger.WithContext(ctx).Warn("Allocation Service health check returned unhealthy status",
+			zap.Int("status_code", resp.StatusCode),
+		)
+	} else {
+		asc.logger.WithContext(ctx).Debug("Allocation Service health check passed",
+			zap.Int("status_code", resp.StatusCode),
+		)
+	}
+
+	return healthy
+}
+
+// GetStats returns client statistics
+func (asc *AllocationServiceClient) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"base_url":      asc.config.BaseURL,
+		"timeout":       asc.config.Timeout.String(),
+		"max_retries":   asc.config.MaxRetries,
+		"retry_backoff": asc.config.RetryBackoff.String(),
+		"circuit_breaker": map[string]interface{}{
+			"failure_threshold": asc.config.CircuitBreaker.FailureThreshold,
+			"timeout":           asc.config.CircuitBreaker.Timeout.String(),
+		},
+	}
+}
+
+// handleErrorResponse handles HTTP error responses
+func (asc *AllocationServiceClient) handleErrorResponse(statusCode int, body []byte, correlationID string) error {
+	switch statusCode {
+	case http.StatusBadRequest:
+		allocationErr := domain.NewAllocationError("bad request", nil, false).
+			WithCorrelationID(correlationID)
+		allocationErr.Code = domain.AllocationBadRequestCode
+		allocationErr.Details = string(body)
+		return allocationErr
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return domain.NewAllocationError("authentication/authorization failed", nil, false).
+			WithCorrelationID(correlationID)
+	case http.StatusTooManyRequests:
+		return domain.NewAllocationError("rate limit exceeded", nil, true).
+			WithCorrelationID(correlationID)
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return domain.NewAllocationError(fmt.Sprintf("server error: %d", statusCode), nil, true).
+			WithCorrelationID(correlationID)
+	default:
+		return domain.NewAllocationError(fmt.Sprintf("unexpected status code: %d", statusCode), nil, true).
+			WithCorrelationID(correlationID)
+	}
+}
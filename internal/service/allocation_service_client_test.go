@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAllocationServiceClient(t *testing.T, baseURL string) *AllocationServiceClient {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 1
+
+	return NewAllocationServiceClient(AllocationServiceClientConfig{
+		AllocationService: config.AllocationServiceConfig{
+			Enabled: true,
+			BaseURL: baseURL,
+			Timeout: time.Second,
+		},
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ResilienceManager: utils.NewResilienceManager(resilienceConfig, appLogger, appMetrics),
+	})
+}
+
+func TestAllocationServiceClient_PostExecution(t *testing.T) {
+	var received []*domain.AllocationServiceExecutionDTO
+	var correlationID, idempotencyKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/executions", r.URL.Path)
+		correlationID = r.Header.Get("X-Correlation-ID")
+		idempotencyKey = r.Header.Get("Idempotency-Key")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestAllocationServiceClient(t, server.URL)
+	ctx := logger.WithCorrelationIDContext(context.Background(), "test-correlation-id")
+
+	err := client.PostExecution(ctx, &domain.AllocationServiceExecutionDTO{ExecutionServiceID: 7, Ticker: "IBM", IdempotencyKey: "fill-3-exec-7-v1"})
+	require.NoError(t, err)
+
+	require.Len(t, received, 1)
+	assert.Equal(t, int64(7), received[0].ExecutionServiceID)
+	assert.Equal(t, "test-correlation-id", correlationID)
+	assert.Equal(t, "fill-3-exec-7-v1", idempotencyKey)
+}
+
+func TestAllocationServiceClient_PostExecution_MapsStatusCodes(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		expectedType  domain.ErrorType
+		wantRetryable bool
+	}{
+		{"bad request", http.StatusBadRequest, domain.ErrorTypeAllocation, false},
+		{"unauthorized", http.StatusUnauthorized, domain.ErrorTypeAllocation, false},
+		{"rate limited", http.StatusTooManyRequests, domain.ErrorTypeAllocation, true},
+		{"server error", http.StatusServiceUnavailable, domain.ErrorTypeAllocation, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			t.Cleanup(server.Close)
+
+			client := newTestAllocationServiceClient(t, server.URL)
+
+			err := client.PostExecution(context.Background(), &domain.AllocationServiceExecutionDTO{ExecutionServiceID: 7})
+
+			var domainErr *domain.DomainError
+			require.ErrorAs(t, err, &domainErr)
+			assert.Equal(t, tt.expectedType, domainErr.Type)
+			assert.Equal(t, tt.wantRetryable, domainErr.IsRetryable())
+		})
+	}
+}
+
+func TestAllocationServiceClient_PostExecution_RetriesOnlyRetryableErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		wantAttempts int64
+	}{
+		// The resilience manager runs the retries again when dead-lettering the failure
+		{"retryable server error", http.StatusBadGateway, 6},
+		{"non-retryable rejection", http.StatusForbidden, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.statusCode)
+			}))
+			t.Cleanup(server.Close)
+
+			client := newTestAllocationServiceClient(t, server.URL)
+			resilienceConfig := utils.GetDefaultResilienceConfig()
+			resilienceConfig.RetryConfig.MaxAttempts = 3
+			resilienceConfig.RetryConfig.InitialDelay = time.Millisecond
+			client.resilienceManager = utils.NewResilienceManager(resilienceConfig, client.logger, client.metrics)
+			ctx := logger.WithCorrelationIDContext(context.Background(), "test-correlation-id")
+
+			err := client.PostExecution(ctx, &domain.AllocationServiceExecutionDTO{ExecutionServiceID: 7})
+
+			require.Error(t, err)
+			assert.True(t, domain.IsErrorType(err, domain.ErrorTypeAllocation))
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+
+			var domainErr *domain.DomainError
+			require.ErrorAs(t, err, &domainErr)
+			assert.Equal(t, "test-correlation-id", domainErr.CorrelationID)
+		})
+	}
+}
+
+func TestAllocationServiceClient_IsHealthy(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthz", r.URL.Path)
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestAllocationServiceClient(t, server.URL)
+
+	assert.True(t, client.IsHealthy(context.Background()))
+
+	healthy = false
+	assert.False(t, client.IsHealthy(context.Background()))
+
+	stats := client.GetStats()
+	assert.Equal(t, server.URL, stats["base_url"])
+	assert.Equal(t, "1s", stats["timeout"])
+}
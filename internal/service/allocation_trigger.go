@@ -0,0 +1,38 @@
+package service
+
+import "github.com/kasbench/globeco-confirmation-service/internal/domain"
+
+// AllocationTrigger decides which fills are posted to the Allocation Service
+type AllocationTrigger string
+
+const (
+	// AllocationTriggerClosed posts fills that are no longer open
+	AllocationTriggerClosed AllocationTrigger = "closed"
+	// AllocationTriggerFull posts fills whose execution is fully filled
+	AllocationTriggerFull AllocationTrigger = "full"
+	// AllocationTriggerTerminal posts fills whose execution reached any final status:
+	// filled, cancelled or deleted
+	AllocationTriggerTerminal AllocationTrigger = "terminal"
+)
+
+// terminalExecutionStatuses are the execution statuses no further fills follow
+var terminalExecutionStatuses = map[string]bool{
+	"FULL":  true,
+	"CNCL":  true,
+	"CNCLD": true,
+	"CPART": true,
+	"DEL":   true,
+}
+
+// ShouldPost reports whether the fill is posted to the Allocation Service; an unset
+// trigger behaves as AllocationTriggerClosed
+func (t AllocationTrigger) ShouldPost(fill *domain.Fill) bool {
+	switch t {
+	case AllocationTriggerFull:
+		return fill.ExecutionStatus == "FULL"
+	case AllocationTriggerTerminal:
+		return terminalExecutionStatuses[fill.ExecutionStatus]
+	default:
+		return !fill.IsOpen
+	}
+}
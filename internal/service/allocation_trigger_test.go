@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocationTrigger_ShouldPost(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger AllocationTrigger
+		isOpen  bool
+		status  string
+		want    bool
+	}{
+		{name: "default posts closed fills", trigger: "", isOpen: false, status: "CNCL", want: true},
+		{name: "default skips open fills", trigger: "", isOpen: true, status: "FULL", want: false},
+		{name: "closed posts closed fills", trigger: AllocationTriggerClosed, isOpen: false, status: "PART", want: true},
+		{name: "closed skips open fills", trigger: AllocationTriggerClosed, isOpen: true, status: "PART", want: false},
+		{name: "full posts full fills", trigger: AllocationTriggerFull, isOpen: false, status: "FULL", want: true},
+		{name: "full skips cancelled fills", trigger: AllocationTriggerFull, isOpen: false, status: "CNCL", want: false},
+		{name: "terminal posts cancelled fills", trigger: AllocationTriggerTerminal, isOpen: false, status: "CNCLD", want: true},
+		{name: "terminal posts deleted fills", trigger: AllocationTriggerTerminal, isOpen: false, status: "DEL", want: true},
+		{name: "terminal skips working fills", trigger: AllocationTriggerTerminal, isOpen: false, status: "WORK", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fill := &domain.Fill{IsOpen: tt.isOpen, ExecutionStatus: tt.status}
+			assert.Equal(t, tt.want, tt.trigger.ShouldPost(fill))
+		})
+	}
+}
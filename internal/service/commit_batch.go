@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// commitBatchingEnabled reports whether handled messages are committed in batches
+// rather than one at a time
+func (kcs *KafkaConsumerService) commitBatchingEnabled() bool {
+	return kcs.config.CommitBatchSize > 1
+}
+
+// commitMessage commits a handled message. With batching, the message is queued and
+// the batch is committed once it is full; the commit loop and Stop commit the rest.
+// Only handled messages are queued, so a commit never covers an unhandled message
+// that per-message commits would not have covered too.
+func (kcs *KafkaConsumerService) commitMessage(ctx context.Context, message kafka.Message) error {
+	if !kcs.commitBatchingEnabled() {
+		if err := kcs.reader.CommitMessages(ctx, message); err != nil {
+			kcs.logger.WithContext(ctx).Error("Failed to commit message",
+				zap.String("topic", message.Topic),
+				zap.Int("partition", message.Partition),
+				zap.Int64("offset", message.Offset),
+				zap.Error(err),
+			)
+			return fmt.Errorf("failed to commit message: %w", err)
+		}
+		return nil
+	}
+
+	kcs.commitMutex.Lock()
+	kcs.pendingCommits = append(kcs.pendingCommits, message)
+	full := len(kcs.pendingCommits) >= kcs.config.CommitBatchSize
+	kcs.commitMutex.Unlock()
+
+	if full {
+		// The message was handled; a failed batch commit only means redelivery
+		kcs.flushCommits(ctx)
+	}
+	return nil
+}
+
+// flushCommits commits the queued messages. They are dropped when the commit fails:
+// their offsets are either covered by a later commit or redelivered.
+func (kcs *KafkaConsumerService) flushCommits(ctx context.Context) {
+	kcs.commitMutex.Lock()
+	defer kcs.commitMutex.Unlock()
+
+	if len(kcs.pendingCommits) == 0 {
+		return
+	}
+
+	messages := kcs.pendingCommits
+	kcs.pendingCommits = nil
+	if err := kcs.reader.CommitMessages(ctx, messages...); err != nil {
+		kcs.logger.WithContext(ctx).Error("Failed to commit message batch",
+			zap.Int("messages", len(messages)),
+			zap.Error(err),
+		)
+		return
+	}
+
+	kcs.logger.WithContext(ctx).Debug("Committed message batch",
+		zap.Int("messages", len(messages)),
+	)
+}
+
+// commitLoop commits the queued messages every CommitInterval until the consumer
+// stops; Stop commits whatever is left
+func (kcs *KafkaConsumerService) commitLoop(ctx context.Context) {
+	defer kcs.wg.Done()
+
+	ticker := time.NewTicker(kcs.config.CommitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			kcs.flushCommits(ctx)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingFillsMessageHandler fails the fills with the given IDs and records the
+// IDs of every fill it is called with
+type failingFillsMessageHandler struct {
+	mutex   sync.Mutex
+	failing map[int64]bool
+	seen    map[int64]bool
+}
+
+func (h *failingFillsMessageHandler) HandleFillMessage(ctx context.Context, fill *domain.Fill) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.seen == nil {
+		h.seen = make(map[int64]bool)
+	}
+	h.seen[fill.ID] = true
+	if h.failing[fill.ID] {
+		return errors.New("execution service unavailable")
+	}
+	return nil
+}
+
+func (h *failingFillsMessageHandler) hasSeen(id int64) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.seen[id]
+}
+
+// newTestFillMessages returns fill messages at consecutive offsets of one partition;
+// the fill at offset i has ID i
+func newTestFillMessages(t *testing.T, count int) []kafka.Message {
+	messages := make([]kafka.Message, count)
+	for i := range messages {
+		message := newTestFillMessage(t)
+
+		var fill domain.Fill
+		require.NoError(t, json.Unmarshal(message.Value, &fill))
+		fill.ID = int64(i)
+		value, err := json.Marshal(&fill)
+		require.NoError(t, err)
+
+		message.Value = value
+		message.Offset = int64(i)
+		messages[i] = message
+	}
+	return messages
+}
+
+func committedOffsets(reader *fakeKafkaReader) []int64 {
+	reader.mutex.Lock()
+	defer reader.mutex.Unlock()
+
+	offsets := make([]int64, len(reader.committed))
+	for i, message := range reader.committed {
+		offsets[i] = message.Offset
+	}
+	return offsets
+}
+
+func TestKafkaConsumerService_BatchedCommits(t *testing.T) {
+	handler := &failingFillsMessageHandler{failing: map[int64]bool{3: true}}
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second, newTestFillMessages(t, 6)...)
+	consumer.config.CommitBatchSize = 2
+	consumer.config.CommitInterval = time.Hour
+
+	consumer.mutex.Lock()
+	consumer.startConsuming(context.Background())
+	consumer.mutex.Unlock()
+
+	// Full batches are committed as they fill up; the failed message never is
+	assert.Eventually(t, func() bool {
+		return handler.hasSeen(5) && assert.ObjectsAreEqual([]int64{0, 1, 2, 4}, committedOffsets(reader))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The partial batch is committed on shutdown
+	require.NoError(t, consumer.Stop(context.Background()))
+	assert.Equal(t, []int64{0, 1, 2, 4, 5}, committedOffsets(reader))
+}
+
+func TestKafkaConsumerService_BatchedCommits_CommitsPartialBatchOnInterval(t *testing.T) {
+	handler := &failingFillsMessageHandler{}
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second, newTestFillMessages(t, 3)...)
+	consumer.config.CommitBatchSize = 100
+	consumer.config.CommitInterval = 10 * time.Millisecond
+
+	consumer.mutex.Lock()
+	consumer.startConsuming(context.Background())
+	consumer.mutex.Unlock()
+
+	assert.Eventually(t, func() bool { return reader.committedCount() == 3 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int64{0, 1, 2}, committedOffsets(reader))
+
+	require.NoError(t, consumer.Stop(context.Background()))
+	assert.Equal(t, 3, reader.committedCount(), "nothing is committed twice")
+}
+
+func TestKafkaConsumerService_PerMessageCommitsByDefault(t *testing.T) {
+	consumer, reader := newTestKafkaConsumer(t, &failingFillsMessageHandler{}, time.Second)
+
+	for _, message := range newTestFillMessages(t, 2) {
+		require.NoError(t, consumer.handleInFlightMessage(context.Background(), message))
+	}
+
+	assert.Equal(t, []int64{0, 1}, committedOffsets(reader))
+	assert.Empty(t, consumer.pendingCommits)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ConfigReloaderConfig represents the configuration for the config reloader
+type ConfigReloaderConfig struct {
+	Load              func() (*config.Config, error) // Reads and validates the current configuration
+	Logger            *logger.Logger
+	ValidationService *ValidationService
+	TenantValidation  map[string]*ValidationService // Tenant validation services keyed by tenant ID
+	RateLimiter       RateLimitUpdater              // HTTP per-client-IP limiter; nil leaves the rate limit to a restart
+}
+
+// RateLimitUpdater changes the rate and burst of a running rate limiter
+type RateLimitUpdater interface {
+	Update(requestsPerSecond float64, burst int)
+}
+
+// ConfigReloader applies the settings that are safe to change on a running service:
+// the log level, validation thresholds and HTTP rate limit. Other settings, including
+// the trusted proxies, still need a restart.
+type ConfigReloader struct {
+	load              func() (*config.Config, error)
+	logger            *logger.Logger
+	validationService *ValidationService
+	tenantValidation  map[string]*ValidationService
+	rateLimiter       RateLimitUpdater
+}
+
+// NewConfigReloader creates a config reloader
+func NewConfigReloader(cfg ConfigReloaderConfig) *ConfigReloader {
+	return &ConfigReloader{
+		load:              cfg.Load,
+		logger:            cfg.Logger,
+		validationService: cfg.ValidationService,
+		tenantValidation:  cfg.TenantValidation,
+		rateLimiter:       cfg.RateLimiter,
+	}
+}
+
+// Reload reads the configuration again and applies it. An invalid configuration is
+// rejected as a whole, leaving the running settings unchanged.
+func (r *ConfigReloader) Reload(ctx context.Context) error {
+	cfg, err := r.load()
+	if err != nil {
+		return fmt.Errorf("failed to reload configuration: %w", err)
+	}
+
+	return r.Apply(ctx, cfg)
+}
+
+// Apply applies the hot-reloadable settings of cfg to the running components
+func (r *ConfigReloader) Apply(ctx context.Context, cfg *config.Config) error {
+	if err := r.logger.SetLevel(cfg.Logging.Level); err != nil {
+		return fmt.Errorf("failed to apply log level: %w", err)
+	}
+
+	if r.validationService != nil {
+		r.validationService.UpdateThresholds(ValidationThresholdsFromConfig(cfg.Validation))
+	}
+	for tenantID, validationService := range r.tenantValidation {
+		validationService.UpdateThresholds(ValidationThresholdsFromConfig(cfg.ValidationFor(tenantID)))
+	}
+	if r.rateLimiter != nil {
+		r.rateLimiter.Update(cfg.HTTP.RateLimit.RequestsPerSecond, cfg.HTTP.RateLimit.Burst)
+	}
+
+	r.logger.WithContext(ctx).Info("Configuration reloaded",
+		zap.String("log_level", cfg.Logging.Level),
+		zap.Int("tenant_validation_services", len(r.tenantValidation)),
+		zap.Float64("rate_limit_requests_per_second", cfg.HTTP.RateLimit.RequestsPerSecond),
+	)
+	return nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/middleware"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestConfigReloader_Reload(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	requestLogger := appLogger.WithFields() // Loggers derived before the reload follow it too
+
+	validationService := NewValidationService(ValidationConfig{Logger: appLogger})
+	tenantValidation := NewValidationService(ValidationConfig{Logger: appLogger})
+
+	tenantShare := 0.5
+	reloaded := config.GetDefaults()
+	reloaded.Logging.Level = "debug"
+	reloaded.Validation.SentBeforeReceivedSeverity = "warning"
+	reloaded.Validation.MaxFillsPerFilledShare = 2
+	reloaded.Tenants = map[string]config.TenantConfig{
+		"acme": {Validation: config.TenantValidationConfig{MaxFillsPerFilledShare: &tenantShare}},
+	}
+
+	var loadErr error
+	reloader := NewConfigReloader(ConfigReloaderConfig{
+		Load: func() (*config.Config, error) {
+			if loadErr != nil {
+				return nil, loadErr
+			}
+			return reloaded, nil
+		},
+		Logger:            appLogger,
+		ValidationService: validationService,
+		TenantValidation:  map[string]*ValidationService{"acme": tenantValidation},
+	})
+
+	assert.False(t, requestLogger.Core().Enabled(zapcore.DebugLevel))
+
+	require.NoError(t, reloader.Reload(context.Background()))
+
+	assert.Equal(t, "debug", appLogger.GetLevel())
+	assert.True(t, requestLogger.Core().Enabled(zapcore.DebugLevel))
+	assert.Equal(t, SeverityWarning, validationService.Thresholds().SentBeforeReceivedSeverity)
+	assert.Equal(t, 2.0, validationService.Thresholds().MaxFillsPerFilledShare)
+	assert.Equal(t, SeverityWarning, tenantValidation.Thresholds().SentBeforeReceivedSeverity)
+	assert.Equal(t, 0.5, tenantValidation.Thresholds().MaxFillsPerFilledShare)
+
+	// A configuration that fails to load leaves the running settings unchanged
+	loadErr = errors.New("configuration validation failed")
+	assert.Error(t, reloader.Reload(context.Background()))
+	assert.Equal(t, "debug", appLogger.GetLevel())
+}
+
+func TestConfigReloader_Reload_RateLimit(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	rateLimiter := middleware.NewClientRateLimiter(middleware.RateLimitConfig{RequestsPerSecond: 1, Burst: 1, Done: done})
+	handler := rateLimiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	get := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	reloaded := config.GetDefaults()
+	reloaded.HTTP.RateLimit.RequestsPerSecond = 1
+	reloader := NewConfigReloader(ConfigReloaderConfig{
+		Load:        func() (*config.Config, error) { return reloaded, nil },
+		Logger:      appLogger,
+		RateLimiter: rateLimiter,
+	})
+
+	assert.Equal(t, http.StatusOK, get())
+	assert.Equal(t, http.StatusTooManyRequests, get())
+
+	// Turning the limit off lets the limited client straight through
+	reloaded.HTTP.RateLimit.RequestsPerSecond = 0
+	require.NoError(t, reloader.Reload(context.Background()))
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, get())
+	}
+
+	// Turning it back on with a lower limit applies from the next request
+	reloaded.HTTP.RateLimit.RequestsPerSecond = 0.001
+	reloaded.HTTP.RateLimit.Burst = 1
+	require.NoError(t, reloader.Reload(context.Background()))
+	assert.Equal(t, http.StatusTooManyRequests, get())
+}
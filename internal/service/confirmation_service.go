@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kasbench/globeco-confirmation-service/internal/config"
@@ -13,6 +15,34 @@ import (
 	"go.uber.org/zap"
 )
 
+// ProcessingMode controls whether fills are applied to downstream services
+type ProcessingMode string
+
+const (
+	// ProcessingModeLive updates the Execution Service and posts to the Allocation Service
+	ProcessingModeLive ProcessingMode = "live"
+	// ProcessingModeShadow validates and duplicate-checks fills and logs the calls it would make
+	ProcessingModeShadow ProcessingMode = "shadow"
+)
+
+// Results of Allocation Service posts, recorded in allocation_posts_total
+const (
+	allocationPostSuccess     = "success"
+	allocationPostFailure     = "failure"
+	allocationPostCircuitOpen = "circuit_open" // Short-circuited, then queued or dead-lettered
+	allocationPostDuplicate   = "duplicate"    // Already posted for this fill version
+	allocationPostSkipped     = "skipped"      // The fill does not meet the allocation trigger
+)
+
+// allocationServiceCircuitBreaker is the name of the Allocation Service circuit breaker
+const allocationServiceCircuitBreaker = "allocation-service"
+
+// AllocationCircuitBreakerName returns the name of the Allocation Service circuit breaker
+// for a tenant, or of the global one for an empty tenant ID
+func AllocationCircuitBreakerName(tenantID string) string {
+	return tenantCircuitBreakerName(allocationServiceCircuitBreaker, tenantID)
+}
+
 // ConfirmationService implements the core business logic for processing fill messages
 type ConfirmationService struct {
 	executionClient    ExecutionServiceClientInterface
@@ -24,6 +54,40 @@ type ConfirmationService struct {
 	validationService  *ValidationService
 	duplicateDetection *DuplicateDetectionService
 	config             *config.Config
+
+	// Canary filtering by execution ID; an empty allowlist allows every execution
+	executionIDAllowlist map[int64]struct{}
+	executionIDDenylist  map[int64]struct{}
+
+	// Rolling error rate over recently handled messages
+	errorRate *errorRateWindow
+
+	// Durations of recently processed messages, for the percentiles in GetStats
+	processingTimes *processingTimeWindow
+
+	mode ProcessingMode
+
+	// Allocation Service posts are skipped while this circuit, or the tenant's own, is
+	// open and queued for replay by a background loop
+	allocationCircuitBreaker *utils.CircuitBreaker
+	pendingAllocations       *pendingAllocationBuffer
+	stopReplay               chan struct{}
+	replayDone               chan struct{}
+
+	// Fail completed fills until their Allocation Service post succeeds
+	allocationRequired bool
+
+	// Decides which fills are posted to the Allocation Service
+	allocationTrigger AllocationTrigger
+
+	// Idempotency keys of recent successful Allocation Service posts; nil when disabled
+	postedAllocations *postedAllocationSet
+
+	// Per-tenant dependencies keyed by lowercased tenant ID
+	tenants map[string]TenantProfile
+
+	// Builds Execution Service update requests from fills
+	updateMapper ExecutionUpdateMapper
 }
 
 // ConfirmationServiceConfig represents the configuration for the confirmation service
@@ -37,6 +101,49 @@ type ConfirmationServiceConfig struct {
 	ValidationService  *ValidationService
 	DuplicateDetection *DuplicateDetectionService
 	Config             *config.Config
+
+	// Optional canary filters; fills for other executions are skipped
+	ExecutionIDAllowlist []int64
+	ExecutionIDDenylist  []int64
+
+	// Number of recent messages the error rate is computed over; defaults to 100
+	ErrorRateWindowSize int
+
+	// Number of recent processing durations the GetStats percentiles are computed
+	// over; defaults to 1000
+	ProcessingTimeWindowSize int
+
+	// Live or shadow processing; defaults to live
+	Mode ProcessingMode
+
+	// Optional circuit breaker for Allocation Service posts. While it is open, posts are
+	// queued (up to PendingAllocationBufferSize, default 1000) and replayed every
+	// PendingAllocationReplayInterval (0 disables the replay loop).
+	AllocationCircuitBreaker        *utils.CircuitBreaker
+	PendingAllocationBufferSize     int
+	PendingAllocationReplayInterval time.Duration
+
+	// When true, a completed fill fails until its Allocation Service post succeeds, so
+	// the offset is not committed and the consumer retries the fill in place. Failed
+	// posts are neither dead-lettered nor queued for replay in this mode.
+	AllocationRequired bool
+
+	// Which fills are posted to the Allocation Service; defaults to closed fills
+	AllocationTrigger AllocationTrigger
+
+	// Idempotency keys of successful Allocation Service posts are remembered this long
+	// (up to PostedAllocationMaxEntries keys, default 10000), so a reprocessed fill is
+	// not posted twice. 0 disables the check.
+	PostedAllocationRetention  time.Duration
+	PostedAllocationMaxEntries int
+
+	// Optional per-tenant overrides keyed by tenant ID (case-insensitive). Fills from
+	// other tenants, or without a tenant, use the global clients and validation.
+	Tenants map[string]TenantProfile
+
+	// Builds Execution Service update requests from fills; defaults to
+	// DefaultExecutionUpdateMapper
+	UpdateMapper ExecutionUpdateMapper
 }
 
 // AllocationServiceClientInterface defines the interface for the Allocation Service client
@@ -47,7 +154,7 @@ type AllocationServiceClientInterface interface {
 
 // NewConfirmationService creates a new confirmation service
 func NewConfirmationService(config ConfirmationServiceConfig) *ConfirmationService {
-	return &ConfirmationService{
+	cs := &ConfirmationService{
 		executionClient:    config.ExecutionClient,
 		allocationClient:   config.AllocationClient,
 		logger:             config.Logger,
@@ -57,7 +164,113 @@ func NewConfirmationService(config ConfirmationServiceConfig) *ConfirmationServi
 		validationService:  config.ValidationService,
 		duplicateDetection: config.DuplicateDetection,
 		config:             config.Config,
+
+		executionIDAllowlist: toExecutionIDSet(config.ExecutionIDAllowlist),
+		executionIDDenylist:  toExecutionIDSet(config.ExecutionIDDenylist),
+
+		errorRate:       newErrorRateWindow(config.ErrorRateWindowSize),
+		processingTimes: newProcessingTimeWindow(config.ProcessingTimeWindowSize),
+
+		mode: config.Mode,
+
+		allocationCircuitBreaker: config.AllocationCircuitBreaker,
+		allocationRequired:       config.AllocationRequired,
+		allocationTrigger:        config.AllocationTrigger,
+
+		tenants: toTenantProfiles(config.Tenants),
+
+		updateMapper: config.UpdateMapper,
+	}
+
+	if cs.updateMapper == nil {
+		cs.updateMapper = DefaultExecutionUpdateMapper
+	}
+
+	if config.PostedAllocationRetention > 0 {
+		cs.postedAllocations = newPostedAllocationSet(config.PostedAllocationRetention, config.PostedAllocationMaxEntries, nil)
+	}
+
+	if cs.hasAllocationCircuitBreaker() {
+		cs.pendingAllocations = newPendingAllocationBuffer(config.PendingAllocationBufferSize)
+
+		if config.PendingAllocationReplayInterval > 0 {
+			cs.stopReplay = make(chan struct{})
+			cs.replayDone = make(chan struct{})
+			go cs.replayLoop(config.PendingAllocationReplayInterval)
+		}
+	}
+
+	return cs
+}
+
+// tenantProfile returns the overrides for the fill's tenant, if any
+func (cs *ConfirmationService) tenantProfile(fill *domain.Fill) TenantProfile {
+	if fill.TenantID == "" {
+		return TenantProfile{}
+	}
+	return cs.tenants[strings.ToLower(fill.TenantID)]
+}
+
+// executionClientFor returns the Execution Service client for the fill's tenant
+func (cs *ConfirmationService) executionClientFor(fill *domain.Fill) ExecutionServiceClientInterface {
+	if client := cs.tenantProfile(fill).ExecutionClient; client != nil {
+		return client
+	}
+	return cs.executionClient
+}
+
+// allocationClientFor returns the Allocation Service client for the fill's tenant
+func (cs *ConfirmationService) allocationClientFor(fill *domain.Fill) AllocationServiceClientInterface {
+	return cs.allocationTargetFor(fill).client
+}
+
+// allocationTarget is an Allocation Service client and the circuit breaker its posts go
+// through; a nil breaker posts directly
+type allocationTarget struct {
+	client  AllocationServiceClientInterface
+	breaker *utils.CircuitBreaker
+}
+
+// allocationTargetFor returns the Allocation Service the fill's tenant posts to
+func (cs *ConfirmationService) allocationTargetFor(fill *domain.Fill) allocationTarget {
+	if profile := cs.tenantProfile(fill); profile.AllocationClient != nil {
+		return allocationTarget{client: profile.AllocationClient, breaker: profile.AllocationCircuitBreaker}
+	}
+	return allocationTarget{client: cs.allocationClient, breaker: cs.allocationCircuitBreaker}
+}
+
+// hasAllocationCircuitBreaker reports whether any Allocation Service posts go through a
+// circuit breaker, and so may need replaying
+func (cs *ConfirmationService) hasAllocationCircuitBreaker() bool {
+	if cs.allocationCircuitBreaker != nil {
+		return true
+	}
+	for _, profile := range cs.tenants {
+		if profile.AllocationCircuitBreaker != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validationServiceFor returns the validation service for the fill's tenant
+func (cs *ConfirmationService) validationServiceFor(fill *domain.Fill) *ValidationService {
+	if validationService := cs.tenantProfile(fill).ValidationService; validationService != nil {
+		return validationService
+	}
+	return cs.validationService
+}
+
+func toExecutionIDSet(ids []int64) map[int64]struct{} {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	set := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
 	}
+	return set
 }
 
 // HandleFillMessage implements the MessageHandler interface
@@ -71,7 +284,18 @@ func (cs *ConfirmationService) HandleFillMessage(ctx context.Context, fill *doma
 	startTime := time.Now()
 	var processingError error
 
-	cs.logger.WithContext(ctx).Info("Processing fill message", zap.Int64("fill_id", fill.ID))
+	// Canary filtering; skipped fills return nil so the message is committed
+	if skip, reason := cs.checkCanary(fill); skip {
+		cs.logger.WithContext(ctx).Info("Skipping fill message excluded from canary processing",
+			zap.Int64("fill_id", fill.ID),
+			zap.Int64("execution_service_id", fill.ExecutionServiceID),
+			zap.String("reason", reason),
+		)
+		cs.metrics.RecordMessageSkippedCanary()
+		return nil
+	}
+
+	cs.logger.WithContext(ctx).Info("Processing fill message", zap.Int64("fill_id", fill.ID), zap.String("tenant_id", fill.TenantID))
 
 	// Start tracing span
 	var span interface{}
@@ -84,9 +308,17 @@ func (cs *ConfirmationService) HandleFillMessage(ctx context.Context, fill *doma
 		}()
 	}
 
-	// Defer recording the processing result for duplicate detection
+	// Defer recording the outcome in the rolling error rate
+	defer func() {
+		cs.metrics.SetMessageErrorRate(cs.errorRate.record(processingError != nil))
+	}()
+
+	// Defer recording the processing result for duplicate detection. Shadow instances
+	// do not record theirs: the fill was never applied, and a shared duplicate store
+	// would make live instances skip it.
+	shadow := cs.Mode() == ProcessingModeShadow
 	defer func() {
-		if cs.duplicateDetection != nil {
+		if cs.duplicateDetection != nil && !shadow {
 			cs.duplicateDetection.RecordProcessedMessage(ctx, fill, processingError == nil, time.Since(startTime), getErrorMessage(processingError))
 		}
 	}()
@@ -105,6 +337,12 @@ func (cs *ConfirmationService) HandleFillMessage(ctx context.Context, fill *doma
 		return nil
 	}
 
+	// Shadow mode stops short of the downstream services
+	if shadow {
+		cs.handleShadowFill(ctx, fill, time.Since(startTime))
+		return nil
+	}
+
 	// Handle Execution Service call
 	updateResponse, execServiceFailed, execErr := cs.handleExecutionServiceCall(ctx, fill)
 	if execServiceFailed {
@@ -112,12 +350,15 @@ func (cs *ConfirmationService) HandleFillMessage(ctx context.Context, fill *doma
 	}
 
 	// Handle Allocation Service call for completed trades
-	cs.handleAllocationServiceCall(ctx, fill)
+	if allocErr := cs.handleAllocationServiceCall(ctx, fill); allocErr != nil && processingError == nil {
+		processingError = allocErr
+		cs.metrics.RecordMessageFailed()
+	}
 
-	if !execServiceFailed {
+	if processingError == nil {
 		cs.logSuccess(ctx, fill, updateResponse, time.Since(startTime))
 		cs.metrics.RecordMessageProcessed()
-		cs.metrics.RecordMessageProcessingTime(time.Since(startTime))
+		cs.recordProcessingTime(time.Since(startTime))
 	}
 
 	return processingError
@@ -145,10 +386,11 @@ func (cs *ConfirmationService) validateInitialFillMessage(ctx context.Context, f
 		}
 	}
 
-	if cs.validationService != nil {
-		validationResult := cs.validationService.ValidateFillMessage(ctx, fill)
+	if validationService := cs.validationServiceFor(fill); validationService != nil {
+		validationResult := validationService.ValidateFillMessage(ctx, fill)
 		if !validationResult.IsValid {
-			return domain.NewValidationError("comprehensive_validation_failed", validationResult.GetErrorSummary())
+			return domain.NewValidationError("comprehensive_validation_failed", validationResult.GetErrorSummary()).
+				WithFieldErrors(validationResult.Errors)
 		}
 		if len(validationResult.Warnings) > 0 {
 			cs.logger.WithContext(ctx).Warn("Fill message validation passed with warnings",
@@ -160,6 +402,18 @@ func (cs *ConfirmationService) validateInitialFillMessage(ctx context.Context, f
 	return nil
 }
 
+func (cs *ConfirmationService) checkCanary(fill *domain.Fill) (bool, string) {
+	if _, denied := cs.executionIDDenylist[fill.ExecutionServiceID]; denied {
+		return true, "execution is denylisted"
+	}
+	if cs.executionIDAllowlist != nil {
+		if _, allowed := cs.executionIDAllowlist[fill.ExecutionServiceID]; !allowed {
+			return true, "execution is not allowlisted"
+		}
+	}
+	return false, ""
+}
+
 func (cs *ConfirmationService) checkForDuplicates(ctx context.Context, fill *domain.Fill) (bool, string) {
 	if cs.duplicateDetection != nil {
 		duplicateResult := cs.duplicateDetection.CheckDuplicate(ctx, fill)
@@ -176,14 +430,44 @@ func (cs *ConfirmationService) checkForDuplicates(ctx context.Context, fill *dom
 	return false, ""
 }
 
+// handleShadowFill logs the downstream calls a live instance would make for the fill
+func (cs *ConfirmationService) handleShadowFill(ctx context.Context, fill *domain.Fill, duration time.Duration) {
+	cs.logger.WithContext(ctx).Info("Shadow mode: would update execution",
+		zap.Int64("fill_id", fill.ID),
+		zap.Int64("execution_service_id", fill.ExecutionServiceID),
+		zap.String("execution_status", fill.ExecutionStatus),
+		zap.Float64("quantity_filled", fill.QuantityFilled),
+	)
+	cs.metrics.RecordShadowCallSkipped("execution-service")
+
+	if cs.allocationTrigger.ShouldPost(fill) && cs.allocationClientFor(fill) != nil {
+		cs.logger.WithContext(ctx).Info("Shadow mode: would post execution to Allocation Service",
+			zap.Int64("fill_id", fill.ID),
+			zap.Int64("execution_service_id", fill.ExecutionServiceID),
+		)
+		cs.metrics.RecordShadowCallSkipped("allocation-service")
+	}
+
+	cs.metrics.RecordMessageShadowProcessed()
+	cs.recordProcessingTime(duration)
+}
+
+// recordProcessingTime records how long a successfully processed message took
+func (cs *ConfirmationService) recordProcessingTime(duration time.Duration) {
+	cs.metrics.RecordMessageProcessingTime(duration)
+	cs.processingTimes.record(duration)
+}
+
 func (cs *ConfirmationService) logSuccess(ctx context.Context, fill *domain.Fill, updateResponse *domain.ExecutionUpdateResponse, duration time.Duration) {
 	cs.logger.WithContext(ctx).Info("Successfully processed fill message",
 		zap.Int64("fill_id", fill.ID),
 		zap.Int64("execution_service_id", fill.ExecutionServiceID),
 		zap.Int("new_version", updateResponse.Version),
+		zap.Bool("execution_changed", updateResponse.Changed),
 		zap.Duration("processing_time", duration),
 		zap.String("final_status", updateResponse.ExecutionStatus),
 	)
+	cs.metrics.RecordMessageProcessedFor(fill.Destination, fill.TradeType, fill.Ticker)
 }
 
 func getErrorMessage(err error) string {
@@ -195,11 +479,14 @@ func getErrorMessage(err error) string {
 
 // validateFillMessage validates business rules for the fill message
 func (cs *ConfirmationService) validateFillMessage(ctx context.Context, fill *domain.Fill, currentExecution *domain.ExecutionResponse) error {
-	// Check if execution IDs match
-	if fill.ExecutionServiceID != currentExecution.ID {
-		errorMsg := fmt.Sprintf("fill execution ID %d does not match current execution ID %d",
-			fill.ExecutionServiceID, currentExecution.ID)
+	validationService := cs.validationServiceFor(fill)
+	if validationService == nil {
+		validationService = NewValidationService(ValidationConfig{Logger: cs.logger})
+	}
+	result := validationService.ValidateAgainstExecution(ctx, fill, currentExecution)
 
+	// An execution ID mismatch can be skipped or downgraded, which skips the remaining checks
+	if result.hasError(CodeExecutionIDMismatch) {
 		// Check if we should skip execution ID validation
 		if cs.config != nil && cs.config.Validation.SkipExecutionIDValidation {
 			cs.logger.WithContext(ctx).Warn("Skipping execution ID validation due to configuration",
@@ -219,43 +506,19 @@ func (cs *ConfirmationService) validateFillMessage(ctx context.Context, fill *do
 			)
 			return nil
 		}
-
-		return domain.NewValidationError("execution_id_mismatch", errorMsg)
 	}
 
-	// Check if trade types match
-	if fill.TradeType != currentExecution.TradeType {
-		return domain.NewValidationError("trade_type_mismatch",
-			fmt.Sprintf("fill trade type %s does not match execution trade type %s",
-				fill.TradeType, currentExecution.TradeType))
+	// The first error is the most fundamental mismatch
+	if !result.IsValid {
+		first := result.Errors[0]
+		return domain.NewValidationError(strings.ToLower(first.Code), first.Message).
+			WithFieldErrors(result.Errors)
 	}
 
-	// Check if destinations match
-	if fill.Destination != currentExecution.Destination {
-		return domain.NewValidationError("destination_mismatch",
-			fmt.Sprintf("fill destination %s does not match execution destination %s",
-				fill.Destination, currentExecution.Destination))
-	}
-
-	// Check if security IDs match
-	if fill.SecurityID != currentExecution.SecurityID {
-		return domain.NewValidationError("security_id_mismatch",
-			fmt.Sprintf("fill security ID %s does not match execution security ID %s",
-				fill.SecurityID, currentExecution.SecurityID))
-	}
-
-	// Check if quantity filled is reasonable
-	if fill.QuantityFilled > currentExecution.Quantity {
-		return domain.NewValidationError("quantity_filled_exceeds_total",
-			fmt.Sprintf("fill quantity filled %d exceeds total execution quantity %d",
-				fill.QuantityFilled, currentExecution.Quantity))
-	}
-
-	// Check if quantity filled is not decreasing (unless it's a correction)
-	if fill.QuantityFilled < currentExecution.QuantityFilled {
-		cs.logger.WithContext(ctx).Warn("Fill quantity is less than current quantity - possible correction",
-			zap.Int64("fill_quantity_filled", fill.QuantityFilled),
-			zap.Int64("current_quantity_filled", currentExecution.QuantityFilled),
+	if len(result.Warnings) > 0 {
+		cs.logger.WithContext(ctx).Warn("Fill is consistent with the execution, with warnings",
+			zap.Int64("fill_id", fill.ID),
+			zap.String("warnings", result.GetWarningSummary()),
 		)
 	}
 
@@ -272,28 +535,29 @@ func (cs *ConfirmationService) validateFillMessage(ctx context.Context, fill *do
 		)
 	}
 
-	// Check timestamps for logical ordering (using Unix timestamps)
-	if fill.ReceivedTimestamp > 0 && fill.SentTimestamp > 0 {
-		if fill.SentTimestamp < fill.ReceivedTimestamp {
-			return domain.NewValidationError("invalid_timestamp_order",
-				"sent timestamp cannot be before received timestamp")
-		}
-	}
+	return nil
+}
 
-	if fill.LastFilledTimestamp > 0 && fill.SentTimestamp > 0 {
-		if fill.LastFilledTimestamp < fill.SentTimestamp {
-			return domain.NewValidationError("invalid_timestamp_order",
-				"last filled timestamp cannot be before sent timestamp")
-		}
+func (cs *ConfirmationService) latestVersionSentinel() int {
+	if cs.config == nil {
+		return 0
 	}
+	return cs.config.Validation.LatestVersionSentinel
+}
 
-	return nil
+func (cs *ConfirmationService) maxConflictRetries() int {
+	if cs.config == nil {
+		return 0
+	}
+	return cs.config.ExecutionService.MaxConflictRetries
 }
 
 // handleExecutionServiceCall handles the interaction with the Execution Service
 func (cs *ConfirmationService) handleExecutionServiceCall(ctx context.Context, fill *domain.Fill) (*domain.ExecutionUpdateResponse, bool, error) {
+	executionClient := cs.executionClientFor(fill)
+
 	// Get current execution from Execution Service to retrieve version
-	execution, err := cs.executionClient.GetExecution(ctx, fill.ExecutionServiceID)
+	execution, err := executionClient.GetExecution(ctx, fill.ExecutionServiceID)
 	if err != nil {
 		processingError := fmt.Errorf("failed to get execution %d: %w", fill.ExecutionServiceID, err)
 		cs.metrics.RecordMessageFailed()
@@ -313,11 +577,34 @@ func (cs *ConfirmationService) handleExecutionServiceCall(ctx context.Context, f
 		return nil, true, processingError
 	}
 
+	if fill.IsLatestVersionSentinel(cs.latestVersionSentinel()) {
+		cs.logger.WithContext(ctx).Debug("Fill requested latest version, using current execution version",
+			zap.Int64("fill_id", fill.ID),
+			zap.Int("execution_version", execution.Version),
+		)
+	}
+
 	// Create update request using the current version
-	updateRequest := fill.ToUpdateRequest(execution.Version)
+	updateRequest := cs.updateMapper.ToUpdateRequest(fill, execution.Version)
+
+	// Update execution in Execution Service, refreshing the version on conflicts
+	updateResponse, err := executionClient.UpdateExecution(ctx, fill.ExecutionServiceID, updateRequest)
+	for attempt := 1; err != nil && domain.IsErrorType(err, domain.ErrorTypeConflict) && attempt <= cs.maxConflictRetries(); attempt++ {
+		cs.logger.WithContext(ctx).Warn("Version conflict updating execution, retrying with refreshed version",
+			zap.Int64("fill_id", fill.ID),
+			zap.Int64("execution_service_id", fill.ExecutionServiceID),
+			zap.Int("stale_version", updateRequest.Version),
+			zap.Int("attempt", attempt),
+		)
 
-	// Update execution in Execution Service
-	updateResponse, err := cs.executionClient.UpdateExecution(ctx, fill.ExecutionServiceID, updateRequest)
+		execution, err = executionClient.GetExecution(ctx, fill.ExecutionServiceID)
+		if err != nil {
+			break
+		}
+
+		updateRequest = cs.updateMapper.ToUpdateRequest(fill, execution.Version)
+		updateResponse, err = executionClient.UpdateExecution(ctx, fill.ExecutionServiceID, updateRequest)
+	}
 	if err != nil {
 		processingError := fmt.Errorf("failed to update execution %d: %w", fill.ExecutionServiceID, err)
 		cs.metrics.RecordMessageFailed()
@@ -330,23 +617,228 @@ func (cs *ConfirmationService) handleExecutionServiceCall(ctx context.Context, f
 	return updateResponse, false, nil
 }
 
-// handleAllocationServiceCall handles the interaction with the Allocation Service
-func (cs *ConfirmationService) handleAllocationServiceCall(ctx context.Context, fill *domain.Fill) {
-	// TEMPORARY: Log the fill object before checking isOpen
-	cs.logger.WithContext(ctx).Info("AllocationServiceCall: fill object", zap.Any("fill", fill))
-	if !fill.IsOpen && cs.allocationClient != nil {
-		allocationDTO := domain.NewAllocationServiceExecutionDTO(fill)
-		err := cs.allocationClient.PostExecution(ctx, allocationDTO)
+// handleAllocationServiceCall handles the interaction with the Allocation Service.
+// Failures are only returned when the Allocation Service is required for completion.
+func (cs *ConfirmationService) handleAllocationServiceCall(ctx context.Context, fill *domain.Fill) error {
+	cs.logger.WithContext(ctx).Debug("Checking fill for Allocation Service",
+		zap.Int64("fill_id", fill.ID),
+		zap.Bool("is_open", fill.IsOpen),
+	)
+	target := cs.allocationTargetFor(fill)
+	if target.client == nil {
+		return nil
+	}
+	if !cs.allocationTrigger.ShouldPost(fill) {
+		cs.metrics.RecordAllocationPost(allocationPostSkipped)
+		return nil
+	}
+
+	allocationDTO := domain.NewAllocationServiceExecutionDTO(fill)
+	err := cs.postAllocation(ctx, target, allocationDTO)
+	if err != nil && cs.allocationRequired {
+		cs.logger.WithContext(ctx).Error("Failed to post to Allocation Service, retrying the fill",
+			zap.Int64("fill_id", fill.ID),
+			zap.Error(err),
+		)
+		return &allocationPendingError{err: fmt.Errorf("failed to post execution %d to Allocation Service: %w", fill.ExecutionServiceID, err)}
+	}
+	if err != nil && cs.allocationCircuitOpen(err) {
+		cs.deferAllocation(ctx, pendingAllocation{target: target, dto: allocationDTO})
+		return nil
+	}
+	if err != nil {
+		cs.logger.WithContext(ctx).Error("Failed to post to Allocation Service",
+			zap.Int64("fill_id", fill.ID),
+			zap.Error(err),
+		)
+		cs.deadLetterAllocation(ctx, allocationDTO, err)
+	}
+	return nil
+}
+
+// allocationPendingError reports a completed fill whose required Allocation Service post
+// failed. The consumer handles such fills again until the post succeeds.
+type allocationPendingError struct {
+	err error
+}
+
+func (e *allocationPendingError) Error() string {
+	return e.err.Error()
+}
+
+func (e *allocationPendingError) Unwrap() error {
+	return e.err
+}
+
+// postAllocation posts to the target Allocation Service through its circuit breaker,
+// which fails fast without an HTTP call while open
+func (cs *ConfirmationService) postAllocation(ctx context.Context, target allocationTarget, dto *domain.AllocationServiceExecutionDTO) error {
+	trackPost := cs.postedAllocations != nil && dto.IdempotencyKey != ""
+	if trackPost && cs.postedAllocations.contains(dto.IdempotencyKey) {
+		cs.logger.WithContext(ctx).Info("Skipping Allocation Service post already made for this fill",
+			zap.Int64("execution_service_id", dto.ExecutionServiceID),
+			zap.String("idempotency_key", dto.IdempotencyKey),
+		)
+		cs.metrics.RecordAllocationPost(allocationPostDuplicate)
+		return nil
+	}
+
+	startTime := time.Now()
+	var err error
+	if target.breaker == nil {
+		err = target.client.PostExecution(ctx, dto)
+	} else {
+		err = target.breaker.Execute(ctx, func(ctx context.Context) error {
+			return target.client.PostExecution(ctx, dto)
+		})
+	}
+
+	switch {
+	case err == nil:
+		cs.metrics.RecordAllocationPost(allocationPostSuccess)
+		cs.metrics.RecordAllocationPostDuration(time.Since(startTime))
+	case domain.IsErrorType(err, domain.ErrorTypeCircuitBreaker):
+		cs.metrics.RecordAllocationPost(allocationPostCircuitOpen) // Not sent, so no duration
+	default:
+		cs.metrics.RecordAllocationPost(allocationPostFailure)
+		cs.metrics.RecordAllocationPostDuration(time.Since(startTime))
+	}
+
+	if err == nil && trackPost {
+		cs.postedAllocations.record(dto.IdempotencyKey)
+	}
+	return err
+}
+
+// allocationCircuitOpen reports whether err means the post was short-circuited and
+// can be replayed later
+func (cs *ConfirmationService) allocationCircuitOpen(err error) bool {
+	return cs.pendingAllocations != nil && domain.IsErrorType(err, domain.ErrorTypeCircuitBreaker)
+}
+
+// deferAllocation queues a post for replay, dead-lettering it when the buffer is full
+func (cs *ConfirmationService) deferAllocation(ctx context.Context, item pendingAllocation) {
+	if !cs.pendingAllocations.add(item) {
+		cs.logger.WithContext(ctx).Error("Pending allocation buffer full, dead-lettering Allocation Service post",
+			zap.Int64("execution_service_id", item.dto.ExecutionServiceID),
+		)
+		cs.deadLetterAllocation(ctx, item.dto, fmt.Errorf("pending allocation buffer full"))
+		return
+	}
+
+	cs.logger.WithContext(ctx).Warn("Allocation Service circuit open, queued post for replay",
+		zap.Int64("execution_service_id", item.dto.ExecutionServiceID),
+	)
+}
+
+// deadLetterAllocation dead-letters a failed post, recording the error's type and
+// whether it was retryable when the client returned a domain error
+func (cs *ConfirmationService) deadLetterAllocation(ctx context.Context, dto *domain.AllocationServiceExecutionDTO, err error) {
+	if cs.resilienceManager != nil {
+		metadata := map[string]interface{}{"service": "allocation-service"}
+		var domainErr *domain.DomainError
+		if errors.As(err, &domainErr) {
+			metadata["error_type"] = string(domainErr.Type)
+			metadata["retryable"] = domainErr.IsRetryable()
+		}
+		_ = cs.resilienceManager.AddToDeadLetterQueue(ctx, dto, "allocation-service failure", []error{err}, 1, metadata)
+	}
+}
+
+// replayLoop periodically replays queued Allocation Service posts until Stop is called
+func (cs *ConfirmationService) replayLoop(interval time.Duration) {
+	defer close(cs.replayDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cs.stopReplay:
+			return
+		case <-ticker.C:
+			cs.replayPendingAllocations(context.Background())
+		}
+	}
+}
+
+// replayPendingAllocations posts queued allocations in order, each to the Allocation
+// Service it was deferred for. Once a circuit is found open again, the rest of its posts
+// are requeued in order while other circuits' posts are still replayed.
+func (cs *ConfirmationService) replayPendingAllocations(ctx context.Context) {
+	if cs.pendingAllocations == nil {
+		return
+	}
+
+	pending := cs.pendingAllocations.take()
+	var requeued []pendingAllocation
+	openCircuits := make(map[*utils.CircuitBreaker]bool)
+	for _, item := range pending {
+		if openCircuits[item.target.breaker] {
+			requeued = append(requeued, item)
+			continue
+		}
+
+		err := cs.postAllocation(ctx, item.target, item.dto)
+		if err != nil && cs.allocationCircuitOpen(err) {
+			openCircuits[item.target.breaker] = true
+			requeued = append(requeued, item)
+			continue
+		}
 		if err != nil {
-			cs.logger.WithContext(ctx).Error("Failed to post to Allocation Service",
-				zap.Int64("fill_id", fill.ID),
+			cs.logger.WithContext(ctx).Error("Failed to replay post to Allocation Service",
+				zap.Int64("execution_service_id", item.dto.ExecutionServiceID),
 				zap.Error(err),
 			)
-			if cs.resilienceManager != nil {
-				_ = cs.resilienceManager.AddToDeadLetterQueue(ctx, allocationDTO, "allocation-service failure", []error{err}, 1, map[string]interface{}{"service": "allocation-service"})
-			}
+			cs.deadLetterAllocation(ctx, item.dto, err)
+			continue
+		}
+		cs.pendingAllocations.recordReplayed()
+	}
+
+	if len(requeued) > 0 {
+		for _, overflow := range cs.pendingAllocations.requeue(requeued) {
+			cs.deadLetterAllocation(ctx, overflow.dto, fmt.Errorf("pending allocation buffer full"))
 		}
 	}
+
+	if replayed := len(pending) - len(requeued); replayed > 0 {
+		cs.logger.WithContext(ctx).Info("Replayed pending Allocation Service posts",
+			zap.Int("count", replayed),
+		)
+	}
+}
+
+// Stop stops the replay loop, dead-letters posts still waiting for replay so they are
+// not lost on shutdown, and stops duplicate detection
+func (cs *ConfirmationService) Stop(ctx context.Context) {
+	if cs.stopReplay != nil {
+		close(cs.stopReplay)
+		<-cs.replayDone
+	}
+
+	cs.deadLetterPendingAllocations(ctx)
+
+	if cs.duplicateDetection != nil {
+		cs.duplicateDetection.Stop()
+	}
+}
+
+// deadLetterPendingAllocations dead-letters every post still waiting for replay
+func (cs *ConfirmationService) deadLetterPendingAllocations(ctx context.Context) {
+	if cs.pendingAllocations == nil {
+		return
+	}
+
+	pending := cs.pendingAllocations.take()
+	for _, item := range pending {
+		cs.deadLetterAllocation(ctx, item.dto, fmt.Errorf("allocation service unavailable at shutdown"))
+	}
+	if len(pending) > 0 {
+		cs.logger.WithContext(ctx).Warn("Dead-lettered pending Allocation Service posts on shutdown",
+			zap.Int("count", len(pending)),
+		)
+	}
 }
 
 // IsHealthy checks if the confirmation service is healthy
@@ -355,21 +847,66 @@ func (cs *ConfirmationService) IsHealthy(ctx context.Context) bool {
 	return cs.executionClient.IsHealthy(ctx)
 }
 
+// Mode returns the processing mode, live unless shadow mode is configured
+func (cs *ConfirmationService) Mode() ProcessingMode {
+	if cs.mode == ProcessingModeShadow {
+		return ProcessingModeShadow
+	}
+	return ProcessingModeLive
+}
+
+// ErrorRate returns the fraction of recently handled messages that failed
+func (cs *ConfirmationService) ErrorRate() float64 {
+	return cs.errorRate.rate()
+}
+
 // GetStats returns service statistics
 func (cs *ConfirmationService) GetStats() map[string]interface{} {
 	stats := map[string]interface{}{
 		"service_name": "globeco-confirmation-service",
+		"mode":         string(cs.Mode()),
 	}
 
+	stats["message_error_rate"] = cs.ErrorRate()
+	stats["error_rate_window_size"] = cs.errorRate.size()
+	stats["processing_time"] = cs.processingTimes.stats()
+
 	// Add execution client stats
 	if cs.executionClient != nil {
 		stats["execution_client"] = cs.executionClient.GetStats()
 	}
 
+	// Add allocation client stats when the client reports them
+	if allocationClient, ok := cs.allocationClient.(interface{ GetStats() map[string]interface{} }); ok {
+		stats["allocation_client"] = allocationClient.GetStats()
+	}
+
+	if cs.allocationCircuitBreaker != nil {
+		breakerStats := cs.allocationCircuitBreaker.GetStats()
+		stats["allocation_circuit_breaker"] = breakerStats
+		stats["allocation_circuit_state"] = breakerStats.State.String()
+	}
+	tenantCircuitStates := make(map[string]string)
+	for tenantID, profile := range cs.tenants {
+		if profile.AllocationCircuitBreaker != nil {
+			tenantCircuitStates[tenantID] = profile.AllocationCircuitBreaker.GetState().String()
+		}
+	}
+	if len(tenantCircuitStates) > 0 {
+		stats["tenant_allocation_circuit_states"] = tenantCircuitStates
+	}
+	if cs.pendingAllocations != nil {
+		stats["pending_allocations"] = cs.pendingAllocations.stats()
+	}
+	if cs.postedAllocations != nil {
+		stats["posted_allocations"] = cs.postedAllocations.stats()
+	}
+
 	// Add resilience manager stats
 	if cs.resilienceManager != nil {
 		stats["circuit_breaker"] = cs.resilienceManager.GetCircuitBreakerStats()
 		stats["dead_letter_queue"] = cs.resilienceManager.GetDeadLetterQueueStats()
+		stats["retry_budget"] = cs.resilienceManager.GetRetryBudgetStats()
 	}
 
 	// Add duplicate detection stats
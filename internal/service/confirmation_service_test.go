@@ -2,15 +2,24 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
 	"github.com/kasbench/globeco-confirmation-service/internal/domain"
 	"github.com/kasbench/globeco-confirmation-service/internal/utils"
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
 	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
 )
 
 // Helper function to create float64 pointer
@@ -435,28 +444,6 @@ func TestConfirmationService_validateFillMessage(t *testing.T) {
 			},
 			expectedError: "invalid_average_price",
 		},
-		{
-			name: "invalid timestamp order",
-			fill: &domain.Fill{
-				ExecutionServiceID:  456,
-				TradeType:           "BUY",
-				Destination:         "ML",
-				SecurityID:          "SEC123",
-				QuantityFilled:      1000,
-				AveragePrice:        190.41,
-				ReceivedTimestamp:   1748354367.512467,
-				SentTimestamp:       1748354367.509362, // Before received
-				LastFilledTimestamp: 1748354504.1602714,
-			},
-			execution: &domain.ExecutionResponse{
-				ID:          456,
-				TradeType:   "BUY",
-				Destination: "ML",
-				SecurityID:  "SEC123",
-				Quantity:    1000,
-			},
-			expectedError: "invalid_timestamp_order",
-		},
 	}
 
 	for _, tt := range tests {
@@ -473,6 +460,57 @@ func TestConfirmationService_validateFillMessage(t *testing.T) {
 	}
 }
 
+func TestConfirmationService_validateFillMessage_AttachesFieldErrors(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+
+	fill := &domain.Fill{
+		ExecutionServiceID: 456,
+		TradeType:          "BUY",
+		Destination:        "GS",
+		SecurityID:         "SEC999",
+		QuantityFilled:     1000,
+		AveragePrice:       190.41,
+	}
+	execution := &domain.ExecutionResponse{ID: 456, TradeType: "BUY", Destination: "ML", SecurityID: "SEC123", Quantity: 1000}
+
+	service := &ConfirmationService{logger: appLogger}
+	err = service.validateFillMessage(context.Background(), fill, execution)
+
+	var domainErr *domain.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "destination_mismatch", domainErr.Message)
+	require.Len(t, domainErr.FieldErrors, 2)
+	assert.Equal(t, "securityId", domainErr.FieldErrors[1].Field)
+
+	// Skipping execution ID validation skips the remaining checks too, as before
+	fill.ExecutionServiceID = 789
+	service.config = config.GetDefaults()
+	service.config.Validation.SkipExecutionIDValidation = true
+	assert.NoError(t, service.validateFillMessage(context.Background(), fill, execution))
+}
+
+func TestConfirmationService_validateFillMessage_UsesReloadedTimestampSeverity(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+
+	fill := newVersionConflictTestFill()
+	fill.ReceivedTimestamp = 3
+	fill.SentTimestamp = 2 // Before received
+	execution := versionConflictTestExecution(1)
+
+	// The startup configuration treats the violation as an error
+	cfg := config.GetDefaults()
+	cfg.Validation.SentBeforeReceivedSeverity = string(SeverityError)
+	validationService := NewValidationService(ValidationConfig{Logger: appLogger})
+	service := &ConfirmationService{logger: appLogger, config: cfg, validationService: validationService}
+
+	// A reload downgrading it to a warning takes effect without a restart
+	validationService.UpdateThresholds(ValidationThresholds{SentBeforeReceivedSeverity: SeverityWarning})
+	assert.NoError(t, service.validateInitialFillMessage(context.Background(), fill))
+	assert.NoError(t, service.validateFillMessage(context.Background(), fill, execution))
+}
+
 func TestConfirmationService_IsHealthy(t *testing.T) {
 	mockClient := &MockExecutionServiceClient{}
 	service := &ConfirmationService{
@@ -535,6 +573,7 @@ func TestConfirmationService_GetStats(t *testing.T) {
 	mockClient.On("GetStats").Return(expectedClientStats)
 	mockResilienceManager.On("GetCircuitBreakerStats").Return(expectedCBStats)
 	mockResilienceManager.On("GetDeadLetterQueueStats").Return(expectedDLQStats)
+	mockResilienceManager.On("GetRetryBudgetStats").Return(utils.RetryBudgetStats{Capacity: 10})
 
 	stats := service.GetStats()
 
@@ -542,6 +581,7 @@ func TestConfirmationService_GetStats(t *testing.T) {
 	assert.Equal(t, expectedClientStats, stats["execution_client"])
 	assert.Equal(t, expectedCBStats, stats["circuit_breaker"])
 	assert.Equal(t, expectedDLQStats, stats["dead_letter_queue"])
+	assert.Equal(t, utils.RetryBudgetStats{Capacity: 10}, stats["retry_budget"])
 
 	mockClient.AssertExpectations(t)
 	mockResilienceManager.AssertExpectations(t)
@@ -562,6 +602,11 @@ func (m *MockResilienceManager) GetDeadLetterQueueStats() utils.DeadLetterQueueS
 	return args.Get(0).(utils.DeadLetterQueueStats)
 }
 
+func (m *MockResilienceManager) GetRetryBudgetStats() utils.RetryBudgetStats {
+	args := m.Called()
+	return args.Get(0).(utils.RetryBudgetStats)
+}
+
 func (m *MockResilienceManager) AddToDeadLetterQueue(ctx context.Context, originalMessage interface{}, failureReason string, errorHistory []error, attemptCount int, metadata map[string]interface{}) error {
 	args := m.Called(ctx, originalMessage, failureReason, errorHistory, attemptCount, metadata)
 	return args.Error(0)
@@ -637,6 +682,71 @@ func TestConfirmationService_HandleFillMessage_AllocationSuccess(t *testing.T) {
 	mockAllocClient.AssertExpectations(t)
 }
 
+func TestConfirmationService_HandleFillMessage_FractionalShares(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	var updateBody, allocationBody []byte
+	executionServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			updateBody, _ = io.ReadAll(r.Body)
+			io.WriteString(w, `{"id": 2, "executionStatus": "FULL", "quantity": 1.5, "quantityFilled": 1.5, "averagePrice": 10, "version": 2}`)
+			return
+		}
+		io.WriteString(w, `{"id": 2, "executionStatus": "PART", "tradeType": "BUY", "destination": "ML", "securityId": "SEC1", "quantity": 1.5, "quantityFilled": 1.0, "version": 1}`)
+	}))
+	t.Cleanup(executionServer.Close)
+	allocationServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allocationBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(allocationServer.Close)
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:   newTestExecutionServiceClient(t, config.ExecutionServiceConfig{BaseURL: executionServer.URL, Timeout: time.Second}),
+		AllocationClient:  newTestAllocationServiceClient(t, allocationServer.URL),
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ValidationService: NewValidationService(ValidationConfig{Logger: appLogger}),
+	})
+
+	now := float64(time.Now().Unix())
+	fill := &domain.Fill{
+		ID:                  1,
+		ExecutionServiceID:  2,
+		IsOpen:              false,
+		ExecutionStatus:     "FULL",
+		TradeType:           "BUY",
+		Destination:         "ML",
+		SecurityID:          "SEC1",
+		Ticker:              "IBM",
+		Quantity:            1.5,
+		ReceivedTimestamp:   now,
+		SentTimestamp:       now,
+		LastFilledTimestamp: now,
+		QuantityFilled:      1.5,
+		AveragePrice:        10,
+		NumberOfFills:       1,
+		TotalAmount:         15,
+		Version:             1,
+	}
+
+	require.NoError(t, service.HandleFillMessage(context.Background(), fill))
+
+	var updateReq domain.ExecutionUpdateRequest
+	require.NoError(t, json.Unmarshal(updateBody, &updateReq))
+	assert.Equal(t, 1.5, updateReq.QuantityFilled, "the fractional fill is not truncated")
+
+	var allocations []*domain.AllocationServiceExecutionDTO
+	require.NoError(t, json.Unmarshal(allocationBody, &allocations))
+	require.Len(t, allocations, 1)
+	assert.Equal(t, 1.5, allocations[0].Quantity)
+	assert.Equal(t, 1.5, allocations[0].QuantityFilled)
+	assert.Equal(t, 15.0, allocations[0].TotalAmount)
+}
+
 // Test: Allocation Service failure should add to DLQ
 func TestConfirmationService_HandleFillMessage_AllocationFailure_DLQ(t *testing.T) {
 	mockExecClient := &MockExecutionServiceClient{}
@@ -835,3 +945,706 @@ func TestConfirmationService_HandleFillMessage_IsOpenTrue_NoAllocationCall(t *te
 	mockExecClient.AssertExpectations(t)
 	mockAllocClient.AssertNotCalled(t, "PostExecution", mock.Anything, mock.Anything)
 }
+
+func newVersionConflictTestService(t *testing.T, maxConflictRetries int) (*ConfirmationService, *MockExecutionServiceClient, *MockResilienceManager) {
+	mockExecClient := &MockExecutionServiceClient{}
+	mockResilience := &MockResilienceManager{}
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	cfg := config.GetDefaults()
+	cfg.Validation.MaxMessageAgeMinutes = 0
+	cfg.ExecutionService.MaxConflictRetries = maxConflictRetries
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:   mockExecClient,
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ResilienceManager: mockResilience,
+		Config:            cfg,
+	})
+
+	return service, mockExecClient, mockResilience
+}
+
+func newVersionConflictTestFill() *domain.Fill {
+	return &domain.Fill{
+		ID:                  1,
+		ExecutionServiceID:  2,
+		IsOpen:              true,
+		ExecutionStatus:     "PARTIAL",
+		TradeType:           "BUY",
+		Destination:         "ML",
+		SecurityID:          "SEC1",
+		Ticker:              "IBM",
+		Quantity:            100,
+		ReceivedTimestamp:   1,
+		SentTimestamp:       2,
+		LastFilledTimestamp: 3,
+		QuantityFilled:      50,
+		AveragePrice:        9.0,
+		NumberOfFills:       1,
+		TotalAmount:         450.0,
+	}
+}
+
+func versionConflictTestExecution(version int) *domain.ExecutionResponse {
+	return &domain.ExecutionResponse{
+		ID:              2,
+		ExecutionStatus: "PARTIAL",
+		TradeType:       "BUY",
+		Destination:     "ML",
+		SecurityID:      "SEC1",
+		Quantity:        100,
+		QuantityFilled:  25,
+		Version:         version,
+	}
+}
+
+func matchUpdateVersion(version int) interface{} {
+	return mock.MatchedBy(func(req *domain.ExecutionUpdateRequest) bool {
+		return req.Version == version
+	})
+}
+
+func TestConfirmationService_HandleFillMessage_VersionConflictRetrySucceeds(t *testing.T) {
+	service, mockExecClient, mockResilience := newVersionConflictTestService(t, 2)
+	ctx := context.Background()
+
+	// The first update uses a stale version; the retry uses the refreshed one
+	mockExecClient.On("GetExecution", mock.Anything, int64(2)).Return(versionConflictTestExecution(1), nil).Once()
+	mockExecClient.On("UpdateExecution", mock.Anything, int64(2), matchUpdateVersion(1)).
+		Return(nil, domain.NewConflictError("execution", "version conflict")).Once()
+	mockExecClient.On("GetExecution", mock.Anything, int64(2)).Return(versionConflictTestExecution(2), nil).Once()
+	mockExecClient.On("UpdateExecution", mock.Anything, int64(2), matchUpdateVersion(2)).
+		Return(&domain.ExecutionUpdateResponse{ID: 2, ExecutionStatus: "PARTIAL", Version: 3}, nil).Once()
+
+	err := service.HandleFillMessage(ctx, newVersionConflictTestFill())
+	assert.NoError(t, err)
+	mockExecClient.AssertExpectations(t)
+	mockResilience.AssertNotCalled(t, "AddToDeadLetterQueue", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConfirmationService_HandleFillMessage_VersionConflictRetriesExhausted(t *testing.T) {
+	service, mockExecClient, mockResilience := newVersionConflictTestService(t, 2)
+	ctx := context.Background()
+
+	// Every update conflicts: one initial attempt plus two retries
+	mockExecClient.On("GetExecution", mock.Anything, int64(2)).Return(versionConflictTestExecution(1), nil).Times(3)
+	mockExecClient.On("UpdateExecution", mock.Anything, int64(2), mock.AnythingOfType("*domain.ExecutionUpdateRequest")).
+		Return(nil, domain.NewConflictError("execution", "version conflict")).Times(3)
+	mockResilience.On("AddToDeadLetterQueue", mock.Anything, mock.Anything, "execution-service failure", mock.Anything, 1, mock.Anything).Return(nil)
+
+	err := service.HandleFillMessage(ctx, newVersionConflictTestFill())
+	require.Error(t, err)
+	assert.True(t, domain.IsErrorType(err, domain.ErrorTypeConflict))
+	mockExecClient.AssertExpectations(t)
+	mockResilience.AssertExpectations(t)
+}
+
+func TestConfirmationService_HandleFillMessage_NonConflictUpdateErrorNotRetried(t *testing.T) {
+	service, mockExecClient, mockResilience := newVersionConflictTestService(t, 2)
+	ctx := context.Background()
+
+	mockExecClient.On("GetExecution", mock.Anything, int64(2)).Return(versionConflictTestExecution(1), nil).Once()
+	mockExecClient.On("UpdateExecution", mock.Anything, int64(2), mock.AnythingOfType("*domain.ExecutionUpdateRequest")).
+		Return(nil, domain.NewValidationError("bad request", "invalid quantity")).Once()
+	mockResilience.On("AddToDeadLetterQueue", mock.Anything, mock.Anything, "execution-service failure", mock.Anything, 1, mock.Anything).Return(nil)
+
+	err := service.HandleFillMessage(ctx, newVersionConflictTestFill())
+	require.Error(t, err)
+	mockExecClient.AssertExpectations(t)
+}
+
+func TestConfirmationService_HandleFillMessage_StructuredValidationErrors(t *testing.T) {
+	mockExecClient := &MockExecutionServiceClient{}
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:   mockExecClient,
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ValidationService: NewValidationService(ValidationConfig{Logger: appLogger}),
+	})
+
+	fill := newVersionConflictTestFill()
+	fill.Ticker = ""
+	fill.TradeType = ""
+
+	err = service.HandleFillMessage(context.Background(), fill)
+	require.Error(t, err)
+
+	var domainErr *domain.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrorTypeValidation, domainErr.Type)
+
+	// The summary is kept in the message while the codes are available per field
+	assert.Contains(t, domainErr.Error(), "ticker: ticker is required")
+	assert.Contains(t, domainErr.FieldErrors, domain.FieldError{Field: "ticker", Code: "REQUIRED_FIELD", Message: "ticker is required"})
+	assert.Contains(t, domainErr.FieldErrors, domain.FieldError{Field: "tradeType", Code: "REQUIRED_FIELD", Message: "tradeType is required"})
+	mockExecClient.AssertNotCalled(t, "GetExecution", mock.Anything, mock.Anything)
+}
+
+func TestConfirmationService_HandleFillMessage_LatestVersionSentinel(t *testing.T) {
+	mockExecClient := &MockExecutionServiceClient{}
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	cfg := config.GetDefaults()
+	cfg.Validation.MaxMessageAgeMinutes = 0
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient: mockExecClient,
+		Logger:          appLogger,
+		Metrics:         appMetrics,
+		ValidationService: NewValidationService(ValidationConfig{
+			Logger:                appLogger,
+			LatestVersionSentinel: cfg.Validation.LatestVersionSentinel,
+		}),
+		Config: cfg,
+	})
+
+	t.Run("sentinel version uses the execution version", func(t *testing.T) {
+		fill := newVersionConflictTestFill()
+		fill.ExecutionStatus = "PART"
+		fill.Version = -1
+
+		mockExecClient.On("GetExecution", mock.Anything, int64(2)).Return(versionConflictTestExecution(7), nil).Once()
+		mockExecClient.On("UpdateExecution", mock.Anything, int64(2), matchUpdateVersion(7)).
+			Return(&domain.ExecutionUpdateResponse{ID: 2, ExecutionStatus: "PARTIAL", Version: 8}, nil).Once()
+
+		err := service.HandleFillMessage(context.Background(), fill)
+		assert.NoError(t, err)
+		mockExecClient.AssertExpectations(t)
+	})
+
+	t.Run("other negative versions are rejected", func(t *testing.T) {
+		fill := newVersionConflictTestFill()
+		fill.ID = 3
+		fill.ExecutionStatus = "PART"
+		fill.Version = -5
+
+		err := service.HandleFillMessage(context.Background(), fill)
+		require.Error(t, err)
+		assert.True(t, domain.IsErrorType(err, domain.ErrorTypeValidation))
+		assert.Contains(t, err.Error(), "version must be non-negative")
+	})
+}
+
+func newCanaryTestService(t *testing.T, allowlist, denylist []int64) (*ConfirmationService, *MockExecutionServiceClient) {
+	mockExecClient := &MockExecutionServiceClient{}
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	cfg := config.GetDefaults()
+	cfg.Validation.MaxMessageAgeMinutes = 0
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:      mockExecClient,
+		Logger:               appLogger,
+		Metrics:              appMetrics,
+		Config:               cfg,
+		ExecutionIDAllowlist: allowlist,
+		ExecutionIDDenylist:  denylist,
+	})
+
+	return service, mockExecClient
+}
+
+func TestConfirmationService_HandleFillMessage_CanaryAllowlist(t *testing.T) {
+	service, mockExecClient := newCanaryTestService(t, []int64{2}, nil)
+
+	t.Run("allowlisted execution is processed", func(t *testing.T) {
+		fill := newVersionConflictTestFill()
+
+		mockExecClient.On("GetExecution", mock.Anything, int64(2)).Return(versionConflictTestExecution(1), nil).Once()
+		mockExecClient.On("UpdateExecution", mock.Anything, int64(2), matchUpdateVersion(1)).
+			Return(&domain.ExecutionUpdateResponse{ID: 2, ExecutionStatus: "PARTIAL", Version: 2}, nil).Once()
+
+		err := service.HandleFillMessage(context.Background(), fill)
+		assert.NoError(t, err)
+		mockExecClient.AssertExpectations(t)
+		assert.Equal(t, 0.0, testutil.ToFloat64(service.metrics.MessagesSkippedCanaryTotal))
+		assert.Equal(t, 1.0, testutil.ToFloat64(service.metrics.MessagesProcessedByDestinationTotal.WithLabelValues("ML", "BUY")))
+	})
+
+	t.Run("other executions are skipped", func(t *testing.T) {
+		fill := newVersionConflictTestFill()
+		fill.ExecutionServiceID = 3
+
+		err := service.HandleFillMessage(context.Background(), fill)
+		assert.NoError(t, err)
+		mockExecClient.AssertNotCalled(t, "GetExecution", mock.Anything, int64(3))
+		assert.Equal(t, 1.0, testutil.ToFloat64(service.metrics.MessagesSkippedCanaryTotal))
+	})
+}
+
+func TestConfirmationService_HandleFillMessage_CanaryDenylist(t *testing.T) {
+	service, mockExecClient := newCanaryTestService(t, nil, []int64{2})
+
+	err := service.HandleFillMessage(context.Background(), newVersionConflictTestFill())
+	assert.NoError(t, err)
+	mockExecClient.AssertNotCalled(t, "GetExecution", mock.Anything, mock.Anything)
+	assert.Equal(t, 1.0, testutil.ToFloat64(service.metrics.MessagesSkippedCanaryTotal))
+}
+
+func TestProcessingTimeWindow(t *testing.T) {
+	window := newProcessingTimeWindow(100)
+	assert.Equal(t, ProcessingTimeStats{}, window.stats())
+
+	// 1ms..100ms, then 10 more that evict the fastest ten
+	for i := 1; i <= 110; i++ {
+		window.record(time.Duration(i) * time.Millisecond)
+	}
+
+	stats := window.stats()
+	assert.Equal(t, 100, stats.Count)
+	assert.Equal(t, 11.0, stats.MinMs)
+	assert.Equal(t, 110.0, stats.MaxMs)
+	assert.InDelta(t, 60.5, stats.MeanMs, 0.0001)
+	assert.InDelta(t, 60.5, stats.P50Ms, 0.0001)
+	assert.InDelta(t, 105.05, stats.P95Ms, 0.0001)
+	assert.InDelta(t, 109.01, stats.P99Ms, 0.0001)
+	assert.True(t, stats.MinMs <= stats.P50Ms && stats.P50Ms <= stats.P95Ms && stats.P95Ms <= stats.P99Ms && stats.P99Ms <= stats.MaxMs)
+}
+
+func TestProcessingTimeWindow_LargeWindow(t *testing.T) {
+	// Large windows are sorted once per stats call, not once per percentile
+	window := newProcessingTimeWindow(100000)
+	for i := 100000; i >= 1; i-- {
+		window.record(time.Duration(i) * time.Millisecond)
+	}
+
+	stats := window.stats()
+	assert.Equal(t, 100000, stats.Count)
+	assert.Equal(t, 1.0, stats.MinMs)
+	assert.Equal(t, 100000.0, stats.MaxMs)
+	assert.InDelta(t, 50000.5, stats.P50Ms, 0.0001)
+}
+
+func TestConfirmationService_GetStats_ProcessingTime(t *testing.T) {
+	mockExecClient := &MockExecutionServiceClient{}
+	mockExecClient.On("GetStats").Return(map[string]interface{}{})
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:          mockExecClient,
+		Logger:                   appLogger,
+		Metrics:                  metrics.New(metrics.Config{Enabled: true, Namespace: "test"}),
+		ProcessingTimeWindowSize: 10,
+	})
+
+	for _, ms := range []int{5, 10, 15, 20, 200} {
+		service.recordProcessingTime(time.Duration(ms) * time.Millisecond)
+	}
+
+	stats, ok := service.GetStats()["processing_time"].(ProcessingTimeStats)
+	require.True(t, ok)
+	assert.Equal(t, 5, stats.Count)
+	assert.Equal(t, 15.0, stats.P50Ms)
+	assert.Greater(t, stats.P99Ms, stats.P50Ms)
+	assert.LessOrEqual(t, stats.P99Ms, 200.0)
+}
+
+func TestConfirmationService_HandleFillMessage_RollingErrorRate(t *testing.T) {
+	mockExecClient := &MockExecutionServiceClient{}
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	// Messages older than the maximum age fail validation
+	cfg := config.GetDefaults()
+	cfg.Validation.MaxMessageAgeMinutes = 60
+	cfg.Validation.WarnOnValidationFailures = false
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:     mockExecClient,
+		Logger:              appLogger,
+		Metrics:             appMetrics,
+		Config:              cfg,
+		ErrorRateWindowSize: 4,
+	})
+
+	mockExecClient.On("GetExecution", mock.Anything, int64(2)).Return(versionConflictTestExecution(1), nil)
+	mockExecClient.On("UpdateExecution", mock.Anything, int64(2), matchUpdateVersion(1)).
+		Return(&domain.ExecutionUpdateResponse{ID: 2, ExecutionStatus: "PARTIAL", Version: 2}, nil)
+
+	handle := func(succeed bool) {
+		fill := newVersionConflictTestFill()
+		if succeed {
+			now := float64(time.Now().Unix())
+			fill.ReceivedTimestamp, fill.SentTimestamp, fill.LastFilledTimestamp = now, now, now
+		}
+		result := service.HandleFillMessage(context.Background(), fill)
+		assert.Equal(t, succeed, result == nil)
+	}
+
+	assert.Equal(t, 0.0, service.ErrorRate())
+
+	// S F S -> 1/3
+	handle(true)
+	handle(false)
+	handle(true)
+	assert.InDelta(t, 1.0/3.0, service.ErrorRate(), 0.0001)
+	assert.InDelta(t, 1.0/3.0, testutil.ToFloat64(service.metrics.MessageErrorRate), 0.0001)
+
+	// S F S S F -> the window of 4 holds F S S F
+	handle(true)
+	handle(false)
+	assert.InDelta(t, 0.5, service.ErrorRate(), 0.0001)
+	assert.InDelta(t, 0.5, testutil.ToFloat64(service.metrics.MessageErrorRate), 0.0001)
+
+	mockExecClient.On("GetStats").Return(map[string]interface{}{})
+	stats := service.GetStats()
+	assert.InDelta(t, 0.5, stats["message_error_rate"], 0.0001)
+	assert.Equal(t, 4, stats["error_rate_window_size"])
+}
+
+func TestConfirmationService_HandleFillMessage_ShadowMode(t *testing.T) {
+	mockExecClient := &MockExecutionServiceClient{}
+	mockAllocClient := &MockAllocationServiceClient{}
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	cfg := config.GetDefaults()
+	cfg.Validation.MaxMessageAgeMinutes = 0
+
+	duplicateDetection := NewDuplicateDetectionService(DuplicateDetectionConfig{Logger: appLogger})
+	defer duplicateDetection.Stop()
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:    mockExecClient,
+		AllocationClient:   mockAllocClient,
+		DuplicateDetection: duplicateDetection,
+		Logger:             appLogger,
+		Metrics:            appMetrics,
+		Config:             cfg,
+		Mode:               ProcessingModeShadow,
+	})
+
+	fill := newVersionConflictTestFill()
+	fill.IsOpen = false
+
+	err = service.HandleFillMessage(context.Background(), fill)
+	assert.NoError(t, err)
+
+	// Neither downstream service is called
+	mockExecClient.AssertNotCalled(t, "GetExecution", mock.Anything, mock.Anything)
+	mockExecClient.AssertNotCalled(t, "UpdateExecution", mock.Anything, mock.Anything, mock.Anything)
+	mockAllocClient.AssertNotCalled(t, "PostExecution", mock.Anything, mock.Anything)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(appMetrics.MessagesShadowProcessedTotal))
+	assert.Equal(t, 0.0, testutil.ToFloat64(appMetrics.MessagesProcessedTotal))
+	assert.Equal(t, 1.0, testutil.ToFloat64(appMetrics.ShadowCallsSkippedTotal.WithLabelValues("execution-service")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(appMetrics.ShadowCallsSkippedTotal.WithLabelValues("allocation-service")))
+
+	// The fill is not recorded as processed, so a live instance sharing the store handles it
+	assert.False(t, duplicateDetection.CheckDuplicate(context.Background(), fill).IsDuplicate)
+
+	mockExecClient.On("GetStats").Return(map[string]interface{}{})
+	assert.Equal(t, "shadow", service.GetStats()["mode"])
+}
+
+func TestConfirmationService_HandleFillMessage_ShadowModeFollowsAllocationTrigger(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	cfg := config.GetDefaults()
+	cfg.Validation.MaxMessageAgeMinutes = 0
+
+	mockAllocClient := &MockAllocationServiceClient{}
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:   &MockExecutionServiceClient{},
+		AllocationClient:  mockAllocClient,
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		Config:            cfg,
+		Mode:              ProcessingModeShadow,
+		AllocationTrigger: AllocationTriggerFull,
+	})
+	allocationSkipped := appMetrics.ShadowCallsSkippedTotal.WithLabelValues("allocation-service")
+
+	// A closed but cancelled fill would not be posted under the full trigger
+	cancelled := newVersionConflictTestFill()
+	cancelled.IsOpen = false
+	cancelled.ExecutionStatus = "CNCL"
+	require.NoError(t, service.HandleFillMessage(context.Background(), cancelled))
+	assert.Equal(t, 0.0, testutil.ToFloat64(allocationSkipped))
+
+	// A fully filled execution would be, even while the fill is still flagged open
+	filled := newVersionConflictTestFill()
+	filled.ID = 2
+	filled.ExecutionStatus = "FULL"
+	require.NoError(t, service.HandleFillMessage(context.Background(), filled))
+	assert.Equal(t, 1.0, testutil.ToFloat64(allocationSkipped))
+
+	mockAllocClient.AssertNotCalled(t, "PostExecution", mock.Anything, mock.Anything)
+}
+
+func TestConfirmationService_Mode_DefaultsToLive(t *testing.T) {
+	service := &ConfirmationService{}
+	assert.Equal(t, ProcessingModeLive, service.Mode())
+}
+
+func newAllocationDegradationTestService(t *testing.T) (*ConfirmationService, *MockAllocationServiceClient, *MockResilienceManager) {
+	mockAllocClient := &MockAllocationServiceClient{}
+	mockResilience := &MockResilienceManager{}
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:   &MockExecutionServiceClient{},
+		AllocationClient:  mockAllocClient,
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ResilienceManager: mockResilience,
+		AllocationCircuitBreaker: utils.NewCircuitBreaker(utils.CircuitBreakerConfig{
+			Name:             "allocation-service",
+			FailureThreshold: 1,
+			SuccessThreshold: 1,
+			Timeout:          20 * time.Millisecond,
+		}, appLogger, appMetrics),
+	})
+
+	return service, mockAllocClient, mockResilience
+}
+
+func TestConfirmationService_HandleAllocationServiceCall_QueuesWhileCircuitOpen(t *testing.T) {
+	service, mockAllocClient, mockResilience := newAllocationDegradationTestService(t)
+	ctx := context.Background()
+
+	fill := newVersionConflictTestFill()
+	fill.IsOpen = false
+
+	// The first failure opens the circuit and is dead-lettered as before
+	mockAllocClient.On("PostExecution", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Once()
+	mockResilience.On("AddToDeadLetterQueue", mock.Anything, mock.Anything, "allocation-service failure", mock.Anything, 1, mock.Anything).Return(nil).Once()
+	service.handleAllocationServiceCall(ctx, fill)
+	assert.Equal(t, utils.StateOpen, service.allocationCircuitBreaker.GetState())
+
+	// While open, posts are queued without an HTTP call or a dead letter
+	service.handleAllocationServiceCall(ctx, fill)
+	service.handleAllocationServiceCall(ctx, fill)
+	mockAllocClient.AssertNumberOfCalls(t, "PostExecution", 1)
+	mockResilience.AssertNumberOfCalls(t, "AddToDeadLetterQueue", 1)
+	assert.Equal(t, 2, service.pendingAllocations.stats().Size)
+
+	// Once the circuit lets calls through again, queued posts are replayed in order
+	time.Sleep(30 * time.Millisecond)
+	mockAllocClient.On("PostExecution", mock.Anything, mock.Anything).Return(nil).Twice()
+	service.replayPendingAllocations(ctx)
+
+	mockAllocClient.AssertNumberOfCalls(t, "PostExecution", 3)
+	pending := service.pendingAllocations.stats()
+	assert.Equal(t, 0, pending.Size)
+	assert.Equal(t, int64(2), pending.Queued)
+	assert.Equal(t, int64(2), pending.Replayed)
+	assert.Equal(t, utils.StateClosed, service.allocationCircuitBreaker.GetState())
+	mockResilience.AssertNumberOfCalls(t, "AddToDeadLetterQueue", 1)
+}
+
+func TestConfirmationService_Stop_DeadLettersPendingAllocations(t *testing.T) {
+	service, mockAllocClient, mockResilience := newAllocationDegradationTestService(t)
+	ctx := context.Background()
+
+	fill := newVersionConflictTestFill()
+	fill.IsOpen = false
+
+	mockAllocClient.On("PostExecution", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Once()
+	mockResilience.On("AddToDeadLetterQueue", mock.Anything, mock.Anything, "allocation-service failure", mock.Anything, 1, mock.Anything).Return(nil)
+	service.handleAllocationServiceCall(ctx, fill)
+	service.handleAllocationServiceCall(ctx, fill)
+	require.Equal(t, 1, service.pendingAllocations.stats().Size)
+
+	service.Stop(ctx)
+
+	assert.Equal(t, 0, service.pendingAllocations.stats().Size)
+	mockResilience.AssertNumberOfCalls(t, "AddToDeadLetterQueue", 2)
+}
+
+func TestConfirmationService_GetStats_AllocationCircuit(t *testing.T) {
+	service := &ConfirmationService{
+		allocationCircuitBreaker: utils.NewCircuitBreaker(utils.CircuitBreakerConfig{Name: "allocation-service"}, nil, nil),
+		pendingAllocations:       newPendingAllocationBuffer(10),
+		tenants: map[string]TenantProfile{
+			"acme": {AllocationCircuitBreaker: utils.NewCircuitBreaker(utils.CircuitBreakerConfig{Name: AllocationCircuitBreakerName("acme")}, nil, nil)},
+		},
+	}
+
+	stats := service.GetStats()
+	assert.Equal(t, "closed", stats["allocation_circuit_state"])
+	assert.Equal(t, map[string]string{"acme": "closed"}, stats["tenant_allocation_circuit_states"])
+	assert.Equal(t, PendingAllocationStats{MaxSize: 10}, stats["pending_allocations"])
+}
+
+func TestConfirmationService_HandleAllocationServiceCall_LogsOnlyFillSummary(t *testing.T) {
+	appLogger, logs := newObservedLogger()
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:   &MockExecutionServiceClient{},
+		Logger:            appLogger,
+		Metrics:           metrics.New(metrics.Config{Enabled: true, Namespace: "test"}),
+		ResilienceManager: &MockResilienceManager{},
+	})
+
+	fill := newVersionConflictTestFill()
+	require.NoError(t, service.handleAllocationServiceCall(context.Background(), fill))
+
+	// No fill dump at info level
+	for _, entry := range logs.All() {
+		assert.NotContains(t, entry.ContextMap(), "fill", "entry %q logs the whole fill", entry.Message)
+	}
+	assert.Equal(t, 0, logs.FilterLevelExact(zapcore.InfoLevel).Len())
+
+	entries := logs.FilterMessage("Checking fill for Allocation Service").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	assert.Equal(t, map[string]interface{}{"fill_id": fill.ID, "is_open": fill.IsOpen}, entries[0].ContextMap())
+}
+
+func TestConfirmationService_HandleAllocationServiceCall_SkipsReprocessedFill(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	mockAllocClient := &MockAllocationServiceClient{}
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:           &MockExecutionServiceClient{},
+		AllocationClient:          mockAllocClient,
+		Logger:                    appLogger,
+		Metrics:                   metrics.New(metrics.Config{Enabled: true, Namespace: "test"}),
+		ResilienceManager:         &MockResilienceManager{},
+		PostedAllocationRetention: time.Hour,
+	})
+	ctx := context.Background()
+
+	fill := newVersionConflictTestFill()
+	fill.IsOpen = false
+	fill.Version = 3
+
+	// A failed post is not remembered, so redelivery posts again
+	mockAllocClient.On("PostExecution", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Once()
+	service.allocationRequired = true
+	require.Error(t, service.handleAllocationServiceCall(ctx, fill))
+
+	mockAllocClient.On("PostExecution", mock.Anything, mock.MatchedBy(func(dto *domain.AllocationServiceExecutionDTO) bool {
+		return dto.IdempotencyKey == "fill-1-exec-2-v3"
+	})).Return(nil).Once()
+	require.NoError(t, service.handleAllocationServiceCall(ctx, fill))
+
+	// Reprocessing the same fill version does not post it again
+	require.NoError(t, service.handleAllocationServiceCall(ctx, fill))
+	mockAllocClient.AssertNumberOfCalls(t, "PostExecution", 2)
+	assert.Equal(t, int64(1), service.postedAllocations.stats().Skipped)
+
+	// A new version of the fill is posted
+	fill.Version = 4
+	mockAllocClient.On("PostExecution", mock.Anything, mock.Anything).Return(nil).Once()
+	require.NoError(t, service.handleAllocationServiceCall(ctx, fill))
+	mockAllocClient.AssertNumberOfCalls(t, "PostExecution", 3)
+}
+
+func TestConfirmationService_HandleAllocationServiceCall_FullTrigger(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	mockAllocClient := &MockAllocationServiceClient{}
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:   &MockExecutionServiceClient{},
+		AllocationClient:  mockAllocClient,
+		Logger:            appLogger,
+		Metrics:           metrics.New(metrics.Config{Enabled: true, Namespace: "test"}),
+		ResilienceManager: &MockResilienceManager{},
+		AllocationTrigger: AllocationTriggerFull,
+	})
+	ctx := context.Background()
+
+	// A closed but cancelled fill is not posted
+	cancelled := newVersionConflictTestFill()
+	cancelled.IsOpen = false
+	cancelled.ExecutionStatus = "CNCL"
+	require.NoError(t, service.handleAllocationServiceCall(ctx, cancelled))
+	mockAllocClient.AssertNotCalled(t, "PostExecution", mock.Anything, mock.Anything)
+
+	filled := newVersionConflictTestFill()
+	filled.IsOpen = false
+	filled.ExecutionStatus = "FULL"
+	mockAllocClient.On("PostExecution", mock.Anything, mock.MatchedBy(func(dto *domain.AllocationServiceExecutionDTO) bool {
+		return dto.ExecutionStatus == "FULL"
+	})).Return(nil).Once()
+	require.NoError(t, service.handleAllocationServiceCall(ctx, filled))
+	mockAllocClient.AssertExpectations(t)
+}
+
+func TestConfirmationService_HandleAllocationServiceCall_RecordsPostMetrics(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+	mockAllocClient := &MockAllocationServiceClient{}
+	mockResilience := &MockResilienceManager{}
+
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:   &MockExecutionServiceClient{},
+		AllocationClient:  mockAllocClient,
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ResilienceManager: mockResilience,
+	})
+	ctx := context.Background()
+
+	fill := newVersionConflictTestFill()
+	fill.IsOpen = false
+
+	mockAllocClient.On("PostExecution", mock.Anything, mock.Anything).Return(nil).Once()
+	require.NoError(t, service.handleAllocationServiceCall(ctx, fill))
+
+	mockAllocClient.On("PostExecution", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Once()
+	mockResilience.On("AddToDeadLetterQueue", mock.Anything, mock.Anything, "allocation-service failure", mock.Anything, 1, mock.Anything).Return(nil).Once()
+	require.NoError(t, service.handleAllocationServiceCall(ctx, fill))
+
+	// Open fills do not meet the default trigger
+	fill.IsOpen = true
+	require.NoError(t, service.handleAllocationServiceCall(ctx, fill))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(appMetrics.AllocationPostsTotal.WithLabelValues("success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(appMetrics.AllocationPostsTotal.WithLabelValues("failure")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(appMetrics.AllocationPostsTotal.WithLabelValues("skipped")))
+	assert.Equal(t, 1, testutil.CollectAndCount(appMetrics.AllocationPostDurationSeconds))
+	mockAllocClient.AssertNumberOfCalls(t, "PostExecution", 2)
+}
+
+func TestConfirmationService_Stop_StopsDuplicateDetection(t *testing.T) {
+	service, mockAllocClient, mockResilience := newAllocationDegradationTestService(t)
+	service.duplicateDetection = NewDuplicateDetectionService(DuplicateDetectionConfig{Logger: service.logger})
+	ctx := context.Background()
+
+	fill := newVersionConflictTestFill()
+	fill.IsOpen = false
+
+	// Open the circuit and queue one post, so Stop has something to dead-letter
+	mockAllocClient.On("PostExecution", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Once()
+	mockResilience.On("AddToDeadLetterQueue", mock.Anything, mock.Anything, "allocation-service failure", mock.Anything, 1, mock.Anything).Return(nil)
+	service.handleAllocationServiceCall(ctx, fill)
+	service.handleAllocationServiceCall(ctx, fill)
+	require.Equal(t, 1, service.pendingAllocations.stats().Size)
+
+	service.Stop(ctx)
+
+	mockResilience.AssertNumberOfCalls(t, "AddToDeadLetterQueue", 2)
+	select {
+	case <-service.duplicateDetection.cleanupDone:
+	default:
+		t.Fatal("duplicate detection cleanup loop still running after Stop")
+	}
+
+	// Stopping duplicate detection again, as its owner may, is a no-op
+	assert.NotPanics(t, service.duplicateDetection.Stop)
+}
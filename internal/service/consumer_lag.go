@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// partitionOffsets holds the newest offset of a partition and the offset the consumer
+// group committed on it; Committed is negative when the group has not committed yet
+type partitionOffsets struct {
+	Topic     string
+	Partition int
+	Latest    int64
+	Committed int64
+}
+
+// lag returns how many messages the consumer group is behind on the partition, or
+// false when the group has not committed an offset there yet
+func (o partitionOffsets) lag() (int64, bool) {
+	if o.Committed < 0 {
+		return 0, false
+	}
+	if o.Latest < o.Committed {
+		// The offsets are read in two requests, so the committed offset can be newer
+		return 0, true
+	}
+	return o.Latest - o.Committed, true
+}
+
+// offsetSource reports and commits the offsets of the partitions of the consumed topics
+type offsetSource interface {
+	Offsets(ctx context.Context, groupID string, topics []string) ([]partitionOffsets, error)
+
+	// OffsetsAt returns the offset of the first message at or after the time by topic
+	// and partition, leaving out partitions without such a message
+	OffsetsAt(ctx context.Context, topics []string, at time.Time) (map[string]map[int]int64, error)
+
+	// CommitOffsets commits offsets for the consumer group while it has no members
+	CommitOffsets(ctx context.Context, groupID string, offsets map[string][]kafka.OffsetCommit) error
+}
+
+// brokerOffsetSource reads partition offsets from the Kafka brokers
+type brokerOffsetSource struct {
+	client *kafka.Client
+}
+
+func newBrokerOffsetSource(brokers []string, timeout time.Duration) *brokerOffsetSource {
+	return &brokerOffsetSource{
+		client: &kafka.Client{
+			Addr:    kafka.TCP(brokers...),
+			Timeout: timeout,
+		},
+	}
+}
+
+// Offsets lists the topics' partitions, then fetches the group's committed offsets and
+// the newest offset of each
+func (s *brokerOffsetSource) Offsets(ctx context.Context, groupID string, topics []string) ([]partitionOffsets, error) {
+	partitions, err := s.partitions(ctx, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	latestRequests := make(map[string][]kafka.OffsetRequest, len(partitions))
+	for topic, ids := range partitions {
+		for _, id := range ids {
+			latestRequests[topic] = append(latestRequests[topic], kafka.LastOffsetOf(id))
+		}
+	}
+
+	committed, err := s.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: partitions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", err)
+	}
+	if committed.Error != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", committed.Error)
+	}
+
+	latest, err := s.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: latestRequests})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partition offsets: %w", err)
+	}
+
+	committedOffsets := make(map[string]map[int]int64, len(committed.Topics))
+	for topic, partitions := range committed.Topics {
+		committedOffsets[topic] = make(map[int]int64, len(partitions))
+		for _, partition := range partitions {
+			if partition.Error != nil {
+				continue
+			}
+			committedOffsets[topic][partition.Partition] = partition.CommittedOffset
+		}
+	}
+
+	var offsets []partitionOffsets
+	for topic, partitions := range latest.Topics {
+		for _, partition := range partitions {
+			if partition.Error != nil {
+				continue
+			}
+
+			committedOffset, ok := committedOffsets[topic][partition.Partition]
+			if !ok {
+				committedOffset = -1
+			}
+			offsets = append(offsets, partitionOffsets{
+				Topic:     topic,
+				Partition: partition.Partition,
+				Latest:    partition.LastOffset,
+				Committed: committedOffset,
+			})
+		}
+	}
+
+	return offsets, nil
+}
+
+// partitions lists the partition IDs of each topic
+func (s *brokerOffsetSource) partitions(ctx context.Context, topics []string) (map[string][]int, error) {
+	metadata, err := s.client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch topic metadata: %w", err)
+	}
+
+	partitions := make(map[string][]int, len(metadata.Topics))
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			return nil, fmt.Errorf("failed to fetch metadata for topic %s: %w", topic.Name, topic.Error)
+		}
+		for _, partition := range topic.Partitions {
+			partitions[topic.Name] = append(partitions[topic.Name], partition.ID)
+		}
+	}
+
+	return partitions, nil
+}
+
+// lagLoop periodically measures consumer lag until the consumer stops
+func (kcs *KafkaConsumerService) lagLoop(ctx context.Context) {
+	defer kcs.wg.Done()
+
+	ticker := time.NewTicker(kcs.config.LagPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			kcs.collectLag(ctx)
+		}
+	}
+}
+
+// collectLag computes the consumer group's lag on every partition of the consumed
+// topics and exports it per partition
+func (kcs *KafkaConsumerService) collectLag(ctx context.Context) {
+	queryCtx, cancel := context.WithTimeout(ctx, kcs.config.ConnectionTimeout)
+	defer cancel()
+
+	offsets, err := kcs.offsetSource.Offsets(queryCtx, kcs.config.ConsumerGroup, kcs.topics)
+	if err != nil {
+		if ctx.Err() == nil {
+			kcs.logger.Warn("Failed to measure Kafka consumer lag", zap.Error(err))
+		}
+		return
+	}
+
+	sort.Slice(offsets, func(i, j int) bool {
+		if offsets[i].Topic != offsets[j].Topic {
+			return offsets[i].Topic < offsets[j].Topic
+		}
+		return offsets[i].Partition < offsets[j].Partition
+	})
+
+	partitionLag := make(map[string]int64, len(offsets))
+	var totalLag int64
+	for _, partition := range offsets {
+		lag, ok := partition.lag()
+		if !ok {
+			continue
+		}
+
+		kcs.metrics.SetKafkaConsumerLag(partition.Topic, partition.Partition, float64(lag))
+		partitionLag[partition.Topic+"/"+strconv.Itoa(partition.Partition)] = lag
+		totalLag += lag
+	}
+
+	kcs.mutex.Lock()
+	kcs.partitionLag = partitionLag
+	kcs.totalLag = totalLag
+	kcs.mutex.Unlock()
+}
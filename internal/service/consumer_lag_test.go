@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOffsetSource returns fixed partition offsets, or an error, and records commits
+type fakeOffsetSource struct {
+	mutex       sync.Mutex
+	offsets     []partitionOffsets
+	offsetsAt   map[string]map[int]int64
+	err         error
+	groupID     string
+	topics      []string
+	at          time.Time
+	committed   map[string][]kafka.OffsetCommit
+	commitError error
+}
+
+func (s *fakeOffsetSource) Offsets(ctx context.Context, groupID string, topics []string) ([]partitionOffsets, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.groupID = groupID
+	s.topics = topics
+	return s.offsets, s.err
+}
+
+func (s *fakeOffsetSource) OffsetsAt(ctx context.Context, topics []string, at time.Time) (map[string]map[int]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.at = at
+	return s.offsetsAt, s.err
+}
+
+func (s *fakeOffsetSource) CommitOffsets(ctx context.Context, groupID string, offsets map[string][]kafka.OffsetCommit) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.commitError != nil {
+		return s.commitError
+	}
+	s.committed = offsets
+	return nil
+}
+
+func (s *fakeOffsetSource) setOffsets(offsets ...partitionOffsets) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.offsets = offsets
+}
+
+func TestPartitionOffsets_Lag(t *testing.T) {
+	tests := []struct {
+		name      string
+		offsets   partitionOffsets
+		wantLag   int64
+		wantKnown bool
+	}{
+		{"behind", partitionOffsets{Latest: 120, Committed: 100}, 20, true},
+		{"caught up", partitionOffsets{Latest: 100, Committed: 100}, 0, true},
+		{"committed after latest was read", partitionOffsets{Latest: 100, Committed: 105}, 0, true},
+		{"nothing committed", partitionOffsets{Latest: 100, Committed: -1}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lag, known := tt.offsets.lag()
+			assert.Equal(t, tt.wantLag, lag)
+			assert.Equal(t, tt.wantKnown, known)
+		})
+	}
+}
+
+func TestKafkaConsumerService_CollectLag_ExportsPartitionLag(t *testing.T) {
+	consumer, _ := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+	consumer.config.ConsumerGroup = "confirmations"
+	consumer.config.ConnectionTimeout = time.Second
+	source := &fakeOffsetSource{}
+	source.setOffsets(
+		partitionOffsets{Topic: "fills", Partition: 0, Latest: 150, Committed: 100},
+		partitionOffsets{Topic: "fills", Partition: 1, Latest: 80, Committed: 75},
+		partitionOffsets{Topic: "fills", Partition: 2, Latest: 40, Committed: -1},
+	)
+	consumer.offsetSource = source
+
+	consumer.collectLag(context.Background())
+
+	assert.Equal(t, "confirmations", source.groupID)
+	assert.Equal(t, []string{"fills"}, source.topics)
+	assert.Equal(t, 50.0, testutil.ToFloat64(consumer.metrics.KafkaConsumerLag.WithLabelValues("fills", "0")))
+	assert.Equal(t, 5.0, testutil.ToFloat64(consumer.metrics.KafkaConsumerLag.WithLabelValues("fills", "1")))
+	assert.Equal(t, 2, testutil.CollectAndCount(&consumer.metrics.KafkaConsumerLag), "partitions without a committed offset are not exported")
+
+	readerStats := consumer.GetStats()["reader_stats"].(map[string]interface{})
+	assert.Equal(t, int64(55), readerStats["lag"])
+	assert.Equal(t, map[string]int64{"fills/0": 50, "fills/1": 5}, readerStats["partition_lag"])
+
+	// A failed query keeps the last measurement
+	source.err = errors.New("broker unavailable")
+	consumer.collectLag(context.Background())
+	readerStats = consumer.GetStats()["reader_stats"].(map[string]interface{})
+	assert.Equal(t, int64(55), readerStats["lag"])
+}
+
+func TestKafkaConsumerService_LagLoop_FollowsLifecycle(t *testing.T) {
+	consumer, _ := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+	consumer.config.LagPollInterval = 10 * time.Millisecond
+	consumer.config.ConnectionTimeout = time.Second
+	source := &fakeOffsetSource{}
+	source.setOffsets(partitionOffsets{Topic: "fills", Partition: 0, Latest: 12, Committed: 10})
+	consumer.offsetSource = source
+
+	consumer.mutex.Lock()
+	consumer.startConsuming(context.Background())
+	consumer.mutex.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(consumer.metrics.KafkaConsumerLag.WithLabelValues("fills", "0")) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, consumer.Stop(context.Background()))
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+)
+
+// Supported fill message formats
+const (
+	MessageFormatJSON     = "json"
+	MessageFormatProtobuf = "protobuf"
+)
+
+// Deserializer decodes a Kafka message value into a fill
+type Deserializer interface {
+	Deserialize(data []byte) (*domain.Fill, error)
+	Format() string
+}
+
+// NewDeserializer returns the deserializer for a message format
+func NewDeserializer(format string) (Deserializer, error) {
+	switch format {
+	case "", MessageFormatJSON:
+		return JSONDeserializer{}, nil
+	case MessageFormatProtobuf:
+		return ProtobufDeserializer{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported message format: %s", format)
+	}
+}
+
+// JSONDeserializer decodes fills encoded as JSON
+type JSONDeserializer struct{}
+
+// Deserialize decodes a JSON-encoded fill
+func (JSONDeserializer) Deserialize(data []byte) (*domain.Fill, error) {
+	var fill domain.Fill
+	if err := json.Unmarshal(data, &fill); err != nil {
+		return nil, err
+	}
+	return &fill, nil
+}
+
+// Format returns the message format name
+func (JSONDeserializer) Format() string {
+	return MessageFormatJSON
+}
+
+// MappedJSONDeserializer decodes JSON fills whose producer uses different field names,
+// renaming fields to the fill's own names first. Fields without a mapping are decoded
+// as they are.
+type MappedJSONDeserializer struct {
+	fieldMapping map[string]string // Lowercased producer field name to fill field name
+}
+
+// NewMappedJSONDeserializer returns a deserializer that renames the producer's fields,
+// keyed by producer field name, to fill JSON field names such as executionServiceId.
+// Producer field names are matched case-insensitively.
+func NewMappedJSONDeserializer(fieldMapping map[string]string) (*MappedJSONDeserializer, error) {
+	fillFields := domain.FillJSONFields()
+
+	normalized := make(map[string]string, len(fieldMapping))
+	for from, to := range fieldMapping {
+		if !fillFields[to] {
+			return nil, fmt.Errorf("field mapping for %s targets unknown fill field %s", from, to)
+		}
+		normalized[strings.ToLower(from)] = to
+	}
+
+	return &MappedJSONDeserializer{fieldMapping: normalized}, nil
+}
+
+// Deserialize renames mapped fields and decodes the result as a JSON-encoded fill
+func (d *MappedJSONDeserializer) Deserialize(data []byte) (*domain.Fill, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	renamed := make(map[string]json.RawMessage, len(fields))
+	for name, value := range fields {
+		if to, ok := d.fieldMapping[strings.ToLower(name)]; ok {
+			name = to
+		}
+		renamed[name] = value
+	}
+
+	mapped, err := json.Marshal(renamed)
+	if err != nil {
+		return nil, err
+	}
+	return JSONDeserializer{}.Deserialize(mapped)
+}
+
+// Format returns the message format name
+func (d *MappedJSONDeserializer) Format() string {
+	return MessageFormatJSON
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func newDeserializerTestFill() *domain.Fill {
+	return &domain.Fill{
+		ID:                  11,
+		ExecutionServiceID:  27,
+		IsOpen:              false,
+		ExecutionStatus:     "FULL",
+		TradeType:           "SELL",
+		Destination:         "ML",
+		SecurityID:          "68336002fe95851f0a2aeda9",
+		Ticker:              "IBM",
+		Quantity:            1000,
+		ReceivedTimestamp:   1748354367.509362,
+		SentTimestamp:       1748354367.512467,
+		LastFilledTimestamp: 1748354504.1602714,
+		QuantityFilled:      1000,
+		AveragePrice:        190.4096,
+		NumberOfFills:       3,
+		TotalAmount:         190409.6,
+		Version:             1,
+		TenantID:            "acme",
+	}
+}
+
+// encodeProtobufFill encodes a fill as the Fill message in fill.proto
+func encodeProtobufFill(fill *domain.Fill) []byte {
+	var b []byte
+	appendVarint := func(num protowire.Number, v uint64) {
+		b = protowire.AppendTag(b, num, protowire.VarintType)
+		b = protowire.AppendVarint(b, v)
+	}
+	appendString := func(num protowire.Number, v string) {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+	}
+	appendDouble := func(num protowire.Number, v float64) {
+		b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v))
+	}
+
+	appendVarint(fillFieldID, uint64(fill.ID))
+	appendVarint(fillFieldExecutionServiceID, uint64(fill.ExecutionServiceID))
+	appendVarint(fillFieldIsOpen, protowire.EncodeBool(fill.IsOpen))
+	appendString(fillFieldExecutionStatus, fill.ExecutionStatus)
+	appendString(fillFieldTradeType, fill.TradeType)
+	appendString(fillFieldDestination, fill.Destination)
+	appendString(fillFieldSecurityID, fill.SecurityID)
+	appendString(fillFieldTicker, fill.Ticker)
+	appendVarint(fillFieldQuantity, uint64(fill.Quantity))
+	appendDouble(fillFieldReceivedTimestamp, fill.ReceivedTimestamp)
+	appendDouble(fillFieldSentTimestamp, fill.SentTimestamp)
+	appendDouble(fillFieldLastFilledTimestamp, fill.LastFilledTimestamp)
+	appendVarint(fillFieldQuantityFilled, uint64(fill.QuantityFilled))
+	appendDouble(fillFieldAveragePrice, fill.AveragePrice)
+	appendVarint(fillFieldNumberOfFills, uint64(fill.NumberOfFills))
+	appendDouble(fillFieldTotalAmount, fill.TotalAmount)
+	appendVarint(fillFieldVersion, uint64(fill.Version))
+	if fill.TenantID != "" {
+		appendString(fillFieldTenantID, fill.TenantID)
+	}
+	return b
+}
+
+func TestNewDeserializer(t *testing.T) {
+	deserializer, err := NewDeserializer("")
+	require.NoError(t, err)
+	assert.Equal(t, MessageFormatJSON, deserializer.Format())
+
+	deserializer, err = NewDeserializer("protobuf")
+	require.NoError(t, err)
+	assert.Equal(t, MessageFormatProtobuf, deserializer.Format())
+
+	_, err = NewDeserializer("avro")
+	assert.EqualError(t, err, "unsupported message format: avro")
+}
+
+func TestMappedJSONDeserializer_DecodesAlternateSchema(t *testing.T) {
+	// Viper lowercases map keys, so producer names must match case-insensitively
+	deserializer, err := NewMappedJSONDeserializer(map[string]string{
+		"exec_service_id":       "executionServiceId",
+		"is_open":               "isOpen",
+		"execution_status":      "executionStatus",
+		"trade_type":            "tradeType",
+		"security_id":           "securityId",
+		"received_timestamp":    "receivedTimestamp",
+		"sent_timestamp":        "sentTimestamp",
+		"last_filled_timestamp": "lastFilledTimestamp",
+		"quantity_filled":       "quantityFilled",
+		"avg_price":             "averagePrice",
+		"number_of_fills":       "numberOfFills",
+		"total_amount":          "totalAmount",
+		"tenant":                "tenantId",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, MessageFormatJSON, deserializer.Format())
+
+	value := []byte(`{
+		"id": 11,
+		"exec_service_id": 27,
+		"is_open": false,
+		"execution_status": "FULL",
+		"trade_type": "SELL",
+		"destination": "ML",
+		"security_id": "68336002fe95851f0a2aeda9",
+		"ticker": "IBM",
+		"quantity": 1000,
+		"received_timestamp": 1748354367.509362,
+		"sent_timestamp": 1748354367.512467,
+		"last_filled_timestamp": 1748354504.1602714,
+		"quantity_filled": 1000,
+		"Avg_Price": 190.4096,
+		"number_of_fills": 3,
+		"total_amount": 190409.6,
+		"version": 1,
+		"tenant": "acme"
+	}`)
+
+	fill, err := deserializer.Deserialize(value)
+	require.NoError(t, err)
+	assert.Equal(t, newDeserializerTestFill(), fill)
+
+	// Fills already using the fill's own names still decode
+	jsonValue, err := json.Marshal(newDeserializerTestFill())
+	require.NoError(t, err)
+	fill, err = deserializer.Deserialize(jsonValue)
+	require.NoError(t, err)
+	assert.Equal(t, newDeserializerTestFill(), fill)
+}
+
+func TestNewMappedJSONDeserializer_RejectsUnknownFillField(t *testing.T) {
+	_, err := NewMappedJSONDeserializer(map[string]string{"avg_price": "avgPrice"})
+	assert.EqualError(t, err, "field mapping for avg_price targets unknown fill field avgPrice")
+}
+
+func TestNewConsumerDeserializer_SelectsFieldMapping(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+
+	newConsumerConfig := func(fieldMapping map[string]string) KafkaConsumerConfig {
+		return KafkaConsumerConfig{
+			Kafka:  config.KafkaConfig{MessageFormat: MessageFormatJSON, FieldMapping: fieldMapping},
+			Logger: appLogger,
+		}
+	}
+
+	assert.IsType(t, JSONDeserializer{}, newConsumerDeserializer(newConsumerConfig(nil)))
+	assert.IsType(t, &MappedJSONDeserializer{}, newConsumerDeserializer(newConsumerConfig(map[string]string{"avg_price": "averagePrice"})))
+
+	// An invalid mapping falls back to plain JSON
+	assert.IsType(t, JSONDeserializer{}, newConsumerDeserializer(newConsumerConfig(map[string]string{"avg_price": "avgPrice"})))
+}
+
+func TestProtobufDeserializer_MatchesJSON(t *testing.T) {
+	expected := newDeserializerTestFill()
+
+	jsonValue, err := json.Marshal(expected)
+	require.NoError(t, err)
+	fromJSON, err := JSONDeserializer{}.Deserialize(jsonValue)
+	require.NoError(t, err)
+
+	fromProtobuf, err := ProtobufDeserializer{}.Deserialize(encodeProtobufFill(expected))
+	require.NoError(t, err)
+
+	assert.Equal(t, fromJSON, fromProtobuf)
+	assert.Equal(t, expected, fromProtobuf)
+}
+
+func TestProtobufDeserializer_SkipsUnknownFields(t *testing.T) {
+	expected := newDeserializerTestFill()
+
+	value := encodeProtobufFill(expected)
+	value = protowire.AppendTag(value, 99, protowire.BytesType)
+	value = protowire.AppendString(value, "added by a newer producer")
+
+	fill, err := ProtobufDeserializer{}.Deserialize(value)
+	require.NoError(t, err)
+	assert.Equal(t, expected, fill)
+}
+
+func TestProtobufDeserializer_DecimalQuantities(t *testing.T) {
+	expected := newDeserializerTestFill()
+	expected.Quantity = 1.5
+	expected.QuantityFilled = 0.5
+
+	// Producers that support fractional shares send the integer fields truncated and
+	// the exact quantities in the decimal fields, which take precedence
+	value := encodeProtobufFill(expected)
+	value = protowire.AppendTag(value, fillFieldQuantityDecimal, protowire.Fixed64Type)
+	value = protowire.AppendFixed64(value, math.Float64bits(expected.Quantity))
+	value = protowire.AppendTag(value, fillFieldQuantityFilledDecimal, protowire.Fixed64Type)
+	value = protowire.AppendFixed64(value, math.Float64bits(expected.QuantityFilled))
+
+	fill, err := ProtobufDeserializer{}.Deserialize(value)
+	require.NoError(t, err)
+	assert.Equal(t, expected, fill)
+}
+
+func TestProtobufDeserializer_Errors(t *testing.T) {
+	t.Run("wrong wire type", func(t *testing.T) {
+		value := protowire.AppendTag(nil, fillFieldTicker, protowire.VarintType)
+		value = protowire.AppendVarint(value, 1)
+
+		_, err := ProtobufDeserializer{}.Deserialize(value)
+		assert.EqualError(t, err, "field 8 has wire type 0, expected length-delimited")
+	})
+
+	t.Run("truncated value", func(t *testing.T) {
+		value := protowire.AppendTag(nil, fillFieldAveragePrice, protowire.Fixed64Type)
+		value = append(value, 0x01, 0x02)
+
+		_, err := ProtobufDeserializer{}.Deserialize(value)
+		assert.Error(t, err)
+	})
+}
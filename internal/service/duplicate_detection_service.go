@@ -1,6 +1,7 @@
 package service
 
 import (
+	"container/list"
 	"context"
 	"fmt"
 	"sync"
@@ -13,15 +14,19 @@ import (
 
 // DuplicateDetectionService handles duplicate message detection and idempotent processing
 type DuplicateDetectionService struct {
-	logger            *logger.Logger
-	processedMessages map[string]*ProcessedMessage
-	mutex             sync.RWMutex
-	retentionPeriod   time.Duration
-	maxEntries        int
+	logger          *logger.Logger
+	store           DuplicateStore
+	retentionPeriod time.Duration
+	maxEntries      int
+
+	// Change detection for reprocessing duplicates as corrections
+	priceChangeThresholdPercent float64
+	quantityChangeTriggers      bool
 
 	// Background cleanup
 	stopCleanup chan struct{}
 	cleanupDone chan struct{}
+	stopOnce    sync.Once
 }
 
 // ProcessedMessage represents a previously processed message
@@ -34,8 +39,10 @@ type ProcessedMessage struct {
 	Success            bool          `json:"success"`
 	ErrorMessage       string        `json:"errorMessage,omitempty"`
 	Version            int           `json:"version"`
-	QuantityFilled     int64         `json:"quantityFilled"`
+	QuantityFilled     float64       `json:"quantityFilled"`
 	AveragePrice       float64       `json:"averagePrice"`
+
+	element *list.Element // Position in the in-memory store's recency list
 }
 
 // DuplicateDetectionConfig represents the configuration for duplicate detection
@@ -43,6 +50,15 @@ type DuplicateDetectionConfig struct {
 	Logger          *logger.Logger
 	RetentionPeriod time.Duration // How long to keep processed message records
 	MaxEntries      int           // Maximum number of entries to keep in memory
+
+	// Price change, in percent, above which a duplicate is reprocessed as a correction
+	PriceChangeThresholdPercent float64
+	// Whether a quantity change alone reprocesses a duplicate; nil defaults to true
+	QuantityChangeTriggers *bool
+
+	// Shares records between instances when Redis.Address is set. Falls back to
+	// the in-memory store if Redis is unreachable at startup.
+	Redis RedisDuplicateStoreConfig
 }
 
 // DuplicateResult represents the result of duplicate detection
@@ -61,14 +77,24 @@ func NewDuplicateDetectionService(config DuplicateDetectionConfig) *DuplicateDet
 	if config.MaxEntries == 0 {
 		config.MaxEntries = 10000 // Default 10k entries
 	}
+	if config.PriceChangeThresholdPercent <= 0 {
+		config.PriceChangeThresholdPercent = 0.1 // Default 0.1%
+	}
+	quantityChangeTriggers := true
+	if config.QuantityChangeTriggers != nil {
+		quantityChangeTriggers = *config.QuantityChangeTriggers
+	}
 
 	service := &DuplicateDetectionService{
-		logger:            config.Logger,
-		processedMessages: make(map[string]*ProcessedMessage),
-		retentionPeriod:   config.RetentionPeriod,
-		maxEntries:        config.MaxEntries,
-		stopCleanup:       make(chan struct{}),
-		cleanupDone:       make(chan struct{}),
+		logger:          config.Logger,
+		store:           newDuplicateStore(config),
+		retentionPeriod: config.RetentionPeriod,
+		maxEntries:      config.MaxEntries,
+		stopCleanup:     make(chan struct{}),
+		cleanupDone:     make(chan struct{}),
+
+		priceChangeThresholdPercent: config.PriceChangeThresholdPercent,
+		quantityChangeTriggers:      quantityChangeTriggers,
 	}
 
 	// Start background cleanup goroutine
@@ -77,13 +103,40 @@ func NewDuplicateDetectionService(config DuplicateDetectionConfig) *DuplicateDet
 	return service
 }
 
+// newDuplicateStore returns the Redis store when configured and reachable, otherwise the in-memory store
+func newDuplicateStore(config DuplicateDetectionConfig) DuplicateStore {
+	if config.Redis.Address == "" {
+		return NewMemoryDuplicateStore(config.MaxEntries, config.Logger)
+	}
+
+	store, err := NewRedisDuplicateStore(config.Redis, config.RetentionPeriod)
+	if err != nil {
+		config.Logger.Warn("Redis unreachable, falling back to in-memory duplicate detection",
+			zap.String("redis_address", config.Redis.Address),
+			zap.Error(err),
+		)
+		return NewMemoryDuplicateStore(config.MaxEntries, config.Logger)
+	}
+
+	config.Logger.Info("Using Redis for duplicate detection",
+		zap.String("redis_address", config.Redis.Address),
+	)
+	return store
+}
+
 // CheckDuplicate checks if a fill message is a duplicate and determines if it should be processed
 func (dds *DuplicateDetectionService) CheckDuplicate(ctx context.Context, fill *domain.Fill) *DuplicateResult {
 	messageKey := dds.generateMessageKey(fill)
 
-	dds.mutex.RLock()
-	previousMessage, exists := dds.processedMessages[messageKey]
-	dds.mutex.RUnlock()
+	previousMessage, exists, err := dds.store.Get(ctx, messageKey)
+	if err != nil {
+		// Without the previous record the message is processed; the update is idempotent
+		dds.logger.WithContext(ctx).Warn("Failed to look up processed message",
+			zap.Int64("fill_id", fill.ID),
+			zap.String("message_key", messageKey),
+			zap.Error(err),
+		)
+	}
 
 	result := &DuplicateResult{
 		IsDuplicate:     exists,
@@ -124,8 +177,8 @@ func (dds *DuplicateDetectionService) CheckDuplicate(ctx context.Context, fill *
 		result.Reason = "Message has significant changes, processing as correction"
 		dds.logger.WithContext(ctx).Info("Processing duplicate with significant changes",
 			zap.Int64("fill_id", fill.ID),
-			zap.Int64("previous_quantity", previousMessage.QuantityFilled),
-			zap.Int64("current_quantity", fill.QuantityFilled),
+			zap.Float64("previous_quantity", previousMessage.QuantityFilled),
+			zap.Float64("current_quantity", fill.QuantityFilled),
 			zap.Float64("previous_price", previousMessage.AveragePrice),
 			zap.Float64("current_price", fill.AveragePrice),
 		)
@@ -160,37 +213,78 @@ func (dds *DuplicateDetectionService) RecordProcessedMessage(ctx context.Context
 		AveragePrice:       fill.AveragePrice,
 	}
 
-	dds.mutex.Lock()
-	defer dds.mutex.Unlock()
-
-	// Check if we need to clean up to stay under max entries
-	if len(dds.processedMessages) >= dds.maxEntries {
-		dds.cleanupOldEntries()
+	if err := dds.store.Set(ctx, messageKey, processedMessage); err != nil {
+		dds.logger.WithContext(ctx).Warn("Failed to record processed message",
+			zap.Int64("fill_id", fill.ID),
+			zap.String("message_key", messageKey),
+			zap.Error(err),
+		)
+		return
 	}
 
-	dds.processedMessages[messageKey] = processedMessage
-
 	dds.logger.WithContext(ctx).Debug("Recorded processed message",
 		zap.Int64("fill_id", fill.ID),
 		zap.String("message_key", messageKey),
 		zap.Bool("success", success),
 		zap.Duration("processing_time", processingTime),
-		zap.Int("total_cached_messages", len(dds.processedMessages)),
 	)
 }
 
+// ProcessedMessageQuery selects a page of processed message records
+type ProcessedMessageQuery struct {
+	ExecutionServiceID int64 // 0 matches every execution
+	Offset             int
+	Limit              int // 0 returns every record from Offset
+}
+
+// ProcessedMessagePage is a page of processed message records, most recent first
+type ProcessedMessagePage struct {
+	Messages []ProcessedMessage `json:"messages"`
+	Total    int                `json:"total"` // Matching records across all pages
+}
+
+// ListProcessedMessages returns the processed message records matching the query,
+// most recently processed first
+func (dds *DuplicateDetectionService) ListProcessedMessages(ctx context.Context, query ProcessedMessageQuery) (*ProcessedMessagePage, error) {
+	return dds.store.List(ctx, query)
+}
+
+// ClearProcessedMessages removes every processed message record, so previously seen
+// fills are processed again, and returns how many records were removed
+func (dds *DuplicateDetectionService) ClearProcessedMessages(ctx context.Context) (int, error) {
+	cleared, err := dds.store.Clear(ctx)
+	if err != nil {
+		return cleared, fmt.Errorf("failed to clear processed messages: %w", err)
+	}
+
+	dds.logger.WithContext(ctx).Info("Cleared processed messages",
+		zap.Int("cleared_count", cleared),
+	)
+	return cleared, nil
+}
+
 // GetProcessedMessageStats returns statistics about processed messages
 func (dds *DuplicateDetectionService) GetProcessedMessageStats() map[string]interface{} {
-	dds.mutex.RLock()
-	defer dds.mutex.RUnlock()
+	memoryStore, ok := dds.store.(*MemoryDuplicateStore)
+	if !ok {
+		// Records in a shared store cannot be enumerated cheaply
+		return map[string]interface{}{
+			"store":            "redis",
+			"retention_period": dds.retentionPeriod.String(),
+
+			"price_change_threshold_percent": dds.priceChangeThresholdPercent,
+			"quantity_change_triggers":       dds.quantityChangeTriggers,
+		}
+	}
 
-	totalMessages := len(dds.processedMessages)
+	totalMessages := 0
 	successCount := 0
 	failureCount := 0
 	oldestMessage := time.Now()
 	newestMessage := time.Time{}
 
-	for _, msg := range dds.processedMessages {
+	memoryStore.forEach(func(msg *ProcessedMessage) {
+		totalMessages++
 		if msg.Success {
 			successCount++
 		} else {
@@ -203,15 +297,24 @@ func (dds *DuplicateDetectionService) GetProcessedMessageStats() map[string]inte
 		if msg.ProcessedAt.After(newestMessage) {
 			newestMessage = msg.ProcessedAt
 		}
+	})
+
+	successRate := 0.0
+	if totalMessages > 0 {
+		successRate = float64(successCount) / float64(totalMessages) * 100
 	}
 
 	stats := map[string]interface{}{
+		"store":            "memory",
 		"total_messages":   totalMessages,
 		"success_count":    successCount,
 		"failure_count":    failureCount,
-		"success_rate":     float64(successCount) / float64(totalMessages) * 100,
+		"success_rate":     successRate,
 		"retention_period": dds.retentionPeriod.String(),
 		"max_entries":      dds.maxEntries,
+
+		"price_change_threshold_percent": dds.priceChangeThresholdPercent,
+		"quantity_change_triggers":       dds.quantityChangeTriggers,
 	}
 
 	if totalMessages > 0 {
@@ -225,8 +328,16 @@ func (dds *DuplicateDetectionService) GetProcessedMessageStats() map[string]inte
 
 // Stop stops the duplicate detection service and cleanup goroutine
 func (dds *DuplicateDetectionService) Stop() {
-	close(dds.stopCleanup)
-	<-dds.cleanupDone
+	dds.stopOnce.Do(func() {
+		close(dds.stopCleanup)
+		<-dds.cleanupDone
+
+		if closer, ok := dds.store.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				dds.logger.Warn("Failed to close duplicate store", zap.Error(err))
+			}
+		}
+	})
 }
 
 // generateMessageKey generates a unique key for a fill message
@@ -241,17 +352,17 @@ func (dds *DuplicateDetectionService) hasSignificantChanges(current *domain.Fill
 	// Check for significant changes in key fields
 
 	// Quantity filled changed
-	if current.QuantityFilled != previous.QuantityFilled {
+	if dds.quantityChangeTriggers && current.QuantityFilled != previous.QuantityFilled {
 		return true
 	}
 
-	// Average price changed significantly (more than 0.1%)
+	// Average price changed by more than the configured threshold
 	priceDiff := current.AveragePrice - previous.AveragePrice
 	if priceDiff < 0 {
 		priceDiff = -priceDiff
 	}
 	priceChangePercent := (priceDiff / previous.AveragePrice) * 100
-	if priceChangePercent > 0.1 {
+	if priceChangePercent > dds.priceChangeThresholdPercent {
 		return true
 	}
 
@@ -280,69 +391,21 @@ func (dds *DuplicateDetectionService) cleanupLoop() {
 	}
 }
 
-// performCleanup removes old entries based on retention period
+// performCleanup removes old entries based on retention period.
+// Records in Redis expire on their own through their TTL.
 func (dds *DuplicateDetectionService) performCleanup() {
-	dds.mutex.Lock()
-	defer dds.mutex.Unlock()
-
-	cutoffTime := time.Now().Add(-dds.retentionPeriod)
-	initialCount := len(dds.processedMessages)
-
-	for key, message := range dds.processedMessages {
-		if message.ProcessedAt.Before(cutoffTime) {
-			delete(dds.processedMessages, key)
-		}
+	memoryStore, ok := dds.store.(*MemoryDuplicateStore)
+	if !ok {
+		return
 	}
 
-	finalCount := len(dds.processedMessages)
-	removedCount := initialCount - finalCount
+	removedCount := memoryStore.RemoveProcessedBefore(time.Now().Add(-dds.retentionPeriod))
 
 	if removedCount > 0 {
 		dds.logger.Info("Cleaned up old processed messages",
 			zap.Int("removed_count", removedCount),
-			zap.Int("remaining_count", finalCount),
+			zap.Int("remaining_count", memoryStore.Len()),
 			zap.Duration("retention_period", dds.retentionPeriod),
 		)
 	}
 }
-
-// cleanupOldEntries removes the oldest entries to stay under max entries limit
-func (dds *DuplicateDetectionService) cleanupOldEntries() {
-	if len(dds.processedMessages) < dds.maxEntries {
-		return
-	}
-
-	// Find the oldest entries to remove
-	type keyTime struct {
-		key  string
-		time time.Time
-	}
-
-	var entries []keyTime
-	for key, message := range dds.processedMessages {
-		entries = append(entries, keyTime{key: key, time: message.ProcessedAt})
-	}
-
-	// Sort by time (oldest first)
-	for i := 0; i < len(entries)-1; i++ {
-		for j := i + 1; j < len(entries); j++ {
-			if entries[i].time.After(entries[j].time) {
-				entries[i], entries[j] = entries[j], entries[i]
-			}
-		}
-	}
-
-	// Remove oldest entries to get under the limit
-	targetSize := dds.maxEntries * 9 / 10 // Remove 10% extra to avoid frequent cleanup
-	removeCount := len(entries) - targetSize
-
-	for i := 0; i < removeCount && i < len(entries); i++ {
-		delete(dds.processedMessages, entries[i].key)
-	}
-
-	dds.logger.Info("Cleaned up old entries due to size limit",
-		zap.Int("removed_count", removeCount),
-		zap.Int("remaining_count", len(dds.processedMessages)),
-		zap.Int("max_entries", dds.maxEntries),
-	)
-}
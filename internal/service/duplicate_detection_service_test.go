@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -32,7 +33,7 @@ func TestNewDuplicateDetectionService(t *testing.T) {
 	assert.Equal(t, appLogger, service.logger)
 	assert.Equal(t, time.Hour, service.retentionPeriod)
 	assert.Equal(t, 1000, service.maxEntries)
-	assert.NotNil(t, service.processedMessages)
+	assert.IsType(t, &MemoryDuplicateStore{}, service.store)
 	assert.NotNil(t, service.stopCleanup)
 	assert.NotNil(t, service.cleanupDone)
 
@@ -58,6 +59,8 @@ func TestNewDuplicateDetectionService_DefaultValues(t *testing.T) {
 
 	assert.Equal(t, 24*time.Hour, service.retentionPeriod) // Default 24 hours
 	assert.Equal(t, 10000, service.maxEntries)             // Default 10k entries
+	assert.Equal(t, 0.1, service.priceChangeThresholdPercent)
+	assert.True(t, service.quantityChangeTriggers)
 
 	// Clean up
 	service.Stop()
@@ -249,9 +252,8 @@ func TestDuplicateDetectionService_RecordProcessedMessage(t *testing.T) {
 
 	// Verify the message was recorded
 	messageKey := service.generateMessageKey(fill)
-	service.mutex.RLock()
-	processedMessage, exists := service.processedMessages[messageKey]
-	service.mutex.RUnlock()
+	processedMessage, exists, err := service.store.Get(ctx, messageKey)
+	require.NoError(t, err)
 
 	assert.True(t, exists)
 	assert.NotNil(t, processedMessage)
@@ -269,9 +271,8 @@ func TestDuplicateDetectionService_RecordProcessedMessage(t *testing.T) {
 	service.RecordProcessedMessage(ctx, fill, false, processingTime, errorMessage)
 
 	// Verify the message was updated
-	service.mutex.RLock()
-	processedMessage, exists = service.processedMessages[messageKey]
-	service.mutex.RUnlock()
+	processedMessage, exists, err = service.store.Get(ctx, messageKey)
+	require.NoError(t, err)
 
 	assert.True(t, exists)
 	assert.False(t, processedMessage.Success)
@@ -311,6 +312,7 @@ func TestDuplicateDetectionService_GetProcessedMessageStats(t *testing.T) {
 
 	stats := service.GetProcessedMessageStats()
 
+	assert.Equal(t, "memory", stats["store"])
 	assert.Equal(t, 5, stats["total_messages"])
 	assert.Equal(t, 3, stats["success_count"])   // 0, 2, 4
 	assert.Equal(t, 2, stats["failure_count"])   // 1, 3
@@ -348,6 +350,57 @@ func TestDuplicateDetectionService_GetProcessedMessageStats_Empty(t *testing.T)
 	assert.Nil(t, stats["time_span"])
 }
 
+func TestDuplicateDetectionService_ClearProcessedMessages(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	service := NewDuplicateDetectionService(DuplicateDetectionConfig{
+		Logger:          appLogger,
+		RetentionPeriod: time.Hour,
+		MaxEntries:      1000,
+	})
+	defer service.Stop()
+
+	ctx := context.Background()
+	fills := make([]*domain.Fill, 4)
+	for i := range fills {
+		fills[i] = &domain.Fill{
+			ID:                 int64(i),
+			ExecutionServiceID: 456,
+			QuantityFilled:     1000,
+			AveragePrice:       190.41,
+			Version:            1,
+		}
+		service.RecordProcessedMessage(ctx, fills[i], i != 0, time.Millisecond, "")
+	}
+	require.Equal(t, 4, service.GetProcessedMessageStats()["total_messages"])
+
+	cleared, err := service.ClearProcessedMessages(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, cleared)
+
+	stats := service.GetProcessedMessageStats()
+	assert.Equal(t, 0, stats["total_messages"])
+	assert.Equal(t, 0, stats["success_count"])
+	assert.Equal(t, 0, stats["failure_count"])
+	assert.Equal(t, 0.0, stats["success_rate"])
+	assert.Nil(t, stats["oldest_message"])
+
+	// Previously processed fills are no longer duplicates
+	assert.False(t, service.CheckDuplicate(ctx, fills[1]).IsDuplicate)
+
+	// The store keeps working after a clear
+	service.RecordProcessedMessage(ctx, fills[1], true, time.Millisecond, "")
+	assert.True(t, service.CheckDuplicate(ctx, fills[1]).IsDuplicate)
+	service.performCleanup()
+	assert.Equal(t, 1, service.GetProcessedMessageStats()["total_messages"])
+}
+
 func TestDuplicateDetectionService_generateMessageKey(t *testing.T) {
 	appLogger, err := logger.New(logger.Config{
 		Level:       "info",
@@ -453,6 +506,106 @@ func TestDuplicateDetectionService_hasSignificantChanges(t *testing.T) {
 	}
 }
 
+func TestDuplicateDetectionService_hasSignificantChanges_CustomThresholds(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	quantityChangeTriggers := false
+	service := NewDuplicateDetectionService(DuplicateDetectionConfig{
+		Logger:                      appLogger,
+		PriceChangeThresholdPercent: 0.5,
+		QuantityChangeTriggers:      &quantityChangeTriggers,
+	})
+	defer service.Stop()
+
+	previous := &ProcessedMessage{
+		QuantityFilled: 1000,
+		AveragePrice:   100.0,
+		Version:        1,
+	}
+
+	tests := []struct {
+		name     string
+		current  *domain.Fill
+		expected bool
+	}{
+		{
+			name: "price change below threshold",
+			current: &domain.Fill{
+				QuantityFilled: 1000,
+				AveragePrice:   100.3, // 0.3% change
+				Version:        1,
+			},
+			expected: false,
+		},
+		{
+			name: "price change above threshold",
+			current: &domain.Fill{
+				QuantityFilled: 1000,
+				AveragePrice:   100.6, // 0.6% change
+				Version:        1,
+			},
+			expected: true,
+		},
+		{
+			name: "quantity change ignored",
+			current: &domain.Fill{
+				QuantityFilled: 1500,
+				AveragePrice:   100.0,
+				Version:        1,
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := service.hasSignificantChanges(tt.current, previous)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestDuplicateDetectionService_CheckDuplicate_CustomThresholdExactDuplicate(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	service := NewDuplicateDetectionService(DuplicateDetectionConfig{
+		Logger:                      appLogger,
+		PriceChangeThresholdPercent: 0.5,
+	})
+	defer service.Stop()
+
+	fill := &domain.Fill{
+		ID:                 123,
+		ExecutionServiceID: 456,
+		QuantityFilled:     1000,
+		AveragePrice:       100.0,
+		Version:            1,
+	}
+	ctx := context.Background()
+	service.RecordProcessedMessage(ctx, fill, true, 100*time.Millisecond, "")
+
+	// A 0.3% reprice is within the 0.5% tolerance
+	repriced := *fill
+	repriced.AveragePrice = 100.3
+
+	result := service.CheckDuplicate(ctx, &repriced)
+	assert.True(t, result.IsDuplicate)
+	assert.False(t, result.ShouldProcess)
+	assert.Contains(t, result.Reason, "Exact duplicate")
+}
+
 func TestDuplicateDetectionService_MaxEntriesCleanup(t *testing.T) {
 	appLogger, err := logger.New(logger.Config{
 		Level:       "info",
@@ -488,15 +641,86 @@ func TestDuplicateDetectionService_MaxEntriesCleanup(t *testing.T) {
 	}
 
 	// Should have triggered cleanup to stay under limit
-	service.mutex.RLock()
-	messageCount := len(service.processedMessages)
-	service.mutex.RUnlock()
+	messageCount := service.store.(*MemoryDuplicateStore).Len()
 
 	// Should be around 90% of max entries (4-5 messages)
 	assert.LessOrEqual(t, messageCount, 5)
 	assert.GreaterOrEqual(t, messageCount, 4)
 }
 
+func TestDuplicateDetectionService_EvictsLeastRecentlyRecorded(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	service := NewDuplicateDetectionService(DuplicateDetectionConfig{
+		Logger:     appLogger,
+		MaxEntries: 3,
+	})
+	defer service.Stop()
+
+	ctx := context.Background()
+	fills := make([]*domain.Fill, 4)
+	for i := range fills {
+		fills[i] = &domain.Fill{ID: int64(i), ExecutionServiceID: 456, QuantityFilled: 1000, AveragePrice: 190.41, Version: 1}
+	}
+
+	service.RecordProcessedMessage(ctx, fills[0], true, time.Millisecond, "")
+	service.RecordProcessedMessage(ctx, fills[1], true, time.Millisecond, "")
+	service.RecordProcessedMessage(ctx, fills[2], true, time.Millisecond, "")
+
+	// Re-recording fill 0 makes fill 1 the least recently recorded
+	service.RecordProcessedMessage(ctx, fills[0], true, time.Millisecond, "")
+	service.RecordProcessedMessage(ctx, fills[3], true, time.Millisecond, "")
+
+	assert.True(t, service.CheckDuplicate(ctx, fills[0]).IsDuplicate)
+	assert.False(t, service.CheckDuplicate(ctx, fills[1]).IsDuplicate)
+	assert.True(t, service.CheckDuplicate(ctx, fills[2]).IsDuplicate)
+	assert.True(t, service.CheckDuplicate(ctx, fills[3]).IsDuplicate)
+	assert.Equal(t, 3, service.store.(*MemoryDuplicateStore).recency.Len())
+}
+
+func TestDuplicateDetectionService_PerformCleanup_RemovesExpiredEntries(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	service := NewDuplicateDetectionService(DuplicateDetectionConfig{
+		Logger:          appLogger,
+		RetentionPeriod: time.Hour,
+	})
+	defer service.Stop()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		fill := &domain.Fill{ID: int64(i), ExecutionServiceID: 456, QuantityFilled: 1000, AveragePrice: 190.41, Version: 1}
+		service.RecordProcessedMessage(ctx, fill, true, time.Millisecond, "")
+	}
+
+	// Age the two oldest entries past the retention period
+	store := service.store.(*MemoryDuplicateStore)
+	store.mutex.Lock()
+	store.processedMessages["fill_0_exec_456"].ProcessedAt = time.Now().Add(-2 * time.Hour)
+	store.processedMessages["fill_1_exec_456"].ProcessedAt = time.Now().Add(-2 * time.Hour)
+	store.mutex.Unlock()
+
+	service.performCleanup()
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	assert.Len(t, store.processedMessages, 1)
+	assert.Contains(t, store.processedMessages, "fill_2_exec_456")
+	assert.Equal(t, 1, store.recency.Len())
+}
+
 func TestDuplicateDetectionService_Stop(t *testing.T) {
 	appLogger, err := logger.New(logger.Config{
 		Level:       "info",
@@ -524,3 +748,97 @@ func TestDuplicateDetectionService_Stop(t *testing.T) {
 		t.Fatal("Stop() did not complete within 1 second")
 	}
 }
+
+func BenchmarkDuplicateDetectionService_RecordProcessedMessage_AtCapacity(b *testing.B) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "error",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(b, err)
+
+	for _, maxEntries := range []int{1000, 10000, 50000} {
+		b.Run(fmt.Sprintf("entries=%d", maxEntries), func(b *testing.B) {
+			service := NewDuplicateDetectionService(DuplicateDetectionConfig{
+				Logger:     appLogger,
+				MaxEntries: maxEntries,
+			})
+			defer service.Stop()
+
+			ctx := context.Background()
+			fill := &domain.Fill{ExecutionServiceID: 456, QuantityFilled: 1000, AveragePrice: 190.41, Version: 1}
+
+			// Fill the cache so every recorded message triggers eviction
+			for i := 0; i < maxEntries; i++ {
+				fill.ID = int64(i)
+				service.RecordProcessedMessage(ctx, fill, true, time.Millisecond, "")
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				fill.ID = int64(maxEntries + i)
+				service.RecordProcessedMessage(ctx, fill, true, time.Millisecond, "")
+			}
+		})
+	}
+}
+
+func TestDuplicateDetectionService_ListProcessedMessages(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	service := NewDuplicateDetectionService(DuplicateDetectionConfig{
+		Logger:          appLogger,
+		RetentionPeriod: time.Hour,
+		MaxEntries:      1000,
+	})
+	defer service.Stop()
+
+	ctx := context.Background()
+	for i := int64(1); i <= 5; i++ {
+		executionID := int64(456)
+		if i%2 == 0 {
+			executionID = 789
+		}
+		service.RecordProcessedMessage(ctx, &domain.Fill{ID: i, ExecutionServiceID: executionID, Version: 1}, true, time.Millisecond, "")
+	}
+
+	fillIDs := func(page *ProcessedMessagePage) []int64 {
+		ids := []int64{}
+		for _, message := range page.Messages {
+			ids = append(ids, message.FillID)
+		}
+		return ids
+	}
+
+	page, err := service.ListProcessedMessages(ctx, ProcessedMessageQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.Total)
+	assert.Len(t, page.Messages, 5)
+
+	page, err = service.ListProcessedMessages(ctx, ProcessedMessageQuery{ExecutionServiceID: 456})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	assert.ElementsMatch(t, []int64{1, 3, 5}, fillIDs(page))
+
+	// Pages cover the matching records without overlap
+	first, err := service.ListProcessedMessages(ctx, ProcessedMessageQuery{ExecutionServiceID: 456, Limit: 2})
+	require.NoError(t, err)
+	second, err := service.ListProcessedMessages(ctx, ProcessedMessageQuery{ExecutionServiceID: 456, Offset: 2, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, first.Messages, 2)
+	assert.Len(t, second.Messages, 1)
+	assert.Equal(t, 3, second.Total)
+	assert.ElementsMatch(t, []int64{1, 3, 5}, append(fillIDs(first), fillIDs(second)...))
+
+	page, err = service.ListProcessedMessages(ctx, ProcessedMessageQuery{Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Messages)
+	assert.Equal(t, 5, page.Total)
+}
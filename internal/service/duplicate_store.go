@@ -0,0 +1,183 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DuplicateStore persists processed message records for duplicate detection
+type DuplicateStore interface {
+	// Get returns the record for key and whether it exists
+	Get(ctx context.Context, key string) (*ProcessedMessage, bool, error)
+	// Set stores the record for key, replacing any previous record
+	Set(ctx context.Context, key string, message *ProcessedMessage) error
+	// Delete removes the record for key if present
+	Delete(ctx context.Context, key string) error
+	// List returns a page of the stored records matching the query, most recent first
+	List(ctx context.Context, query ProcessedMessageQuery) (*ProcessedMessagePage, error)
+	// Clear removes every stored record and returns how many were removed
+	Clear(ctx context.Context) (int, error)
+}
+
+// MemoryDuplicateStore keeps processed message records in memory for a single
+// instance, evicting the least recently recorded message once full
+type MemoryDuplicateStore struct {
+	logger            *logger.Logger
+	processedMessages map[string]*ProcessedMessage
+	recency           *list.List // Message keys, most recently recorded first
+	mutex             sync.RWMutex
+	maxEntries        int
+}
+
+// NewMemoryDuplicateStore creates an in-memory store holding at most maxEntries records
+func NewMemoryDuplicateStore(maxEntries int, appLogger *logger.Logger) *MemoryDuplicateStore {
+	return &MemoryDuplicateStore{
+		logger:            appLogger,
+		processedMessages: make(map[string]*ProcessedMessage),
+		recency:           list.New(),
+		maxEntries:        maxEntries,
+	}
+}
+
+// Get returns the record for key and whether it exists
+func (s *MemoryDuplicateStore) Get(ctx context.Context, key string) (*ProcessedMessage, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	message, exists := s.processedMessages[key]
+	return message, exists, nil
+}
+
+// Set stores the record for key and makes it the most recently recorded
+func (s *MemoryDuplicateStore) Set(ctx context.Context, key string, message *ProcessedMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if previous, exists := s.processedMessages[key]; exists {
+		message.element = previous.element
+		s.recency.MoveToFront(message.element)
+	} else {
+		// Evict the least recently recorded message to stay under max entries
+		if len(s.processedMessages) >= s.maxEntries {
+			s.evictOldest()
+		}
+		message.element = s.recency.PushFront(key)
+	}
+
+	s.processedMessages[key] = message
+	return nil
+}
+
+// Delete removes the record for key if present
+func (s *MemoryDuplicateStore) Delete(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if message, exists := s.processedMessages[key]; exists {
+		s.recency.Remove(message.element)
+		delete(s.processedMessages, key)
+	}
+	return nil
+}
+
+// List returns a page of the stored records matching the query, most recently
+// processed first
+func (s *MemoryDuplicateStore) List(ctx context.Context, query ProcessedMessageQuery) (*ProcessedMessagePage, error) {
+	s.mutex.RLock()
+	matching := make([]ProcessedMessage, 0, len(s.processedMessages))
+	for _, message := range s.processedMessages {
+		if query.ExecutionServiceID == 0 || message.ExecutionServiceID == query.ExecutionServiceID {
+			matching = append(matching, *message)
+		}
+	}
+	s.mutex.RUnlock()
+
+	sort.Slice(matching, func(i, j int) bool {
+		if !matching[i].ProcessedAt.Equal(matching[j].ProcessedAt) {
+			return matching[i].ProcessedAt.After(matching[j].ProcessedAt)
+		}
+		return matching[i].FillID > matching[j].FillID
+	})
+
+	page := &ProcessedMessagePage{Messages: []ProcessedMessage{}, Total: len(matching)}
+	if query.Offset >= len(matching) {
+		return page, nil
+	}
+
+	end := len(matching)
+	if query.Limit > 0 && query.Offset+query.Limit < end {
+		end = query.Offset + query.Limit
+	}
+	page.Messages = matching[query.Offset:end]
+
+	return page, nil
+}
+
+// Clear removes every stored record and returns how many were removed
+func (s *MemoryDuplicateStore) Clear(ctx context.Context) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cleared := len(s.processedMessages)
+	s.processedMessages = make(map[string]*ProcessedMessage)
+	s.recency.Init()
+	return cleared, nil
+}
+
+// Len returns the number of stored records
+func (s *MemoryDuplicateStore) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.processedMessages)
+}
+
+// RemoveProcessedBefore removes records processed before cutoff and returns how many were removed
+func (s *MemoryDuplicateStore) RemoveProcessedBefore(cutoff time.Time) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The recency list is ordered by ProcessedAt, so expired entries are at the back
+	removed := 0
+	for element := s.recency.Back(); element != nil; element = s.recency.Back() {
+		if !s.processedMessages[element.Value.(string)].ProcessedAt.Before(cutoff) {
+			break
+		}
+		s.evictOldest()
+		removed++
+	}
+	return removed
+}
+
+// forEach calls fn for every stored record while holding the read lock
+func (s *MemoryDuplicateStore) forEach(fn func(message *ProcessedMessage)) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, message := range s.processedMessages {
+		fn(message)
+	}
+}
+
+// evictOldest removes the least recently recorded message. The caller must hold the write lock.
+func (s *MemoryDuplicateStore) evictOldest() {
+	element := s.recency.Back()
+	if element == nil {
+		return
+	}
+
+	messageKey := s.recency.Remove(element).(string)
+	delete(s.processedMessages, messageKey)
+
+	s.logger.Debug("Evicted processed message",
+		zap.String("message_key", messageKey),
+		zap.Int("remaining_count", len(s.processedMessages)),
+		zap.Int("max_entries", s.maxEntries),
+	)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemoryDuplicateStore(t *testing.T, maxEntries int) *MemoryDuplicateStore {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	return NewMemoryDuplicateStore(maxEntries, appLogger)
+}
+
+func TestMemoryDuplicateStore_GetSetDelete(t *testing.T) {
+	store := newTestMemoryDuplicateStore(t, 10)
+	ctx := context.Background()
+
+	_, exists, err := store.Get(ctx, "fill_1_exec_2")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, store.Set(ctx, "fill_1_exec_2", &ProcessedMessage{FillID: 1, ExecutionServiceID: 2, Success: true}))
+
+	message, exists, err := store.Get(ctx, "fill_1_exec_2")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, int64(1), message.FillID)
+	assert.Equal(t, 1, store.Len())
+
+	require.NoError(t, store.Delete(ctx, "fill_1_exec_2"))
+	_, exists, err = store.Get(ctx, "fill_1_exec_2")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Equal(t, 0, store.Len())
+	assert.Equal(t, 0, store.recency.Len())
+
+	// Deleting a missing key is not an error
+	assert.NoError(t, store.Delete(ctx, "fill_1_exec_2"))
+}
+
+func TestMemoryDuplicateStore_RemoveProcessedBefore(t *testing.T) {
+	store := newTestMemoryDuplicateStore(t, 10)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.Set(ctx, "old", &ProcessedMessage{ProcessedAt: now.Add(-2 * time.Hour)}))
+	require.NoError(t, store.Set(ctx, "new", &ProcessedMessage{ProcessedAt: now}))
+
+	assert.Equal(t, 1, store.RemoveProcessedBefore(now.Add(-time.Hour)))
+	assert.Equal(t, 1, store.Len())
+
+	_, exists, err := store.Get(ctx, "new")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
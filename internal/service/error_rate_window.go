@@ -0,0 +1,71 @@
+package service
+
+import "sync"
+
+// defaultErrorRateWindowSize is used when no window size is configured
+const defaultErrorRateWindowSize = 100
+
+// errorRateWindow tracks the failure rate over the most recent message outcomes
+type errorRateWindow struct {
+	mutex    sync.Mutex
+	outcomes []bool // true = failure
+	pos      int
+	count    int
+	failures int
+}
+
+func newErrorRateWindow(size int) *errorRateWindow {
+	if size <= 0 {
+		size = defaultErrorRateWindowSize
+	}
+	return &errorRateWindow{outcomes: make([]bool, size)}
+}
+
+// record adds an outcome, evicting the oldest once the window is full, and returns the updated rate
+func (w *errorRateWindow) record(failed bool) float64 {
+	if w == nil {
+		return 0
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.count == len(w.outcomes) {
+		if w.outcomes[w.pos] {
+			w.failures--
+		}
+	} else {
+		w.count++
+	}
+
+	w.outcomes[w.pos] = failed
+	if failed {
+		w.failures++
+	}
+	w.pos = (w.pos + 1) % len(w.outcomes)
+
+	return float64(w.failures) / float64(w.count)
+}
+
+// rate returns the failure rate over the outcomes in the window, or 0 when empty
+func (w *errorRateWindow) rate() float64 {
+	if w == nil {
+		return 0
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.count == 0 {
+		return 0
+	}
+	return float64(w.failures) / float64(w.count)
+}
+
+// size returns the number of outcomes the window holds when full
+func (w *errorRateWindow) size() int {
+	if w == nil {
+		return 0
+	}
+	return len(w.outcomes)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+)
+
+// ExecutionCacheStats represents Execution Service response cache statistics
+type ExecutionCacheStats struct {
+	Enabled bool   `json:"enabled"`
+	TTL     string `json:"ttl,omitempty"`
+	Size    int    `json:"size"`
+	Hits    int64  `json:"hits"`
+	Misses  int64  `json:"misses"`
+}
+
+// executionCacheState is the cache's view of one execution
+type executionCacheState struct {
+	response   *domain.ExecutionResponse // nil when nothing is cached
+	expiresAt  time.Time
+	generation uint64 // Bumped on invalidation
+	inFlight   int    // Fetches started on a miss and not yet finished
+}
+
+// executionFetch is a fetch started on a cache miss; its response is only cached if
+// the execution was not invalidated while it was in flight
+type executionFetch struct {
+	executionID int64
+	generation  uint64
+}
+
+// executionCache holds GetExecution responses for a short TTL, so a burst of fills for
+// the same execution reads it once. Updates invalidate the execution, since they change
+// its version, and stop fetches already in flight from caching the old version.
+type executionCache struct {
+	mutex     sync.Mutex
+	ttl       time.Duration
+	clock     utils.Clock
+	states    map[int64]*executionCacheState
+	lastSweep time.Time
+
+	hits   int64
+	misses int64
+}
+
+func newExecutionCache(ttl time.Duration, clock utils.Clock) *executionCache {
+	if clock == nil {
+		clock = utils.RealClock{}
+	}
+	return &executionCache{
+		ttl:       ttl,
+		clock:     clock,
+		states:    make(map[int64]*executionCacheState),
+		lastSweep: clock.Now(),
+	}
+}
+
+// get returns a copy of the cached response for an execution, or on a miss the fetch
+// the caller must finish once the execution has been read
+func (c *executionCache) get(executionID int64) (*domain.ExecutionResponse, *executionFetch) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state, ok := c.states[executionID]
+	if !ok {
+		state = &executionCacheState{}
+		c.states[executionID] = state
+	}
+
+	if state.response != nil && c.clock.Now().Before(state.expiresAt) {
+		c.hits++
+		response := *state.response
+		return &response, nil
+	}
+
+	c.misses++
+	state.response = nil
+	state.inFlight++
+	return nil, &executionFetch{executionID: executionID, generation: state.generation}
+}
+
+// finish completes a fetch, caching its response unless the fetch failed (nil response)
+// or the execution was invalidated since it started
+func (c *executionCache) finish(fetch *executionFetch, response *domain.ExecutionResponse) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state := c.states[fetch.executionID]
+	state.inFlight--
+
+	now := c.clock.Now()
+	if response != nil && state.generation == fetch.generation {
+		cached := *response
+		state.response = &cached
+		state.expiresAt = now.Add(c.ttl)
+	}
+
+	// Drop expired executions at most once per TTL so executions read only once do not
+	// pile up; executions with fetches in flight keep their generation
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for id, state := range c.states {
+			if state.inFlight == 0 && (state.response == nil || !now.Before(state.expiresAt)) {
+				delete(c.states, id)
+			}
+		}
+		c.lastSweep = now
+	}
+}
+
+// invalidate drops the cached response for an execution and stops fetches in flight
+// from caching theirs
+func (c *executionCache) invalidate(executionID int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state, ok := c.states[executionID]
+	if !ok {
+		return // Nothing cached and nothing in flight
+	}
+
+	state.response = nil
+	state.generation++
+}
+
+// stats returns cache statistics
+func (c *executionCache) stats() ExecutionCacheStats {
+	if c == nil {
+		return ExecutionCacheStats{}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	size := 0
+	for _, state := range c.states {
+		if state.response != nil {
+			size++
+		}
+	}
+
+	return ExecutionCacheStats{
+		Enabled: true,
+		TTL:     c.ttl.String(),
+		Size:    size,
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+}
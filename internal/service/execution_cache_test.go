@@ -0,0 +1,92 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionCache_HitsUntilExpired(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	cache := newExecutionCache(500*time.Millisecond, clock)
+
+	cached, fetch := cache.get(1)
+	assert.Nil(t, cached)
+	require.NotNil(t, fetch)
+	cache.finish(fetch, &domain.ExecutionResponse{ID: 1, Version: 3})
+
+	cached, fetch = cache.get(1)
+	require.NotNil(t, cached)
+	assert.Nil(t, fetch)
+	assert.Equal(t, 3, cached.Version)
+
+	// Callers get a copy, so they cannot change the cached response
+	cached.Version = 99
+	cached, _ = cache.get(1)
+	assert.Equal(t, 3, cached.Version)
+
+	clock.Advance(500 * time.Millisecond)
+	cached, fetch = cache.get(1)
+	assert.Nil(t, cached)
+	cache.finish(fetch, nil)
+
+	assert.Equal(t, ExecutionCacheStats{Enabled: true, TTL: "500ms", Size: 0, Hits: 2, Misses: 2}, cache.stats())
+}
+
+func TestExecutionCache_InvalidateDropsInFlightFetches(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	cache := newExecutionCache(time.Minute, clock)
+
+	cache.finish(mustMiss(t, cache, 1), &domain.ExecutionResponse{ID: 1, Version: 3})
+	cache.invalidate(1)
+	_, fetch := cache.get(1)
+	require.NotNil(t, fetch, "an invalidated execution is fetched again")
+
+	// The execution is updated while the fetch is in flight, so the version it read
+	// may already be stale and is not cached
+	cache.invalidate(1)
+	cache.finish(fetch, &domain.ExecutionResponse{ID: 1, Version: 3})
+
+	cache.finish(mustMiss(t, cache, 1), &domain.ExecutionResponse{ID: 1, Version: 4})
+	cached, _ := cache.get(1)
+	require.NotNil(t, cached)
+	assert.Equal(t, 4, cached.Version)
+}
+
+func TestExecutionCache_SweepsExpiredExecutions(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	cache := newExecutionCache(time.Second, clock)
+
+	cache.finish(mustMiss(t, cache, 1), &domain.ExecutionResponse{ID: 1})
+	inFlight := mustMiss(t, cache, 2)
+	cache.invalidate(2)
+
+	clock.Advance(2 * time.Second)
+	cache.finish(mustMiss(t, cache, 3), &domain.ExecutionResponse{ID: 3})
+
+	// Execution 1 expired; execution 2 keeps its state while its fetch is in flight
+	assert.NotContains(t, cache.states, int64(1))
+	assert.Contains(t, cache.states, int64(2))
+	assert.Contains(t, cache.states, int64(3))
+
+	cache.finish(inFlight, &domain.ExecutionResponse{ID: 2})
+	cached, _ := cache.get(2)
+	assert.Nil(t, cached, "the fetch started before the invalidation")
+}
+
+func TestExecutionCache_DisabledStats(t *testing.T) {
+	var cache *executionCache
+	assert.Equal(t, ExecutionCacheStats{}, cache.stats())
+}
+
+// mustMiss starts a fetch for an execution that is not cached
+func mustMiss(t *testing.T, cache *executionCache, executionID int64) *executionFetch {
+	cached, fetch := cache.get(executionID)
+	require.Nil(t, cached)
+	require.NotNil(t, fetch)
+	return fetch
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kasbench/globeco-confirmation-service/internal/config"
@@ -22,12 +25,39 @@ import (
 type ExecutionServiceClient struct {
 	config            config.ExecutionServiceConfig
 	httpClient        *http.Client
+	transport         *http.Transport // Base transport, kept for connection pool stats
 	logger            *logger.Logger
 	metrics           *metrics.Metrics
 	resilienceManager *utils.ResilienceManager
 	tracingProvider   *utils.TracingProvider
+
+	// Separate circuit breakers so failing updates do not block reads
+	getCircuitBreaker    *utils.CircuitBreaker
+	updateCircuitBreaker *utils.CircuitBreaker
+
+	// Optional adaptive limit on concurrent requests
+	concurrency *utils.AdaptiveConcurrencyLimiter
+
+	// Optional pacing of requests; nil when disabled
+	rateLimiter *utils.RateLimiter
+
+	// Optional short-lived cache of GetExecution responses; nil when disabled
+	cache *executionCache
+
+	// Logs request and response bodies when debug payload logging is enabled
+	payloadLogger payloadLogger
 }
 
+// Circuit breaker names for the Execution Service operations
+const (
+	executionGetCircuitBreaker    = "execution-service-get"
+	executionUpdateCircuitBreaker = "execution-service-update"
+
+	// Operation labels for Execution Service latency metrics
+	executionGetOperation    = "get_execution"
+	executionUpdateOperation = "update_execution"
+)
+
 // ExecutionServiceClientConfig represents the configuration for the Execution Service client
 type ExecutionServiceClientConfig struct {
 	ExecutionService  config.ExecutionServiceConfig
@@ -35,39 +65,176 @@ type ExecutionServiceClientConfig struct {
 	Metrics           *metrics.Metrics
 	ResilienceManager *utils.ResilienceManager
 	TracingProvider   *utils.TracingProvider
+	Concurrency       *utils.AdaptiveConcurrencyLimiter // Optional; nil leaves requests unbounded
+	Clock             utils.Clock                       // Tells the time for the response cache; defaults to the system clock
+	TokenProvider     TokenProvider                     // Optional; requests carry its bearer token when set
+	Logging           config.LoggingConfig
+	TenantID          string // Optional; scopes the client's circuit breakers to the tenant
 }
 
+// Connection pool defaults for settings left at zero
+const (
+	defaultExecutionMaxIdleConns        = 10
+	defaultExecutionMaxIdleConnsPerHost = 10
+	defaultExecutionIdleConnTimeout     = 30 * time.Second
+
+	// Health check defaults for settings left unset
+	defaultExecutionHealthTimeout    = 5 * time.Second
+	defaultExecutionHealthPath       = "/actuator/health/liveness"
+	defaultExecutionHealthyStatusMin = 200
+	defaultExecutionHealthyStatusMax = 299
+)
+
 // NewExecutionServiceClient creates a new Execution Service client
 func NewExecutionServiceClient(config ExecutionServiceClientConfig) *ExecutionServiceClient {
-	// Create base transport
-	baseTransport := &http.Transport{
-		MaxIdleConns:        10,
-		MaxIdleConnsPerHost: 10,
-		IdleConnTimeout:     30 * time.Second,
-		DisableCompression:  false,
-	}
+	// Create base transport and wrap it with OpenTelemetry instrumentation
+	baseTransport := newExecutionTransport(config.ExecutionService)
+	var transport http.RoundTripper = otelhttp.NewTransport(baseTransport)
 
-	// Wrap transport with OpenTelemetry instrumentation
-	instrumentedTransport := otelhttp.NewTransport(baseTransport)
+	// Authenticate every request, including health checks, when a token provider is set
+	if config.TokenProvider != nil {
+		transport = &bearerTokenTransport{provider: config.TokenProvider, next: transport}
+	}
 
-	// Create HTTP client with timeout and instrumented transport
+	// Create HTTP client with instrumented transport. Timeouts are applied per
+	// request so GET and PUT calls can use different limits.
 	httpClient := &http.Client{
-		Timeout:   config.ExecutionService.Timeout,
-		Transport: instrumentedTransport,
+		Transport: transport,
+	}
+
+	var cache *executionCache
+	if config.ExecutionService.CacheTTL > 0 {
+		cache = newExecutionCache(config.ExecutionService.CacheTTL, config.Clock)
 	}
 
 	return &ExecutionServiceClient{
-		config:            config.ExecutionService,
-		httpClient:        httpClient,
-		logger:            config.Logger,
-		metrics:           config.Metrics,
-		resilienceManager: config.ResilienceManager,
-		tracingProvider:   config.TracingProvider,
+		config:               config.ExecutionService,
+		httpClient:           httpClient,
+		transport:            baseTransport,
+		logger:               config.Logger,
+		metrics:              config.Metrics,
+		resilienceManager:    config.ResilienceManager,
+		tracingProvider:      config.TracingProvider,
+		getCircuitBreaker:    config.ResilienceManager.GetCircuitBreaker(tenantCircuitBreakerName(executionGetCircuitBreaker, config.TenantID)),
+		updateCircuitBreaker: config.ResilienceManager.GetCircuitBreaker(tenantCircuitBreakerName(executionUpdateCircuitBreaker, config.TenantID)),
+		concurrency:          config.Concurrency,
+		rateLimiter: utils.NewRateLimiter(utils.RateLimiterConfig{
+			Rate:  config.ExecutionService.RateLimit,
+			Burst: config.ExecutionService.RateLimitBurst,
+		}),
+		cache:         cache,
+		payloadLogger: newPayloadLogger(config.Logger, config.Logging),
+	}
+}
+
+// tenantCircuitBreakerName scopes a circuit breaker name to a tenant, so one tenant's
+// failing service does not open the breaker for the others
+func tenantCircuitBreakerName(name, tenantID string) string {
+	if tenantID == "" {
+		return name
+	}
+	return name + ":" + tenantID
+}
+
+// newExecutionTransport creates the base transport with the configured connection pool
+func newExecutionTransport(executionConfig config.ExecutionServiceConfig) *http.Transport {
+	transport := &http.Transport{
+		MaxIdleConns:        executionConfig.MaxIdleConns,
+		MaxIdleConnsPerHost: executionConfig.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     executionConfig.MaxConnsPerHost,
+		IdleConnTimeout:     executionConfig.IdleConnTimeout,
+		DisableCompression:  executionConfig.DisableCompression,
 	}
+
+	if transport.MaxIdleConns <= 0 {
+		transport.MaxIdleConns = defaultExecutionMaxIdleConns
+	}
+	if transport.MaxIdleConnsPerHost <= 0 {
+		transport.MaxIdleConnsPerHost = defaultExecutionMaxIdleConnsPerHost
+	}
+	if transport.MaxConnsPerHost < 0 {
+		transport.MaxConnsPerHost = 0
+	}
+	if transport.IdleConnTimeout <= 0 {
+		transport.IdleConnTimeout = defaultExecutionIdleConnTimeout
+	}
+
+	return transport
 }
 
-// GetExecution retrieves an execution by ID from the Execution Service
+// doRequest sends the request and records its latency under the operation and response status
+func (esc *ExecutionServiceClient) doRequest(req *http.Request, operation string) (*http.Response, error) {
+	start := time.Now()
+	resp, err := esc.httpClient.Do(req)
+
+	statusCode := "error"
+	if err == nil {
+		statusCode = strconv.Itoa(resp.StatusCode)
+	}
+	esc.metrics.RecordExecutionAPILatency(operation, statusCode, time.Since(start))
+
+	return resp, err
+}
+
+// withRateLimit makes each request attempt wait for the rate limit, recording the wait
+// under the operation. Waiting happens before a concurrency slot is taken. A wait that
+// runs out is not sent, so it does not count against the circuit breaker.
+func (esc *ExecutionServiceClient) withRateLimit(operation string, fn func(ctx context.Context) error) func(ctx context.Context) error {
+	if esc.rateLimiter == nil {
+		return fn
+	}
+
+	return func(ctx context.Context) error {
+		wait, err := esc.rateLimiter.Wait(ctx)
+		esc.metrics.RecordExecutionRateLimitWait(operation, wait)
+		if err != nil {
+			return domain.NewTimeoutError("execution-service rate limit", err).WithNotSent()
+		}
+
+		return fn(ctx)
+	}
+}
+
+// withConcurrencyLimit runs each request attempt under the adaptive concurrency limit.
+// Slow attempts and retryable failures (timeouts, 429s, 5xx) lower the limit. Like a
+// rate limit wait, a wait for a slot that runs out is not sent.
+func (esc *ExecutionServiceClient) withConcurrencyLimit(fn func(ctx context.Context) error) func(ctx context.Context) error {
+	if esc.concurrency == nil {
+		return fn
+	}
+
+	return func(ctx context.Context) error {
+		if err := esc.concurrency.Acquire(ctx); err != nil {
+			return domain.NewTimeoutError("execution-service concurrency limit", err).WithNotSent()
+		}
+
+		start := time.Now()
+		err := fn(ctx)
+
+		var domainErr *domain.DomainError
+		failed := errors.As(err, &domainErr) && domainErr.IsRetryable()
+		esc.concurrency.Release(time.Since(start), failed)
+		esc.metrics.SetExecutionServiceConcurrencyLimit(esc.concurrency.Limit())
+
+		return err
+	}
+}
+
+// GetExecution retrieves an execution by ID from the Execution Service, or from the
+// response cache when it is enabled and holds a recent response
 func (esc *ExecutionServiceClient) GetExecution(ctx context.Context, executionID int64) (*domain.ExecutionResponse, error) {
+	var fetch *executionFetch
+	if esc.cache != nil {
+		var cached *domain.ExecutionResponse
+		if cached, fetch = esc.cache.get(executionID); cached != nil {
+			esc.logger.WithContext(ctx).Debug("Using cached execution",
+				zap.Int64("execution_id", executionID),
+				zap.Int("version", cached.Version),
+			)
+			return cached, nil
+		}
+	}
+
 	url := fmt.Sprintf("%s/api/v1/execution/%d", esc.config.BaseURL, executionID)
 
 	correlationID := logger.GetCorrelationID(ctx)
@@ -78,7 +245,7 @@ func (esc *ExecutionServiceClient) GetExecution(ctx context.Context, executionID
 
 	var response *domain.ExecutionResponse
 
-	err := esc.resilienceManager.ExecuteAPICall(ctx, "GET", url, func(ctx context.Context) error {
+	err := esc.resilienceManager.ExecuteAPICallWithCircuitBreaker(ctx, esc.getCircuitBreaker, "GET", url, esc.withRateLimit(executionGetOperation, esc.withConcurrencyLimit(func(ctx context.Context) error {
 		// Start tracing span
 		var span interface{}
 		if esc.tracingProvider != nil {
@@ -90,6 +257,10 @@ func (esc *ExecutionServiceClient) GetExecution(ctx context.Context, executionID
 			}()
 		}
 
+		// Bound this attempt by the GET timeout
+		ctx, cancel := withRequestTimeout(ctx, esc.getTimeout())
+		defer cancel()
+
 		// Create HTTP request
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
@@ -103,7 +274,7 @@ func (esc *ExecutionServiceClient) GetExecution(ctx context.Context, executionID
 		req.Header.Set("X-Correlation-ID", correlationID)
 
 		// Make the request
-		resp, err := esc.httpClient.Do(req)
+		resp, err := esc.doRequest(req, executionGetOperation)
 		if err != nil {
 			return domain.NewExternalError("execution-service", "request failed", err, true).
 				WithCorrelationID(correlationID)
@@ -119,13 +290,11 @@ func (esc *ExecutionServiceClient) GetExecution(ctx context.Context, executionID
 
 		// Check status code
 		if resp.StatusCode != http.StatusOK {
-			return esc.handleErrorResponse(resp.StatusCode, body, correlationID)
+			return esc.handleErrorResponse(resp.StatusCode, resp.Header, body, correlationID)
 		}
 
-		// Log raw response for debugging
-		esc.logger.WithContext(ctx).Debug("Raw execution service response",
+		esc.payloadLogger.log(ctx, "Raw execution service response", "response_body", body,
 			zap.Int64("requested_execution_id", executionID),
-			zap.String("response_body", string(body)),
 		)
 
 		// Parse response
@@ -146,7 +315,15 @@ func (esc *ExecutionServiceClient) GetExecution(ctx context.Context, executionID
 
 		response = &execResp
 		return nil
-	})
+	})))
+
+	if fetch != nil {
+		if err != nil {
+			esc.cache.finish(fetch, nil)
+		} else {
+			esc.cache.finish(fetch, response)
+		}
+	}
 
 	if err != nil {
 		esc.logger.WithContext(ctx).Error("Failed to get execution",
@@ -174,14 +351,16 @@ func (esc *ExecutionServiceClient) UpdateExecution(ctx context.Context, executio
 	esc.logger.WithContext(ctx).Debug("Updating execution in Execution Service",
 		zap.Int64("execution_id", executionID),
 		zap.String("url", url),
-		zap.Int64("quantity_filled", updateReq.QuantityFilled),
+		zap.Float64("quantity_filled", updateReq.QuantityFilled),
 		zap.Float64("average_price", updateReq.AveragePrice),
 		zap.Int("version", updateReq.Version),
 	)
 
 	var response *domain.ExecutionUpdateResponse
 
-	err := esc.resilienceManager.ExecuteAPICall(ctx, "PUT", url, func(ctx context.Context) error {
+	// An update whose response was lost may have been applied, so it is only retried
+	// when the request provably never reached the Execution Service or was turned away
+	err := esc.resilienceManager.ExecuteAPICallWithOptions(ctx, esc.updateCircuitBreaker, "PUT", url, utils.RetryOptions{RetrySafe: false}, esc.withRateLimit(executionUpdateOperation, esc.withConcurrencyLimit(func(ctx context.Context) error {
 		// Start tracing span
 		var span interface{}
 		if esc.tracingProvider != nil {
@@ -200,6 +379,14 @@ func (esc *ExecutionServiceClient) UpdateExecution(ctx context.Context, executio
 				WithCorrelationID(correlationID)
 		}
 
+		esc.payloadLogger.log(ctx, "Raw execution service request", "request_body", requestBody,
+			zap.Int64("execution_id", executionID),
+		)
+
+		// Bound this attempt by the update timeout
+		ctx, cancel := withRequestTimeout(ctx, esc.updateTimeout())
+		defer cancel()
+
 		// Create HTTP request
 		req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(requestBody))
 		if err != nil {
@@ -213,7 +400,7 @@ func (esc *ExecutionServiceClient) UpdateExecution(ctx context.Context, executio
 		req.Header.Set("X-Correlation-ID", correlationID)
 
 		// Make the request
-		resp, err := esc.httpClient.Do(req)
+		resp, err := esc.doRequest(req, executionUpdateOperation)
 		if err != nil {
 			return domain.NewExternalError("execution-service", "request failed", err, true).
 				WithCorrelationID(correlationID)
@@ -229,9 +416,13 @@ func (esc *ExecutionServiceClient) UpdateExecution(ctx context.Context, executio
 
 		// Check status code
 		if resp.StatusCode != http.StatusOK {
-			return esc.handleErrorResponse(resp.StatusCode, body, correlationID)
+			return esc.handleErrorResponse(resp.StatusCode, resp.Header, body, correlationID)
 		}
 
+		esc.payloadLogger.log(ctx, "Raw execution service response", "response_body", body,
+			zap.Int64("execution_id", executionID),
+		)
+
 		// Parse response
 		var updateResp domain.ExecutionUpdateResponse
 		if err := json.Unmarshal(body, &updateResp); err != nil {
@@ -239,9 +430,16 @@ func (esc *ExecutionServiceClient) UpdateExecution(ctx context.Context, executio
 				WithCorrelationID(correlationID)
 		}
 
+		updateResp.InferChanged(updateReq.Version)
 		response = &updateResp
 		return nil
-	})
+	})))
+
+	// A successful update changes the version, and a failed one may mean the cached
+	// version is stale (a conflict) or leave it unknown, so drop it either way
+	if esc.cache != nil {
+		esc.cache.invalidate(executionID)
+	}
 
 	if err != nil {
 		esc.logger.WithContext(ctx).Error("Failed to update execution",
@@ -253,22 +451,37 @@ func (esc *ExecutionServiceClient) UpdateExecution(ctx context.Context, executio
 
 	esc.logger.WithContext(ctx).Info("Successfully updated execution",
 		zap.Int64("execution_id", executionID),
-		zap.Int64("quantity_filled", updateReq.QuantityFilled),
+		zap.Float64("quantity_filled", updateReq.QuantityFilled),
 		zap.Float64("average_price", updateReq.AveragePrice),
 		zap.Int("new_version", response.Version),
+		zap.Bool("changed", response.Changed),
 	)
 
+	if !response.Changed {
+		esc.logger.WithContext(ctx).Warn("Execution update was a no-op, version unchanged",
+			zap.Int64("execution_id", executionID),
+			zap.Int("version", response.Version),
+		)
+		if esc.metrics != nil {
+			esc.metrics.RecordExecutionUpdateNoOp()
+		}
+	}
+
 	return response, nil
 }
 
 // IsHealthy checks if the Execution Service is healthy
 func (esc *ExecutionServiceClient) IsHealthy(ctx context.Context) bool {
-	// Create a health check context with shorter timeout
-	healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	// Create a health check context with its own, usually shorter, timeout
+	healthCtx, cancel := context.WithTimeout(ctx, esc.healthTimeout())
 	defer cancel()
 
-	// Use the Spring Boot Actuator health endpoint
-	url := fmt.Sprintf("%s/actuator/health/liveness", esc.config.BaseURL)
+	// Defaults to the Spring Boot Actuator liveness endpoint
+	healthPath := esc.config.HealthPath
+	if healthPath == "" {
+		healthPath = defaultExecutionHealthPath
+	}
+	url := esc.config.BaseURL + healthPath
 
 	req, err := http.NewRequestWithContext(healthCtx, "GET", url, nil)
 	if err != nil {
@@ -286,8 +499,9 @@ func (esc *ExecutionServiceClient) IsHealthy(ctx context.Context) bool {
 	}
 	defer resp.Body.Close()
 
-	// Consider 200-299 as healthy (even if empty list)
-	healthy := resp.StatusCode >= 200 && resp.StatusCode < 300
+	// Consider the configured status range (200-299 by default) as healthy
+	minStatus, maxStatus := esc.healthyStatusRange()
+	healthy := resp.StatusCode >= minStatus && resp.StatusCode <= maxStatus
 
 	if !healthy {
 		esc.logger.WithContext(ctx).Warn("Execution Service health check returned unhealthy status",
@@ -305,19 +519,83 @@ func (esc *ExecutionServiceClient) IsHealthy(ctx context.Context) bool {
 // GetStats returns client statistics
 func (esc *ExecutionServiceClient) GetStats() map[string]interface{} {
 	return map[string]interface{}{
-		"base_url":      esc.config.BaseURL,
-		"timeout":       esc.config.Timeout.String(),
-		"max_retries":   esc.config.MaxRetries,
-		"retry_backoff": esc.config.RetryBackoff.String(),
+		"base_url":       esc.config.BaseURL,
+		"timeout":        esc.config.Timeout.String(),
+		"get_timeout":    esc.getTimeout().String(),
+		"update_timeout": esc.updateTimeout().String(),
+		"health_timeout": esc.healthTimeout().String(),
+		"max_retries":    esc.config.MaxRetries,
+		"retry_backoff":  esc.config.RetryBackoff.String(),
+		"connection_pool": map[string]interface{}{
+			"max_idle_conns":          esc.transport.MaxIdleConns,
+			"max_idle_conns_per_host": esc.transport.MaxIdleConnsPerHost,
+			"max_conns_per_host":      esc.transport.MaxConnsPerHost,
+			"idle_conn_timeout":       esc.transport.IdleConnTimeout.String(),
+			"disable_compression":     esc.transport.DisableCompression,
+		},
 		"circuit_breaker": map[string]interface{}{
-			"failure_threshold": esc.config.CircuitBreaker.FailureThreshold,
-			"timeout":           esc.config.CircuitBreaker.Timeout.String(),
+			"failure_threshold":      esc.config.CircuitBreaker.FailureThreshold,
+			"timeout":                esc.config.CircuitBreaker.Timeout.String(),
+			"window_size":            esc.config.CircuitBreaker.WindowSize,
+			"failure_rate_threshold": esc.config.CircuitBreaker.FailureRateThreshold,
+		},
+		"circuit_breakers": map[string]interface{}{
+			"get":    esc.getCircuitBreaker.GetStats(),
+			"update": esc.updateCircuitBreaker.GetStats(),
 		},
+		"adaptive_concurrency": esc.concurrency.GetStats(),
+		"rate_limit":           esc.rateLimiter.GetStats(),
+		"cache":                esc.cache.stats(),
+	}
+}
+
+// getTimeout returns the per-request timeout for GET calls
+func (esc *ExecutionServiceClient) getTimeout() time.Duration {
+	if esc.config.GetTimeout > 0 {
+		return esc.config.GetTimeout
 	}
+	return esc.config.Timeout
+}
+
+// updateTimeout returns the per-request timeout for PUT calls
+func (esc *ExecutionServiceClient) updateTimeout() time.Duration {
+	if esc.config.UpdateTimeout > 0 {
+		return esc.config.UpdateTimeout
+	}
+	return esc.config.Timeout
+}
+
+// healthTimeout returns the timeout for health checks
+func (esc *ExecutionServiceClient) healthTimeout() time.Duration {
+	if esc.config.HealthTimeout > 0 {
+		return esc.config.HealthTimeout
+	}
+	return defaultExecutionHealthTimeout
+}
+
+// healthyStatusRange returns the range of health check status codes that count as healthy
+func (esc *ExecutionServiceClient) healthyStatusRange() (int, int) {
+	minStatus, maxStatus := esc.config.HealthyStatusMin, esc.config.HealthyStatusMax
+	if minStatus <= 0 {
+		minStatus = defaultExecutionHealthyStatusMin
+	}
+	if maxStatus <= 0 {
+		maxStatus = defaultExecutionHealthyStatusMax
+	}
+	return minStatus, maxStatus
+}
+
+// withRequestTimeout bounds a single request by timeout; a zero timeout leaves
+// the request bounded only by the parent context
+func withRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 // handleErrorResponse handles HTTP error responses
-func (esc *ExecutionServiceClient) handleErrorResponse(statusCode int, body []byte, correlationID string) error {
+func (esc *ExecutionServiceClient) handleErrorResponse(statusCode int, header http.Header, body []byte, correlationID string) error {
 	switch statusCode {
 	case http.StatusNotFound:
 		return domain.NewNotFoundError("execution", "execution not found").
@@ -332,9 +610,21 @@ func (esc *ExecutionServiceClient) handleErrorResponse(statusCode int, body []by
 		return domain.NewExternalError("execution-service", "authentication/authorization failed", nil, false).
 			WithCorrelationID(correlationID)
 	case http.StatusTooManyRequests:
+		// Throttled requests are not acted on, so even updates can be sent again
 		return domain.NewExternalError("execution-service", "rate limit exceeded", nil, true).
-			WithCorrelationID(correlationID)
-	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			WithCorrelationID(correlationID).
+			WithRetryAfter(parseRetryAfter(header.Get("Retry-After"), time.Now())).
+			WithRequestRejected()
+	case http.StatusServiceUnavailable:
+		unavailableErr := domain.NewExternalError("execution-service", fmt.Sprintf("server error: %d", statusCode), nil, true).
+			WithCorrelationID(correlationID).
+			WithRetryAfter(parseRetryAfter(header.Get("Retry-After"), time.Now()))
+		// A Retry-After says the service is turning requests away rather than failing them
+		if header.Get("Retry-After") != "" {
+			unavailableErr.WithRequestRejected()
+		}
+		return unavailableErr
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
 		return domain.NewExternalError("execution-service", fmt.Sprintf("server error: %d", statusCode), nil, true).
 			WithCorrelationID(correlationID)
 	default:
@@ -342,3 +632,25 @@ func (esc *ExecutionServiceClient) handleErrorResponse(statusCode int, body []by
 			WithCorrelationID(correlationID)
 	}
 }
+
+// parseRetryAfter parses a Retry-After header given as delay seconds or an HTTP date,
+// returning zero when it is absent, invalid or already past
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+	return date.Sub(now)
+}
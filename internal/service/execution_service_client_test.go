@@ -0,0 +1,748 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// newSlowExecutionServer returns a server that answers every request after delay
+func newSlowExecutionServer(t *testing.T, delay time.Duration) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(domain.ExecutionUpdateResponse{ID: 1, Version: 2})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestExecutionServiceClient(t *testing.T, executionConfig config.ExecutionServiceConfig) *ExecutionServiceClient {
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 1
+
+	return newTestExecutionServiceClientWithResilience(t, executionConfig, resilienceConfig)
+}
+
+func newTestExecutionServiceClientWithResilience(t *testing.T, executionConfig config.ExecutionServiceConfig, resilienceConfig utils.ResilienceConfig) *ExecutionServiceClient {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	return NewExecutionServiceClient(ExecutionServiceClientConfig{
+		ExecutionService:  executionConfig,
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ResilienceManager: utils.NewResilienceManager(resilienceConfig, appLogger, appMetrics),
+	})
+}
+
+func TestExecutionServiceClient_PerOperationTimeouts(t *testing.T) {
+	server := newSlowExecutionServer(t, 200*time.Millisecond)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL:       server.URL,
+		Timeout:       time.Second,
+		GetTimeout:    50 * time.Millisecond,
+		UpdateTimeout: 2 * time.Second,
+	})
+
+	ctx := context.Background()
+
+	// The slow server trips the short GET timeout
+	_, err := client.GetExecution(ctx, 1)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The same server responds within the longer PUT timeout
+	response, err := client.UpdateExecution(ctx, 1, &domain.ExecutionUpdateRequest{
+		QuantityFilled: 100,
+		AveragePrice:   10,
+		Version:        1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Version)
+}
+
+func TestExecutionServiceClient_SlowUpdateTimesOutIndependently(t *testing.T) {
+	server := newSlowExecutionServer(t, 200*time.Millisecond)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL:       server.URL,
+		Timeout:       time.Second,
+		UpdateTimeout: 50 * time.Millisecond,
+		HealthTimeout: 2 * time.Second,
+	})
+
+	ctx := context.Background()
+
+	// The slow server trips the short PUT timeout
+	_, err := client.UpdateExecution(ctx, 1, &domain.ExecutionUpdateRequest{
+		QuantityFilled: 100,
+		AveragePrice:   10,
+		Version:        1,
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Reads and health checks keep their own budgets
+	_, err = client.GetExecution(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, client.IsHealthy(ctx))
+}
+
+func TestExecutionServiceClient_HealthTimeout(t *testing.T) {
+	server := newSlowExecutionServer(t, 200*time.Millisecond)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL:       server.URL,
+		Timeout:       time.Second,
+		HealthTimeout: 50 * time.Millisecond,
+	})
+
+	assert.False(t, client.IsHealthy(context.Background()))
+}
+
+func TestExecutionServiceClient_IsHealthy_CustomPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL:    server.URL,
+		Timeout:    time.Second,
+		HealthPath: "/healthz",
+	})
+	assert.True(t, client.IsHealthy(context.Background()))
+
+	// The default Actuator path is not served
+	client = newTestExecutionServiceClient(t, config.ExecutionServiceConfig{BaseURL: server.URL, Timeout: time.Second})
+	assert.False(t, client.IsHealthy(context.Background()))
+}
+
+func TestExecutionServiceClient_IsHealthy_StatusRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL:          server.URL,
+		Timeout:          time.Second,
+		HealthyStatusMin: 200,
+		HealthyStatusMax: 200,
+	})
+	assert.False(t, client.IsHealthy(context.Background()), "204 is outside the configured 200-200 range")
+}
+
+func TestExecutionServiceClient_OperationTimeoutsDefaultToClientTimeout(t *testing.T) {
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL: "http://localhost:8084",
+		Timeout: 3 * time.Second,
+	})
+
+	assert.Equal(t, 3*time.Second, client.getTimeout())
+	assert.Equal(t, 3*time.Second, client.updateTimeout())
+
+	stats := client.GetStats()
+	assert.Equal(t, "3s", stats["get_timeout"])
+	assert.Equal(t, "3s", stats["update_timeout"])
+	assert.Equal(t, "5s", stats["health_timeout"])
+}
+
+func TestExecutionServiceClient_SeparateCircuitBreakersPerOperation(t *testing.T) {
+	// Updates always conflict while reads succeed
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{ID: 1, Version: 1})
+	}))
+	t.Cleanup(server.Close)
+
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 1
+	resilienceConfig.CircuitBreakerConfig.FailureThreshold = 2
+
+	client := newTestExecutionServiceClientWithResilience(t, config.ExecutionServiceConfig{
+		BaseURL: server.URL,
+		Timeout: time.Second,
+	}, resilienceConfig)
+
+	ctx := context.Background()
+	updateReq := &domain.ExecutionUpdateRequest{QuantityFilled: 100, AveragePrice: 10, Version: 1}
+
+	// Trip the update circuit breaker
+	for i := 0; i < 2; i++ {
+		_, err := client.UpdateExecution(ctx, 1, updateReq)
+		require.Error(t, err)
+	}
+	assert.Equal(t, utils.StateOpen, client.updateCircuitBreaker.GetState())
+
+	_, err := client.UpdateExecution(ctx, 1, updateReq)
+	var domainErr *domain.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrorTypeCircuitBreaker, domainErr.Type)
+
+	// Reads are unaffected by the open update circuit
+	execution, err := client.GetExecution(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), execution.ID)
+	assert.Equal(t, utils.StateClosed, client.getCircuitBreaker.GetState())
+
+	circuitBreakers := client.GetStats()["circuit_breakers"].(map[string]interface{})
+	assert.Equal(t, utils.StateClosed, circuitBreakers["get"].(utils.CircuitBreakerStats).State)
+	assert.Equal(t, utils.StateOpen, circuitBreakers["update"].(utils.CircuitBreakerStats).State)
+	assert.Equal(t, int64(1), circuitBreakers["update"].(utils.CircuitBreakerStats).TotalRejections)
+}
+
+func TestExecutionServiceClient_CircuitBreakersPerTenant(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+
+	resilienceManager := utils.NewResilienceManager(utils.GetDefaultResilienceConfig(), appLogger, nil)
+	newClient := func(tenantID string) *ExecutionServiceClient {
+		return NewExecutionServiceClient(ExecutionServiceClientConfig{
+			ExecutionService:  config.ExecutionServiceConfig{BaseURL: "http://localhost:8084", Timeout: time.Second},
+			Logger:            appLogger,
+			ResilienceManager: resilienceManager,
+			TenantID:          tenantID,
+		})
+	}
+
+	global := newClient("")
+	acme := newClient("acme")
+	globex := newClient("globex")
+
+	assert.Same(t, resilienceManager.GetCircuitBreaker("execution-service-get"), global.getCircuitBreaker)
+	assert.Same(t, resilienceManager.GetCircuitBreaker("execution-service-update:acme"), acme.updateCircuitBreaker)
+	assert.NotSame(t, acme.getCircuitBreaker, globex.getCircuitBreaker)
+	assert.NotSame(t, acme.updateCircuitBreaker, global.updateCircuitBreaker)
+}
+
+func TestExecutionServiceClient_UpdateExecution_InfersChanged(t *testing.T) {
+	tests := []struct {
+		name            string
+		responseVersion int
+		expectChanged   bool
+		expectedNoOps   float64
+	}{
+		{"version incremented", 4, true, 0},
+		{"version unchanged", 3, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(domain.ExecutionUpdateResponse{ID: 1, Version: tt.responseVersion})
+			}))
+			t.Cleanup(server.Close)
+
+			client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+				BaseURL: server.URL,
+				Timeout: time.Second,
+			})
+
+			response, err := client.UpdateExecution(context.Background(), 1, &domain.ExecutionUpdateRequest{
+				QuantityFilled: 100,
+				AveragePrice:   10,
+				Version:        3,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectChanged, response.Changed)
+			assert.Equal(t, tt.expectedNoOps, testutil.ToFloat64(client.metrics.ExecutionUpdateNoOpsTotal))
+		})
+	}
+}
+
+func TestExecutionServiceClient_CachesGetExecution(t *testing.T) {
+	var gets atomic.Int64
+	var version atomic.Int64
+	version.Store(1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			json.NewEncoder(w).Encode(domain.ExecutionUpdateResponse{ID: 1, Version: int(version.Add(1))})
+			return
+		}
+		gets.Add(1)
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{ID: 1, Version: int(version.Load())})
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL:  server.URL,
+		Timeout:  time.Second,
+		CacheTTL: time.Minute,
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		execution, err := client.GetExecution(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, execution.Version)
+	}
+	assert.Equal(t, int64(1), gets.Load(), "a burst of reads fetches the execution once")
+
+	// An update changes the version, so the next read fetches it again
+	_, err := client.UpdateExecution(ctx, 1, &domain.ExecutionUpdateRequest{QuantityFilled: 100, AveragePrice: 10, Version: 1})
+	require.NoError(t, err)
+
+	execution, err := client.GetExecution(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, execution.Version)
+	assert.Equal(t, int64(2), gets.Load())
+
+	stats := client.GetStats()["cache"].(ExecutionCacheStats)
+	assert.True(t, stats.Enabled)
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+}
+
+func TestNewExecutionTransport(t *testing.T) {
+	transport := newExecutionTransport(config.ExecutionServiceConfig{
+		MaxIdleConns:        50,
+		MaxIdleConnsPerHost: 20,
+		MaxConnsPerHost:     40,
+		IdleConnTimeout:     time.Minute,
+		DisableCompression:  true,
+	})
+	assert.Equal(t, 50, transport.MaxIdleConns)
+	assert.Equal(t, 20, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 40, transport.MaxConnsPerHost)
+	assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	assert.True(t, transport.DisableCompression)
+
+	// Unset values fall back to the defaults
+	transport = newExecutionTransport(config.ExecutionServiceConfig{})
+	assert.Equal(t, 10, transport.MaxIdleConns)
+	assert.Equal(t, 10, transport.MaxIdleConnsPerHost)
+	assert.Zero(t, transport.MaxConnsPerHost)
+	assert.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+	assert.False(t, transport.DisableCompression)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{BaseURL: "http://localhost", MaxConnsPerHost: 5})
+	pool := client.GetStats()["connection_pool"].(map[string]interface{})
+	assert.Equal(t, 5, pool["max_conns_per_host"])
+	assert.Equal(t, 10, pool["max_idle_conns"])
+}
+
+func TestExecutionServiceClient_CacheDisabledByDefault(t *testing.T) {
+	var gets atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gets.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{ID: 1, Version: 1})
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{BaseURL: server.URL, Timeout: time.Second})
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetExecution(context.Background(), 1)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(2), gets.Load())
+	assert.False(t, client.GetStats()["cache"].(ExecutionCacheStats).Enabled)
+}
+
+func TestExecutionServiceClient_AdaptiveConcurrencyBacksOffOnSlowResponses(t *testing.T) {
+	server := newSlowExecutionServer(t, 50*time.Millisecond)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL: server.URL,
+		Timeout: time.Second,
+	})
+	client.concurrency = utils.NewAdaptiveConcurrencyLimiter(utils.AdaptiveConcurrencyConfig{
+		MinLimit:      1,
+		MaxLimit:      8,
+		LatencyTarget: 10 * time.Millisecond,
+	})
+
+	_, err := client.UpdateExecution(context.Background(), 1, &domain.ExecutionUpdateRequest{Version: 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, client.concurrency.Limit())
+	assert.Equal(t, 4.0, testutil.ToFloat64(client.metrics.ExecutionServiceConcurrencyLimit))
+
+	stats := client.GetStats()["adaptive_concurrency"].(utils.AdaptiveConcurrencyStats)
+	assert.Equal(t, int64(1), stats.Decreases)
+}
+
+func TestExecutionServiceClient_RecordsAPILatencyByOperation(t *testing.T) {
+	// Reads succeed while updates are rejected
+	var puts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			puts.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{ID: 1, Version: 1})
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL: server.URL,
+		Timeout: time.Second,
+	})
+
+	ctx := context.Background()
+	_, err := client.GetExecution(ctx, 1)
+	require.NoError(t, err)
+	_, err = client.GetExecution(ctx, 1)
+	require.NoError(t, err)
+	_, err = client.UpdateExecution(ctx, 1, &domain.ExecutionUpdateRequest{Version: 1})
+	require.Error(t, err)
+
+	families, err := client.metrics.Gatherer().Gather()
+	require.NoError(t, err)
+
+	sampleCounts := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "test_execution_api_latency_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			sampleCounts[labels["operation"]+"/"+labels["status_code"]] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+
+	// Every HTTP attempt is observed, including the replay made when queueing the failure
+	assert.Equal(t, map[string]uint64{
+		"get_execution/200":    2,
+		"update_execution/400": uint64(puts.Load()),
+	}, sampleCounts)
+}
+
+func TestExecutionServiceClient_RateLimitPacesRequests(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{ID: 1, Version: 1})
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+		BaseURL:        server.URL,
+		Timeout:        time.Second,
+		RateLimit:      20,
+		RateLimitBurst: 1,
+	})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := client.GetExecution(context.Background(), 1)
+		require.NoError(t, err)
+	}
+
+	// After the first request, each one waits 50ms for a token
+	assert.GreaterOrEqual(t, time.Since(start), 140*time.Millisecond)
+	assert.Equal(t, int64(4), requests.Load())
+	assert.Equal(t, 1, testutil.CollectAndCount(&client.metrics.ExecutionRateLimitWait))
+	assert.Equal(t, int64(3), client.rateLimiter.GetStats().Waits)
+
+	t.Run("cancellation aborts a waiting call", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := client.UpdateExecution(ctx, 1, &domain.ExecutionUpdateRequest{Version: 1})
+
+		require.Error(t, err)
+		assert.Equal(t, int64(4), requests.Load(), "the cancelled call is never sent")
+		assert.True(t, domain.IsNotSent(err))
+	})
+}
+
+func TestExecutionServiceClient_RateLimitWaitsDoNotTripCircuitBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{ID: 1, Version: 1})
+	}))
+	t.Cleanup(server.Close)
+
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 1
+	resilienceConfig.CircuitBreakerConfig.FailureThreshold = 1
+
+	client := newTestExecutionServiceClientWithResilience(t, config.ExecutionServiceConfig{
+		BaseURL:        server.URL,
+		Timeout:        time.Second,
+		RateLimit:      1,
+		RateLimitBurst: 1,
+	}, resilienceConfig)
+
+	_, err := client.GetExecution(context.Background(), 1)
+	require.NoError(t, err)
+
+	// The next calls give up waiting for a token before they are sent
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err = client.GetExecution(ctx, 1)
+		cancel()
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, utils.StateClosed, client.getCircuitBreaker.GetState())
+	assert.Equal(t, int64(0), client.getCircuitBreaker.GetStats().TotalFailures)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"delay seconds", "120", 2 * time.Minute},
+		{"HTTP date", now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{"past HTTP date", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"zero seconds", "0", 0},
+		{"negative seconds", "-5", 0},
+		{"invalid", "soon", 0},
+		{"absent", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseRetryAfter(tt.value, now))
+		})
+	}
+}
+
+func TestExecutionServiceClient_SurfacesRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		retryAfter string
+		want       time.Duration
+	}{
+		{"429 with delay seconds", http.StatusTooManyRequests, "3", 3 * time.Second},
+		{"503 with delay seconds", http.StatusServiceUnavailable, "7", 7 * time.Second},
+		{"503 without header", http.StatusServiceUnavailable, "", 0},
+		{"500 ignores header", http.StatusInternalServerError, "7", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statusCode)
+			}))
+			t.Cleanup(server.Close)
+
+			client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{
+				BaseURL: server.URL,
+				Timeout: time.Second,
+			})
+
+			_, err := client.GetExecution(context.Background(), 1)
+
+			require.Error(t, err)
+			assert.Equal(t, tt.want, domain.RetryAfterOf(err))
+		})
+	}
+}
+
+func TestExecutionServiceClient_RetriesAfterRequestedDelay(t *testing.T) {
+	var attempts []time.Time
+	var mutex sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		attempts = append(attempts, time.Now())
+		first := len(attempts) == 1
+		mutex.Unlock()
+
+		if first {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{ID: 1, Version: 1})
+	}))
+	t.Cleanup(server.Close)
+
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 2
+	resilienceConfig.RetryConfig.InitialDelay = time.Millisecond
+	client := newTestExecutionServiceClientWithResilience(t, config.ExecutionServiceConfig{
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	}, resilienceConfig)
+
+	_, err := client.GetExecution(context.Background(), 1)
+	require.NoError(t, err)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Len(t, attempts, 2)
+	assert.GreaterOrEqual(t, attempts[1].Sub(attempts[0]), time.Second, "the retry waits for the Retry-After delay, not the 1ms backoff")
+}
+
+func TestExecutionServiceClient_UpdateExecution_RetriesOnlyPreRequestErrors(t *testing.T) {
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 3
+	resilienceConfig.RetryConfig.InitialDelay = time.Millisecond
+	updateReq := &domain.ExecutionUpdateRequest{QuantityFilled: 10, AveragePrice: 1.5, Version: 1}
+
+	// The service may have applied an update that failed with an error response
+	var updates atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		updates.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestExecutionServiceClientWithResilience(t, config.ExecutionServiceConfig{
+		BaseURL: server.URL,
+		Timeout: time.Second,
+	}, resilienceConfig)
+
+	_, err := client.UpdateExecution(context.Background(), 1, updateReq)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), updates.Load())
+
+	// GETs are idempotent and still retried
+	var gets atomic.Int32
+	getServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gets.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(getServer.Close)
+
+	client = newTestExecutionServiceClientWithResilience(t, config.ExecutionServiceConfig{
+		BaseURL: getServer.URL,
+		Timeout: time.Second,
+	}, resilienceConfig)
+
+	_, err = client.GetExecution(context.Background(), 1)
+	require.Error(t, err)
+	assert.Greater(t, gets.Load(), int32(1))
+}
+
+func TestExecutionServiceClient_UpdateExecution_RetriesRejectedRequests(t *testing.T) {
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 3
+	resilienceConfig.RetryConfig.InitialDelay = time.Millisecond
+	updateReq := &domain.ExecutionUpdateRequest{QuantityFilled: 10, AveragePrice: 1.5, Version: 1}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		retryAfter string
+	}{
+		{"rate limited", http.StatusTooManyRequests, ""},
+		{"unavailable with Retry-After", http.StatusServiceUnavailable, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The service turns the first update away, then applies the second
+			var updates atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if updates.Add(1) == 1 {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.statusCode)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(domain.ExecutionUpdateResponse{ID: 1, Version: 2})
+			}))
+			t.Cleanup(server.Close)
+
+			client := newTestExecutionServiceClientWithResilience(t, config.ExecutionServiceConfig{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			}, resilienceConfig)
+
+			start := time.Now()
+			response, err := client.UpdateExecution(context.Background(), 1, updateReq)
+			require.NoError(t, err)
+			assert.Equal(t, 2, response.Version)
+			assert.Equal(t, int32(2), updates.Load())
+
+			// The retry waits for the delay the service asked for
+			if tt.retryAfter != "" {
+				assert.GreaterOrEqual(t, time.Since(start), time.Second)
+			}
+		})
+	}
+}
+
+func TestExecutionServiceClient_PropagatesTraceContext(t *testing.T) {
+	useTraceContextPropagator(t)
+
+	traceparents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{ID: 1, Version: 1})
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestExecutionServiceClient(t, config.ExecutionServiceConfig{BaseURL: server.URL, Timeout: time.Second})
+
+	// A trace continued from a Kafka message
+	traceID, err := oteltrace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := oteltrace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	ctx := oteltrace.ContextWithRemoteSpanContext(context.Background(), oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: oteltrace.FlagsSampled,
+		Remote:     true,
+	}))
+
+	_, err = client.GetExecution(ctx, 1)
+	require.NoError(t, err)
+	assert.Contains(t, <-traceparents, "4bf92f3577b34da6a3ce929d0e0e4736")
+}
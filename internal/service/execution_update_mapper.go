@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+)
+
+// Optional fill fields an ExecutionUpdateFieldMapper can add to update requests
+const (
+	UpdateFieldTotalAmount   = "total_amount"
+	UpdateFieldNumberOfFills = "number_of_fills"
+)
+
+// ExecutionUpdateMapper builds the Execution Service update request for a fill
+type ExecutionUpdateMapper interface {
+	ToUpdateRequest(fill *domain.Fill, currentVersion int) *domain.ExecutionUpdateRequest
+}
+
+// ExecutionUpdateMapperFunc adapts a function to ExecutionUpdateMapper
+type ExecutionUpdateMapperFunc func(fill *domain.Fill, currentVersion int) *domain.ExecutionUpdateRequest
+
+// ToUpdateRequest calls f(fill, currentVersion)
+func (f ExecutionUpdateMapperFunc) ToUpdateRequest(fill *domain.Fill, currentVersion int) *domain.ExecutionUpdateRequest {
+	return f(fill, currentVersion)
+}
+
+// DefaultExecutionUpdateMapper sends the filled quantity, average price and version
+var DefaultExecutionUpdateMapper ExecutionUpdateMapper = ExecutionUpdateMapperFunc(func(fill *domain.Fill, currentVersion int) *domain.ExecutionUpdateRequest {
+	return fill.ToUpdateRequest(currentVersion)
+})
+
+// ExecutionUpdateFieldMapper sends the default fields plus a chosen set of optional fill fields
+type ExecutionUpdateFieldMapper struct {
+	totalAmount   bool
+	numberOfFills bool
+}
+
+// NewExecutionUpdateFieldMapper returns a mapper that also sends the named fields
+// (UpdateFieldTotalAmount, UpdateFieldNumberOfFills)
+func NewExecutionUpdateFieldMapper(fields []string) (*ExecutionUpdateFieldMapper, error) {
+	mapper := &ExecutionUpdateFieldMapper{}
+	for _, field := range fields {
+		switch field {
+		case UpdateFieldTotalAmount:
+			mapper.totalAmount = true
+		case UpdateFieldNumberOfFills:
+			mapper.numberOfFills = true
+		default:
+			return nil, fmt.Errorf("unknown execution update field: %q", field)
+		}
+	}
+	return mapper, nil
+}
+
+// ToUpdateRequest implements ExecutionUpdateMapper
+func (m *ExecutionUpdateFieldMapper) ToUpdateRequest(fill *domain.Fill, currentVersion int) *domain.ExecutionUpdateRequest {
+	req := fill.ToUpdateRequest(currentVersion)
+	if m.totalAmount {
+		totalAmount := fill.TotalAmount
+		req.TotalAmount = &totalAmount
+	}
+	if m.numberOfFills {
+		numberOfFills := fill.NumberOfFills
+		req.NumberOfFills = &numberOfFills
+	}
+	return req
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultExecutionUpdateMapper(t *testing.T) {
+	fill := newVersionConflictTestFill()
+
+	req := DefaultExecutionUpdateMapper.ToUpdateRequest(fill, 4)
+
+	assert.Equal(t, fill.QuantityFilled, req.QuantityFilled)
+	assert.Equal(t, fill.AveragePrice, req.AveragePrice)
+	assert.Equal(t, 4, req.Version)
+	assert.Nil(t, req.TotalAmount)
+	assert.Nil(t, req.NumberOfFills)
+	assert.NotContains(t, req.String(), "totalAmount")
+}
+
+func TestExecutionUpdateFieldMapper_IncludesExtraFields(t *testing.T) {
+	fill := newVersionConflictTestFill()
+
+	mapper, err := NewExecutionUpdateFieldMapper([]string{UpdateFieldTotalAmount, UpdateFieldNumberOfFills})
+	require.NoError(t, err)
+
+	req := mapper.ToUpdateRequest(fill, 4)
+
+	assert.Equal(t, fill.QuantityFilled, req.QuantityFilled)
+	assert.Equal(t, 4, req.Version)
+	require.NotNil(t, req.TotalAmount)
+	assert.Equal(t, fill.TotalAmount, *req.TotalAmount)
+	require.NotNil(t, req.NumberOfFills)
+	assert.Equal(t, fill.NumberOfFills, *req.NumberOfFills)
+	assert.Contains(t, req.String(), `"totalAmount":450`)
+	assert.Contains(t, req.String(), `"numberOfFills":1`)
+}
+
+func TestExecutionUpdateFieldMapper_UnknownField(t *testing.T) {
+	_, err := NewExecutionUpdateFieldMapper([]string{"ticker"})
+	assert.Error(t, err)
+}
+
+func TestConfirmationService_HandleFillMessage_UsesUpdateMapper(t *testing.T) {
+	service, mockExecClient, _ := newVersionConflictTestService(t, 0)
+	mapper, err := NewExecutionUpdateFieldMapper([]string{UpdateFieldNumberOfFills})
+	require.NoError(t, err)
+	service.updateMapper = mapper
+
+	mockExecClient.On("GetExecution", mock.Anything, int64(2)).Return(versionConflictTestExecution(1), nil).Once()
+	mockExecClient.On("UpdateExecution", mock.Anything, int64(2), mock.MatchedBy(func(req *domain.ExecutionUpdateRequest) bool {
+		return req.NumberOfFills != nil && *req.NumberOfFills == 1 && req.TotalAmount == nil
+	})).Return(&domain.ExecutionUpdateResponse{ID: 2, ExecutionStatus: "PARTIAL", Version: 2}, nil).Once()
+
+	err = service.HandleFillMessage(context.Background(), newVersionConflictTestFill())
+	assert.NoError(t, err)
+	mockExecClient.AssertExpectations(t)
+}
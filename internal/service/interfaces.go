@@ -19,6 +19,7 @@ type ExecutionServiceClientInterface interface {
 type ResilienceManagerInterface interface {
 	GetCircuitBreakerStats() utils.CircuitBreakerStats
 	GetDeadLetterQueueStats() utils.DeadLetterQueueStats
+	GetRetryBudgetStats() utils.RetryBudgetStats
 	AddToDeadLetterQueue(ctx context.Context, originalMessage interface{}, failureReason string, errorHistory []error, attemptCount int, metadata map[string]interface{}) error
 }
 
@@ -27,7 +28,11 @@ type KafkaConsumerInterface interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context) error
 	IsHealthy(ctx context.Context) bool
+	IsRunning() bool
 	GetStats() map[string]interface{}
+	Pause(ctx context.Context)
+	Resume(ctx context.Context)
+	IsPaused() bool
 }
 
 // Ensure our concrete types implement the interfaces
@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kasbench/globeco-confirmation-service/internal/config"
@@ -13,13 +17,42 @@ import (
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
 	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 )
 
+// defaultCorrelationIDHeader is the Kafka message header carrying the producer's
+// correlation ID when none is configured
+const defaultCorrelationIDHeader = "X-Correlation-ID"
+
+// retryCountHeader is the Kafka message header carrying how many times a message was retried
+const retryCountHeader = "X-Retry-Count"
+
+// maxTrackedFailedDeliveries bounds how many failed messages are remembered for redelivery detection
+const maxTrackedFailedDeliveries = 1000
+
+// backpressurePollInterval is how often a paused consumer rechecks the dead letter queue
+const backpressurePollInterval = time.Second
+
+// retryInPlaceInterval is how long the consumer waits before handling a failed message again
+const retryInPlaceInterval = time.Second
+
+// readerStatsInterval is how often the reader's counters are exported as metrics
+const readerStatsInterval = 15 * time.Second
+
+// kafkaReader is the subset of *kafka.Reader used by the consumer
+type kafkaReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+	Stats() kafka.ReaderStats
+}
+
 // KafkaConsumerService handles Kafka message consumption
 type KafkaConsumerService struct {
 	config            config.KafkaConfig
-	reader            *kafka.Reader
+	reader            kafkaReader
+	readerConfig      kafka.ReaderConfig
 	logger            *logger.Logger
 	metrics           *metrics.Metrics
 	resilienceManager *utils.ResilienceManager
@@ -33,11 +66,73 @@ type KafkaConsumerService struct {
 	doneCh chan struct{}
 	wg     sync.WaitGroup
 
+	// Draining: fetching stops as soon as Stop is called, while the in-flight
+	// message may finish until the drain deadline passes
+	drainTimeout   time.Duration
+	loopCancel     context.CancelFunc
+	abandonCtx     context.Context
+	abandon        context.CancelFunc
+	inFlight       int32
+	drainedCount   int64
+	abandonedCount int64
+
+	// Backpressure: consumption pauses while the dead letter queue is backed up.
+	// Operators can also pause it through the admin API; either keeps it paused.
+	paused            bool
+	pausedByOperator  bool
+	pauseCount        int64
+	pausePollInterval time.Duration
+
+	// Redelivery detection for messages without a retry-count header:
+	// failed deliveries by topic/partition/offset
+	failedDeliveries map[string]int
+	redeliveredCount int64
+
+	// Messages dead-lettered after reaching the maximum processing attempts
+	poisonCount int64
+
+	// How long to wait before handling a failed message again in place
+	retryInterval time.Duration
+
+	// Decodes fill message values in the configured format
+	deserializer Deserializer
+
+	// Logs raw message values when debug payload logging is enabled
+	payloadLogger payloadLogger
+
+	// Reader counters accumulated from Stats, which resets them on every call
+	readerStatsInterval time.Duration
+	readerTotals        kafka.ReaderStats
+
+	// Worker pool: with more than one worker, each partition's messages are queued
+	// for the same worker, and the adaptive limit bounds how many workers are active
+	workerQueues  []chan kafka.Message
+	workerLimiter *utils.AdaptiveConcurrencyLimiter
+
+	// Handled messages waiting for the next batch commit when commits are batched
+	commitMutex    sync.Mutex
+	pendingCommits []kafka.Message
+
+	// Partitions messages were fetched from since the previous stats collection, logged
+	// on rebalances since kafka-go does not expose the group's partition assignment
+	fetchedPartitions map[string]struct{}
+
+	// Consumer group lag per topic/partition, measured every LagPollInterval
+	offsetSource offsetSource
+	partitionLag map[string]int64
+	totalLag     int64
+
+	// Partitions without committed offsets start here; the reader is then created by
+	// Start, after the offsets are committed
+	startTimestamp time.Time
+
 	// State tracking
-	isRunning    bool
-	mutex        sync.RWMutex
-	lastMessage  time.Time
-	messageCount int64
+	isRunning          bool
+	mutex              sync.RWMutex
+	lastMessage        time.Time
+	messageCount       int64
+	topics             []string
+	topicMessageCounts map[string]int64
 }
 
 // MessageHandler defines the interface for handling processed messages
@@ -53,19 +148,89 @@ type KafkaConsumerConfig struct {
 	ResilienceManager *utils.ResilienceManager
 	TracingProvider   *utils.TracingProvider
 	MessageHandler    MessageHandler
+	DrainTimeout      time.Duration // How long Stop waits for the in-flight message to finish
+	Logging           config.LoggingConfig
+	WorkerPoolSize    int                               // Workers handling messages concurrently; 1 or less handles them in the consume loop
+	WorkerConcurrency *utils.AdaptiveConcurrencyLimiter // Optional adaptive limit on active workers; nil keeps every worker active
 }
 
 // NewKafkaConsumerService creates a new Kafka consumer service
 func NewKafkaConsumerService(config KafkaConsumerConfig) *KafkaConsumerService {
-	// Create Kafka reader
-	reader := kafka.NewReader(kafka.ReaderConfig{
+	topics := config.Kafka.GetTopics()
+	readerConfig := newReaderConfig(config)
+
+	startTimestamp, err := config.Kafka.GetStartTimestamp()
+	if err != nil {
+		config.Logger.Warn("Ignoring invalid Kafka start timestamp",
+			zap.String("start_timestamp", config.Kafka.StartTimestamp),
+			zap.Error(err),
+		)
+	}
+
+	var reader kafkaReader
+	if startTimestamp.IsZero() {
+		reader = kafka.NewReader(readerConfig)
+	}
+
+	drainTimeout := config.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = config.Kafka.DrainTimeout
+	}
+	if drainTimeout <= 0 {
+		drainTimeout = 10 * time.Second
+	}
+
+	abandonCtx, abandon := context.WithCancel(context.Background())
+
+	var workerQueues []chan kafka.Message
+	if config.WorkerPoolSize > 1 {
+		workerQueues = make([]chan kafka.Message, config.WorkerPoolSize)
+		for i := range workerQueues {
+			workerQueues[i] = make(chan kafka.Message, 1)
+		}
+	}
+
+	return &KafkaConsumerService{
+		config:              config.Kafka,
+		reader:              reader,
+		readerConfig:        readerConfig,
+		logger:              config.Logger,
+		metrics:             config.Metrics,
+		resilienceManager:   config.ResilienceManager,
+		tracingProvider:     config.TracingProvider,
+		messageHandler:      config.MessageHandler,
+		stopCh:              make(chan struct{}),
+		doneCh:              make(chan struct{}),
+		drainTimeout:        drainTimeout,
+		abandonCtx:          abandonCtx,
+		abandon:             abandon,
+		pausePollInterval:   backpressurePollInterval,
+		retryInterval:       retryInPlaceInterval,
+		readerStatsInterval: readerStatsInterval,
+		offsetSource:        newBrokerOffsetSource(config.Kafka.Brokers, config.Kafka.ConnectionTimeout),
+		startTimestamp:      startTimestamp,
+		failedDeliveries:    make(map[string]int),
+		fetchedPartitions:   make(map[string]struct{}),
+		topics:              topics,
+		topicMessageCounts:  make(map[string]int64),
+		deserializer:        newConsumerDeserializer(config),
+		payloadLogger:       newPayloadLogger(config.Logger, config.Logging),
+		workerQueues:        workerQueues,
+		workerLimiter:       config.WorkerConcurrency,
+	}
+}
+
+// newReaderConfig returns the Kafka reader configuration; several topics are consumed
+// through GroupTopics
+func newReaderConfig(config KafkaConsumerConfig) kafka.ReaderConfig {
+	topics := config.Kafka.GetTopics()
+	readerConfig := kafka.ReaderConfig{
 		Brokers:     config.Kafka.Brokers,
-		Topic:       config.Kafka.Topic,
 		GroupID:     config.Kafka.ConsumerGroup,
 		MinBytes:    1,
 		MaxBytes:    10e6, // 10MB
 		MaxWait:     1 * time.Second,
-		StartOffset: kafka.LastOffset,
+		StartOffset: readerStartOffset(config.Kafka.StartOffset),
 
 		// Error handling
 		ErrorLogger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
@@ -79,19 +244,39 @@ func NewKafkaConsumerService(config KafkaConsumerConfig) *KafkaConsumerService {
 			Timeout:   config.Kafka.ConnectionTimeout,
 			DualStack: true,
 		},
-	})
+	}
+	if len(topics) > 1 {
+		readerConfig.GroupTopics = topics
+	} else if len(topics) == 1 {
+		readerConfig.Topic = topics[0]
+	}
+	return readerConfig
+}
 
-	return &KafkaConsumerService{
-		config:            config.Kafka,
-		reader:            reader,
-		logger:            config.Logger,
-		metrics:           config.Metrics,
-		resilienceManager: config.ResilienceManager,
-		tracingProvider:   config.TracingProvider,
-		messageHandler:    config.MessageHandler,
-		stopCh:            make(chan struct{}),
-		doneCh:            make(chan struct{}),
+// newConsumerDeserializer returns the deserializer for the configured message format,
+// renaming fields first when a field mapping is configured, and falls back to JSON when
+// the format or mapping is not supported
+func newConsumerDeserializer(config KafkaConsumerConfig) Deserializer {
+	if len(config.Kafka.FieldMapping) > 0 && config.Kafka.MessageFormat == MessageFormatJSON {
+		deserializer, err := NewMappedJSONDeserializer(config.Kafka.FieldMapping)
+		if err == nil {
+			return deserializer
+		}
+		config.Logger.Warn("Ignoring invalid fill field mapping",
+			zap.Any("field_mapping", config.Kafka.FieldMapping),
+			zap.Error(err),
+		)
+	}
+
+	deserializer, err := NewDeserializer(config.Kafka.MessageFormat)
+	if err != nil {
+		config.Logger.Warn("Falling back to JSON fill messages",
+			zap.String("message_format", config.Kafka.MessageFormat),
+			zap.Error(err),
+		)
+		return JSONDeserializer{}
 	}
+	return deserializer
 }
 
 // Start starts the Kafka consumer
@@ -108,7 +293,7 @@ func (kcs *KafkaConsumerService) Start(ctx context.Context) error {
 
 	kcs.logger.WithContext(ctx).Info("Starting Kafka consumer",
 		zap.Strings("brokers", kcs.config.Brokers),
-		zap.String("topic", kcs.config.Topic),
+		zap.Strings("topics", kcs.topics),
 		zap.String("consumer_group", kcs.config.ConsumerGroup),
 	)
 
@@ -117,41 +302,111 @@ func (kcs *KafkaConsumerService) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to connect to Kafka: %w", err)
 	}
 
-	kcs.isRunning = true
-	kcs.wg.Add(1)
-	go kcs.consumeLoop(ctx)
+	if kcs.reader == nil {
+		// Falling back to the start offset beats not consuming when the seek fails,
+		// e.g. because another instance already joined the group
+		if err := kcs.seekToStartTimestamp(ctx); err != nil {
+			kcs.logger.WithContext(ctx).Warn("Failed to seek to the Kafka start timestamp, using the start offset",
+				zap.Time("start_timestamp", kcs.startTimestamp),
+				zap.String("start_offset", kcs.config.StartOffset),
+				zap.Error(err),
+			)
+		}
+		kcs.reader = kafka.NewReader(kcs.readerConfig)
+	}
+
+	kcs.startConsuming(ctx)
 
 	kcs.logger.WithContext(ctx).Info("Kafka consumer started successfully")
 	return nil
 }
 
-// Stop stops the Kafka consumer
+// startConsuming launches the consume loop; the caller must hold the mutex
+func (kcs *KafkaConsumerService) startConsuming(ctx context.Context) {
+	loopCtx, loopCancel := context.WithCancel(ctx)
+	kcs.loopCancel = loopCancel
+	kcs.isRunning = true
+	kcs.wg.Add(2)
+	go kcs.consumeLoop(loopCtx)
+	go kcs.readerStatsLoop(loopCtx)
+
+	if kcs.workerPoolEnabled() {
+		kcs.startWorkers(loopCtx)
+	}
+
+	if kcs.config.LagPollInterval > 0 {
+		kcs.wg.Add(1)
+		go kcs.lagLoop(loopCtx)
+	}
+
+	if kcs.commitBatchingEnabled() && kcs.config.CommitInterval > 0 {
+		kcs.wg.Add(1)
+		go kcs.commitLoop(loopCtx)
+	}
+}
+
+// Stop stops the Kafka consumer. Fetching stops immediately; a message that is
+// already being processed is allowed to finish until the drain timeout or the
+// deadline of ctx, whichever comes first, after which it is abandoned.
 func (kcs *KafkaConsumerService) Stop(ctx context.Context) error {
 	kcs.mutex.Lock()
-	defer kcs.mutex.Unlock()
-
 	if !kcs.isRunning {
+		kcs.mutex.Unlock()
 		return nil
 	}
+	kcs.isRunning = false
+	kcs.mutex.Unlock()
 
-	kcs.logger.WithContext(ctx).Info("Stopping Kafka consumer")
+	inFlight := atomic.LoadInt32(&kcs.inFlight)
+	kcs.logger.WithContext(ctx).Info("Stopping Kafka consumer",
+		zap.Int32("in_flight_messages", inFlight),
+		zap.Duration("drain_timeout", kcs.drainTimeout),
+	)
 
-	// Signal stop
+	// Signal stop and interrupt any pending fetch
 	close(kcs.stopCh)
+	kcs.loopCancel()
+
+	drainCtx, cancel := context.WithTimeout(ctx, kcs.drainTimeout)
+	defer cancel()
+
+	loopDone := make(chan struct{})
+	go func() {
+		kcs.wg.Wait()
+		close(loopDone)
+	}()
+
+	// Wait for the in-flight message to finish, abandoning it at the drain deadline
+	select {
+	case <-loopDone:
+	case <-drainCtx.Done():
+		kcs.logger.WithContext(ctx).Warn("Drain deadline exceeded, abandoning in-flight message",
+			zap.Int32("in_flight_messages", atomic.LoadInt32(&kcs.inFlight)),
+		)
+		kcs.abandon()
+		<-loopDone
+	}
+	kcs.abandon()
 
-	// Wait for consumer loop to finish
-	kcs.wg.Wait()
+	// Commit the messages handled since the last batch commit
+	kcs.flushCommits(ctx)
 
-	// Close reader
+	// Export the reader counters gathered since the last collection, then close the reader
+	kcs.collectReaderStats()
 	if err := kcs.reader.Close(); err != nil {
 		kcs.logger.WithContext(ctx).Warn("Error closing Kafka reader", zap.Error(err))
 	}
 
-	kcs.isRunning = false
 	close(kcs.doneCh)
 
+	kcs.mutex.RLock()
+	messageCount := kcs.messageCount
+	kcs.mutex.RUnlock()
+
 	kcs.logger.WithContext(ctx).Info("Kafka consumer stopped",
-		zap.Int64("total_messages_processed", kcs.messageCount),
+		zap.Int64("total_messages_processed", messageCount),
+		zap.Int64("drained_messages", atomic.LoadInt64(&kcs.drainedCount)),
+		zap.Int64("abandoned_messages", atomic.LoadInt64(&kcs.abandonedCount)),
 	)
 
 	return nil
@@ -178,35 +433,124 @@ func (kcs *KafkaConsumerService) IsHealthy(ctx context.Context) bool {
 	return kcs.testConnection(ctx) == nil
 }
 
+// IsRunning reports whether the consumer loop has been started and not stopped
+func (kcs *KafkaConsumerService) IsRunning() bool {
+	kcs.mutex.RLock()
+	defer kcs.mutex.RUnlock()
+
+	return kcs.isRunning
+}
+
 // GetStats returns consumer statistics
 func (kcs *KafkaConsumerService) GetStats() map[string]interface{} {
 	kcs.mutex.RLock()
 	defer kcs.mutex.RUnlock()
 
+	topicCounts := make(map[string]int64, len(kcs.topicMessageCounts))
+	for topic, count := range kcs.topicMessageCounts {
+		topicCounts[topic] = count
+	}
+
 	stats := map[string]interface{}{
 		"is_running":     kcs.isRunning,
 		"message_count":  kcs.messageCount,
 		"last_message":   kcs.lastMessage,
 		"brokers":        kcs.config.Brokers,
 		"topic":          kcs.config.Topic,
+		"topics":         kcs.topics,
 		"consumer_group": kcs.config.ConsumerGroup,
+		"paused":         kcs.isPausedLocked(),
+		"pause_count":    kcs.pauseCount,
+
+		"paused_by_operator":     kcs.pausedByOperator,
+		"paused_by_backpressure": kcs.paused,
+
+		"redelivered_count":    atomic.LoadInt64(&kcs.redeliveredCount),
+		"poison_count":         atomic.LoadInt64(&kcs.poisonCount),
+		"topic_message_counts": topicCounts,
+		"worker_pool_size":     len(kcs.workerQueues),
 	}
 
-	// Add reader stats if available
-	if kcs.reader != nil {
-		readerStats := kcs.reader.Stats()
-		stats["reader_stats"] = map[string]interface{}{
-			"messages":   readerStats.Messages,
-			"bytes":      readerStats.Bytes,
-			"rebalances": readerStats.Rebalances,
-			"timeouts":   readerStats.Timeouts,
-			"errors":     readerStats.Errors,
-		}
+	if kcs.workerLimiter != nil {
+		stats["worker_concurrency"] = kcs.workerLimiter.GetStats()
+	}
+
+	// Reader counters are totals since start; calling Stats here would reset them
+	// before they are exported
+	partitionLag := make(map[string]int64, len(kcs.partitionLag))
+	for partition, lag := range kcs.partitionLag {
+		partitionLag[partition] = lag
+	}
+	stats["reader_stats"] = map[string]interface{}{
+		"messages":      kcs.readerTotals.Messages,
+		"bytes":         kcs.readerTotals.Bytes,
+		"rebalances":    kcs.readerTotals.Rebalances,
+		"timeouts":      kcs.readerTotals.Timeouts,
+		"errors":        kcs.readerTotals.Errors,
+		"lag":           kcs.totalLag,
+		"partition_lag": partitionLag,
 	}
 
 	return stats
 }
 
+// readerStatsLoop periodically exports the reader's counters until the consumer stops
+func (kcs *KafkaConsumerService) readerStatsLoop(ctx context.Context) {
+	defer kcs.wg.Done()
+
+	ticker := time.NewTicker(kcs.readerStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			kcs.collectReaderStats()
+		}
+	}
+}
+
+// collectReaderStats adds the reader's counters since the previous call, which is what
+// kafka-go's Stats returns, to the Prometheus counters and the running totals
+func (kcs *KafkaConsumerService) collectReaderStats() {
+	delta := kcs.reader.Stats()
+
+	kcs.metrics.RecordKafkaReaderStats(delta.Errors, delta.Timeouts, delta.Rebalances)
+
+	kcs.mutex.Lock()
+	kcs.readerTotals.Messages += delta.Messages
+	kcs.readerTotals.Bytes += delta.Bytes
+	kcs.readerTotals.Rebalances += delta.Rebalances
+	kcs.readerTotals.Timeouts += delta.Timeouts
+	kcs.readerTotals.Errors += delta.Errors
+	totalRebalances := kcs.readerTotals.Rebalances
+	fetchedPartitions := make([]string, 0, len(kcs.fetchedPartitions))
+	for partition := range kcs.fetchedPartitions {
+		fetchedPartitions = append(fetchedPartitions, partition)
+	}
+	kcs.fetchedPartitions = make(map[string]struct{})
+	kcs.mutex.Unlock()
+
+	if delta.Rebalances > 0 {
+		sort.Strings(fetchedPartitions)
+		kcs.logger.Info("Kafka consumer group rebalanced",
+			zap.String("consumer_group", kcs.config.ConsumerGroup),
+			zap.Int64("rebalances", delta.Rebalances),
+			zap.Int64("total_rebalances", totalRebalances),
+			zap.Strings("fetched_partitions", fetchedPartitions),
+		)
+	}
+
+	if delta.Errors > 0 {
+		kcs.logger.Warn("Kafka reader reported errors",
+			zap.Int64("errors", delta.Errors),
+			zap.Int64("timeouts", delta.Timeouts),
+			zap.Int64("rebalances", delta.Rebalances),
+		)
+	}
+}
+
 // consumeLoop is the main message consumption loop
 func (kcs *KafkaConsumerService) consumeLoop(ctx context.Context) {
 	defer kcs.wg.Done()
@@ -225,6 +569,17 @@ func (kcs *KafkaConsumerService) consumeLoop(ctx context.Context) {
 			kcs.logger.WithContext(ctx).Info("Kafka consumer loop cancelled")
 			return
 		default:
+			if kcs.updateBackpressure(ctx) || kcs.IsPausedByOperator() {
+				// Wait for the dead letter queue to drain or an operator to resume
+				// consumption before fetching more
+				select {
+				case <-kcs.stopCh:
+				case <-ctx.Done():
+				case <-time.After(kcs.pausePollInterval):
+				}
+				continue
+			}
+
 			if err := kcs.processMessage(ctx); err != nil {
 				kcs.logger.WithContext(ctx).Error("Error processing message", zap.Error(err))
 				// Continue processing other messages
@@ -233,8 +588,111 @@ func (kcs *KafkaConsumerService) consumeLoop(ctx context.Context) {
 	}
 }
 
+// updateBackpressure pauses consumption once the dead letter queue reaches the
+// high-water mark and resumes it when the queue drops below the low-water mark.
+// It returns whether consumption is paused.
+func (kcs *KafkaConsumerService) updateBackpressure(ctx context.Context) bool {
+	if kcs.config.DLQPauseHighWaterMark <= 0 || kcs.resilienceManager == nil {
+		return false
+	}
+
+	dlqSize := kcs.resilienceManager.GetDeadLetterQueueStats().CurrentSize
+
+	kcs.mutex.Lock()
+	defer kcs.mutex.Unlock()
+
+	wasPaused := kcs.isPausedLocked()
+
+	switch {
+	case !kcs.paused && dlqSize >= kcs.config.DLQPauseHighWaterMark:
+		kcs.paused = true
+		kcs.pauseCount++
+		kcs.logger.WithContext(ctx).Warn("Pausing Kafka consumption, dead letter queue reached high-water mark",
+			zap.Int("dlq_size", dlqSize),
+			zap.Int("high_water_mark", kcs.config.DLQPauseHighWaterMark),
+		)
+	case kcs.paused && dlqSize < kcs.config.DLQResumeLowWaterMark:
+		kcs.paused = false
+		kcs.logger.WithContext(ctx).Info("Resuming Kafka consumption, dead letter queue dropped below low-water mark",
+			zap.Int("dlq_size", dlqSize),
+			zap.Int("low_water_mark", kcs.config.DLQResumeLowWaterMark),
+		)
+	default:
+		return kcs.paused
+	}
+
+	if kcs.metrics != nil && wasPaused != kcs.isPausedLocked() {
+		kcs.metrics.SetKafkaConsumerPaused(kcs.isPausedLocked())
+	}
+
+	return kcs.paused
+}
+
+// Pause stops fetching messages until Resume is called. A message already being
+// fetched or processed still completes.
+func (kcs *KafkaConsumerService) Pause(ctx context.Context) {
+	kcs.mutex.Lock()
+	defer kcs.mutex.Unlock()
+
+	if kcs.pausedByOperator {
+		return
+	}
+
+	wasPaused := kcs.isPausedLocked()
+	kcs.pausedByOperator = true
+	kcs.logger.WithContext(ctx).Warn("Pausing Kafka consumption by operator request")
+
+	if kcs.metrics != nil && !wasPaused {
+		kcs.metrics.SetKafkaConsumerPaused(true)
+	}
+}
+
+// Resume lifts an operator pause. Consumption stays paused while dead letter queue
+// backpressure applies.
+func (kcs *KafkaConsumerService) Resume(ctx context.Context) {
+	kcs.mutex.Lock()
+	defer kcs.mutex.Unlock()
+
+	if !kcs.pausedByOperator {
+		return
+	}
+
+	kcs.pausedByOperator = false
+	kcs.logger.WithContext(ctx).Info("Resuming Kafka consumption by operator request",
+		zap.Bool("paused_by_backpressure", kcs.paused),
+	)
+
+	if kcs.metrics != nil && !kcs.paused {
+		kcs.metrics.SetKafkaConsumerPaused(false)
+	}
+}
+
+// IsPaused reports whether consumption is paused, by an operator or by dead letter
+// queue backpressure
+func (kcs *KafkaConsumerService) IsPaused() bool {
+	kcs.mutex.RLock()
+	defer kcs.mutex.RUnlock()
+
+	return kcs.isPausedLocked()
+}
+
+// IsPausedByOperator reports whether consumption is paused through the admin API
+func (kcs *KafkaConsumerService) IsPausedByOperator() bool {
+	kcs.mutex.RLock()
+	defer kcs.mutex.RUnlock()
+
+	return kcs.pausedByOperator
+}
+
+// isPausedLocked must be called with the mutex held
+func (kcs *KafkaConsumerService) isPausedLocked() bool {
+	return kcs.paused || kcs.pausedByOperator
+}
+
 // processMessage processes a single Kafka message
 func (kcs *KafkaConsumerService) processMessage(ctx context.Context) error {
+	loopCtx := ctx
+
 	// Set timeout for message fetch
 	fetchCtx, cancel := context.WithTimeout(ctx, kcs.config.FetchTimeout)
 	defer cancel()
@@ -243,33 +701,171 @@ func (kcs *KafkaConsumerService) processMessage(ctx context.Context) error {
 	return kcs.resilienceManager.ExecuteKafkaOperation(
 		fetchCtx,
 		"consume_message",
-		kcs.config.Topic,
+		strings.Join(kcs.topics, ","),
 		-1, // Partition unknown at this point
 		-1, // Offset unknown at this point
 		func(ctx context.Context) error {
 			message, err := kcs.reader.FetchMessage(ctx)
 			if err != nil {
-				if err == context.DeadlineExceeded {
-					// Timeout is expected, not an error
+				if err == context.DeadlineExceeded || ctx.Err() != nil {
+					// Timeout or stop is expected, not an error
 					return nil
 				}
 				return fmt.Errorf("failed to fetch message: %w", err)
 			}
 
-			// Process the message
-			return kcs.handleMessage(ctx, message)
+			// Hand the message to the worker pool, which has its own deadlines
+			if kcs.workerPoolEnabled() {
+				kcs.dispatchMessage(loopCtx, message)
+				return nil
+			}
+
+			// Process the message, letting it drain past Stop
+			return kcs.handleInFlightMessage(ctx, message)
 		},
 	)
 }
 
+// handleInFlightMessage handles a fetched message in a context that survives Stop
+// and is only cancelled when the drain deadline passes
+func (kcs *KafkaConsumerService) handleInFlightMessage(ctx context.Context, message kafka.Message) error {
+	kcs.mutex.Lock()
+	if kcs.fetchedPartitions == nil {
+		kcs.fetchedPartitions = make(map[string]struct{})
+	}
+	kcs.fetchedPartitions[deliveryPartition(message)] = struct{}{}
+	kcs.mutex.Unlock()
+
+	atomic.AddInt32(&kcs.inFlight, 1)
+	defer atomic.AddInt32(&kcs.inFlight, -1)
+
+	// Each retry gets as long as the first attempt had
+	var attemptTimeout time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		attemptTimeout = time.Until(deadline)
+	}
+
+	inFlightCtx, cancel := kcs.inFlightContext(ctx)
+	defer cancel()
+
+	err := kcs.handleMessage(inFlightCtx, message)
+	kcs.trackDelivery(message, err)
+
+	// kafka-go does not redeliver an uncommitted message until its partition is
+	// reassigned, so a message that needs another attempt gets it here, holding back
+	// the messages behind it
+	for err != nil && kcs.shouldRetryInPlace(inFlightCtx, message, err) && kcs.waitToRetry(inFlightCtx) {
+		retryCtx, cancelRetry := kcs.retryContext(ctx, attemptTimeout)
+		err = kcs.handleMessage(retryCtx, message)
+		kcs.trackDelivery(message, err)
+		cancelRetry()
+	}
+
+	if err != nil && kcs.isPoisonMessage(inFlightCtx, message, err) {
+		err = kcs.skipPoisonMessage(inFlightCtx, message, err)
+	}
+
+	// Account for messages that were in flight when Stop was called
+	select {
+	case <-kcs.stopCh:
+		if kcs.abandonCtx.Err() != nil {
+			atomic.AddInt64(&kcs.abandonedCount, 1)
+		} else {
+			atomic.AddInt64(&kcs.drainedCount, 1)
+		}
+	default:
+	}
+
+	return err
+}
+
+// inFlightContext keeps the values and deadline of ctx but replaces its
+// cancellation with the consumer's abandon signal
+func (kcs *KafkaConsumerService) inFlightContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	inFlightCtx := context.WithoutCancel(ctx)
+
+	var cancel context.CancelFunc
+	if deadline, ok := ctx.Deadline(); ok {
+		inFlightCtx, cancel = context.WithDeadline(inFlightCtx, deadline)
+	} else {
+		inFlightCtx, cancel = context.WithCancel(inFlightCtx)
+	}
+
+	stopAbandon := context.AfterFunc(kcs.abandonCtx, cancel)
+	return inFlightCtx, func() {
+		stopAbandon()
+		cancel()
+	}
+}
+
+// retryContext returns an in-flight context for handling a message again, with a
+// fresh deadline of attemptTimeout (none when zero)
+func (kcs *KafkaConsumerService) retryContext(ctx context.Context, attemptTimeout time.Duration) (context.Context, context.CancelFunc) {
+	retryCtx := context.WithoutCancel(ctx)
+	if attemptTimeout <= 0 {
+		return kcs.inFlightContext(retryCtx)
+	}
+
+	retryCtx, cancelTimeout := context.WithTimeout(retryCtx, attemptTimeout)
+	inFlightCtx, cancel := kcs.inFlightContext(retryCtx)
+	return inFlightCtx, func() {
+		cancel()
+		cancelTimeout()
+	}
+}
+
+// shouldRetryInPlace reports whether a failed message is handled again before the
+// consumer moves on: fills waiting on a required allocation post until it succeeds,
+// and other non-transient failures until they use up their processing attempts
+func (kcs *KafkaConsumerService) shouldRetryInPlace(ctx context.Context, message kafka.Message, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var pending *allocationPendingError
+	if errors.As(err, &pending) {
+		return true
+	}
+
+	return kcs.config.MaxProcessingAttempts > 0 && !isTransientFailure(err) && !kcs.isPoisonMessage(ctx, message, err)
+}
+
+// waitToRetry waits before a failed message is handled again. It returns false when
+// the consumer stops first, leaving the message uncommitted for redelivery.
+func (kcs *KafkaConsumerService) waitToRetry(ctx context.Context) bool {
+	select {
+	case <-kcs.stopCh:
+		return false
+	case <-ctx.Done():
+		return false
+	case <-time.After(kcs.retryInterval):
+		return true
+	}
+}
+
 // handleMessage handles a single Kafka message
 func (kcs *KafkaConsumerService) handleMessage(ctx context.Context, message kafka.Message) error {
 	startTime := time.Now()
 
-	// Generate correlation ID for this message
-	correlationID := logger.GenerateCorrelationID()
+	// Reuse the producer's correlation ID when present, otherwise generate one
+	correlationID := getHeaderValue(message.Headers, kcs.correlationIDHeader())
+	if correlationID != "" {
+		kcs.metrics.RecordCorrelationIDInherited()
+	} else {
+		correlationID = logger.GenerateCorrelationID()
+		kcs.metrics.RecordCorrelationIDGenerated()
+	}
 	ctx = logger.WithCorrelationIDContext(ctx, correlationID)
 
+	priorFailures := kcs.priorDeliveryFailures(message)
+	if priorFailures > 0 {
+		kcs.metrics.RecordMessageRedelivered()
+		atomic.AddInt64(&kcs.redeliveredCount, 1)
+	}
+
+	// Continue the producer's trace when the message carries W3C trace context
+	ctx = otel.GetTextMapPropagator().Extract(ctx, (*kafkaHeaderCarrier)(&message.Headers))
+
 	// Start tracing span
 	var span interface{}
 	if kcs.tracingProvider != nil {
@@ -286,18 +882,30 @@ func (kcs *KafkaConsumerService) handleMessage(ctx context.Context, message kafk
 		}()
 	}
 
+	kcs.metrics.RecordMessageSize(len(message.Value))
+
 	kcs.logger.WithContext(ctx).Debug("Processing Kafka message",
 		zap.String("topic", message.Topic),
 		zap.Int("partition", message.Partition),
 		zap.Int64("offset", message.Offset),
 		zap.Int("message_size", len(message.Value)),
 	)
+	kcs.payloadLogger.log(ctx, "Raw Kafka message", "message_value", message.Value,
+		zap.String("topic", message.Topic),
+		zap.Int("partition", message.Partition),
+		zap.Int64("offset", message.Offset),
+	)
+
+	// Reject oversized messages before spending memory on decoding them
+	if kcs.config.MaxMessageSizeBytes > 0 && len(message.Value) > kcs.config.MaxMessageSizeBytes {
+		return kcs.rejectOversizedMessage(ctx, message)
+	}
 
 	// Parse the fill message
-	var fill domain.Fill
-	if err := json.Unmarshal(message.Value, &fill); err != nil {
+	fill, err := kcs.deserializer.Deserialize(message.Value)
+	if err != nil {
 		kcs.metrics.RecordMessageFailed()
-		return fmt.Errorf("failed to unmarshal fill message: %w", err)
+		return fmt.Errorf("failed to deserialize %s fill message: %w", kcs.deserializer.Format(), err)
 	}
 
 	// Validate the fill message
@@ -306,12 +914,15 @@ func (kcs *KafkaConsumerService) handleMessage(ctx context.Context, message kafk
 		return fmt.Errorf("invalid fill message: %w", err)
 	}
 
+	// Route the fill with its tenant's overrides
+	fill.TenantID = resolveTenantID(message.Headers, fill)
+
 	// Handle the message with resilience
-	err := kcs.resilienceManager.ExecuteWithResilience(
+	err = kcs.resilienceManager.ExecuteWithResilience(
 		ctx,
 		"handle_fill_message",
 		func(ctx context.Context) error {
-			return kcs.messageHandler.HandleFillMessage(ctx, &fill)
+			return kcs.messageHandler.HandleFillMessage(ctx, fill)
 		},
 		map[string]interface{}{
 			"topic":     message.Topic,
@@ -325,6 +936,7 @@ func (kcs *KafkaConsumerService) handleMessage(ctx context.Context, message kafk
 		kcs.metrics.RecordMessageFailed()
 		kcs.logger.WithContext(ctx).Error("Failed to handle fill message",
 			zap.Int64("fill_id", fill.ID),
+			zap.String("topic", message.Topic),
 			zap.Error(err),
 		)
 
@@ -333,35 +945,213 @@ func (kcs *KafkaConsumerService) handleMessage(ctx context.Context, message kafk
 	}
 
 	// Commit the message
-	if err := kcs.reader.CommitMessages(ctx, message); err != nil {
-		kcs.logger.WithContext(ctx).Error("Failed to commit message",
-			zap.Int("partition", message.Partition),
-			zap.Int64("offset", message.Offset),
-			zap.Error(err),
-		)
-		return fmt.Errorf("failed to commit message: %w", err)
+	if err := kcs.commitMessage(ctx, message); err != nil {
+		return err
 	}
 
 	// Update metrics and state
 	processingTime := time.Since(startTime)
 	kcs.metrics.RecordMessageProcessed()
 	kcs.metrics.RecordMessageProcessingTime(processingTime)
+	kcs.metrics.RecordMessageProcessingTimeBySize(len(message.Value), processingTime)
 
 	kcs.mutex.Lock()
 	kcs.messageCount++
+	if kcs.topicMessageCounts == nil {
+		kcs.topicMessageCounts = make(map[string]int64)
+	}
+	kcs.topicMessageCounts[message.Topic]++
 	kcs.lastMessage = time.Now()
 	kcs.mutex.Unlock()
 
 	kcs.logger.WithContext(ctx).Info("Successfully processed fill message",
 		zap.Int64("fill_id", fill.ID),
+		zap.String("topic", message.Topic),
 		zap.Int64("execution_service_id", fill.ExecutionServiceID),
 		zap.Duration("processing_time", processingTime),
 		zap.Int64("total_messages", kcs.messageCount),
 	)
 
+	if priorFailures > 0 {
+		kcs.logger.WithContext(ctx).Info("Fill message succeeded after redelivery",
+			zap.Int64("fill_id", fill.ID),
+			zap.Int("prior_failures", priorFailures),
+			zap.Int("partition", message.Partition),
+			zap.Int64("offset", message.Offset),
+		)
+	}
+
+	return nil
+}
+
+// rejectOversizedMessage dead-letters a message that exceeds the maximum size
+func (kcs *KafkaConsumerService) rejectOversizedMessage(ctx context.Context, message kafka.Message) error {
+	kcs.metrics.RecordOversizedMessage()
+	kcs.metrics.RecordMessageFailed()
+
+	kcs.logger.WithContext(ctx).Warn("Rejecting oversized Kafka message",
+		zap.String("topic", message.Topic),
+		zap.Int("partition", message.Partition),
+		zap.Int64("offset", message.Offset),
+		zap.Int("message_size", len(message.Value)),
+		zap.Int("max_message_size", kcs.config.MaxMessageSizeBytes),
+	)
+
+	// The value itself is not kept, only where to find it
+	sizeErr := fmt.Errorf("message size %d bytes exceeds maximum of %d bytes", len(message.Value), kcs.config.MaxMessageSizeBytes)
+	return kcs.deadLetterAndCommit(ctx, message, nil, "message too large", sizeErr, 1)
+}
+
+// isPoisonMessage reports whether a failed message has used up its processing attempts.
+// Transient failures (retryable errors, shutdown) never make a message poison, so an
+// outage downstream does not dead-letter healthy messages.
+func (kcs *KafkaConsumerService) isPoisonMessage(ctx context.Context, message kafka.Message, err error) bool {
+	if kcs.config.MaxProcessingAttempts <= 0 || ctx.Err() != nil || isTransientFailure(err) {
+		return false
+	}
+
+	kcs.mutex.RLock()
+	defer kcs.mutex.RUnlock()
+
+	return kcs.failedDeliveries[deliveryKey(message)] >= kcs.config.MaxProcessingAttempts
+}
+
+// isTransientFailure reports whether err is expected to clear up on its own
+func isTransientFailure(err error) bool {
+	var domainErr *domain.DomainError
+	return errors.As(err, &domainErr) && domainErr.IsRetryable()
+}
+
+// skipPoisonMessage dead-letters a message that keeps failing and commits it so the
+// partition can move past it
+func (kcs *KafkaConsumerService) skipPoisonMessage(ctx context.Context, message kafka.Message, cause error) error {
+	kcs.metrics.RecordPoisonMessage()
+	atomic.AddInt64(&kcs.poisonCount, 1)
+
+	kcs.logger.WithContext(ctx).Warn("Skipping poison Kafka message",
+		zap.String("topic", message.Topic),
+		zap.Int("partition", message.Partition),
+		zap.Int64("offset", message.Offset),
+		zap.Int("attempts", kcs.config.MaxProcessingAttempts),
+		zap.Error(cause),
+	)
+
+	if err := kcs.deadLetterAndCommit(ctx, message, string(message.Value), "poison message", cause, kcs.config.MaxProcessingAttempts); err != nil {
+		return err
+	}
+
+	kcs.trackDelivery(message, nil)
 	return nil
 }
 
+// deadLetterAndCommit adds a message the consumer gives up on to the dead letter queue
+// and commits it so the partition is not stuck redelivering it
+func (kcs *KafkaConsumerService) deadLetterAndCommit(ctx context.Context, message kafka.Message, originalMessage interface{}, reason string, cause error, attempts int) error {
+	if err := kcs.resilienceManager.AddToDeadLetterQueue(ctx, originalMessage, reason, []error{cause}, attempts, map[string]interface{}{
+		"topic":        message.Topic,
+		"partition":    message.Partition,
+		"offset":       message.Offset,
+		"message_size": len(message.Value),
+	}); err != nil {
+		kcs.logger.WithContext(ctx).Error("Failed to add message to dead letter queue",
+			zap.String("topic", message.Topic),
+			zap.Int64("offset", message.Offset),
+			zap.String("reason", reason),
+			zap.Error(err),
+		)
+	}
+
+	return kcs.commitMessage(ctx, message)
+}
+
+// priorDeliveryFailures returns how many times the message failed before this delivery,
+// preferring the producer's retry-count header over locally tracked failures
+func (kcs *KafkaConsumerService) priorDeliveryFailures(message kafka.Message) int {
+	if value := getHeaderValue(message.Headers, retryCountHeader); value != "" {
+		if retryCount, err := strconv.Atoi(value); err == nil && retryCount > 0 {
+			return retryCount
+		}
+	}
+
+	kcs.mutex.RLock()
+	defer kcs.mutex.RUnlock()
+
+	return kcs.failedDeliveries[deliveryKey(message)]
+}
+
+// trackDelivery remembers failed deliveries so a redelivery of the same offset can be recognized
+func (kcs *KafkaConsumerService) trackDelivery(message kafka.Message, err error) {
+	key := deliveryKey(message)
+
+	kcs.mutex.Lock()
+	defer kcs.mutex.Unlock()
+
+	if err == nil {
+		delete(kcs.failedDeliveries, key)
+		return
+	}
+
+	if _, tracked := kcs.failedDeliveries[key]; tracked || len(kcs.failedDeliveries) < maxTrackedFailedDeliveries {
+		kcs.failedDeliveries[key]++
+	}
+}
+
+// correlationIDHeader returns the message header carrying the producer's correlation ID
+func (kcs *KafkaConsumerService) correlationIDHeader() string {
+	if kcs.config.CorrelationIDHeader != "" {
+		return kcs.config.CorrelationIDHeader
+	}
+	return defaultCorrelationIDHeader
+}
+
+// deliveryPartition identifies the topic/partition a message was fetched from
+func deliveryPartition(message kafka.Message) string {
+	return message.Topic + "/" + strconv.Itoa(message.Partition)
+}
+
+// deliveryKey identifies a message by its position in the topic
+func deliveryKey(message kafka.Message) string {
+	return fmt.Sprintf("%s/%d/%d", message.Topic, message.Partition, message.Offset)
+}
+
+// getHeaderValue returns the value of the first header matching key (case-insensitive)
+func getHeaderValue(headers []kafka.Header, key string) string {
+	for _, header := range headers {
+		if strings.EqualFold(header.Key, key) {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// kafkaHeaderCarrier adapts Kafka message headers to an OpenTelemetry text map carrier
+type kafkaHeaderCarrier []kafka.Header
+
+// Get returns the value of the header matching key (case-insensitive)
+func (c *kafkaHeaderCarrier) Get(key string) string {
+	return getHeaderValue(*c, key)
+}
+
+// Set replaces the header matching key, or adds it
+func (c *kafkaHeaderCarrier) Set(key, value string) {
+	for i, header := range *c {
+		if strings.EqualFold(header.Key, key) {
+			(*c)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c = append(*c, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys returns the header keys
+func (c *kafkaHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c))
+	for _, header := range *c {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
+
 // testConnection tests the Kafka connection
 func (kcs *KafkaConsumerService) testConnection(ctx context.Context) error {
 	// Create a test context with timeout
@@ -375,14 +1165,16 @@ func (kcs *KafkaConsumerService) testConnection(ctx context.Context) error {
 	}
 	defer conn.Close()
 
-	// Test if topic exists
-	partitions, err := conn.ReadPartitions(kcs.config.Topic)
-	if err != nil {
-		return fmt.Errorf("failed to read topic partitions: %w", err)
-	}
+	// Test that every topic exists
+	for _, topic := range kcs.topics {
+		partitions, err := conn.ReadPartitions(topic)
+		if err != nil {
+			return fmt.Errorf("failed to read partitions for topic %s: %w", topic, err)
+		}
 
-	if len(partitions) == 0 {
-		return fmt.Errorf("topic %s has no partitions", kcs.config.Topic)
+		if len(partitions) == 0 {
+			return fmt.Errorf("topic %s has no partitions", topic)
+		}
 	}
 
 	return nil
@@ -0,0 +1,948 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// fakeKafkaReader serves queued messages and then blocks until the fetch context is done
+type fakeKafkaReader struct {
+	mutex     sync.Mutex
+	messages  []kafka.Message
+	committed []kafka.Message
+	closed    bool
+	stats     kafka.ReaderStats // Counters since the last Stats call, like kafka-go
+}
+
+func (r *fakeKafkaReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mutex.Lock()
+	if len(r.messages) > 0 {
+		message := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mutex.Unlock()
+		return message, nil
+	}
+	r.mutex.Unlock()
+
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeKafkaReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.committed = append(r.committed, msgs...)
+	return nil
+}
+
+func (r *fakeKafkaReader) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *fakeKafkaReader) Stats() kafka.ReaderStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	stats := r.stats
+	r.stats = kafka.ReaderStats{}
+	return stats
+}
+
+func (r *fakeKafkaReader) addStats(errors, timeouts, rebalances int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.stats.Errors += errors
+	r.stats.Timeouts += timeouts
+	r.stats.Rebalances += rebalances
+}
+
+func (r *fakeKafkaReader) committedCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.committed)
+}
+
+// slowMessageHandler signals when processing starts and honours context cancellation
+type slowMessageHandler struct {
+	delay   time.Duration
+	started chan struct{}
+	once    sync.Once
+}
+
+func (h *slowMessageHandler) HandleFillMessage(ctx context.Context, fill *domain.Fill) error {
+	h.once.Do(func() { close(h.started) })
+	select {
+	case <-time.After(h.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// recordingMessageHandler records the correlation ID of each handled message
+type recordingMessageHandler struct {
+	mutex          sync.Mutex
+	correlationIDs []string
+}
+
+func (h *recordingMessageHandler) HandleFillMessage(ctx context.Context, fill *domain.Fill) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.correlationIDs = append(h.correlationIDs, logger.GetCorrelationID(ctx))
+	return nil
+}
+
+func newTestFillMessage(t *testing.T, headers ...kafka.Header) kafka.Message {
+	now := float64(time.Now().Unix())
+	value, err := json.Marshal(&domain.Fill{
+		ID:                  1,
+		ExecutionServiceID:  2,
+		ExecutionStatus:     "FULL",
+		TradeType:           "BUY",
+		Destination:         "ML",
+		SecurityID:          "SEC123",
+		Ticker:              "IBM",
+		Quantity:            100,
+		ReceivedTimestamp:   now,
+		SentTimestamp:       now,
+		LastFilledTimestamp: now,
+		QuantityFilled:      100,
+		AveragePrice:        10,
+		NumberOfFills:       1,
+		TotalAmount:         1000,
+	})
+	require.NoError(t, err)
+
+	return kafka.Message{Topic: "fills", Value: value, Headers: headers}
+}
+
+func newTestKafkaConsumer(t *testing.T, handler MessageHandler, drainTimeout time.Duration, messages ...kafka.Message) (*KafkaConsumerService, *fakeKafkaReader) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	reader := &fakeKafkaReader{messages: messages}
+
+	consumer := NewKafkaConsumerService(KafkaConsumerConfig{
+		Kafka: config.KafkaConfig{
+			Brokers:      []string{"localhost:9092"},
+			Topic:        "fills",
+			FetchTimeout: 5 * time.Second,
+		},
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ResilienceManager: utils.NewResilienceManager(utils.GetDefaultResilienceConfig(), appLogger, appMetrics),
+		MessageHandler:    handler,
+		DrainTimeout:      drainTimeout,
+	})
+	consumer.reader.Close()
+	consumer.reader = reader
+
+	return consumer, reader
+}
+
+func TestKafkaConsumerService_Stop_DrainsInFlightMessage(t *testing.T) {
+	handler := &slowMessageHandler{delay: 200 * time.Millisecond, started: make(chan struct{})}
+	consumer, reader := newTestKafkaConsumer(t, handler, 2*time.Second, newTestFillMessage(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.mutex.Lock()
+	consumer.startConsuming(ctx)
+	consumer.mutex.Unlock()
+
+	<-handler.started
+
+	// Simulate application shutdown cancelling the root context mid-flight
+	cancel()
+	require.NoError(t, consumer.Stop(context.Background()))
+
+	assert.Equal(t, 1, reader.committedCount())
+	assert.Equal(t, int64(1), consumer.drainedCount)
+	assert.Equal(t, int64(0), consumer.abandonedCount)
+	assert.True(t, reader.closed)
+}
+
+func TestKafkaConsumerService_Stop_AbandonsAfterDrainTimeout(t *testing.T) {
+	handler := &slowMessageHandler{delay: 5 * time.Second, started: make(chan struct{})}
+	consumer, reader := newTestKafkaConsumer(t, handler, 100*time.Millisecond, newTestFillMessage(t))
+
+	consumer.mutex.Lock()
+	consumer.startConsuming(context.Background())
+	consumer.mutex.Unlock()
+
+	<-handler.started
+
+	start := time.Now()
+	require.NoError(t, consumer.Stop(context.Background()))
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Equal(t, 0, reader.committedCount())
+	assert.Equal(t, int64(0), consumer.drainedCount)
+	assert.Equal(t, int64(1), consumer.abandonedCount)
+}
+
+func TestKafkaConsumerService_HandleMessage_CorrelationIDMetrics(t *testing.T) {
+	tests := []struct {
+		name              string
+		headers           []kafka.Header
+		expectedGenerated float64
+		expectedInherited float64
+	}{
+		{
+			name:              "no correlation header",
+			expectedGenerated: 1,
+			expectedInherited: 0,
+		},
+		{
+			name:              "correlation header present",
+			headers:           []kafka.Header{{Key: "X-Correlation-ID", Value: []byte("producer-correlation-id")}},
+			expectedGenerated: 0,
+			expectedInherited: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &recordingMessageHandler{}
+			consumer, reader := newTestKafkaConsumer(t, handler, time.Second)
+
+			err := consumer.handleMessage(context.Background(), newTestFillMessage(t, tt.headers...))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedGenerated, testutil.ToFloat64(consumer.metrics.CorrelationIDGeneratedTotal))
+			assert.Equal(t, tt.expectedInherited, testutil.ToFloat64(consumer.metrics.CorrelationIDInheritedTotal))
+			assert.Equal(t, 1, reader.committedCount())
+
+			require.Len(t, handler.correlationIDs, 1)
+			if len(tt.headers) > 0 {
+				assert.Equal(t, "producer-correlation-id", handler.correlationIDs[0])
+			} else {
+				assert.NotEmpty(t, handler.correlationIDs[0])
+			}
+		})
+	}
+}
+
+// fillDeadLetterQueue adds n messages to the consumer's dead letter queue
+func fillDeadLetterQueue(t *testing.T, consumer *KafkaConsumerService, n int) {
+	for i := 0; i < n; i++ {
+		err := consumer.resilienceManager.AddToDeadLetterQueue(context.Background(), i, "execution service down", nil, 1, nil)
+		require.NoError(t, err)
+	}
+}
+
+// drainDeadLetterQueue removes n messages from the consumer's dead letter queue
+func drainDeadLetterQueue(consumer *KafkaConsumerService, n int) {
+	for _, message := range consumer.resilienceManager.GetDeadLetterMessages()[:n] {
+		consumer.resilienceManager.RemoveDeadLetterMessage(context.Background(), message.ID)
+	}
+}
+
+func TestKafkaConsumerService_UpdateBackpressure_Hysteresis(t *testing.T) {
+	consumer, _ := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+	consumer.config.DLQPauseHighWaterMark = 5
+	consumer.config.DLQResumeLowWaterMark = 2
+	ctx := context.Background()
+
+	fillDeadLetterQueue(t, consumer, 4)
+	assert.False(t, consumer.updateBackpressure(ctx))
+
+	// Crossing the high-water mark pauses consumption
+	fillDeadLetterQueue(t, consumer, 1)
+	assert.True(t, consumer.updateBackpressure(ctx))
+	assert.True(t, consumer.IsPaused())
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.KafkaConsumerPaused))
+
+	// Between the marks the consumer stays paused
+	drainDeadLetterQueue(consumer, 2)
+	assert.True(t, consumer.updateBackpressure(ctx))
+
+	// Dropping below the low-water mark resumes consumption
+	drainDeadLetterQueue(consumer, 2)
+	assert.False(t, consumer.updateBackpressure(ctx))
+	assert.False(t, consumer.IsPaused())
+	assert.Equal(t, 0.0, testutil.ToFloat64(consumer.metrics.KafkaConsumerPaused))
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.KafkaConsumerPauses))
+
+	stats := consumer.GetStats()
+	assert.Equal(t, false, stats["paused"])
+	assert.Equal(t, int64(1), stats["pause_count"])
+}
+
+func TestKafkaConsumerService_UpdateBackpressure_Disabled(t *testing.T) {
+	consumer, _ := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+
+	fillDeadLetterQueue(t, consumer, 10)
+	assert.False(t, consumer.updateBackpressure(context.Background()))
+}
+
+func TestKafkaConsumerService_ConsumeLoop_PausedStopsFetching(t *testing.T) {
+	handler := &recordingMessageHandler{}
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second, newTestFillMessage(t))
+	consumer.config.DLQPauseHighWaterMark = 2
+	consumer.config.DLQResumeLowWaterMark = 1
+	consumer.pausePollInterval = 10 * time.Millisecond
+	fillDeadLetterQueue(t, consumer, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	consumer.mutex.Lock()
+	consumer.startConsuming(ctx)
+	consumer.mutex.Unlock()
+
+	// The pending message is not fetched while paused
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, consumer.IsPaused())
+	reader.mutex.Lock()
+	assert.Len(t, reader.messages, 1)
+	reader.mutex.Unlock()
+
+	// Draining the dead letter queue resumes consumption
+	drainDeadLetterQueue(consumer, 2)
+	assert.Eventually(t, func() bool {
+		reader.mutex.Lock()
+		defer reader.mutex.Unlock()
+		return len(reader.committed) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, consumer.IsPaused())
+
+	require.NoError(t, consumer.Stop(context.Background()))
+}
+
+func TestKafkaConsumerService_PauseAndResume(t *testing.T) {
+	consumer, _ := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+	consumer.config.DLQPauseHighWaterMark = 2
+	consumer.config.DLQResumeLowWaterMark = 1
+	ctx := context.Background()
+
+	consumer.Pause(ctx)
+	assert.True(t, consumer.IsPaused())
+	assert.True(t, consumer.IsPausedByOperator())
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.KafkaConsumerPaused))
+
+	stats := consumer.GetStats()
+	assert.Equal(t, true, stats["paused"])
+	assert.Equal(t, true, stats["paused_by_operator"])
+	assert.Equal(t, false, stats["paused_by_backpressure"])
+
+	// Backpressure while already paused by an operator does not count as another pause
+	fillDeadLetterQueue(t, consumer, 2)
+	assert.True(t, consumer.updateBackpressure(ctx))
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.KafkaConsumerPauses))
+
+	// Resuming lifts only the operator pause
+	consumer.Resume(ctx)
+	assert.True(t, consumer.IsPaused())
+	assert.False(t, consumer.IsPausedByOperator())
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.KafkaConsumerPaused))
+
+	drainDeadLetterQueue(consumer, 2)
+	assert.False(t, consumer.updateBackpressure(ctx))
+	assert.False(t, consumer.IsPaused())
+	assert.Equal(t, 0.0, testutil.ToFloat64(consumer.metrics.KafkaConsumerPaused))
+
+	stats = consumer.GetStats()
+	assert.Equal(t, false, stats["paused"])
+	assert.Equal(t, false, stats["paused_by_operator"])
+}
+
+func TestKafkaConsumerService_ConsumeLoop_OperatorPauseStopsFetching(t *testing.T) {
+	consumer, reader := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second, newTestFillMessage(t))
+	consumer.pausePollInterval = 10 * time.Millisecond
+	consumer.Pause(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	consumer.mutex.Lock()
+	consumer.startConsuming(ctx)
+	consumer.mutex.Unlock()
+
+	// The pending message is not fetched while paused
+	time.Sleep(50 * time.Millisecond)
+	reader.mutex.Lock()
+	assert.Len(t, reader.messages, 1)
+	reader.mutex.Unlock()
+
+	consumer.Resume(context.Background())
+	assert.Eventually(t, func() bool {
+		return reader.committedCount() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, consumer.Stop(context.Background()))
+}
+
+func TestKafkaConsumerService_HandleMessage_CommitsCanarySkippedMessage(t *testing.T) {
+	// The test fill message targets execution 2, which is not allowlisted
+	service, mockExecClient := newCanaryTestService(t, []int64{99}, nil)
+	consumer, reader := newTestKafkaConsumer(t, service, time.Second, newTestFillMessage(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	consumer.mutex.Lock()
+	consumer.startConsuming(ctx)
+	consumer.mutex.Unlock()
+
+	assert.Eventually(t, func() bool { return reader.committedCount() == 1 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, consumer.Stop(context.Background()))
+
+	mockExecClient.AssertNotCalled(t, "GetExecution", mock.Anything, mock.Anything)
+	assert.Equal(t, 1.0, testutil.ToFloat64(service.metrics.MessagesSkippedCanaryTotal))
+}
+
+// toggleMessageHandler fails every message while fail is set
+type toggleMessageHandler struct {
+	fail atomic.Bool
+}
+
+func (h *toggleMessageHandler) HandleFillMessage(ctx context.Context, fill *domain.Fill) error {
+	if h.fail.Load() {
+		return domain.NewValidationError("rejected by test handler", "")
+	}
+	return nil
+}
+
+func TestKafkaConsumerService_HandleMessage_RetryCountHeader(t *testing.T) {
+	tests := []struct {
+		name                string
+		headers             []kafka.Header
+		expectedRedelivered float64
+	}{
+		{"no retry header", nil, 0},
+		{"retry count present", []kafka.Header{{Key: "X-Retry-Count", Value: []byte("2")}}, 1},
+		{"retry count zero", []kafka.Header{{Key: "x-retry-count", Value: []byte("0")}}, 0},
+		{"retry count malformed", []kafka.Header{{Key: "X-Retry-Count", Value: []byte("twice")}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consumer, reader := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+
+			err := consumer.handleMessage(context.Background(), newTestFillMessage(t, tt.headers...))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedRedelivered, testutil.ToFloat64(consumer.metrics.MessagesRedeliveredTotal))
+			assert.Equal(t, 1, reader.committedCount())
+		})
+	}
+}
+
+func TestKafkaConsumerService_HandleInFlightMessage_DetectsLocalRedelivery(t *testing.T) {
+	handler := &toggleMessageHandler{}
+	handler.fail.Store(true)
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second)
+
+	message := newTestFillMessage(t)
+	message.Offset = 42
+
+	require.Error(t, consumer.handleInFlightMessage(context.Background(), message))
+	assert.Equal(t, 0.0, testutil.ToFloat64(consumer.metrics.MessagesRedeliveredTotal))
+
+	// The same offset delivered again is counted as a redelivery
+	handler.fail.Store(false)
+	require.NoError(t, consumer.handleInFlightMessage(context.Background(), message))
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.MessagesRedeliveredTotal))
+	assert.Equal(t, 1, reader.committedCount())
+	assert.Equal(t, int64(1), consumer.GetStats()["redelivered_count"])
+
+	// Success forgets the failure
+	assert.Empty(t, consumer.failedDeliveries)
+}
+
+func TestKafkaConsumerService_GetStats_CountsMessagesPerTopic(t *testing.T) {
+	consumer, reader := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+
+	for _, topic := range []string{"fills", "fills-replay", "fills"} {
+		message := newTestFillMessage(t)
+		message.Topic = topic
+		require.NoError(t, consumer.handleMessage(context.Background(), message))
+	}
+
+	stats := consumer.GetStats()
+	assert.Equal(t, []string{"fills"}, stats["topics"])
+	assert.Equal(t, map[string]int64{"fills": 2, "fills-replay": 1}, stats["topic_message_counts"])
+	assert.Equal(t, int64(3), stats["message_count"])
+	assert.Equal(t, 3, reader.committedCount())
+}
+
+func TestKafkaConsumerService_HandleMessage_Protobuf(t *testing.T) {
+	handler := &recordingMessageHandler{}
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second)
+	consumer.deserializer = ProtobufDeserializer{}
+
+	message := kafka.Message{Topic: "fills", Value: encodeProtobufFill(newDeserializerTestFill())}
+	require.NoError(t, consumer.handleMessage(context.Background(), message))
+	assert.Equal(t, 1, reader.committedCount())
+
+	// JSON values are rejected once the consumer expects Protobuf
+	err := consumer.handleMessage(context.Background(), newTestFillMessage(t))
+	assert.ErrorContains(t, err, "failed to deserialize protobuf fill message")
+}
+
+func TestKafkaConsumerService_HandleMessage_RecordsMessageSize(t *testing.T) {
+	consumer, _ := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+
+	message := newTestFillMessage(t)
+	require.NoError(t, consumer.handleMessage(context.Background(), message))
+
+	// The fill message is under 1KiB, so processing time is recorded in the smallest class
+	assert.Equal(t, 1, testutil.CollectAndCount(consumer.metrics.MessageSizeBytes))
+	assert.Equal(t, 1, testutil.CollectAndCount(&consumer.metrics.MessageProcessingTimeBySizeClass, "test_message_processing_duration_by_size_seconds"))
+	assert.Equal(t, "lt_1024", metrics.MessageSizeClass(len(message.Value), metrics.DefaultMessageSizeClasses))
+}
+
+func TestKafkaConsumerService_HandleMessage_RejectsOversizedMessage(t *testing.T) {
+	handler := &recordingMessageHandler{}
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second)
+
+	message := newTestFillMessage(t)
+	consumer.config.MaxMessageSizeBytes = len(message.Value) - 1
+
+	require.NoError(t, consumer.handleMessage(context.Background(), message))
+
+	// The message is committed and dead-lettered without reaching the handler
+	assert.Equal(t, 1, reader.committedCount())
+	assert.Empty(t, handler.correlationIDs)
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.OversizedMessagesTotal))
+
+	deadLetters := consumer.resilienceManager.GetDeadLetterMessages()
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, "message too large", deadLetters[0].FailureReason)
+	assert.Equal(t, "fills", deadLetters[0].Topic)
+
+	// Messages at the limit are processed normally
+	consumer.config.MaxMessageSizeBytes = len(message.Value)
+	require.NoError(t, consumer.handleMessage(context.Background(), message))
+	assert.Len(t, handler.correlationIDs, 1)
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.OversizedMessagesTotal))
+}
+
+// failingMessageHandler fails every message with err
+type failingMessageHandler struct {
+	err error
+}
+
+func (h *failingMessageHandler) HandleFillMessage(ctx context.Context, fill *domain.Fill) error {
+	return h.err
+}
+
+// poisonDeadLetters returns the dead letters the consumer added for poison messages
+func poisonDeadLetters(consumer *KafkaConsumerService) []utils.DeadLetterMessage {
+	var poison []utils.DeadLetterMessage
+	for _, message := range consumer.resilienceManager.GetDeadLetterMessages() {
+		if message.FailureReason == "poison message" {
+			poison = append(poison, message)
+		}
+	}
+	return poison
+}
+
+func TestKafkaConsumerService_HandleInFlightMessage_SkipsPoisonMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler MessageHandler
+		message func(t *testing.T) kafka.Message
+	}{
+		{
+			name:    "undecodable message",
+			handler: &recordingMessageHandler{},
+			message: func(t *testing.T) kafka.Message {
+				return kafka.Message{Topic: "fills", Value: []byte("{not json")}
+			},
+		},
+		{
+			name:    "message rejected by handler",
+			handler: &failingMessageHandler{err: domain.NewValidationError("rejected by test handler", "")},
+			message: func(t *testing.T) kafka.Message {
+				return newTestFillMessage(t)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consumer, reader := newTestKafkaConsumer(t, tt.handler, time.Second)
+			consumer.config.MaxProcessingAttempts = 3
+			consumer.retryInterval = time.Millisecond
+
+			message := tt.message(t)
+			message.Offset = 7
+
+			// The message is retried in place until the last attempt dead-letters and commits it
+			require.NoError(t, consumer.handleInFlightMessage(context.Background(), message))
+			assert.Equal(t, 1, reader.committedCount())
+			assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.PoisonMessagesTotal))
+			assert.Equal(t, int64(1), consumer.GetStats()["poison_count"])
+			assert.Empty(t, consumer.failedDeliveries)
+
+			poison := poisonDeadLetters(consumer)
+			require.Len(t, poison, 1)
+			assert.Equal(t, int64(7), poison[0].Offset)
+			assert.Equal(t, 3, poison[0].AttemptCount)
+		})
+	}
+}
+
+func TestKafkaConsumerService_HandleInFlightMessage_TransientFailuresAreNotPoison(t *testing.T) {
+	handler := &failingMessageHandler{err: domain.NewCircuitBreakerError("execution-service")}
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second)
+	consumer.config.MaxProcessingAttempts = 2
+
+	// Skip the resilience manager's own retries
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 1
+	consumer.resilienceManager = utils.NewResilienceManager(resilienceConfig, consumer.logger, consumer.metrics)
+
+	message := newTestFillMessage(t)
+	for attempt := 0; attempt < 4; attempt++ {
+		require.Error(t, consumer.handleInFlightMessage(context.Background(), message))
+	}
+
+	assert.Equal(t, 0, reader.committedCount())
+	assert.Equal(t, 0.0, testutil.ToFloat64(consumer.metrics.PoisonMessagesTotal))
+	assert.Empty(t, poisonDeadLetters(consumer))
+}
+
+func TestKafkaConsumerService_CollectReaderStats_ExportsDeltas(t *testing.T) {
+	consumer, reader := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+
+	reader.addStats(2, 3, 1)
+	consumer.collectReaderStats()
+	assert.Equal(t, 2.0, testutil.ToFloat64(consumer.metrics.KafkaReaderErrorsTotal))
+	assert.Equal(t, 3.0, testutil.ToFloat64(consumer.metrics.KafkaReaderTimeoutsTotal))
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.KafkaReaderRebalancesTotal))
+
+	// Only what happened since the previous collection is added
+	reader.addStats(1, 0, 0)
+	consumer.collectReaderStats()
+	assert.Equal(t, 3.0, testutil.ToFloat64(consumer.metrics.KafkaReaderErrorsTotal))
+	assert.Equal(t, 3.0, testutil.ToFloat64(consumer.metrics.KafkaReaderTimeoutsTotal))
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.KafkaReaderRebalancesTotal))
+
+	// GetStats reports the running totals without consuming the reader's counters
+	readerStats := consumer.GetStats()["reader_stats"].(map[string]interface{})
+	assert.Equal(t, int64(3), readerStats["errors"])
+	assert.Equal(t, int64(3), readerStats["timeouts"])
+	assert.Equal(t, int64(1), readerStats["rebalances"])
+}
+
+func TestKafkaConsumerService_CollectReaderStats_LogsRebalances(t *testing.T) {
+	consumer, reader := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+	appLogger, logs := newObservedLogger()
+	consumer.logger = appLogger
+	consumer.config.ConsumerGroup = "confirmations"
+
+	// No rebalance since the previous collection
+	reader.addStats(0, 1, 0)
+	consumer.collectReaderStats()
+	assert.Equal(t, 0, logs.FilterMessage("Kafka consumer group rebalanced").Len())
+
+	for _, partition := range []int{2, 0, 2} {
+		consumer.mutex.Lock()
+		consumer.fetchedPartitions[deliveryPartition(kafka.Message{Topic: "fills", Partition: partition})] = struct{}{}
+		consumer.mutex.Unlock()
+	}
+	reader.addStats(0, 0, 2)
+	consumer.collectReaderStats()
+
+	rebalances := logs.FilterMessage("Kafka consumer group rebalanced").All()
+	require.Len(t, rebalances, 1)
+	fields := rebalances[0].ContextMap()
+	assert.Equal(t, "confirmations", fields["consumer_group"])
+	assert.Equal(t, int64(2), fields["rebalances"])
+	assert.Equal(t, int64(2), fields["total_rebalances"])
+	assert.Equal(t, []interface{}{"fills/0", "fills/2"}, fields["fetched_partitions"])
+	assert.Equal(t, 2.0, testutil.ToFloat64(consumer.metrics.KafkaReaderRebalancesTotal))
+
+	// Each rebalance is logged once, with the partitions fetched since the previous collection
+	reader.addStats(0, 0, 1)
+	consumer.collectReaderStats()
+	rebalances = logs.FilterMessage("Kafka consumer group rebalanced").All()
+	require.Len(t, rebalances, 2)
+	assert.Equal(t, int64(3), rebalances[1].ContextMap()["total_rebalances"])
+	assert.Equal(t, []interface{}{}, rebalances[1].ContextMap()["fetched_partitions"])
+}
+
+func TestKafkaConsumerService_ReaderStatsLoop_FollowsLifecycle(t *testing.T) {
+	consumer, reader := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+	consumer.readerStatsInterval = 10 * time.Millisecond
+
+	consumer.mutex.Lock()
+	consumer.startConsuming(context.Background())
+	consumer.mutex.Unlock()
+
+	reader.addStats(4, 0, 0)
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(consumer.metrics.KafkaReaderErrorsTotal) == 4
+	}, time.Second, 10*time.Millisecond)
+
+	// Counters gathered since the last tick are exported by Stop at the latest
+	reader.addStats(0, 2, 0)
+	require.NoError(t, consumer.Stop(context.Background()))
+	assert.Equal(t, 2.0, testutil.ToFloat64(consumer.metrics.KafkaReaderTimeoutsTotal))
+}
+
+// newAllocationRequiredTestConsumer wires a confirmation service whose Allocation
+// Service posts fail into a consumer, so commits reflect the allocation outcome
+func newAllocationRequiredTestConsumer(t *testing.T, required bool) (*KafkaConsumerService, *fakeKafkaReader, *MockResilienceManager) {
+	mockExecClient := &MockExecutionServiceClient{}
+	mockAllocClient := &MockAllocationServiceClient{}
+	mockResilience := &MockResilienceManager{}
+
+	mockExecClient.On("GetExecution", mock.Anything, int64(2)).Return(&domain.ExecutionResponse{
+		ID:              2,
+		ExecutionStatus: "PARTIAL",
+		TradeType:       "BUY",
+		Destination:     "ML",
+		SecurityID:      "SEC123",
+		Quantity:        100,
+		QuantityFilled:  50,
+		Version:         1,
+	}, nil)
+	mockExecClient.On("UpdateExecution", mock.Anything, int64(2), mock.AnythingOfType("*domain.ExecutionUpdateRequest")).Return(&domain.ExecutionUpdateResponse{ID: 2, Version: 2}, nil)
+	mockAllocClient.On("PostExecution", mock.Anything, mock.AnythingOfType("*domain.AllocationServiceExecutionDTO")).Return(errors.New("connection refused"))
+
+	consumer, reader := newTestKafkaConsumer(t, nil, time.Second)
+	consumer.messageHandler = NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:    mockExecClient,
+		AllocationClient:   mockAllocClient,
+		Logger:             consumer.logger,
+		Metrics:            consumer.metrics,
+		ResilienceManager:  mockResilience,
+		AllocationRequired: required,
+	})
+
+	// Skip the resilience manager's own retries
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 1
+	consumer.resilienceManager = utils.NewResilienceManager(resilienceConfig, consumer.logger, consumer.metrics)
+
+	return consumer, reader, mockResilience
+}
+
+func TestKafkaConsumerService_HandleMessage_AllocationRequired(t *testing.T) {
+	t.Run("strict mode leaves the offset uncommitted", func(t *testing.T) {
+		consumer, reader, mockResilience := newAllocationRequiredTestConsumer(t, true)
+
+		err := consumer.handleMessage(context.Background(), newTestFillMessage(t))
+		assert.ErrorContains(t, err, "Allocation Service")
+		assert.Equal(t, 0, reader.committedCount())
+		mockResilience.AssertNotCalled(t, "AddToDeadLetterQueue", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("strict mode retries the fill until the post succeeds", func(t *testing.T) {
+		consumer, reader, _ := newAllocationRequiredTestConsumer(t, true)
+		consumer.retryInterval = time.Millisecond
+
+		// Replace the failing post with one that succeeds on the third attempt
+		mockAllocClient := &MockAllocationServiceClient{}
+		mockAllocClient.On("PostExecution", mock.Anything, mock.AnythingOfType("*domain.AllocationServiceExecutionDTO")).Return(errors.New("connection refused")).Twice()
+		mockAllocClient.On("PostExecution", mock.Anything, mock.AnythingOfType("*domain.AllocationServiceExecutionDTO")).Return(nil).Once()
+		consumer.messageHandler.(*ConfirmationService).allocationClient = mockAllocClient
+
+		require.NoError(t, consumer.handleInFlightMessage(context.Background(), newTestFillMessage(t)))
+		assert.Equal(t, 1, reader.committedCount())
+		mockAllocClient.AssertNumberOfCalls(t, "PostExecution", 3)
+	})
+
+	t.Run("strict mode stops retrying when the consumer stops", func(t *testing.T) {
+		consumer, reader, _ := newAllocationRequiredTestConsumer(t, true)
+		consumer.retryInterval = time.Hour
+		close(consumer.stopCh)
+
+		assert.Error(t, consumer.handleInFlightMessage(context.Background(), newTestFillMessage(t)))
+		assert.Equal(t, 0, reader.committedCount())
+	})
+
+	t.Run("lenient mode commits and dead-letters the post", func(t *testing.T) {
+		consumer, reader, mockResilience := newAllocationRequiredTestConsumer(t, false)
+		mockResilience.On("AddToDeadLetterQueue", mock.Anything, mock.AnythingOfType("*domain.AllocationServiceExecutionDTO"), "allocation-service failure", mock.Anything, 1, mock.Anything).Return(nil).Once()
+
+		require.NoError(t, consumer.handleMessage(context.Background(), newTestFillMessage(t)))
+		assert.Equal(t, 1, reader.committedCount())
+		mockResilience.AssertExpectations(t)
+	})
+}
+
+// useTraceContextPropagator installs the W3C trace context propagator SetupOTel
+// configures, restoring the previous one when the test ends
+func useTraceContextPropagator(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+}
+
+// spanRecordingMessageHandler records the span active when each fill is handled
+type spanRecordingMessageHandler struct {
+	span oteltrace.Span
+}
+
+func (h *spanRecordingMessageHandler) HandleFillMessage(ctx context.Context, fill *domain.Fill) error {
+	h.span = oteltrace.SpanFromContext(ctx)
+	return nil
+}
+
+func TestKafkaConsumerService_HandleMessage_ContinuesProducerTrace(t *testing.T) {
+	useTraceContextPropagator(t)
+	tracingProvider, err := utils.NewTracingProvider(utils.TracingConfig{Enabled: true, ServiceName: "test", Exporter: "stdout"})
+	require.NoError(t, err)
+
+	handler := &spanRecordingMessageHandler{}
+	consumer, _ := newTestKafkaConsumer(t, handler, time.Second)
+	consumer.tracingProvider = tracingProvider
+
+	traceparent := kafka.Header{Key: "traceparent", Value: []byte("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")}
+	require.NoError(t, consumer.handleMessage(context.Background(), newTestFillMessage(t, traceparent)))
+
+	span, ok := handler.span.(sdktrace.ReadOnlySpan)
+	require.True(t, ok, "the handler runs inside the consumer span")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.Parent().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+}
+
+func TestKafkaConsumerService_HandleMessage_StartsNewTraceWithoutTraceparent(t *testing.T) {
+	useTraceContextPropagator(t)
+	tracingProvider, err := utils.NewTracingProvider(utils.TracingConfig{Enabled: true, ServiceName: "test", Exporter: "stdout"})
+	require.NoError(t, err)
+
+	handler := &spanRecordingMessageHandler{}
+	consumer, _ := newTestKafkaConsumer(t, handler, time.Second)
+	consumer.tracingProvider = tracingProvider
+
+	require.NoError(t, consumer.handleMessage(context.Background(), newTestFillMessage(t)))
+
+	span, ok := handler.span.(sdktrace.ReadOnlySpan)
+	require.True(t, ok)
+	assert.True(t, span.SpanContext().IsValid())
+	assert.False(t, span.Parent().IsValid())
+}
+
+func TestKafkaConsumerService_HandleMessage_PropagatesConfiguredCorrelationIDHeader(t *testing.T) {
+	// The Execution Service records the correlation ID of every request
+	correlationIDs := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationIDs <- r.Header.Get("X-Correlation-ID")
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			json.NewEncoder(w).Encode(domain.ExecutionUpdateResponse{ID: 2, ExecutionStatus: "FULL", Version: 2})
+			return
+		}
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{
+			ID:              2,
+			ExecutionStatus: "PART",
+			TradeType:       "BUY",
+			Destination:     "ML",
+			SecurityID:      "SEC123",
+			Quantity:        100,
+			QuantityFilled:  50,
+			Version:         1,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	consumer, reader := newTestKafkaConsumer(t, nil, time.Second)
+	consumer.config.CorrelationIDHeader = "X-Request-ID"
+	consumer.messageHandler = NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient: newTestExecutionServiceClient(t, config.ExecutionServiceConfig{BaseURL: server.URL, Timeout: time.Second}),
+		Logger:          consumer.logger,
+		Metrics:         consumer.metrics,
+	})
+
+	message := newTestFillMessage(t,
+		kafka.Header{Key: "X-Correlation-ID", Value: []byte("ignored-correlation-id")},
+		kafka.Header{Key: "X-Request-ID", Value: []byte("producer-request-id")},
+	)
+	require.NoError(t, consumer.handleMessage(context.Background(), message))
+	assert.Equal(t, 1, reader.committedCount())
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumer.metrics.CorrelationIDInheritedTotal))
+
+	// Both the version lookup and the update carry the producer's ID
+	close(correlationIDs)
+	var received []string
+	for correlationID := range correlationIDs {
+		received = append(received, correlationID)
+	}
+	assert.Equal(t, []string{"producer-request-id", "producer-request-id"}, received)
+}
+
+// validatingMessageHandler rejects fills its validation service finds invalid
+type validatingMessageHandler struct {
+	validation *ValidationService
+	handled    int32
+}
+
+func (h *validatingMessageHandler) HandleFillMessage(ctx context.Context, fill *domain.Fill) error {
+	if result := h.validation.ValidateFillMessage(ctx, fill); !result.IsValid {
+		return errors.New("fill validation failed")
+	}
+	atomic.AddInt32(&h.handled, 1)
+	return nil
+}
+
+func TestKafkaConsumerService_HandleMessage_ProcessesMisorderedFillWhenSeverityIsWarning(t *testing.T) {
+	handler := &validatingMessageHandler{}
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second)
+	handler.validation = NewValidationService(ValidationConfig{
+		Logger:                     consumer.logger,
+		SentBeforeReceivedSeverity: SeverityWarning,
+	})
+
+	now := float64(time.Now().Unix())
+	value, err := json.Marshal(&domain.Fill{
+		ID:                  1,
+		ExecutionServiceID:  2,
+		ExecutionStatus:     "FULL",
+		TradeType:           "BUY",
+		Destination:         "ML",
+		SecurityID:          "SEC123",
+		Ticker:              "IBM",
+		Quantity:            100,
+		ReceivedTimestamp:   now,
+		SentTimestamp:       now - 60, // Before received
+		LastFilledTimestamp: now,
+		QuantityFilled:      100,
+		AveragePrice:        10,
+		NumberOfFills:       1,
+		TotalAmount:         1000,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, consumer.handleMessage(context.Background(), kafka.Message{Topic: "fills", Value: value}))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&handler.handled))
+	assert.Equal(t, 1, reader.committedCount())
+}
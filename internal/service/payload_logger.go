@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// payloadLogger logs raw payloads at debug level when debug payload logging is
+// enabled. Payloads may contain sensitive data, so nothing is logged otherwise, and
+// the configured redact fields are masked in the payloads that are.
+type payloadLogger struct {
+	logger       *logger.Logger
+	enabled      bool
+	maxLength    int
+	redactFields []string
+	dataUtils    *utils.DataUtils
+}
+
+func newPayloadLogger(appLogger *logger.Logger, logging config.LoggingConfig) payloadLogger {
+	return payloadLogger{
+		logger:       appLogger,
+		enabled:      logging.DebugPayloadLogging && appLogger != nil,
+		maxLength:    logging.DebugPayloadMaxLength,
+		redactFields: logging.RedactFields,
+		dataUtils:    utils.NewDataUtils(),
+	}
+}
+
+// log logs the payload, truncated to the configured length, under the given key
+func (p payloadLogger) log(ctx context.Context, message, key string, payload []byte, fields ...zap.Field) {
+	if !p.enabled {
+		return
+	}
+
+	fields = append(fields, zap.String(key, p.dataUtils.TruncateString(p.redact(payload), p.maxLength)))
+	p.logger.WithContext(ctx).Debug(message, fields...)
+}
+
+// redact masks the redact fields of a JSON object payload. Other payloads, such as
+// protobuf messages, cannot be masked, so they are not logged while fields are redacted.
+func (p payloadLogger) redact(payload []byte) string {
+	if len(p.redactFields) == 0 {
+		return string(payload)
+	}
+
+	redacted, ok := logger.Redact(json.RawMessage(payload), p.redactFields).(map[string]interface{})
+	if !ok {
+		return logger.RedactedValue
+	}
+
+	encoded, err := json.Marshal(redacted)
+	if err != nil {
+		return logger.RedactedValue
+	}
+	return string(encoded)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newObservedLogger returns a debug-level logger whose entries are recorded
+func newObservedLogger() (*logger.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return &logger.Logger{Logger: zap.New(core)}, logs
+}
+
+func TestPayloadLogger(t *testing.T) {
+	ctx := context.Background()
+	payload := []byte(strings.Repeat("x", 20))
+
+	t.Run("disabled by default", func(t *testing.T) {
+		appLogger, logs := newObservedLogger()
+		newPayloadLogger(appLogger, config.GetDefaults().Logging).log(ctx, "Raw payload", "payload", payload)
+
+		assert.Equal(t, 0, logs.Len())
+	})
+
+	t.Run("enabled truncates payload", func(t *testing.T) {
+		appLogger, logs := newObservedLogger()
+		payloadLogger := newPayloadLogger(appLogger, config.LoggingConfig{DebugPayloadLogging: true, DebugPayloadMaxLength: 10})
+		payloadLogger.log(ctx, "Raw payload", "payload", payload, zap.Int64("execution_id", 1))
+
+		require.Equal(t, 1, logs.Len())
+		entry := logs.All()[0]
+		assert.Equal(t, zapcore.DebugLevel, entry.Level)
+		assert.Equal(t, "Raw payload", entry.Message)
+		assert.Equal(t, "xxxxxxx...", entry.ContextMap()["payload"])
+		assert.Equal(t, int64(1), entry.ContextMap()["execution_id"])
+	})
+
+	t.Run("masks redact fields", func(t *testing.T) {
+		appLogger, logs := newObservedLogger()
+		payloadLogger := newPayloadLogger(appLogger, config.LoggingConfig{
+			DebugPayloadLogging:   true,
+			DebugPayloadMaxLength: 4096,
+			RedactFields:          []string{"securityId"},
+		})
+		payloadLogger.log(ctx, "Raw payload", "payload", []byte(`{"id":1,"securityId":"SEC123"}`))
+
+		require.Equal(t, 1, logs.Len())
+		assert.JSONEq(t, `{"id":1,"securityId":"[REDACTED]"}`, logs.All()[0].ContextMap()["payload"].(string))
+	})
+
+	t.Run("does not log payloads it cannot mask", func(t *testing.T) {
+		appLogger, logs := newObservedLogger()
+		payloadLogger := newPayloadLogger(appLogger, config.LoggingConfig{
+			DebugPayloadLogging:   true,
+			DebugPayloadMaxLength: 4096,
+			RedactFields:          []string{"securityId"},
+		})
+		payloadLogger.log(ctx, "Raw payload", "payload", []byte("\x0a\x06SEC123"))
+
+		require.Equal(t, 1, logs.Len())
+		assert.Equal(t, logger.RedactedValue, logs.All()[0].ContextMap()["payload"])
+	})
+}
+
+func TestExecutionServiceClient_DebugPayloadLogging(t *testing.T) {
+	server := newSlowExecutionServer(t, 0)
+	appLogger, logs := newObservedLogger()
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 1
+
+	client := NewExecutionServiceClient(ExecutionServiceClientConfig{
+		ExecutionService:  config.ExecutionServiceConfig{BaseURL: server.URL, Timeout: time.Second},
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ResilienceManager: utils.NewResilienceManager(resilienceConfig, appLogger, appMetrics),
+		Logging:           config.LoggingConfig{DebugPayloadLogging: true, DebugPayloadMaxLength: 4096},
+	})
+
+	_, err := client.UpdateExecution(context.Background(), 1, &domain.ExecutionUpdateRequest{QuantityFilled: 100, AveragePrice: 10, Version: 1})
+	require.NoError(t, err)
+
+	requests := logs.FilterMessage("Raw execution service request").All()
+	require.Len(t, requests, 1)
+	assert.Contains(t, requests[0].ContextMap()["request_body"], `"quantityFilled":100`)
+
+	responses := logs.FilterMessage("Raw execution service response").All()
+	require.Len(t, responses, 1)
+	assert.Contains(t, responses[0].ContextMap()["response_body"], `"version":2`)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+)
+
+// defaultPendingAllocationBufferSize is used when no buffer size is configured
+const defaultPendingAllocationBufferSize = 1000
+
+// PendingAllocationStats represents pending allocation buffer statistics
+type PendingAllocationStats struct {
+	Size     int   `json:"size"`
+	MaxSize  int   `json:"max_size"`
+	Queued   int64 `json:"queued"`
+	Replayed int64 `json:"replayed"`
+	Dropped  int64 `json:"dropped"` // Sent to the dead letter queue because the buffer was full
+}
+
+// pendingAllocation is a deferred post and the Allocation Service it is replayed to
+type pendingAllocation struct {
+	target allocationTarget
+	dto    *domain.AllocationServiceExecutionDTO
+}
+
+// pendingAllocationBuffer holds Allocation Service posts deferred while their circuit is
+// open, in arrival order, until they can be replayed
+type pendingAllocationBuffer struct {
+	mutex   sync.Mutex
+	items   []pendingAllocation
+	maxSize int
+
+	queued   int64
+	replayed int64
+	dropped  int64
+}
+
+func newPendingAllocationBuffer(maxSize int) *pendingAllocationBuffer {
+	if maxSize <= 0 {
+		maxSize = defaultPendingAllocationBufferSize
+	}
+	return &pendingAllocationBuffer{maxSize: maxSize}
+}
+
+// add queues a deferred post, returning false when the buffer is full
+func (b *pendingAllocationBuffer) add(item pendingAllocation) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.items) >= b.maxSize {
+		b.dropped++
+		return false
+	}
+
+	b.items = append(b.items, item)
+	b.queued++
+	return true
+}
+
+// take removes and returns every queued post
+func (b *pendingAllocationBuffer) take() []pendingAllocation {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	items := b.items
+	b.items = nil
+	return items
+}
+
+// requeue puts posts that could not be replayed back ahead of any queued since they
+// were taken, returning those that no longer fit
+func (b *pendingAllocationBuffer) requeue(items []pendingAllocation) []pendingAllocation {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	merged := append(items, b.items...)
+	if len(merged) <= b.maxSize {
+		b.items = merged
+		return nil
+	}
+
+	b.items = merged[:b.maxSize]
+	overflow := merged[b.maxSize:]
+	b.dropped += int64(len(overflow))
+	return overflow
+}
+
+// recordReplayed counts a post that was replayed successfully
+func (b *pendingAllocationBuffer) recordReplayed() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.replayed++
+}
+
+// stats returns pending allocation buffer statistics
+func (b *pendingAllocationBuffer) stats() PendingAllocationStats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return PendingAllocationStats{
+		Size:     len(b.items),
+		MaxSize:  b.maxSize,
+		Queued:   b.queued,
+		Replayed: b.replayed,
+		Dropped:  b.dropped,
+	}
+}
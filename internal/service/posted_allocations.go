@@ -0,0 +1,112 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+)
+
+// defaultPostedAllocationMaxEntries is used when no maximum is configured
+const defaultPostedAllocationMaxEntries = 10000
+
+// PostedAllocationStats represents posted allocation tracking statistics
+type PostedAllocationStats struct {
+	Size      int    `json:"size"`
+	Retention string `json:"retention"`
+	Skipped   int64  `json:"skipped"` // Posts skipped because the key was already posted
+}
+
+// postedAllocation is an idempotency key and when it was posted
+type postedAllocation struct {
+	key      string
+	postedAt time.Time
+}
+
+// postedAllocationSet remembers the idempotency keys of successful Allocation Service
+// posts for the retention period, so a reprocessed fill is not posted again. Keys are
+// kept in posting order, so the oldest are evicted first when the set is full.
+type postedAllocationSet struct {
+	mutex      sync.Mutex
+	retention  time.Duration
+	maxEntries int
+	clock      utils.Clock
+	keys       map[string]*list.Element
+	order      *list.List // postedAllocation values, oldest first
+
+	skipped int64
+}
+
+func newPostedAllocationSet(retention time.Duration, maxEntries int, clock utils.Clock) *postedAllocationSet {
+	if maxEntries <= 0 {
+		maxEntries = defaultPostedAllocationMaxEntries
+	}
+	if clock == nil {
+		clock = utils.RealClock{}
+	}
+	return &postedAllocationSet{
+		retention:  retention,
+		maxEntries: maxEntries,
+		clock:      clock,
+		keys:       make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// contains reports whether the key was posted within the retention period, counting
+// the post it saves
+func (s *postedAllocationSet) contains(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.expire()
+	if _, ok := s.keys[key]; !ok {
+		return false
+	}
+	s.skipped++
+	return true
+}
+
+// record remembers a successfully posted key
+func (s *postedAllocationSet) record(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.keys[key]; ok {
+		s.order.Remove(element)
+	}
+	s.keys[key] = s.order.PushBack(postedAllocation{key: key, postedAt: s.clock.Now()})
+
+	s.expire()
+	for s.order.Len() > s.maxEntries {
+		s.removeOldest()
+	}
+}
+
+// expire drops keys posted longer ago than the retention period; the caller holds the mutex
+func (s *postedAllocationSet) expire() {
+	cutoff := s.clock.Now().Add(-s.retention)
+	for s.order.Len() > 0 && !s.order.Front().Value.(postedAllocation).postedAt.After(cutoff) {
+		s.removeOldest()
+	}
+}
+
+// removeOldest drops the oldest key; the caller holds the mutex
+func (s *postedAllocationSet) removeOldest() {
+	oldest := s.order.Remove(s.order.Front()).(postedAllocation)
+	delete(s.keys, oldest.key)
+}
+
+// stats returns posted allocation tracking statistics
+func (s *postedAllocationSet) stats() PostedAllocationStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.expire()
+	return PostedAllocationStats{
+		Size:      s.order.Len(),
+		Retention: s.retention.String(),
+		Skipped:   s.skipped,
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostedAllocationSet_ExpiresAfterRetention(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	posted := newPostedAllocationSet(time.Hour, 10, clock)
+
+	assert.False(t, posted.contains("fill-1-exec-2-v1"))
+	posted.record("fill-1-exec-2-v1")
+
+	clock.Advance(59 * time.Minute)
+	assert.True(t, posted.contains("fill-1-exec-2-v1"))
+
+	clock.Advance(time.Minute)
+	assert.False(t, posted.contains("fill-1-exec-2-v1"))
+
+	stats := posted.stats()
+	assert.Equal(t, 0, stats.Size)
+	assert.Equal(t, int64(1), stats.Skipped)
+	assert.Equal(t, "1h0m0s", stats.Retention)
+}
+
+func TestPostedAllocationSet_EvictsOldestWhenFull(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	posted := newPostedAllocationSet(time.Hour, 2, clock)
+
+	posted.record("a")
+	clock.Advance(time.Second)
+	posted.record("b")
+	clock.Advance(time.Second)
+	posted.record("a") // Posting again makes it the newest
+	posted.record("c")
+
+	assert.False(t, posted.contains("b"))
+	assert.True(t, posted.contains("a"))
+	assert.True(t, posted.contains("c"))
+	assert.Equal(t, 2, posted.stats().Size)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Defaults for the price anomaly check
+const (
+	defaultPriceAnomalyTolerancePercent = 20.0
+	defaultPriceAnomalyMaxSecurities    = 10000
+)
+
+// CodePriceAnomaly flags a fill priced far from the recent moving average for its security
+const CodePriceAnomaly = "PRICE_ANOMALY"
+
+// securityPrices is the recent average prices of one security, oldest first
+type securityPrices struct {
+	securityID string
+	prices     []float64
+}
+
+// priceHistory remembers the last few fill prices of each security. It holds at most
+// window prices per security and maxSecurities securities, evicting the security
+// least recently seen when full.
+type priceHistory struct {
+	mutex         sync.Mutex
+	window        int
+	maxSecurities int
+	securities    map[string]*list.Element
+	order         *list.List // *securityPrices, least recently seen first
+}
+
+func newPriceHistory(window, maxSecurities int) *priceHistory {
+	if maxSecurities <= 0 {
+		maxSecurities = defaultPriceAnomalyMaxSecurities
+	}
+	return &priceHistory{
+		window:        window,
+		maxSecurities: maxSecurities,
+		securities:    make(map[string]*list.Element),
+		order:         list.New(),
+	}
+}
+
+// prices returns the recent prices of the security, oldest first
+func (h *priceHistory) prices(securityID string) []float64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	element, ok := h.securities[securityID]
+	if !ok {
+		return nil
+	}
+
+	entry := element.Value.(*securityPrices)
+	prices := make([]float64, len(entry.prices))
+	copy(prices, entry.prices)
+	return prices
+}
+
+// record adds a price for the security
+func (h *priceHistory) record(securityID string, price float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	element, ok := h.securities[securityID]
+	if !ok {
+		element = h.order.PushBack(&securityPrices{securityID: securityID})
+		h.securities[securityID] = element
+		for h.order.Len() > h.maxSecurities {
+			oldest := h.order.Remove(h.order.Front()).(*securityPrices)
+			delete(h.securities, oldest.securityID)
+		}
+	} else {
+		h.order.MoveToBack(element)
+	}
+
+	entry := element.Value.(*securityPrices)
+	entry.prices = append(entry.prices, price)
+	if len(entry.prices) > h.window {
+		entry.prices = entry.prices[len(entry.prices)-h.window:]
+	}
+}
+
+// size returns the number of securities with a price history
+func (h *priceHistory) size() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.order.Len()
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+)
+
+// defaultProcessingTimeWindowSize is used when no window size is configured
+const defaultProcessingTimeWindowSize = 1000
+
+// ProcessingTimeStats summarizes recent processing durations in milliseconds
+type ProcessingTimeStats struct {
+	Count  int     `json:"count"`
+	MeanMs float64 `json:"mean_ms"`
+	MinMs  float64 `json:"min_ms"`
+	MaxMs  float64 `json:"max_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+}
+
+// processingTimeWindow keeps the most recent message processing durations
+type processingTimeWindow struct {
+	mutex     sync.Mutex
+	durations []float64 // milliseconds
+	pos       int
+	count     int
+}
+
+func newProcessingTimeWindow(size int) *processingTimeWindow {
+	if size <= 0 {
+		size = defaultProcessingTimeWindowSize
+	}
+	return &processingTimeWindow{durations: make([]float64, size)}
+}
+
+// record adds a duration, evicting the oldest once the window is full
+func (w *processingTimeWindow) record(duration time.Duration) {
+	if w == nil {
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.durations[w.pos] = float64(duration) / float64(time.Millisecond)
+	w.pos = (w.pos + 1) % len(w.durations)
+	if w.count < len(w.durations) {
+		w.count++
+	}
+}
+
+// stats returns the mean, range and percentiles of the durations in the window
+func (w *processingTimeWindow) stats() ProcessingTimeStats {
+	if w == nil {
+		return ProcessingTimeStats{}
+	}
+
+	w.mutex.Lock()
+	values := make([]float64, w.count)
+	copy(values, w.durations[:w.count])
+	w.mutex.Unlock()
+
+	if len(values) == 0 {
+		return ProcessingTimeStats{}
+	}
+
+	// One sort serves the range and every percentile
+	sort.Float64s(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+
+	dataUtils := utils.NewDataUtils()
+	return ProcessingTimeStats{
+		Count:  len(values),
+		MeanMs: sum / float64(len(values)),
+		MinMs:  values[0],
+		MaxMs:  values[len(values)-1],
+		P50Ms:  dataUtils.CalculateSortedPercentile(values, 50),
+		P95Ms:  dataUtils.CalculateSortedPercentile(values, 95),
+		P99Ms:  dataUtils.CalculateSortedPercentile(values, 99),
+	}
+}
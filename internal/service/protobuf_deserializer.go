@@ -0,0 +1,167 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the Fill message in fill.proto
+const (
+	fillFieldID                  protowire.Number = 1
+	fillFieldExecutionServiceID  protowire.Number = 2
+	fillFieldIsOpen              protowire.Number = 3
+	fillFieldExecutionStatus     protowire.Number = 4
+	fillFieldTradeType           protowire.Number = 5
+	fillFieldDestination         protowire.Number = 6
+	fillFieldSecurityID          protowire.Number = 7
+	fillFieldTicker              protowire.Number = 8
+	fillFieldQuantity            protowire.Number = 9
+	fillFieldReceivedTimestamp   protowire.Number = 10
+	fillFieldSentTimestamp       protowire.Number = 11
+	fillFieldLastFilledTimestamp protowire.Number = 12
+	fillFieldQuantityFilled      protowire.Number = 13
+	fillFieldAveragePrice        protowire.Number = 14
+	fillFieldNumberOfFills       protowire.Number = 15
+	fillFieldTotalAmount         protowire.Number = 16
+	fillFieldVersion             protowire.Number = 17
+	fillFieldTenantID            protowire.Number = 18
+
+	fillFieldQuantityDecimal       protowire.Number = 19
+	fillFieldQuantityFilledDecimal protowire.Number = 20
+)
+
+// ProtobufDeserializer decodes fills encoded as the Fill message in fill.proto.
+// Unknown fields are skipped so producers can add fields without breaking the consumer.
+type ProtobufDeserializer struct{}
+
+// Deserialize decodes a Protobuf-encoded fill. The decimal quantity fields override
+// the whole-number ones wherever they appear in the message.
+func (ProtobufDeserializer) Deserialize(data []byte) (*domain.Fill, error) {
+	var fill domain.Fill
+	var quantityDecimal, quantityFilledDecimal *float64
+
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, fmt.Errorf("invalid field tag: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		var err error
+		switch num {
+		case fillFieldID:
+			fill.ID, n, err = consumeInt64(data, num, typ)
+		case fillFieldExecutionServiceID:
+			fill.ExecutionServiceID, n, err = consumeInt64(data, num, typ)
+		case fillFieldIsOpen:
+			var v int64
+			v, n, err = consumeInt64(data, num, typ)
+			fill.IsOpen = v != 0
+		case fillFieldExecutionStatus:
+			fill.ExecutionStatus, n, err = consumeString(data, num, typ)
+		case fillFieldTradeType:
+			fill.TradeType, n, err = consumeString(data, num, typ)
+		case fillFieldDestination:
+			fill.Destination, n, err = consumeString(data, num, typ)
+		case fillFieldSecurityID:
+			fill.SecurityID, n, err = consumeString(data, num, typ)
+		case fillFieldTicker:
+			fill.Ticker, n, err = consumeString(data, num, typ)
+		case fillFieldQuantity:
+			var v int64
+			v, n, err = consumeInt64(data, num, typ)
+			fill.Quantity = float64(v)
+		case fillFieldReceivedTimestamp:
+			fill.ReceivedTimestamp, n, err = consumeDouble(data, num, typ)
+		case fillFieldSentTimestamp:
+			fill.SentTimestamp, n, err = consumeDouble(data, num, typ)
+		case fillFieldLastFilledTimestamp:
+			fill.LastFilledTimestamp, n, err = consumeDouble(data, num, typ)
+		case fillFieldQuantityFilled:
+			var v int64
+			v, n, err = consumeInt64(data, num, typ)
+			fill.QuantityFilled = float64(v)
+		case fillFieldAveragePrice:
+			fill.AveragePrice, n, err = consumeDouble(data, num, typ)
+		case fillFieldNumberOfFills:
+			var v int64
+			v, n, err = consumeInt64(data, num, typ)
+			fill.NumberOfFills = int(int32(v))
+		case fillFieldTotalAmount:
+			fill.TotalAmount, n, err = consumeDouble(data, num, typ)
+		case fillFieldVersion:
+			var v int64
+			v, n, err = consumeInt64(data, num, typ)
+			fill.Version = int(int32(v))
+		case fillFieldTenantID:
+			fill.TenantID, n, err = consumeString(data, num, typ)
+		case fillFieldQuantityDecimal:
+			var v float64
+			v, n, err = consumeDouble(data, num, typ)
+			quantityDecimal = &v
+		case fillFieldQuantityFilledDecimal:
+			var v float64
+			v, n, err = consumeDouble(data, num, typ)
+			quantityFilledDecimal = &v
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				err = fmt.Errorf("invalid value for field %d: %w", num, protowire.ParseError(n))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+	}
+
+	if quantityDecimal != nil {
+		fill.Quantity = *quantityDecimal
+	}
+	if quantityFilledDecimal != nil {
+		fill.QuantityFilled = *quantityFilledDecimal
+	}
+
+	return &fill, nil
+}
+
+// Format returns the message format name
+func (ProtobufDeserializer) Format() string {
+	return MessageFormatProtobuf
+}
+
+func consumeInt64(data []byte, num protowire.Number, typ protowire.Type) (int64, int, error) {
+	if typ != protowire.VarintType {
+		return 0, 0, fmt.Errorf("field %d has wire type %d, expected varint", num, typ)
+	}
+	v, n := protowire.ConsumeVarint(data)
+	if n < 0 {
+		return 0, 0, fmt.Errorf("invalid value for field %d: %w", num, protowire.ParseError(n))
+	}
+	return int64(v), n, nil
+}
+
+func consumeString(data []byte, num protowire.Number, typ protowire.Type) (string, int, error) {
+	if typ != protowire.BytesType {
+		return "", 0, fmt.Errorf("field %d has wire type %d, expected length-delimited", num, typ)
+	}
+	v, n := protowire.ConsumeString(data)
+	if n < 0 {
+		return "", 0, fmt.Errorf("invalid value for field %d: %w", num, protowire.ParseError(n))
+	}
+	return v, n, nil
+}
+
+func consumeDouble(data []byte, num protowire.Number, typ protowire.Type) (float64, int, error) {
+	if typ != protowire.Fixed64Type {
+		return 0, 0, fmt.Errorf("field %d has wire type %d, expected fixed64", num, typ)
+	}
+	v, n := protowire.ConsumeFixed64(data)
+	if n < 0 {
+		return 0, 0, fmt.Errorf("invalid value for field %d: %w", num, protowire.ParseError(n))
+	}
+	return math.Float64frombits(v), n, nil
+}
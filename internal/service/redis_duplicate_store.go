@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisDuplicateStoreConfig represents the configuration for the Redis duplicate store
+type RedisDuplicateStoreConfig struct {
+	Address     string        // host:port; empty disables Redis
+	Password    string        // Optional
+	DB          int           // Redis logical database
+	KeyPrefix   string        // Prepended to every message key
+	DialTimeout time.Duration // Also bounds the startup connectivity check
+}
+
+// redisListBatchSize bounds the keys fetched per MGET when listing records
+const redisListBatchSize = 100
+
+// RedisDuplicateStore shares processed message records between instances through Redis.
+// Records are stored as JSON and expire after the retention period. Sorted set indexes,
+// one across all records and one per execution, rank record keys by when they were
+// stored, so pages are listed without enumerating the keyspace.
+type RedisDuplicateStore struct {
+	client    *redis.Client
+	keyPrefix string
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+// NewRedisDuplicateStore connects to Redis and verifies it is reachable
+func NewRedisDuplicateStore(config RedisDuplicateStoreConfig, ttl time.Duration) (*RedisDuplicateStore, error) {
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "confirmation:processed:"
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:        config.Address,
+		Password:    config.Password,
+		DB:          config.DB,
+		DialTimeout: config.DialTimeout,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.DialTimeout)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", config.Address, err)
+	}
+
+	return &RedisDuplicateStore{
+		client:    client,
+		keyPrefix: config.KeyPrefix,
+		ttl:       ttl,
+		now:       time.Now,
+	}, nil
+}
+
+// indexPrefix is the key prefix of the record indexes
+func (s *RedisDuplicateStore) indexPrefix() string {
+	return s.keyPrefix + "index:"
+}
+
+// indexKey returns the index of an execution's records, or of every record for 0
+func (s *RedisDuplicateStore) indexKey(executionServiceID int64) string {
+	if executionServiceID == 0 {
+		return s.indexPrefix() + "all"
+	}
+	return s.indexPrefix() + "exec:" + strconv.FormatInt(executionServiceID, 10)
+}
+
+// pruneIndex queues the removal of index entries whose records have expired
+func (s *RedisDuplicateStore) pruneIndex(ctx context.Context, pipe redis.Pipeliner, indexKey string) {
+	if s.ttl <= 0 {
+		return
+	}
+	expiredBefore := s.now().Add(-s.ttl).UnixMicro()
+	pipe.ZRemRangeByScore(ctx, indexKey, "-inf", "("+strconv.FormatInt(expiredBefore, 10))
+}
+
+// Get returns the record for key and whether it exists
+func (s *RedisDuplicateStore) Get(ctx context.Context, key string) (*ProcessedMessage, bool, error) {
+	data, err := s.client.Get(ctx, s.keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get processed message %s: %w", key, err)
+	}
+
+	var message ProcessedMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, false, fmt.Errorf("failed to decode processed message %s: %w", key, err)
+	}
+	return &message, true, nil
+}
+
+// Set stores the record for key with the retention period as its TTL and indexes it.
+// Each index expires with the last record added to it.
+func (s *RedisDuplicateStore) Set(ctx context.Context, key string, message *ProcessedMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode processed message %s: %w", key, err)
+	}
+
+	stored := redis.Z{Score: float64(s.now().UnixMicro()), Member: key}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.keyPrefix+key, data, s.ttl)
+		for _, indexKey := range []string{s.indexKey(0), s.indexKey(message.ExecutionServiceID)} {
+			s.pruneIndex(ctx, pipe, indexKey)
+			pipe.ZAdd(ctx, indexKey, stored)
+			if s.ttl > 0 {
+				pipe.Expire(ctx, indexKey, s.ttl)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set processed message %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the record for key if present, along with its index entries
+func (s *RedisDuplicateStore) Delete(ctx context.Context, key string) error {
+	// The record names the execution index it is in
+	message, exists, err := s.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.keyPrefix+key)
+		pipe.ZRem(ctx, s.indexKey(0), key)
+		if exists {
+			pipe.ZRem(ctx, s.indexKey(message.ExecutionServiceID), key)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete processed message %s: %w", key, err)
+	}
+	return nil
+}
+
+// List returns a page of the records matching the query, most recently stored first.
+// The page is read from the query's index, fetching only the page's records in MGET
+// batches.
+func (s *RedisDuplicateStore) List(ctx context.Context, query ProcessedMessageQuery) (*ProcessedMessagePage, error) {
+	indexKey := s.indexKey(query.ExecutionServiceID)
+	stop := int64(-1)
+	if query.Limit > 0 {
+		stop = int64(query.Offset + query.Limit - 1)
+	}
+
+	var total *redis.IntCmd
+	var keys *redis.StringSliceCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		s.pruneIndex(ctx, pipe, indexKey)
+		total = pipe.ZCard(ctx, indexKey)
+		keys = pipe.ZRevRange(ctx, indexKey, int64(query.Offset), stop)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processed messages: %w", err)
+	}
+
+	page := &ProcessedMessagePage{Messages: []ProcessedMessage{}, Total: int(total.Val())}
+	pageKeys := keys.Val()
+	for start := 0; start < len(pageKeys); start += redisListBatchSize {
+		batch := pageKeys[start:min(start+redisListBatchSize, len(pageKeys))]
+		recordKeys := make([]string, len(batch))
+		for i, key := range batch {
+			recordKeys[i] = s.keyPrefix + key
+		}
+
+		values, err := s.client.MGet(ctx, recordKeys...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list processed messages: %w", err)
+		}
+		for i, value := range values {
+			data, ok := value.(string)
+			if !ok {
+				continue // Expired since the index was pruned
+			}
+
+			var message ProcessedMessage
+			if err := json.Unmarshal([]byte(data), &message); err != nil {
+				return nil, fmt.Errorf("failed to decode processed message %s: %w", batch[i], err)
+			}
+			page.Messages = append(page.Messages, message)
+		}
+	}
+
+	return page, nil
+}
+
+// Clear removes every record and index under the key prefix and returns how many records
+// were removed. Records from other instances are removed too, since the store is shared.
+func (s *RedisDuplicateStore) Clear(ctx context.Context) (int, error) {
+	cleared := 0
+
+	iter := s.client.Scan(ctx, 0, s.keyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		removed, err := s.client.Del(ctx, iter.Val()).Result()
+		if err != nil {
+			return cleared, fmt.Errorf("failed to delete processed message %s: %w", iter.Val(), err)
+		}
+		if !strings.HasPrefix(iter.Val(), s.indexPrefix()) {
+			cleared += int(removed)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return cleared, fmt.Errorf("failed to clear processed messages: %w", err)
+	}
+
+	return cleared, nil
+}
+
+// Close closes the Redis client
+func (s *RedisDuplicateStore) Close() error {
+	return s.client.Close()
+}
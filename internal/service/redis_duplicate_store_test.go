@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisDuplicateStore_StoresRecordsWithRetentionTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	store, err := NewRedisDuplicateStore(RedisDuplicateStoreConfig{Address: mr.Addr()}, time.Hour)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	processedAt := time.Now().Truncate(time.Second)
+
+	require.NoError(t, store.Set(ctx, "fill_1_exec_2", &ProcessedMessage{
+		FillID:             1,
+		ExecutionServiceID: 2,
+		ProcessedAt:        processedAt,
+		Success:            true,
+		QuantityFilled:     1000,
+		AveragePrice:       190.41,
+	}))
+
+	assert.True(t, mr.Exists("confirmation:processed:fill_1_exec_2"))
+	assert.Equal(t, time.Hour, mr.TTL("confirmation:processed:fill_1_exec_2"))
+
+	message, exists, err := store.Get(ctx, "fill_1_exec_2")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, int64(1), message.FillID)
+	assert.True(t, message.Success)
+	assert.Equal(t, 190.41, message.AveragePrice)
+	assert.True(t, processedAt.Equal(message.ProcessedAt))
+
+	require.NoError(t, store.Delete(ctx, "fill_1_exec_2"))
+	_, exists, err = store.Get(ctx, "fill_1_exec_2")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestDuplicateDetectionService_RedisStoreSharedAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	config := DuplicateDetectionConfig{
+		Logger:          appLogger,
+		RetentionPeriod: time.Hour,
+		MaxEntries:      1000,
+		Redis:           RedisDuplicateStoreConfig{Address: mr.Addr()},
+	}
+
+	first := NewDuplicateDetectionService(config)
+	defer first.Stop()
+	second := NewDuplicateDetectionService(config)
+	defer second.Stop()
+
+	assert.IsType(t, &RedisDuplicateStore{}, first.store)
+
+	ctx := context.Background()
+	fill := &domain.Fill{ID: 123, ExecutionServiceID: 456, QuantityFilled: 1000, AveragePrice: 190.41, Version: 1}
+
+	first.RecordProcessedMessage(ctx, fill, true, time.Millisecond, "")
+
+	result := second.CheckDuplicate(ctx, fill)
+	assert.True(t, result.IsDuplicate)
+	assert.False(t, result.ShouldProcess)
+
+	stats := second.GetProcessedMessageStats()
+	assert.Equal(t, "redis", stats["store"])
+}
+
+func TestDuplicateDetectionService_FallsBackToMemoryWhenRedisUnreachable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	address := mr.Addr()
+	mr.Close()
+
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	service := NewDuplicateDetectionService(DuplicateDetectionConfig{
+		Logger:          appLogger,
+		RetentionPeriod: time.Hour,
+		MaxEntries:      1000,
+		Redis:           RedisDuplicateStoreConfig{Address: address, DialTimeout: 100 * time.Millisecond},
+	})
+	defer service.Stop()
+
+	assert.IsType(t, &MemoryDuplicateStore{}, service.store)
+
+	ctx := context.Background()
+	fill := &domain.Fill{ID: 123, ExecutionServiceID: 456, QuantityFilled: 1000, AveragePrice: 190.41, Version: 1}
+	service.RecordProcessedMessage(ctx, fill, true, time.Millisecond, "")
+	assert.True(t, service.CheckDuplicate(ctx, fill).IsDuplicate)
+}
+
+func TestRedisDuplicateStore_List(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	store, err := NewRedisDuplicateStore(RedisDuplicateStoreConfig{Address: mr.Addr()}, time.Hour)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	require.NoError(t, store.Set(ctx, "fill_1_exec_2", &ProcessedMessage{FillID: 1, ExecutionServiceID: 2}))
+	require.NoError(t, store.Set(ctx, "fill_3_exec_4", &ProcessedMessage{FillID: 3, ExecutionServiceID: 4}))
+
+	// Keys outside the prefix are ignored
+	require.NoError(t, mr.Set("unrelated", "value"))
+
+	page, err := store.List(ctx, ProcessedMessageQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Messages, 2)
+	assert.Equal(t, 2, page.Total)
+
+	fillIDs := []int64{page.Messages[0].FillID, page.Messages[1].FillID}
+	assert.ElementsMatch(t, []int64{1, 3}, fillIDs)
+}
+
+func TestRedisDuplicateStore_ListPagesFromIndex(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	store, err := NewRedisDuplicateStore(RedisDuplicateStoreConfig{Address: mr.Addr()}, time.Hour)
+	require.NoError(t, err)
+	defer store.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for fillID := int64(1); fillID <= 5; fillID++ {
+		executionID := int64(456)
+		if fillID%2 == 0 {
+			executionID = 789
+		}
+		key := fmt.Sprintf("fill_%d_exec_%d", fillID, executionID)
+		require.NoError(t, store.Set(ctx, key, &ProcessedMessage{FillID: fillID, ExecutionServiceID: executionID}))
+		now = now.Add(time.Minute)
+	}
+
+	fillIDs := func(page *ProcessedMessagePage) []int64 {
+		ids := []int64{}
+		for _, message := range page.Messages {
+			ids = append(ids, message.FillID)
+		}
+		return ids
+	}
+
+	// Most recently stored first, across pages
+	page, err := store.List(ctx, ProcessedMessageQuery{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 4}, fillIDs(page))
+	assert.Equal(t, 5, page.Total)
+	page, err = store.List(ctx, ProcessedMessageQuery{Offset: 2, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, fillIDs(page))
+
+	// Each execution has its own index
+	page, err = store.List(ctx, ProcessedMessageQuery{ExecutionServiceID: 456, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 1}, fillIDs(page))
+	assert.Equal(t, 3, page.Total)
+
+	// Deleted records leave the indexes
+	require.NoError(t, store.Delete(ctx, "fill_5_exec_456"))
+	page, err = store.List(ctx, ProcessedMessageQuery{ExecutionServiceID: 456})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 1}, fillIDs(page))
+	assert.Equal(t, 2, page.Total)
+
+	// Entries older than the retention period are pruned with their records
+	now = now.Add(time.Hour - 3*time.Minute)
+	page, err = store.List(ctx, ProcessedMessageQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 3}, fillIDs(page))
+	assert.Equal(t, 2, page.Total)
+}
+
+func TestRedisDuplicateStore_Clear(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	store, err := NewRedisDuplicateStore(RedisDuplicateStoreConfig{Address: mr.Addr()}, time.Hour)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	require.NoError(t, store.Set(ctx, "fill_1_exec_2", &ProcessedMessage{FillID: 1, ExecutionServiceID: 2}))
+	require.NoError(t, store.Set(ctx, "fill_3_exec_4", &ProcessedMessage{FillID: 3, ExecutionServiceID: 4}))
+	require.NoError(t, mr.Set("unrelated", "value"))
+
+	cleared, err := store.Clear(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cleared)
+
+	page, err := store.List(ctx, ProcessedMessageQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Messages)
+	assert.Zero(t, page.Total)
+	assert.True(t, mr.Exists("unrelated"), "keys outside the prefix are kept")
+}
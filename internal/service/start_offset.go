@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// Start offsets of a consumer group without committed offsets
+const (
+	StartOffsetEarliest = "earliest"
+	StartOffsetLatest   = "latest"
+)
+
+// readerStartOffset returns the reader start offset for the configured start offset,
+// defaulting to the latest
+func readerStartOffset(startOffset string) int64 {
+	if startOffset == StartOffsetEarliest {
+		return kafka.FirstOffset
+	}
+	return kafka.LastOffset
+}
+
+// timestampStartOffsets selects the offsets to commit so partitions the consumer group
+// has not committed on start at the timestamp. Partitions with a committed offset keep
+// it, and partitions without messages after the timestamp fall back to the reader's
+// start offset.
+func timestampStartOffsets(partitions []partitionOffsets, atTimestamp map[string]map[int]int64) map[string][]kafka.OffsetCommit {
+	offsets := make(map[string][]kafka.OffsetCommit)
+	for _, partition := range partitions {
+		if partition.Committed >= 0 {
+			continue
+		}
+
+		offset, ok := atTimestamp[partition.Topic][partition.Partition]
+		if !ok || offset < 0 {
+			continue
+		}
+		offsets[partition.Topic] = append(offsets[partition.Topic], kafka.OffsetCommit{
+			Partition: partition.Partition,
+			Offset:    offset,
+		})
+	}
+
+	for topic := range offsets {
+		sort.Slice(offsets[topic], func(i, j int) bool {
+			return offsets[topic][i].Partition < offsets[topic][j].Partition
+		})
+	}
+	return offsets
+}
+
+// seekToStartTimestamp commits the start timestamp's offsets for the partitions the
+// consumer group has not committed on. It runs before the reader joins the group,
+// since the brokers only accept commits from outside a group while it is empty.
+func (kcs *KafkaConsumerService) seekToStartTimestamp(ctx context.Context) error {
+	queryCtx, cancel := context.WithTimeout(ctx, kcs.config.ConnectionTimeout)
+	defer cancel()
+
+	partitions, err := kcs.offsetSource.Offsets(queryCtx, kcs.config.ConsumerGroup, kcs.topics)
+	if err != nil {
+		return err
+	}
+
+	atTimestamp, err := kcs.offsetSource.OffsetsAt(queryCtx, kcs.topics, kcs.startTimestamp)
+	if err != nil {
+		return err
+	}
+
+	offsets := timestampStartOffsets(partitions, atTimestamp)
+	if len(offsets) == 0 {
+		kcs.logger.WithContext(ctx).Info("No partitions to start at the Kafka start timestamp",
+			zap.Time("start_timestamp", kcs.startTimestamp),
+		)
+		return nil
+	}
+
+	if err := kcs.offsetSource.CommitOffsets(queryCtx, kcs.config.ConsumerGroup, offsets); err != nil {
+		return err
+	}
+
+	for topic, commits := range offsets {
+		for _, commit := range commits {
+			kcs.logger.WithContext(ctx).Info("Starting partition at the Kafka start timestamp",
+				zap.String("topic", topic),
+				zap.Int("partition", commit.Partition),
+				zap.Int64("offset", commit.Offset),
+				zap.Time("start_timestamp", kcs.startTimestamp),
+			)
+		}
+	}
+	return nil
+}
+
+// OffsetsAt lists the offset of the first message at or after the time on every
+// partition of the topics
+func (s *brokerOffsetSource) OffsetsAt(ctx context.Context, topics []string, at time.Time) (map[string]map[int]int64, error) {
+	partitions, err := s.partitions(ctx, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make(map[string][]kafka.OffsetRequest, len(partitions))
+	for topic, ids := range partitions {
+		for _, id := range ids {
+			requests[topic] = append(requests[topic], kafka.TimeOffsetOf(id, at))
+		}
+	}
+
+	response, err := s.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: requests})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partition offsets: %w", err)
+	}
+
+	offsets := make(map[string]map[int]int64, len(response.Topics))
+	for topic, partitions := range response.Topics {
+		for _, partition := range partitions {
+			if partition.Error != nil {
+				return nil, fmt.Errorf("failed to list offsets for %s/%d: %w", topic, partition.Partition, partition.Error)
+			}
+
+			// The broker answers with the first offset at or after the time; a partition
+			// without such a message has none
+			for offset := range partition.Offsets {
+				if offsets[topic] == nil {
+					offsets[topic] = make(map[int]int64)
+				}
+				offsets[topic][partition.Partition] = offset
+			}
+		}
+	}
+
+	return offsets, nil
+}
+
+// CommitOffsets commits offsets for the consumer group from outside it, which the
+// brokers accept while the group has no members
+func (s *brokerOffsetSource) CommitOffsets(ctx context.Context, groupID string, offsets map[string][]kafka.OffsetCommit) error {
+	response, err := s.client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      groupID,
+		GenerationID: -1,
+		Topics:       offsets,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit start offsets: %w", err)
+	}
+
+	for topic, partitions := range response.Topics {
+		for _, partition := range partitions {
+			if partition.Error != nil {
+				return fmt.Errorf("failed to commit start offset for %s/%d: %w", topic, partition.Partition, partition.Error)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReaderConfig_StartOffset(t *testing.T) {
+	tests := []struct {
+		name        string
+		startOffset string
+		want        int64
+	}{
+		{"earliest", StartOffsetEarliest, kafka.FirstOffset},
+		{"latest", StartOffsetLatest, kafka.LastOffset},
+		{"unset defaults to latest", "", kafka.LastOffset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerConfig := newReaderConfig(KafkaConsumerConfig{
+				Kafka: config.KafkaConfig{
+					Brokers:       []string{"localhost:9092"},
+					Topic:         "fills",
+					ConsumerGroup: "confirmations",
+					StartOffset:   tt.startOffset,
+				},
+			})
+
+			assert.Equal(t, tt.want, readerConfig.StartOffset)
+			assert.Equal(t, "fills", readerConfig.Topic)
+			assert.Equal(t, "confirmations", readerConfig.GroupID)
+		})
+	}
+}
+
+func TestTimestampStartOffsets(t *testing.T) {
+	partitions := []partitionOffsets{
+		{Topic: "fills", Partition: 1, Latest: 90, Committed: -1},
+		{Topic: "fills", Partition: 0, Latest: 100, Committed: -1},
+		{Topic: "fills", Partition: 2, Latest: 80, Committed: 75}, // Already consumed
+		{Topic: "fills", Partition: 3, Latest: 60, Committed: -1}, // No messages after the timestamp
+		{Topic: "fills-replay", Partition: 0, Latest: 10, Committed: -1},
+	}
+	atTimestamp := map[string]map[int]int64{
+		"fills":        {0: 40, 1: 30, 2: 20},
+		"fills-replay": {0: 5},
+	}
+
+	offsets := timestampStartOffsets(partitions, atTimestamp)
+
+	assert.Equal(t, map[string][]kafka.OffsetCommit{
+		"fills":        {{Partition: 0, Offset: 40}, {Partition: 1, Offset: 30}},
+		"fills-replay": {{Partition: 0, Offset: 5}},
+	}, offsets)
+	assert.Empty(t, timestampStartOffsets(partitions, nil))
+}
+
+func TestKafkaConsumerService_SeekToStartTimestamp(t *testing.T) {
+	startTimestamp := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	consumer, _ := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+	consumer.config.ConsumerGroup = "confirmations"
+	consumer.config.ConnectionTimeout = time.Second
+	consumer.startTimestamp = startTimestamp
+	source := &fakeOffsetSource{offsetsAt: map[string]map[int]int64{"fills": {0: 40, 1: 30}}}
+	source.setOffsets(
+		partitionOffsets{Topic: "fills", Partition: 0, Latest: 100, Committed: -1},
+		partitionOffsets{Topic: "fills", Partition: 1, Latest: 90, Committed: 60},
+	)
+	consumer.offsetSource = source
+
+	require.NoError(t, consumer.seekToStartTimestamp(context.Background()))
+
+	assert.Equal(t, startTimestamp, source.at)
+	assert.Equal(t, "confirmations", source.groupID)
+	assert.Equal(t, map[string][]kafka.OffsetCommit{"fills": {{Partition: 0, Offset: 40}}}, source.committed)
+}
+
+func TestNewKafkaConsumerService_StartTimestampDefersReader(t *testing.T) {
+	consumer, _ := newTestKafkaConsumer(t, &recordingMessageHandler{}, time.Second)
+	assert.True(t, consumer.startTimestamp.IsZero())
+
+	withTimestamp := NewKafkaConsumerService(KafkaConsumerConfig{
+		Kafka: config.KafkaConfig{
+			Brokers:        []string{"localhost:9092"},
+			Topic:          "fills",
+			StartOffset:    StartOffsetEarliest,
+			StartTimestamp: "2024-01-02T15:04:05Z",
+		},
+		Logger:  consumer.logger,
+		Metrics: consumer.metrics,
+	})
+
+	// The reader joins the group only after the start offsets are committed
+	assert.Nil(t, withTimestamp.reader)
+	assert.Equal(t, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), withTimestamp.startTimestamp)
+	assert.Equal(t, kafka.FirstOffset, withTimestamp.readerConfig.StartOffset)
+}
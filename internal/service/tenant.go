@@ -0,0 +1,46 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/segmentio/kafka-go"
+)
+
+// tenantIDHeader is the Kafka message header carrying the tenant a fill belongs to
+const tenantIDHeader = "X-Tenant-ID"
+
+// TenantProfile holds the dependencies one tenant's fills are processed with. Nil
+// fields fall back to the confirmation service's global dependencies.
+type TenantProfile struct {
+	ExecutionClient   ExecutionServiceClientInterface
+	AllocationClient  AllocationServiceClientInterface
+	ValidationService *ValidationService
+
+	// Optional circuit breaker for posts to the tenant's AllocationClient, named with
+	// AllocationCircuitBreakerName; while it is open the posts are queued for replay
+	AllocationCircuitBreaker *utils.CircuitBreaker
+}
+
+// resolveTenantID returns the tenant a message belongs to: the X-Tenant-ID header when
+// present, otherwise the fill's tenantId field
+func resolveTenantID(headers []kafka.Header, fill *domain.Fill) string {
+	if tenantID := getHeaderValue(headers, tenantIDHeader); tenantID != "" {
+		return tenantID
+	}
+	return fill.TenantID
+}
+
+// toTenantProfiles keys profiles by lowercased tenant ID so lookups are case-insensitive
+func toTenantProfiles(profiles map[string]TenantProfile) map[string]TenantProfile {
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	normalized := make(map[string]TenantProfile, len(profiles))
+	for tenantID, profile := range profiles {
+		normalized[strings.ToLower(tenantID)] = profile
+	}
+	return normalized
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTenantExecutionServer returns an Execution Service that accepts every update and
+// counts the requests it receives
+func newTenantExecutionServer(t *testing.T, requests *atomic.Int64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			json.NewEncoder(w).Encode(domain.ExecutionUpdateResponse{ID: 2, ExecutionStatus: "PART", Version: 2})
+			return
+		}
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{
+			ID:              2,
+			ExecutionStatus: "PART",
+			TradeType:       "BUY",
+			Destination:     "ML",
+			SecurityID:      "SEC1",
+			Quantity:        100,
+			QuantityFilled:  25,
+			Version:         1,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTenantTestFill(tenantID string) *domain.Fill {
+	now := float64(time.Now().Unix())
+	return &domain.Fill{
+		ID:                  1,
+		ExecutionServiceID:  2,
+		IsOpen:              true,
+		ExecutionStatus:     "PART",
+		TradeType:           "BUY",
+		Destination:         "ML",
+		SecurityID:          "SEC1",
+		Ticker:              "IBM",
+		Quantity:            100,
+		ReceivedTimestamp:   now,
+		SentTimestamp:       now,
+		LastFilledTimestamp: now,
+		QuantityFilled:      50,
+		AveragePrice:        10,
+		NumberOfFills:       3,
+		TotalAmount:         500,
+		TenantID:            tenantID,
+	}
+}
+
+func TestResolveTenantID(t *testing.T) {
+	fill := newTenantTestFill("acme")
+
+	assert.Equal(t, "acme", resolveTenantID(nil, fill))
+	assert.Equal(t, "globex", resolveTenantID([]kafka.Header{{Key: "x-tenant-id", Value: []byte("globex")}}, fill))
+	assert.Equal(t, "", resolveTenantID(nil, newTenantTestFill("")))
+}
+
+func TestConfirmationService_HandleFillMessage_AppliesTenantOverrides(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	var globalRequests, acmeRequests, globexRequests atomic.Int64
+	globalServer := newTenantExecutionServer(t, &globalRequests)
+	acmeServer := newTenantExecutionServer(t, &acmeRequests)
+	globexServer := newTenantExecutionServer(t, &globexRequests)
+
+	// Globex allows at most one sub-fill per hundred filled shares; the global
+	// validation does not check the fill count
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:   newTestExecutionServiceClient(t, config.ExecutionServiceConfig{BaseURL: globalServer.URL, Timeout: time.Second}),
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ValidationService: NewValidationService(ValidationConfig{Logger: appLogger}),
+		Tenants: map[string]TenantProfile{
+			"acme": {
+				ExecutionClient: newTestExecutionServiceClient(t, config.ExecutionServiceConfig{BaseURL: acmeServer.URL, Timeout: time.Second}),
+			},
+			"Globex": {
+				ExecutionClient: newTestExecutionServiceClient(t, config.ExecutionServiceConfig{BaseURL: globexServer.URL, Timeout: time.Second}),
+				ValidationService: NewValidationService(ValidationConfig{
+					Logger:                     appLogger,
+					MaxFillsPerFilledShare:     0.01,
+					ExcessiveFillCountSeverity: SeverityError,
+				}),
+			},
+		},
+	})
+	ctx := context.Background()
+
+	require.NoError(t, service.HandleFillMessage(ctx, newTenantTestFill("ACME")))
+	assert.Equal(t, int64(2), acmeRequests.Load(), "acme fills go to acme's Execution Service")
+
+	err = service.HandleFillMessage(ctx, newTenantTestFill("globex"))
+	assert.ErrorContains(t, err, "numberOfFills (3) is implausible")
+	assert.Zero(t, globexRequests.Load(), "globex's stricter validation rejects the fill first")
+
+	fill := newTenantTestFill("globex")
+	fill.NumberOfFills = 0
+	require.NoError(t, service.HandleFillMessage(ctx, fill))
+	assert.Equal(t, int64(2), globexRequests.Load(), "globex fills go to globex's Execution Service")
+
+	// Unknown tenants and fills without a tenant use the global settings
+	require.NoError(t, service.HandleFillMessage(ctx, newTenantTestFill("initech")))
+	require.NoError(t, service.HandleFillMessage(ctx, newTenantTestFill("")))
+	assert.Equal(t, int64(4), globalRequests.Load())
+	assert.Equal(t, int64(2), acmeRequests.Load())
+}
+
+func TestConfirmationService_HandleAllocationServiceCall_TenantCircuitBreaker(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+	newBreaker := func(tenantID string) *utils.CircuitBreaker {
+		return utils.NewCircuitBreaker(utils.CircuitBreakerConfig{
+			Name:             AllocationCircuitBreakerName(tenantID),
+			FailureThreshold: 1,
+			SuccessThreshold: 1,
+			Timeout:          20 * time.Millisecond,
+		}, appLogger, appMetrics)
+	}
+
+	globalClient := &MockAllocationServiceClient{}
+	acmeClient := &MockAllocationServiceClient{}
+	mockResilience := &MockResilienceManager{}
+	acmeBreaker := newBreaker("acme")
+	service := NewConfirmationService(ConfirmationServiceConfig{
+		ExecutionClient:          &MockExecutionServiceClient{},
+		AllocationClient:         globalClient,
+		Logger:                   appLogger,
+		Metrics:                  appMetrics,
+		ResilienceManager:        mockResilience,
+		AllocationCircuitBreaker: newBreaker(""),
+		Tenants: map[string]TenantProfile{
+			"acme": {AllocationClient: acmeClient, AllocationCircuitBreaker: acmeBreaker},
+		},
+	})
+	ctx := context.Background()
+
+	acmeFill := newTenantTestFill("acme")
+	acmeFill.IsOpen = false
+	globalFill := newTenantTestFill("")
+	globalFill.IsOpen = false
+
+	// Acme's failure opens only acme's circuit
+	acmeClient.On("PostExecution", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Once()
+	mockResilience.On("AddToDeadLetterQueue", mock.Anything, mock.Anything, "allocation-service failure", mock.Anything, 1, mock.Anything).Return(nil).Once()
+	require.NoError(t, service.handleAllocationServiceCall(ctx, acmeFill))
+	assert.Equal(t, utils.StateOpen, acmeBreaker.GetState())
+	assert.Equal(t, utils.StateClosed, service.allocationCircuitBreaker.GetState())
+
+	// Acme's posts are queued while the global Allocation Service is still posted to
+	require.NoError(t, service.handleAllocationServiceCall(ctx, acmeFill))
+	globalClient.On("PostExecution", mock.Anything, mock.Anything).Return(nil).Once()
+	require.NoError(t, service.handleAllocationServiceCall(ctx, globalFill))
+	acmeClient.AssertNumberOfCalls(t, "PostExecution", 1)
+	globalClient.AssertNumberOfCalls(t, "PostExecution", 1)
+	assert.Equal(t, 1, service.pendingAllocations.stats().Size)
+
+	// The queued post is replayed to acme's Allocation Service once its circuit recovers
+	time.Sleep(30 * time.Millisecond)
+	acmeClient.On("PostExecution", mock.Anything, mock.Anything).Return(nil).Once()
+	service.replayPendingAllocations(ctx)
+
+	acmeClient.AssertNumberOfCalls(t, "PostExecution", 2)
+	globalClient.AssertNumberOfCalls(t, "PostExecution", 1)
+	assert.Equal(t, 0, service.pendingAllocations.stats().Size)
+	assert.Equal(t, utils.StateClosed, acmeBreaker.GetState())
+}
+
+func TestConfirmationService_ReplayPendingAllocations_SkipsOnlyOpenCircuits(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	openBreaker := utils.NewCircuitBreaker(utils.CircuitBreakerConfig{Name: AllocationCircuitBreakerName("acme"), FailureThreshold: 1, Timeout: time.Hour}, appLogger, appMetrics)
+	openBreaker.Execute(context.Background(), func(ctx context.Context) error { return errors.New("connection refused") })
+	require.Equal(t, utils.StateOpen, openBreaker.GetState())
+
+	acmeClient := &MockAllocationServiceClient{}
+	globexClient := &MockAllocationServiceClient{}
+	service := &ConfirmationService{logger: appLogger, metrics: appMetrics, pendingAllocations: newPendingAllocationBuffer(10)}
+
+	acme := allocationTarget{client: acmeClient, breaker: openBreaker}
+	globex := allocationTarget{client: globexClient}
+	for i, target := range []allocationTarget{acme, globex, acme, globex} {
+		service.pendingAllocations.add(pendingAllocation{target: target, dto: &domain.AllocationServiceExecutionDTO{ExecutionServiceID: int64(i)}})
+	}
+
+	globexClient.On("PostExecution", mock.Anything, mock.Anything).Return(nil).Twice()
+	service.replayPendingAllocations(context.Background())
+
+	// Acme's posts stay queued in order behind its open circuit
+	acmeClient.AssertNotCalled(t, "PostExecution", mock.Anything, mock.Anything)
+	globexClient.AssertNumberOfCalls(t, "PostExecution", 2)
+	pending := service.pendingAllocations.take()
+	require.Len(t, pending, 2)
+	assert.Equal(t, int64(0), pending[0].dto.ExecutionServiceID)
+	assert.Equal(t, int64(2), pending[1].dto.ExecutionServiceID)
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+)
+
+// Token is a bearer token and when it expires; a zero Expiry never expires
+type Token struct {
+	Value  string
+	Expiry time.Time
+}
+
+// TokenProvider supplies the bearer tokens attached to authenticated requests
+type TokenProvider interface {
+	// Token returns a valid token, reusing the current one while it has not expired
+	Token(ctx context.Context) (Token, error)
+	// Refresh discards the current token and returns a new one, after the server
+	// rejected the current token
+	Refresh(ctx context.Context) (Token, error)
+}
+
+// StaticTokenProvider always returns the same token
+type StaticTokenProvider struct {
+	token string
+}
+
+// NewStaticTokenProvider creates a provider for a long-lived token
+func NewStaticTokenProvider(token string) *StaticTokenProvider {
+	return &StaticTokenProvider{token: token}
+}
+
+// Token returns the static token
+func (p *StaticTokenProvider) Token(ctx context.Context) (Token, error) {
+	return Token{Value: p.token}, nil
+}
+
+// Refresh returns the static token; there is no other to fall back to
+func (p *StaticTokenProvider) Refresh(ctx context.Context) (Token, error) {
+	return p.Token(ctx)
+}
+
+// NewTokenProviderFromConfig creates the token provider the auth configuration asks
+// for: a client credentials provider when a token URL is set, a static provider when
+// a token is set, and nil when requests are unauthenticated
+func NewTokenProviderFromConfig(auth config.AuthConfig) TokenProvider {
+	switch {
+	case auth.TokenURL != "":
+		return NewClientCredentialsTokenProvider(ClientCredentialsConfig{
+			TokenURL:     auth.TokenURL,
+			ClientID:     auth.ClientID,
+			ClientSecret: auth.ClientSecret,
+			Scopes:       auth.Scopes,
+		})
+	case auth.Token != "":
+		return NewStaticTokenProvider(auth.Token)
+	default:
+		return nil
+	}
+}
+
+// ClientCredentialsConfig represents the configuration for the OAuth2 client credentials token provider
+type ClientCredentialsConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	HTTPClient   *http.Client  // Defaults to a client with a 10s timeout
+	ExpiryLeeway time.Duration // Tokens are renewed this long before they expire; defaults to 30s
+	Clock        utils.Clock   // Defaults to the system clock
+}
+
+// ClientCredentialsTokenProvider fetches tokens with the OAuth2 client credentials
+// grant and reuses each until shortly before it expires
+type ClientCredentialsTokenProvider struct {
+	config ClientCredentialsConfig
+
+	mutex sync.Mutex
+	token Token
+}
+
+// tokenResponse is the token endpoint's response
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// NewClientCredentialsTokenProvider creates an OAuth2 client credentials token provider
+func NewClientCredentialsTokenProvider(config ClientCredentialsConfig) *ClientCredentialsTokenProvider {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.ExpiryLeeway <= 0 {
+		config.ExpiryLeeway = 30 * time.Second
+	}
+	if config.Clock == nil {
+		config.Clock = utils.RealClock{}
+	}
+
+	return &ClientCredentialsTokenProvider{config: config}
+}
+
+// Token returns the current token, fetching a new one when there is none or it is
+// about to expire
+func (p *ClientCredentialsTokenProvider) Token(ctx context.Context) (Token, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.token.Value != "" && (p.token.Expiry.IsZero() || p.config.Clock.Now().Add(p.config.ExpiryLeeway).Before(p.token.Expiry)) {
+		return p.token, nil
+	}
+
+	return p.fetch(ctx)
+}
+
+// Refresh fetches a new token regardless of the current one's expiry
+func (p *ClientCredentialsTokenProvider) Refresh(ctx context.Context) (Token, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.fetch(ctx)
+}
+
+// fetch requests a token from the token endpoint; the caller holds the mutex, so
+// concurrent callers wait for one fetch instead of each starting their own
+func (p *ClientCredentialsTokenProvider) fetch(ctx context.Context) (Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(p.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.config.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Token{}, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Token{}, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var tokenResp tokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return Token{}, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return Token{}, fmt.Errorf("token response has no access_token")
+	}
+
+	token := Token{Value: tokenResp.AccessToken}
+	if tokenResp.ExpiresIn > 0 {
+		token.Expiry = p.config.Clock.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	}
+	p.token = token
+
+	return token, nil
+}
+
+// bearerTokenTransport attaches a bearer token to each request. When the server
+// answers 401 it refreshes the token once and resends the request.
+type bearerTokenTransport struct {
+	provider TokenProvider
+	next     http.RoundTripper
+}
+
+// RoundTrip sends the request with the current token, retrying once with a refreshed
+// token on a 401
+func (t *bearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.provider.Token(req.Context())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, fmt.Errorf("failed to get bearer token: %w", err)
+	}
+
+	// The request body is consumed by the first attempt, so keep a way to resend it
+	retryable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	resp, err := t.next.RoundTrip(withBearerToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !retryable {
+		return resp, err
+	}
+
+	token, err = t.provider.Refresh(req.Context())
+	if err != nil {
+		// Keep the 401 so the caller reports the authentication failure
+		return resp, nil
+	}
+
+	retry := withBearerToken(req, token)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return t.next.RoundTrip(retry)
+}
+
+// withBearerToken returns a copy of the request carrying the token, since a
+// RoundTripper must not modify its request
+func withBearerToken(req *http.Request, token Token) *http.Request {
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token.Value)
+	return authorized
+}
+
+// closeRequestBody closes the body of a request that will not be sent, as the
+// RoundTripper contract requires
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTokenProvider hands out "token-1", "token-2", ... and counts refreshes
+type mockTokenProvider struct {
+	mutex     sync.Mutex
+	issued    int
+	refreshes int
+}
+
+func (p *mockTokenProvider) Token(ctx context.Context) (Token, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.issued == 0 {
+		p.issued = 1
+	}
+	return Token{Value: fmt.Sprintf("token-%d", p.issued)}, nil
+}
+
+func (p *mockTokenProvider) Refresh(ctx context.Context) (Token, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.refreshes++
+	p.issued++
+	return Token{Value: fmt.Sprintf("token-%d", p.issued)}, nil
+}
+
+// newAuthExecutionServer returns an Execution Service that only accepts the given
+// bearer token and records the Authorization headers it receives
+func newAuthExecutionServer(t *testing.T, validToken string, headers *[]string) *httptest.Server {
+	var mutex sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		*headers = append(*headers, r.Header.Get("Authorization"))
+		mutex.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer "+validToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			var update domain.ExecutionUpdateRequest
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil || update.Version != 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(domain.ExecutionUpdateResponse{ID: 1, Version: 2})
+			return
+		}
+		json.NewEncoder(w).Encode(domain.ExecutionResponse{ID: 1, Version: 1})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestExecutionServiceClientWithTokenProvider(t *testing.T, baseURL string, provider TokenProvider) *ExecutionServiceClient {
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	resilienceConfig := utils.GetDefaultResilienceConfig()
+	resilienceConfig.RetryConfig.MaxAttempts = 1
+
+	return NewExecutionServiceClient(ExecutionServiceClientConfig{
+		ExecutionService:  config.ExecutionServiceConfig{BaseURL: baseURL, Timeout: time.Second},
+		Logger:            appLogger,
+		Metrics:           appMetrics,
+		ResilienceManager: utils.NewResilienceManager(resilienceConfig, appLogger, appMetrics),
+		TokenProvider:     provider,
+	})
+}
+
+func TestExecutionServiceClient_AttachesBearerToken(t *testing.T) {
+	var headers []string
+	server := newAuthExecutionServer(t, "token-1", &headers)
+	client := newTestExecutionServiceClientWithTokenProvider(t, server.URL, &mockTokenProvider{})
+	ctx := context.Background()
+
+	_, err := client.GetExecution(ctx, 1)
+	require.NoError(t, err)
+
+	_, err = client.UpdateExecution(ctx, 1, &domain.ExecutionUpdateRequest{QuantityFilled: 100, AveragePrice: 10, Version: 1})
+	require.NoError(t, err)
+
+	assert.True(t, client.IsHealthy(ctx))
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-1"}, headers)
+}
+
+func TestExecutionServiceClient_RefreshesTokenOnUnauthorized(t *testing.T) {
+	var headers []string
+	server := newAuthExecutionServer(t, "token-2", &headers)
+	provider := &mockTokenProvider{}
+	client := newTestExecutionServiceClientWithTokenProvider(t, server.URL, provider)
+	ctx := context.Background()
+
+	// The update body is resent with the refreshed token
+	response, err := client.UpdateExecution(ctx, 1, &domain.ExecutionUpdateRequest{QuantityFilled: 100, AveragePrice: 10, Version: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Version)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, headers)
+	assert.Equal(t, 1, provider.refreshes)
+
+	// Later requests use the refreshed token
+	_, err = client.GetExecution(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-2", headers[2])
+}
+
+func TestBearerTokenTransport_RefreshesTokenOnlyOnce(t *testing.T) {
+	var headers []string
+	server := newAuthExecutionServer(t, "never-valid", &headers)
+	provider := &mockTokenProvider{}
+	client := &http.Client{Transport: &bearerTokenTransport{provider: provider, next: http.DefaultTransport}}
+
+	resp, err := client.Get(server.URL + "/api/v1/execution/1")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, headers)
+	assert.Equal(t, 1, provider.refreshes)
+}
+
+func TestStaticTokenProvider(t *testing.T) {
+	provider := NewStaticTokenProvider("secret")
+
+	token, err := provider.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "secret", token.Value)
+	assert.True(t, token.Expiry.IsZero())
+
+	token, err = provider.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "secret", token.Value)
+}
+
+func TestClientCredentialsTokenProvider(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "confirmation" || clientSecret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "execution:read execution:write", r.PostForm.Get("scope"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("access-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   300,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	clock := utils.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	provider := NewClientCredentialsTokenProvider(ClientCredentialsConfig{
+		TokenURL:     server.URL,
+		ClientID:     "confirmation",
+		ClientSecret: "s3cret",
+		Scopes:       []string{"execution:read", "execution:write"},
+		Clock:        clock,
+	})
+	ctx := context.Background()
+
+	token, err := provider.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.Value)
+	assert.Equal(t, clock.Now().Add(5*time.Minute), token.Expiry)
+
+	// The token is reused until it is about to expire
+	clock.Advance(4 * time.Minute)
+	token, err = provider.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.Value)
+	assert.Equal(t, int64(1), requests.Load())
+
+	clock.Advance(31 * time.Second)
+	token, err = provider.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", token.Value)
+
+	// Refresh always fetches a new token
+	token, err = provider.Refresh(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "access-3", token.Value)
+}
+
+func TestClientCredentialsTokenProvider_RejectedCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewClientCredentialsTokenProvider(ClientCredentialsConfig{TokenURL: server.URL, ClientID: "confirmation"})
+
+	_, err := provider.Token(context.Background())
+	assert.ErrorContains(t, err, "token endpoint returned status 401")
+}
+
+func TestNewTokenProviderFromConfig(t *testing.T) {
+	assert.Nil(t, NewTokenProviderFromConfig(config.AuthConfig{}))
+	assert.IsType(t, &StaticTokenProvider{}, NewTokenProviderFromConfig(config.AuthConfig{Token: "secret"}))
+	assert.IsType(t, &ClientCredentialsTokenProvider{}, NewTokenProviderFromConfig(config.AuthConfig{
+		TokenURL: "https://auth.example.com/token",
+		ClientID: "confirmation",
+	}))
+}
@@ -4,23 +4,153 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/kasbench/globeco-confirmation-service/internal/config"
 	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
 	"go.uber.org/zap"
 )
 
+// ValidationSeverity controls whether a rule violation is reported as an error or a warning
+type ValidationSeverity string
+
+const (
+	// SeverityError reports the violation as a validation error
+	SeverityError ValidationSeverity = "error"
+	// SeverityWarning reports the violation as a validation warning
+	SeverityWarning ValidationSeverity = "warning"
+)
+
+const (
+	// defaultFutureToleranceSeconds allows for clock skew between producers and this service
+	defaultFutureToleranceSeconds = 3600
+	// defaultMaxTimestampAge is the age beyond which a receivedTimestamp is flagged as old
+	defaultMaxTimestampAge = 365 * 24 * time.Hour
+)
+
+// Default formats of fill string fields
+var (
+	defaultTickerPattern      = regexp.MustCompile(`^[A-Z]{1,5}$`)
+	defaultSecurityIDPattern  = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+	defaultDestinationPattern = regexp.MustCompile(`^[A-Z]{2,4}$`)
+)
+
+// MissingFieldMode controls how omitted optional fill fields are handled
+type MissingFieldMode string
+
+const (
+	// MissingFieldStrict leaves omitted fields at zero and reports warnings
+	MissingFieldStrict MissingFieldMode = "strict"
+	// MissingFieldLenient fills in defaults for omitted fields before validation
+	MissingFieldLenient MissingFieldMode = "lenient"
+)
+
 // ValidationService handles comprehensive validation of fill messages
 type ValidationService struct {
-	logger *logger.Logger
+	logger                *logger.Logger
+	metrics               *metrics.Metrics
+	latestVersionSentinel int
+	missingFieldMode      MissingFieldMode
+	dataUtils             *utils.DataUtils
+	timeUtils             *utils.TimeUtils
+
+	// Thresholds can be updated while fills are being validated
+	thresholdsMutex sync.RWMutex
+	thresholds      ValidationThresholds
+
+	// Expected formats of the ticker, security ID and destination
+	tickerPattern      *regexp.Regexp
+	securityIDPattern  *regexp.Regexp
+	destinationPattern *regexp.Regexp
+
+	// Recent prices per security for the price anomaly check; nil when disabled
+	priceHistory                 *priceHistory
+	priceAnomalyTolerancePercent float64
+}
+
+// ValidationThresholds are the validation limits and severities that can be changed
+// on a running service. Zero values fall back to the same defaults as ValidationConfig.
+type ValidationThresholds struct {
+	SentBeforeReceivedSeverity   ValidationSeverity
+	LastFilledBeforeSentSeverity ValidationSeverity
+	MaxFillsPerFilledShare       float64 // 0 disables the check
+	ExcessiveFillCountSeverity   ValidationSeverity
+	TotalAmountTolerancePercent  float64
+	FutureToleranceSeconds       int64
+	MaxTimestampAge              time.Duration
+}
+
+// withDefaults returns the thresholds with unset values replaced by their defaults
+func (t ValidationThresholds) withDefaults() ValidationThresholds {
+	if t.SentBeforeReceivedSeverity == "" {
+		t.SentBeforeReceivedSeverity = SeverityError
+	}
+	if t.LastFilledBeforeSentSeverity == "" {
+		t.LastFilledBeforeSentSeverity = SeverityError
+	}
+	if t.ExcessiveFillCountSeverity == "" {
+		t.ExcessiveFillCountSeverity = SeverityWarning
+	}
+	if t.TotalAmountTolerancePercent <= 0 {
+		t.TotalAmountTolerancePercent = 1.0
+	}
+	if t.FutureToleranceSeconds <= 0 {
+		t.FutureToleranceSeconds = defaultFutureToleranceSeconds
+	}
+	if t.MaxTimestampAge <= 0 {
+		t.MaxTimestampAge = defaultMaxTimestampAge
+	}
+	return t
+}
+
+// ValidationThresholdsFromConfig returns the thresholds set by the validation configuration
+func ValidationThresholdsFromConfig(validation config.ValidationConfig) ValidationThresholds {
+	return ValidationThresholds{
+		SentBeforeReceivedSeverity:   ValidationSeverity(validation.SentBeforeReceivedSeverity),
+		LastFilledBeforeSentSeverity: ValidationSeverity(validation.LastFilledBeforeSentSeverity),
+		MaxFillsPerFilledShare:       validation.MaxFillsPerFilledShare,
+		ExcessiveFillCountSeverity:   ValidationSeverity(validation.ExcessiveFillCountSeverity),
+		TotalAmountTolerancePercent:  validation.TotalAmountTolerancePercent,
+		FutureToleranceSeconds:       validation.FutureToleranceSeconds,
+		MaxTimestampAge:              validation.MaxTimestampAge,
+	}
 }
 
 // ValidationConfig represents the configuration for the validation service
 type ValidationConfig struct {
-	Logger *logger.Logger
+	Logger                       *logger.Logger
+	Metrics                      *metrics.Metrics   // Optional; validation findings are counted by code and field
+	SentBeforeReceivedSeverity   ValidationSeverity // Severity when sentTimestamp < receivedTimestamp
+	LastFilledBeforeSentSeverity ValidationSeverity // Severity when lastFilledTimestamp < sentTimestamp
+	LatestVersionSentinel        int                // Negative version meaning "use the current execution version"; 0 disables
+	MissingFieldMode             MissingFieldMode   // Defaults to strict
+	MaxFillsPerFilledShare       float64            // Largest plausible numberOfFills per filled share; 0 disables
+	ExcessiveFillCountSeverity   ValidationSeverity // Severity when numberOfFills exceeds that limit; defaults to warning
+	TotalAmountTolerancePercent  float64            // Allowed totalAmount deviation from quantityFilled * averagePrice; defaults to 1.0
+	FutureToleranceSeconds       int64              // How far timestamps may be ahead of now before a warning; defaults to 3600
+	MaxTimestampAge              time.Duration      // Age beyond which receivedTimestamp is flagged as old; defaults to 1 year
+	Clock                        utils.Clock        // Tells the time for the timestamp checks; defaults to the system clock
+
+	// Warn when a fill's averagePrice is more than PriceAnomalyTolerancePercent (default
+	// 20) away from the moving average of the last PriceAnomalyWindow prices for the
+	// security. 0 disables the check. Prices are kept for at most
+	// PriceAnomalyMaxSecurities securities (default 10000).
+	PriceAnomalyWindow           int
+	PriceAnomalyTolerancePercent float64
+	PriceAnomalyMaxSecurities    int
+
+	// Expected formats of fill string fields; nil uses the defaults of 1-5 uppercase
+	// letters for tickers, alphanumeric security IDs and 2-4 uppercase letter destinations
+	TickerPattern      *regexp.Regexp
+	SecurityIDPattern  *regexp.Regexp
+	DestinationPattern *regexp.Regexp
 }
 
 // ValidationResult represents the result of validation
@@ -30,12 +160,9 @@ type ValidationResult struct {
 	Warnings []ValidationWarning
 }
 
-// ValidationError represents a validation error
-type ValidationError struct {
-	Field   string `json:"field"`
-	Code    string `json:"code"`
-	Message string `json:"message"`
-}
+// ValidationError represents a validation error. It is the domain field error so
+// validation results can be attached to a domain.DomainError unchanged.
+type ValidationError = domain.FieldError
 
 // ValidationWarning represents a validation warning
 type ValidationWarning struct {
@@ -46,12 +173,71 @@ type ValidationWarning struct {
 
 // NewValidationService creates a new validation service
 func NewValidationService(config ValidationConfig) *ValidationService {
-	return &ValidationService{
-		logger: config.Logger,
+	if config.MissingFieldMode == "" {
+		config.MissingFieldMode = MissingFieldStrict
+	}
+	if config.TickerPattern == nil {
+		config.TickerPattern = defaultTickerPattern
+	}
+	if config.SecurityIDPattern == nil {
+		config.SecurityIDPattern = defaultSecurityIDPattern
+	}
+	if config.DestinationPattern == nil {
+		config.DestinationPattern = defaultDestinationPattern
+	}
+
+	vs := &ValidationService{
+		logger:                config.Logger,
+		metrics:               config.Metrics,
+		latestVersionSentinel: config.LatestVersionSentinel,
+		missingFieldMode:      config.MissingFieldMode,
+		dataUtils:             utils.NewDataUtils(),
+		timeUtils:             utils.NewTimeUtilsWithClock(config.Clock),
+		tickerPattern:         config.TickerPattern,
+		securityIDPattern:     config.SecurityIDPattern,
+		destinationPattern:    config.DestinationPattern,
+		thresholds: ValidationThresholds{
+			SentBeforeReceivedSeverity:   config.SentBeforeReceivedSeverity,
+			LastFilledBeforeSentSeverity: config.LastFilledBeforeSentSeverity,
+			MaxFillsPerFilledShare:       config.MaxFillsPerFilledShare,
+			ExcessiveFillCountSeverity:   config.ExcessiveFillCountSeverity,
+			TotalAmountTolerancePercent:  config.TotalAmountTolerancePercent,
+			FutureToleranceSeconds:       config.FutureToleranceSeconds,
+			MaxTimestampAge:              config.MaxTimestampAge,
+		}.withDefaults(),
+	}
+
+	if config.PriceAnomalyWindow > 0 {
+		vs.priceHistory = newPriceHistory(config.PriceAnomalyWindow, config.PriceAnomalyMaxSecurities)
+		vs.priceAnomalyTolerancePercent = config.PriceAnomalyTolerancePercent
+		if vs.priceAnomalyTolerancePercent <= 0 {
+			vs.priceAnomalyTolerancePercent = defaultPriceAnomalyTolerancePercent
+		}
 	}
+
+	return vs
 }
 
-// ValidateFillMessage performs comprehensive validation of a fill message
+// Thresholds returns the validation thresholds in effect
+func (vs *ValidationService) Thresholds() ValidationThresholds {
+	vs.thresholdsMutex.RLock()
+	defer vs.thresholdsMutex.RUnlock()
+
+	return vs.thresholds
+}
+
+// UpdateThresholds replaces the validation thresholds; fills already being validated
+// finish with the previous ones
+func (vs *ValidationService) UpdateThresholds(thresholds ValidationThresholds) {
+	thresholds = thresholds.withDefaults()
+
+	vs.thresholdsMutex.Lock()
+	vs.thresholds = thresholds
+	vs.thresholdsMutex.Unlock()
+}
+
+// ValidateFillMessage performs comprehensive validation of a fill message.
+// In lenient mode omitted optional fields are first filled in on the fill itself.
 func (vs *ValidationService) ValidateFillMessage(ctx context.Context, fill *domain.Fill) *ValidationResult {
 	result := &ValidationResult{
 		IsValid:  true,
@@ -59,6 +245,10 @@ func (vs *ValidationService) ValidateFillMessage(ctx context.Context, fill *doma
 		Warnings: []ValidationWarning{},
 	}
 
+	if vs.missingFieldMode == MissingFieldLenient {
+		vs.applyMissingFieldDefaults(ctx, fill)
+	}
+
 	vs.logger.WithContext(ctx).Debug("Starting comprehensive fill message validation",
 		zap.Int64("fill_id", fill.ID),
 		zap.Int64("execution_service_id", fill.ExecutionServiceID),
@@ -72,6 +262,7 @@ func (vs *ValidationService) ValidateFillMessage(ctx context.Context, fill *doma
 
 	// 3. Business Rules Validation
 	vs.validateBusinessRules(ctx, fill, result)
+	vs.checkPriceAnomaly(fill, result)
 
 	// 4. Schema Validation
 	vs.validateSchema(fill, result)
@@ -85,6 +276,14 @@ func (vs *ValidationService) ValidateFillMessage(ctx context.Context, fill *doma
 	// 7. Timestamp Validation
 	vs.validateTimestamps(fill, result)
 
+	vs.recordFindings(ctx, fill, result)
+
+	// Only fills that pass validation feed the price history, so rejected messages
+	// cannot skew the moving average
+	if result.IsValid {
+		vs.recordPrice(fill)
+	}
+
 	// Log validation results
 	if !result.IsValid {
 		vs.logger.WithContext(ctx).Warn("Fill message validation failed",
@@ -106,6 +305,138 @@ func (vs *ValidationService) ValidateFillMessage(ctx context.Context, fill *doma
 	return result
 }
 
+// Codes of findings from validating a fill against its current execution
+const (
+	CodeExecutionIDMismatch        = "EXECUTION_ID_MISMATCH"
+	CodeTradeTypeMismatch          = "TRADE_TYPE_MISMATCH"
+	CodeDestinationMismatch        = "DESTINATION_MISMATCH"
+	CodeSecurityIDMismatch         = "SECURITY_ID_MISMATCH"
+	CodeQuantityFilledExceedsTotal = "QUANTITY_FILLED_EXCEEDS_TOTAL"
+	CodePossibleCorrection         = "POSSIBLE_CORRECTION"
+)
+
+// ValidateAgainstExecution checks that a fill is consistent with the execution it
+// updates, as currently held by the Execution Service. Errors are reported in the
+// order the checks run, so the first one is the most fundamental mismatch.
+func (vs *ValidationService) ValidateAgainstExecution(ctx context.Context, fill *domain.Fill, execution *domain.ExecutionResponse) *ValidationResult {
+	result := &ValidationResult{
+		IsValid:  true,
+		Errors:   []ValidationError{},
+		Warnings: []ValidationWarning{},
+	}
+
+	if fill.ExecutionServiceID != execution.ID {
+		result.addError("executionServiceId", CodeExecutionIDMismatch,
+			fmt.Sprintf("fill execution ID %d does not match current execution ID %d",
+				fill.ExecutionServiceID, execution.ID))
+	}
+
+	if fill.TradeType != execution.TradeType {
+		result.addError("tradeType", CodeTradeTypeMismatch,
+			fmt.Sprintf("fill trade type %s does not match execution trade type %s",
+				fill.TradeType, execution.TradeType))
+	}
+
+	if fill.Destination != execution.Destination {
+		result.addError("destination", CodeDestinationMismatch,
+			fmt.Sprintf("fill destination %s does not match execution destination %s",
+				fill.Destination, execution.Destination))
+	}
+
+	if fill.SecurityID != execution.SecurityID {
+		result.addError("securityId", CodeSecurityIDMismatch,
+			fmt.Sprintf("fill security ID %s does not match execution security ID %s",
+				fill.SecurityID, execution.SecurityID))
+	}
+
+	if fill.QuantityFilled > execution.Quantity {
+		result.addError("quantityFilled", CodeQuantityFilledExceedsTotal,
+			fmt.Sprintf("fill quantity filled %s exceeds total execution quantity %s",
+				domain.FormatQuantity(fill.QuantityFilled), domain.FormatQuantity(execution.Quantity)))
+	}
+
+	// A decreasing filled quantity is allowed, since corrections may lower it
+	if fill.QuantityFilled < execution.QuantityFilled {
+		result.addWarning("quantityFilled", CodePossibleCorrection,
+			fmt.Sprintf("fill quantity filled %s is less than current quantity filled %s",
+				domain.FormatQuantity(fill.QuantityFilled), domain.FormatQuantity(execution.QuantityFilled)))
+	}
+
+	vs.recordFindings(ctx, fill, result)
+
+	return result
+}
+
+// recordFindings counts each validation error and warning by code and field, and logs
+// every distinct code once with the fields it was raised for
+func (vs *ValidationService) recordFindings(ctx context.Context, fill *domain.Fill, result *ValidationResult) {
+	errorFields := make(map[string][]string)
+	var errorCodes []string
+	for _, e := range result.Errors {
+		if vs.metrics != nil {
+			vs.metrics.RecordValidationError(e.Code, e.Field)
+		}
+		if _, seen := errorFields[e.Code]; !seen {
+			errorCodes = append(errorCodes, e.Code)
+		}
+		errorFields[e.Code] = append(errorFields[e.Code], e.Field)
+	}
+
+	warningFields := make(map[string][]string)
+	var warningCodes []string
+	for _, w := range result.Warnings {
+		if vs.metrics != nil {
+			vs.metrics.RecordValidationWarning(w.Code, w.Field)
+		}
+		if _, seen := warningFields[w.Code]; !seen {
+			warningCodes = append(warningCodes, w.Code)
+		}
+		warningFields[w.Code] = append(warningFields[w.Code], w.Field)
+	}
+
+	for _, code := range errorCodes {
+		vs.logger.WithContext(ctx).Warn("Fill validation error",
+			zap.Int64("fill_id", fill.ID),
+			zap.String("code", code),
+			zap.Strings("fields", errorFields[code]),
+		)
+	}
+	for _, code := range warningCodes {
+		vs.logger.WithContext(ctx).Info("Fill validation warning",
+			zap.Int64("fill_id", fill.ID),
+			zap.String("code", code),
+			zap.Strings("fields", warningFields[code]),
+		)
+	}
+}
+
+// applyMissingFieldDefaults fills in optional fields that producers omit. A zero value is
+// treated as omitted: totalAmount is computed from quantity and price, numberOfFills defaults to 1.
+func (vs *ValidationService) applyMissingFieldDefaults(ctx context.Context, fill *domain.Fill) {
+	if fill.QuantityFilled <= 0 {
+		return
+	}
+
+	var defaulted []string
+
+	if fill.TotalAmount == 0 && fill.AveragePrice > 0 {
+		fill.TotalAmount = fill.QuantityFilled * fill.AveragePrice
+		defaulted = append(defaulted, "totalAmount")
+	}
+
+	if fill.NumberOfFills == 0 {
+		fill.NumberOfFills = 1
+		defaulted = append(defaulted, "numberOfFills")
+	}
+
+	if len(defaulted) > 0 {
+		vs.logger.WithContext(ctx).Debug("Applied defaults for missing fill fields",
+			zap.Int64("fill_id", fill.ID),
+			zap.Strings("fields", defaulted),
+		)
+	}
+}
+
 // validateRequiredFields validates that all required fields are present and non-zero
 func (vs *ValidationService) validateRequiredFields(fill *domain.Fill, result *ValidationResult) {
 	if fill.ExecutionServiceID <= 0 {
@@ -120,7 +451,7 @@ func (vs *ValidationService) validateRequiredFields(fill *domain.Fill, result *V
 		result.addError("averagePrice", "REQUIRED_FIELD", "averagePrice must be positive")
 	}
 
-	if fill.Version < 0 {
+	if fill.Version < 0 && !fill.IsLatestVersionSentinel(vs.latestVersionSentinel) {
 		result.addError("version", "REQUIRED_FIELD", "version must be non-negative")
 	}
 
@@ -180,11 +511,13 @@ func (vs *ValidationService) validateDataTypes(fill *domain.Fill, result *Valida
 
 // validateBusinessRules validates business-specific rules
 func (vs *ValidationService) validateBusinessRules(ctx context.Context, fill *domain.Fill, result *ValidationResult) {
+	thresholds := vs.Thresholds()
+
 	// Rule 1: Quantity filled should not exceed original quantity
 	if fill.QuantityFilled > fill.Quantity {
 		result.addError("quantityFilled", "BUSINESS_RULE_VIOLATION",
-			fmt.Sprintf("quantityFilled (%d) cannot exceed original quantity (%d)",
-				fill.QuantityFilled, fill.Quantity))
+			fmt.Sprintf("quantityFilled (%s) cannot exceed original quantity (%s)",
+				domain.FormatQuantity(fill.QuantityFilled), domain.FormatQuantity(fill.Quantity)))
 	}
 
 	// Rule 2: Average price should be reasonable (> 0 and < 10000)
@@ -226,35 +559,81 @@ func (vs *ValidationService) validateBusinessRules(ctx context.Context, fill *do
 	}
 
 	// Rule 5: Total amount should match quantity filled * average price (with tolerance)
-	expectedTotal := float64(fill.QuantityFilled) * fill.AveragePrice
-	tolerance := expectedTotal * 0.01 // 1% tolerance
-	if fill.TotalAmount > 0 && (fill.TotalAmount < expectedTotal-tolerance || fill.TotalAmount > expectedTotal+tolerance) {
+	expectedTotal := vs.dataUtils.CalculateTotalAmount(fill.QuantityFilled, fill.AveragePrice)
+	tolerance := expectedTotal * thresholds.TotalAmountTolerancePercent / 100
+	if fill.TotalAmount > 0 && !vs.dataUtils.ValidateTotalAmount(fill.QuantityFilled, fill.AveragePrice, fill.TotalAmount, tolerance) {
 		result.addWarning("totalAmount", "CALCULATION_MISMATCH",
 			fmt.Sprintf("totalAmount (%.2f) does not match expected value (%.2f) based on quantity and price",
 				fill.TotalAmount, expectedTotal))
 	}
 
+	if fill.TotalAmount == 0 && fill.QuantityFilled > 0 {
+		result.addWarning("totalAmount", "MISSING_FIELD",
+			"totalAmount is missing while quantityFilled is positive")
+	}
+
 	// Rule 6: Number of fills should be reasonable
 	if fill.NumberOfFills <= 0 && fill.QuantityFilled > 0 {
 		result.addWarning("numberOfFills", "INCONSISTENT_DATA",
 			"numberOfFills should be positive when quantityFilled is positive")
 	}
 
+	// Rule 6b: Number of fills should be plausible for the filled quantity; a sub-fill
+	// is rarely smaller than a share, so there should not be more sub-fills than shares filled.
+	// Fills of less than a share are allowed the fills of a whole share.
+	if thresholds.MaxFillsPerFilledShare > 0 && fill.NumberOfFills > 0 &&
+		float64(fill.NumberOfFills) > math.Max(1, fill.QuantityFilled)*thresholds.MaxFillsPerFilledShare {
+		result.addWithSeverity(thresholds.ExcessiveFillCountSeverity, "numberOfFills", "IMPLAUSIBLE_FILL_COUNT",
+			fmt.Sprintf("numberOfFills (%d) is implausible for quantityFilled (%s); at most %g fills per filled share are allowed",
+				fill.NumberOfFills, domain.FormatQuantity(fill.QuantityFilled), thresholds.MaxFillsPerFilledShare))
+	}
+
 	// Rule 7: If execution is FULL, quantity filled should equal total quantity
 	if fill.ExecutionStatus == "FULL" && fill.QuantityFilled != fill.Quantity {
 		result.addWarning("quantityFilled", "STATUS_QUANTITY_MISMATCH",
-			fmt.Sprintf("execution status is FULL but quantityFilled (%d) does not equal total quantity (%d)",
-				fill.QuantityFilled, fill.Quantity))
+			fmt.Sprintf("execution status is FULL but quantityFilled (%s) does not equal total quantity (%s)",
+				domain.FormatQuantity(fill.QuantityFilled), domain.FormatQuantity(fill.Quantity)))
 	}
 
 	// Rule 8: If execution is PARTIAL, quantity filled should be less than total
 	if fill.ExecutionStatus == "PARTIAL" && fill.QuantityFilled >= fill.Quantity {
 		result.addWarning("quantityFilled", "STATUS_QUANTITY_MISMATCH",
-			fmt.Sprintf("execution status is PARTIAL but quantityFilled (%d) is not less than total quantity (%d)",
-				fill.QuantityFilled, fill.Quantity))
+			fmt.Sprintf("execution status is PARTIAL but quantityFilled (%s) is not less than total quantity (%s)",
+				domain.FormatQuantity(fill.QuantityFilled), domain.FormatQuantity(fill.Quantity)))
 	}
 }
 
+// checkPriceAnomaly warns when the fill's average price is outside the tolerance band
+// around the moving average of recent prices for the security
+func (vs *ValidationService) checkPriceAnomaly(fill *domain.Fill, result *ValidationResult) {
+	if vs.priceHistory == nil || fill.SecurityID == "" || fill.AveragePrice <= 0 {
+		return
+	}
+
+	previous := vs.priceHistory.prices(fill.SecurityID)
+	averages := vs.dataUtils.CalculateMovingAverage(previous, vs.priceHistory.window)
+	if len(averages) == 0 {
+		return // Not enough history yet
+	}
+
+	movingAverage := averages[len(averages)-1]
+	tolerance := movingAverage * vs.priceAnomalyTolerancePercent / 100
+	if !vs.dataUtils.IsWithinTolerance(fill.AveragePrice, movingAverage, tolerance) {
+		result.addWarning("averagePrice", CodePriceAnomaly,
+			fmt.Sprintf("averagePrice (%.2f) differs from the moving average (%.2f) of the last %d prices for security %s by more than %g%%",
+				fill.AveragePrice, movingAverage, vs.priceHistory.window, fill.SecurityID, vs.priceAnomalyTolerancePercent))
+	}
+}
+
+// recordPrice adds the fill's average price to the price history of its security
+func (vs *ValidationService) recordPrice(fill *domain.Fill) {
+	if vs.priceHistory == nil || fill.SecurityID == "" || fill.AveragePrice <= 0 {
+		return
+	}
+
+	vs.priceHistory.record(fill.SecurityID, fill.AveragePrice)
+}
+
 // validateSchema validates the JSON schema structure
 func (vs *ValidationService) validateSchema(fill *domain.Fill, result *ValidationResult) {
 	// Try to marshal and unmarshal to validate JSON structure
@@ -328,25 +707,30 @@ func (vs *ValidationService) validateRanges(fill *domain.Fill, result *Validatio
 
 // validateFormats validates string field formats
 func (vs *ValidationService) validateFormats(fill *domain.Fill, result *ValidationResult) {
-	// Validate ticker format (typically 1-5 uppercase letters)
-	tickerRegex := regexp.MustCompile(`^[A-Z]{1,5}$`)
-	if !tickerRegex.MatchString(fill.Ticker) {
+	// Validate ticker format (by default 1-5 uppercase letters)
+	if !vs.tickerPattern.MatchString(fill.Ticker) {
 		result.addWarning("ticker", "INVALID_FORMAT",
-			fmt.Sprintf("ticker '%s' does not match expected format (1-5 uppercase letters)", fill.Ticker))
-	}
-
-	// Validate security ID format (should be alphanumeric)
-	securityIDRegex := regexp.MustCompile(`^[A-Za-z0-9]+$`)
-	if !securityIDRegex.MatchString(fill.SecurityID) {
-		result.addWarning("securityId", "INVALID_FORMAT",
-			fmt.Sprintf("securityId '%s' contains invalid characters", fill.SecurityID))
+			fmt.Sprintf("ticker '%s' does not match expected format (%s)", fill.Ticker,
+				describePattern(vs.tickerPattern, defaultTickerPattern, "1-5 uppercase letters")))
+	}
+
+	// Validate security ID format (by default alphanumeric)
+	if !vs.securityIDPattern.MatchString(fill.SecurityID) {
+		if vs.securityIDPattern == defaultSecurityIDPattern {
+			result.addWarning("securityId", "INVALID_FORMAT",
+				fmt.Sprintf("securityId '%s' contains invalid characters", fill.SecurityID))
+		} else {
+			result.addWarning("securityId", "INVALID_FORMAT",
+				fmt.Sprintf("securityId '%s' does not match expected format (%s)", fill.SecurityID,
+					describePattern(vs.securityIDPattern, defaultSecurityIDPattern, "")))
+		}
 	}
 
-	// Validate destination format (typically 2-4 uppercase letters)
-	destinationRegex := regexp.MustCompile(`^[A-Z]{2,4}$`)
-	if !destinationRegex.MatchString(fill.Destination) {
+	// Validate destination format (by default 2-4 uppercase letters)
+	if !vs.destinationPattern.MatchString(fill.Destination) {
 		result.addWarning("destination", "INVALID_FORMAT",
-			fmt.Sprintf("destination '%s' does not match expected format (2-4 uppercase letters)", fill.Destination))
+			fmt.Sprintf("destination '%s' does not match expected format (%s)", fill.Destination,
+				describePattern(vs.destinationPattern, defaultDestinationPattern, "2-4 uppercase letters")))
 	}
 
 	// Validate string lengths
@@ -363,43 +747,49 @@ func (vs *ValidationService) validateFormats(fill *domain.Fill, result *Validati
 	}
 }
 
+// describePattern describes an expected format in warnings: in words for the default
+// pattern, including a configured copy of it, and as the regular expression otherwise
+func describePattern(pattern, defaultPattern *regexp.Regexp, defaultDescription string) string {
+	if pattern.String() == defaultPattern.String() && defaultDescription != "" {
+		return defaultDescription
+	}
+	return "pattern " + pattern.String()
+}
+
 // validateTimestamps validates timestamp fields and their relationships
 func (vs *ValidationService) validateTimestamps(fill *domain.Fill, result *ValidationResult) {
-	now := time.Now().Unix()
-
-	// Validate timestamps are not in the future (with 1 hour tolerance for clock skew)
-	futureThreshold := float64(now + 3600)
+	thresholds := vs.Thresholds()
 
-	if fill.ReceivedTimestamp > futureThreshold {
+	// Validate timestamps are not in the future (with a tolerance for clock skew)
+	if vs.timeUtils.IsTimestampInFuture(fill.ReceivedTimestamp, thresholds.FutureToleranceSeconds) {
 		result.addWarning("receivedTimestamp", "FUTURE_TIMESTAMP", "receivedTimestamp is in the future")
 	}
 
-	if fill.SentTimestamp > futureThreshold {
+	if vs.timeUtils.IsTimestampInFuture(fill.SentTimestamp, thresholds.FutureToleranceSeconds) {
 		result.addWarning("sentTimestamp", "FUTURE_TIMESTAMP", "sentTimestamp is in the future")
 	}
 
-	if fill.LastFilledTimestamp > futureThreshold {
+	if vs.timeUtils.IsTimestampInFuture(fill.LastFilledTimestamp, thresholds.FutureToleranceSeconds) {
 		result.addWarning("lastFilledTimestamp", "FUTURE_TIMESTAMP", "lastFilledTimestamp is in the future")
 	}
 
-	// Validate timestamps are not too old (more than 1 year)
-	oldThreshold := float64(now - 365*24*3600)
-
-	if fill.ReceivedTimestamp > 0 && fill.ReceivedTimestamp < oldThreshold {
-		result.addWarning("receivedTimestamp", "OLD_TIMESTAMP", "receivedTimestamp is more than 1 year old")
+	// Validate timestamps are not too old
+	if vs.timeUtils.IsTimestampTooOld(fill.ReceivedTimestamp, thresholds.MaxTimestampAge) {
+		result.addWarning("receivedTimestamp", "OLD_TIMESTAMP",
+			fmt.Sprintf("receivedTimestamp is more than %s old", formatTimestampAge(thresholds.MaxTimestampAge)))
 	}
 
 	// Validate timestamp ordering
 	if fill.ReceivedTimestamp > 0 && fill.SentTimestamp > 0 {
 		if fill.SentTimestamp < fill.ReceivedTimestamp {
-			result.addError("sentTimestamp", "INVALID_TIMESTAMP_ORDER",
+			result.addWithSeverity(thresholds.SentBeforeReceivedSeverity, "sentTimestamp", "INVALID_TIMESTAMP_ORDER",
 				"sentTimestamp cannot be before receivedTimestamp")
 		}
 	}
 
 	if fill.LastFilledTimestamp > 0 && fill.SentTimestamp > 0 {
 		if fill.LastFilledTimestamp < fill.SentTimestamp {
-			result.addError("lastFilledTimestamp", "INVALID_TIMESTAMP_ORDER",
+			result.addWithSeverity(thresholds.LastFilledBeforeSentSeverity, "lastFilledTimestamp", "INVALID_TIMESTAMP_ORDER",
 				"lastFilledTimestamp cannot be before sentTimestamp")
 		}
 	}
@@ -414,6 +804,22 @@ func (vs *ValidationService) validateTimestamps(fill *domain.Fill, result *Valid
 	}
 }
 
+// formatTimestampAge describes the staleness threshold in warnings, in days when it is
+// a whole number of them
+func formatTimestampAge(age time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case age == defaultMaxTimestampAge:
+		return "1 year"
+	case age == day:
+		return "1 day"
+	case age%day == 0:
+		return fmt.Sprintf("%d days", age/day)
+	default:
+		return age.String()
+	}
+}
+
 // Helper methods for ValidationResult
 func (vr *ValidationResult) addError(field, code, message string) {
 	vr.IsValid = false
@@ -432,6 +838,23 @@ func (vr *ValidationResult) addWarning(field, code, message string) {
 	})
 }
 
+func (vr *ValidationResult) addWithSeverity(severity ValidationSeverity, field, code, message string) {
+	if severity == SeverityWarning {
+		vr.addWarning(field, code, message)
+		return
+	}
+	vr.addError(field, code, message)
+}
+
+func (vr *ValidationResult) hasError(code string) bool {
+	for _, e := range vr.Errors {
+		if e.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
 // GetErrorSummary returns a summary of validation errors
 func (vr *ValidationResult) GetErrorSummary() string {
 	if len(vr.Errors) == 0 {
@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -437,6 +441,797 @@ func TestValidationService_ValidateFillMessage_TimestampValidation(t *testing.T)
 	}
 }
 
+func TestValidationService_ValidateFillMessage_TimestampThresholds(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	service := NewValidationService(ValidationConfig{Logger: appLogger, Clock: utils.NewFakeClock(now)})
+	ctx := context.Background()
+
+	newFill := func(received, sent, lastFilled time.Time) *domain.Fill {
+		return &domain.Fill{
+			ID:                  123,
+			ExecutionServiceID:  456,
+			ExecutionStatus:     "FULL",
+			TradeType:           "BUY",
+			Destination:         "ML",
+			SecurityID:          "SEC123",
+			Ticker:              "IBM",
+			Quantity:            1000,
+			ReceivedTimestamp:   float64(received.Unix()),
+			SentTimestamp:       float64(sent.Unix()),
+			LastFilledTimestamp: float64(lastFilled.Unix()),
+			QuantityFilled:      1000,
+			AveragePrice:        190.41,
+			NumberOfFills:       1,
+			TotalAmount:         190410,
+			Version:             1,
+		}
+	}
+	warningCodes := func(result *ValidationResult) map[string]string {
+		codes := make(map[string]string)
+		for _, warning := range result.Warnings {
+			codes[warning.Field] = warning.Code
+		}
+		return codes
+	}
+
+	tests := []struct {
+		name     string
+		fill     *domain.Fill
+		expected map[string]string
+	}{
+		{
+			name:     "an hour ahead is within the clock skew tolerance",
+			fill:     newFill(now.Add(time.Hour), now.Add(time.Hour), now.Add(time.Hour)),
+			expected: map[string]string{},
+		},
+		{
+			name: "more than an hour ahead is in the future",
+			fill: newFill(now, now, now.Add(time.Hour+time.Second)),
+			expected: map[string]string{
+				"lastFilledTimestamp": "FUTURE_TIMESTAMP",
+			},
+		},
+		{
+			name:     "exactly a year old is not flagged",
+			fill:     newFill(now.Add(-365*24*time.Hour), now.Add(-365*24*time.Hour), now.Add(-365*24*time.Hour)),
+			expected: map[string]string{},
+		},
+		{
+			name:     "older than a year is flagged",
+			fill:     newFill(now.Add(-365*24*time.Hour-time.Second), now.Add(-365*24*time.Hour-time.Second), now.Add(-365*24*time.Hour-time.Second)),
+			expected: map[string]string{"receivedTimestamp": "OLD_TIMESTAMP"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := service.ValidateFillMessage(ctx, tt.fill)
+			assert.True(t, result.IsValid)
+			assert.Equal(t, tt.expected, warningCodes(result))
+		})
+	}
+}
+
+func TestValidationService_ValidateFillMessage_ConfiguredTimestampThresholds(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	newFill := func(timestamp time.Time) *domain.Fill {
+		return &domain.Fill{
+			ID:                  123,
+			ExecutionServiceID:  456,
+			ExecutionStatus:     "FULL",
+			TradeType:           "BUY",
+			Destination:         "ML",
+			SecurityID:          "SEC123",
+			Ticker:              "IBM",
+			Quantity:            1000,
+			ReceivedTimestamp:   float64(timestamp.Unix()),
+			SentTimestamp:       float64(timestamp.Unix()),
+			LastFilledTimestamp: float64(timestamp.Unix()),
+			QuantityFilled:      1000,
+			AveragePrice:        190.41,
+			NumberOfFills:       1,
+			TotalAmount:         190410,
+			Version:             1,
+		}
+	}
+
+	t.Run("backfill within the max age", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:          appLogger,
+			MaxTimestampAge: 180 * 24 * time.Hour,
+			Clock:           utils.NewFakeClock(now),
+		})
+
+		result := service.ValidateFillMessage(ctx, newFill(now.AddDate(0, -2, 0)))
+		assert.True(t, result.IsValid)
+		assert.Empty(t, result.Warnings)
+
+		result = service.ValidateFillMessage(ctx, newFill(now.AddDate(0, -7, 0)))
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, "OLD_TIMESTAMP", result.Warnings[0].Code)
+		assert.Equal(t, "receivedTimestamp is more than 180 days old", result.Warnings[0].Message)
+	})
+
+	t.Run("tight future tolerance", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:                 appLogger,
+			FutureToleranceSeconds: 5,
+			Clock:                  utils.NewFakeClock(now),
+		})
+
+		result := service.ValidateFillMessage(ctx, newFill(now.Add(5*time.Second)))
+		assert.Empty(t, result.Warnings)
+
+		result = service.ValidateFillMessage(ctx, newFill(now.Add(10*time.Second)))
+		assert.Len(t, result.Warnings, 3)
+		for _, warning := range result.Warnings {
+			assert.Equal(t, "FUTURE_TIMESTAMP", warning.Code)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger, Clock: utils.NewFakeClock(now)})
+		assert.Equal(t, int64(3600), service.Thresholds().FutureToleranceSeconds)
+		assert.Equal(t, 365*24*time.Hour, service.Thresholds().MaxTimestampAge)
+
+		result := service.ValidateFillMessage(ctx, newFill(now.AddDate(0, -2, 0)))
+		assert.Empty(t, result.Warnings)
+
+		result = service.ValidateFillMessage(ctx, newFill(now.AddDate(-2, 0, 0)))
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, "receivedTimestamp is more than 1 year old", result.Warnings[0].Message)
+	})
+}
+
+func TestValidationService_ValidateFillMessage_TimestampOrderSeverity(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	fill := &domain.Fill{
+		ID:                 123,
+		ExecutionServiceID: 456,
+		ExecutionStatus:    "FULL",
+		TradeType:          "BUY",
+		Destination:        "ML",
+		SecurityID:         "SEC123",
+		Ticker:             "IBM",
+		Quantity:           1000,
+		ReceivedTimestamp:  float64(now - 3600),
+		SentTimestamp:      float64(now - 3700), // Before received
+		QuantityFilled:     1000,
+		AveragePrice:       190.41,
+		NumberOfFills:      1,
+		Version:            1,
+	}
+
+	t.Run("error by default", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger})
+
+		result := service.ValidateFillMessage(ctx, fill)
+
+		assert.False(t, result.IsValid)
+		require.NotEmpty(t, result.Errors)
+		assert.Equal(t, "INVALID_TIMESTAMP_ORDER", result.Errors[0].Code)
+	})
+
+	t.Run("warning when configured", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:                     appLogger,
+			SentBeforeReceivedSeverity: SeverityWarning,
+		})
+
+		result := service.ValidateFillMessage(ctx, fill)
+
+		assert.True(t, result.IsValid)
+		assert.Empty(t, result.Errors)
+
+		found := false
+		for _, warning := range result.Warnings {
+			if warning.Code == "INVALID_TIMESTAMP_ORDER" && warning.Field == "sentTimestamp" {
+				found = true
+				break
+			}
+		}
+		assert.True(t, found, "Expected sentTimestamp ordering warning")
+	})
+
+	t.Run("other relationship keeps its own severity", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:                     appLogger,
+			SentBeforeReceivedSeverity: SeverityWarning,
+		})
+
+		lateFill := *fill
+		lateFill.SentTimestamp = float64(now - 3500)
+		lateFill.LastFilledTimestamp = float64(now - 3600) // Before sent
+
+		result := service.ValidateFillMessage(ctx, &lateFill)
+
+		assert.False(t, result.IsValid)
+		require.NotEmpty(t, result.Errors)
+		assert.Equal(t, "lastFilledTimestamp", result.Errors[0].Field)
+	})
+
+	t.Run("updated thresholds apply to later fills", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger})
+		assert.False(t, service.ValidateFillMessage(ctx, fill).IsValid)
+
+		service.UpdateThresholds(ValidationThresholds{SentBeforeReceivedSeverity: SeverityWarning})
+
+		assert.True(t, service.ValidateFillMessage(ctx, fill).IsValid)
+		// Unset thresholds fall back to their defaults
+		assert.Equal(t, SeverityError, service.Thresholds().LastFilledBeforeSentSeverity)
+		assert.Equal(t, 1.0, service.Thresholds().TotalAmountTolerancePercent)
+	})
+}
+
+func TestValidationService_ValidateFillMessage_FillCountPlausibility(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := float64(time.Now().Unix())
+
+	newFill := func(quantityFilled float64, numberOfFills int) *domain.Fill {
+		return &domain.Fill{
+			ID:                  123,
+			ExecutionServiceID:  456,
+			ExecutionStatus:     "PART",
+			TradeType:           "BUY",
+			Destination:         "ML",
+			SecurityID:          "SEC123",
+			Ticker:              "IBM",
+			Quantity:            1000,
+			ReceivedTimestamp:   now,
+			SentTimestamp:       now,
+			LastFilledTimestamp: now,
+			QuantityFilled:      quantityFilled,
+			AveragePrice:        10,
+			NumberOfFills:       numberOfFills,
+			TotalAmount:         quantityFilled * 10,
+			Version:             1,
+		}
+	}
+
+	hasWarning := func(result *ValidationResult) bool {
+		for _, w := range result.Warnings {
+			if w.Code == "IMPLAUSIBLE_FILL_COUNT" && w.Field == "numberOfFills" {
+				return true
+			}
+		}
+		return false
+	}
+	hasError := func(result *ValidationResult) bool {
+		for _, e := range result.Errors {
+			if e.Code == "IMPLAUSIBLE_FILL_COUNT" && e.Field == "numberOfFills" {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("plausible ratio passes", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger, MaxFillsPerFilledShare: 1})
+
+		result := service.ValidateFillMessage(ctx, newFill(500, 12))
+
+		assert.True(t, result.IsValid)
+		assert.False(t, hasWarning(result))
+	})
+
+	t.Run("implausible ratio warns by default", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger, MaxFillsPerFilledShare: 1})
+
+		result := service.ValidateFillMessage(ctx, newFill(10, 3000))
+
+		assert.True(t, result.IsValid)
+		assert.True(t, hasWarning(result))
+	})
+
+	t.Run("fractional fill with one sub-fill passes", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger, MaxFillsPerFilledShare: 1})
+
+		result := service.ValidateFillMessage(ctx, newFill(0.5, 1))
+
+		assert.False(t, hasWarning(result))
+		assert.False(t, hasError(result))
+	})
+
+	t.Run("implausible ratio is an error when configured", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:                     appLogger,
+			MaxFillsPerFilledShare:     1,
+			ExcessiveFillCountSeverity: SeverityError,
+		})
+
+		result := service.ValidateFillMessage(ctx, newFill(10, 3000))
+
+		assert.False(t, result.IsValid)
+		assert.True(t, hasError(result))
+	})
+
+	t.Run("disabled without a limit", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger})
+
+		result := service.ValidateFillMessage(ctx, newFill(10, 3000))
+
+		assert.False(t, hasWarning(result))
+		assert.False(t, hasError(result))
+	})
+}
+
+func TestValidationService_ValidateFillMessage_TotalAmountTolerance(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := float64(time.Now().Unix())
+
+	// quantityFilled * averagePrice is 10000
+	newFill := func(totalAmount float64) *domain.Fill {
+		return &domain.Fill{
+			ID:                  123,
+			ExecutionServiceID:  456,
+			ExecutionStatus:     "FULL",
+			TradeType:           "BUY",
+			Destination:         "ML",
+			SecurityID:          "SEC123",
+			Ticker:              "IBM",
+			Quantity:            1000,
+			ReceivedTimestamp:   now,
+			SentTimestamp:       now,
+			LastFilledTimestamp: now,
+			QuantityFilled:      1000,
+			AveragePrice:        10,
+			NumberOfFills:       1,
+			TotalAmount:         totalAmount,
+			Version:             1,
+		}
+	}
+
+	hasMismatch := func(result *ValidationResult) bool {
+		for _, w := range result.Warnings {
+			if w.Code == "CALCULATION_MISMATCH" && w.Field == "totalAmount" {
+				return true
+			}
+		}
+		return false
+	}
+
+	tests := []struct {
+		name             string
+		tolerancePercent float64
+		totalAmount      float64
+		wantMismatch     bool
+	}{
+		{"default 1% allows 0.8%", 0, 10080, false},
+		{"default 1% flags 1.5%", 0, 10150, true},
+		{"0.5% allows 0.4%", 0.5, 10040, false},
+		{"0.5% flags 0.8%", 0.5, 9920, true},
+		{"2% allows 1.5%", 2, 10150, false},
+		{"2% flags 2.5%", 2, 9750, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewValidationService(ValidationConfig{
+				Logger:                      appLogger,
+				TotalAmountTolerancePercent: tt.tolerancePercent,
+			})
+
+			result := service.ValidateFillMessage(ctx, newFill(tt.totalAmount))
+
+			assert.True(t, result.IsValid)
+			assert.Equal(t, tt.wantMismatch, hasMismatch(result))
+		})
+	}
+}
+
+func TestValidationService_ValidateFillMessage_PriceAnomaly(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	now := float64(time.Now().Unix())
+
+	newFill := func(securityID string, averagePrice float64) *domain.Fill {
+		return &domain.Fill{
+			ID:                  123,
+			ExecutionServiceID:  456,
+			ExecutionStatus:     "FULL",
+			TradeType:           "BUY",
+			Destination:         "ML",
+			SecurityID:          securityID,
+			Ticker:              "IBM",
+			Quantity:            1000,
+			ReceivedTimestamp:   now,
+			SentTimestamp:       now,
+			LastFilledTimestamp: now,
+			QuantityFilled:      1000,
+			AveragePrice:        averagePrice,
+			NumberOfFills:       1,
+			TotalAmount:         1000 * averagePrice,
+			Version:             1,
+		}
+	}
+
+	hasAnomaly := func(result *ValidationResult) bool {
+		for _, w := range result.Warnings {
+			if w.Code == CodePriceAnomaly && w.Field == "averagePrice" {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("flags an outlier against the moving average", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:                       appLogger,
+			PriceAnomalyWindow:           4,
+			PriceAnomalyTolerancePercent: 10,
+		})
+
+		// Too little history to judge the first prices
+		for _, price := range []float64{100, 102, 98, 101} {
+			assert.False(t, hasAnomaly(service.ValidateFillMessage(ctx, newFill("SEC123", price))), price)
+		}
+
+		// The moving average of 100, 102, 98, 101 is 100.25
+		assert.False(t, hasAnomaly(service.ValidateFillMessage(ctx, newFill("SEC123", 108))), "within 10%")
+
+		result := service.ValidateFillMessage(ctx, newFill("SEC123", 150))
+		assert.True(t, result.IsValid, "anomalies are warnings")
+		assert.True(t, hasAnomaly(result))
+
+		// Other securities have their own history
+		assert.False(t, hasAnomaly(service.ValidateFillMessage(ctx, newFill("SEC999", 5))))
+	})
+
+	t.Run("invalid fills do not feed the history", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:                       appLogger,
+			PriceAnomalyWindow:           2,
+			PriceAnomalyTolerancePercent: 10,
+		})
+
+		for _, price := range []float64{100, 100} {
+			service.ValidateFillMessage(ctx, newFill("SEC123", price))
+		}
+
+		invalid := newFill("SEC123", 500)
+		invalid.TradeType = "HOLD"
+		require.False(t, service.ValidateFillMessage(ctx, invalid).IsValid)
+
+		assert.Equal(t, []float64{100, 100}, service.priceHistory.prices("SEC123"))
+		assert.False(t, hasAnomaly(service.ValidateFillMessage(ctx, newFill("SEC123", 105))))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger})
+
+		for _, price := range []float64{100, 100, 100, 100, 1000} {
+			assert.False(t, hasAnomaly(service.ValidateFillMessage(ctx, newFill("SEC123", price))))
+		}
+		assert.Nil(t, service.priceHistory)
+	})
+
+	t.Run("history is bounded", func(t *testing.T) {
+		history := newPriceHistory(3, 2)
+
+		for i := 1; i <= 5; i++ {
+			history.record("SEC1", float64(i))
+		}
+		assert.Equal(t, []float64{3, 4, 5}, history.prices("SEC1"))
+
+		history.record("SEC2", 1)
+		history.record("SEC3", 1)
+		assert.Equal(t, 2, history.size())
+		assert.Empty(t, history.prices("SEC1"), "the least recently seen security was evicted")
+	})
+}
+
+func TestValidationService_ValidateAgainstExecution(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	service := NewValidationService(ValidationConfig{Logger: appLogger})
+	ctx := context.Background()
+
+	newFill := func() *domain.Fill {
+		return &domain.Fill{
+			ExecutionServiceID: 456,
+			TradeType:          "BUY",
+			Destination:        "ML",
+			SecurityID:         "SEC123",
+			QuantityFilled:     600,
+			AveragePrice:       10,
+		}
+	}
+	newExecution := func() *domain.ExecutionResponse {
+		return &domain.ExecutionResponse{
+			ID:             456,
+			TradeType:      "BUY",
+			Destination:    "ML",
+			SecurityID:     "SEC123",
+			Quantity:       1000,
+			QuantityFilled: 500,
+		}
+	}
+
+	t.Run("consistent fill", func(t *testing.T) {
+		result := service.ValidateAgainstExecution(ctx, newFill(), newExecution())
+
+		assert.True(t, result.IsValid)
+		assert.Empty(t, result.Errors)
+		assert.Empty(t, result.Warnings)
+	})
+
+	t.Run("every mismatch is reported in check order", func(t *testing.T) {
+		fill := newFill()
+		fill.ExecutionServiceID = 789
+		fill.TradeType = "SELL"
+		fill.Destination = "GS"
+		fill.SecurityID = "SEC999"
+		fill.QuantityFilled = 1500
+
+		result := service.ValidateAgainstExecution(ctx, fill, newExecution())
+
+		assert.False(t, result.IsValid)
+		assert.Equal(t, []ValidationError{
+			{Field: "executionServiceId", Code: CodeExecutionIDMismatch, Message: "fill execution ID 789 does not match current execution ID 456"},
+			{Field: "tradeType", Code: CodeTradeTypeMismatch, Message: "fill trade type SELL does not match execution trade type BUY"},
+			{Field: "destination", Code: CodeDestinationMismatch, Message: "fill destination GS does not match execution destination ML"},
+			{Field: "securityId", Code: CodeSecurityIDMismatch, Message: "fill security ID SEC999 does not match execution security ID SEC123"},
+			{Field: "quantityFilled", Code: CodeQuantityFilledExceedsTotal, Message: "fill quantity filled 1500 exceeds total execution quantity 1000"},
+		}, result.Errors)
+	})
+
+	t.Run("decreasing filled quantity is a warning", func(t *testing.T) {
+		fill := newFill()
+		fill.QuantityFilled = 400
+
+		result := service.ValidateAgainstExecution(ctx, fill, newExecution())
+
+		assert.True(t, result.IsValid)
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, CodePossibleCorrection, result.Warnings[0].Code)
+		assert.Equal(t, "quantityFilled", result.Warnings[0].Field)
+	})
+}
+
+func TestValidationService_ValidateFillMessage_LatestVersionSentinel(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		sentinel      int
+		version       int
+		expectVersion bool // whether a version error is expected
+	}{
+		{"sentinel accepted", -1, -1, false},
+		{"other negative rejected", -1, -5, true},
+		{"sentinel rejected when disabled", 0, -1, true},
+		{"regular version accepted", -1, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewValidationService(ValidationConfig{Logger: appLogger, LatestVersionSentinel: tt.sentinel})
+
+			now := float64(time.Now().Unix())
+			fill := &domain.Fill{
+				ID:                  123,
+				ExecutionServiceID:  456,
+				ExecutionStatus:     "FULL",
+				TradeType:           "BUY",
+				Destination:         "ML",
+				SecurityID:          "SEC123",
+				Ticker:              "IBM",
+				Quantity:            1000,
+				ReceivedTimestamp:   now - 60,
+				SentTimestamp:       now - 50,
+				LastFilledTimestamp: now - 40,
+				QuantityFilled:      1000,
+				AveragePrice:        190.41,
+				NumberOfFills:       3,
+				TotalAmount:         190410.0,
+				Version:             tt.version,
+			}
+
+			result := service.ValidateFillMessage(context.Background(), fill)
+
+			hasVersionError := false
+			for _, validationErr := range result.Errors {
+				if validationErr.Field == "version" {
+					hasVersionError = true
+				}
+			}
+			assert.Equal(t, tt.expectVersion, hasVersionError)
+			assert.Equal(t, !tt.expectVersion, result.IsValid)
+		})
+	}
+}
+
+func TestValidationService_ValidateFillMessage_MissingFieldMode(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	// totalAmount and numberOfFills omitted by the producer
+	newFill := func() *domain.Fill {
+		now := float64(time.Now().Unix())
+		return &domain.Fill{
+			ID:                  123,
+			ExecutionServiceID:  456,
+			ExecutionStatus:     "FULL",
+			TradeType:           "BUY",
+			Destination:         "ML",
+			SecurityID:          "SEC123",
+			Ticker:              "IBM",
+			Quantity:            1000,
+			ReceivedTimestamp:   now - 60,
+			SentTimestamp:       now - 50,
+			LastFilledTimestamp: now - 40,
+			QuantityFilled:      1000,
+			AveragePrice:        190.41,
+			Version:             1,
+		}
+	}
+
+	warningFields := func(result *ValidationResult) []string {
+		fields := []string{}
+		for _, warning := range result.Warnings {
+			fields = append(fields, warning.Field)
+		}
+		return fields
+	}
+
+	t.Run("strict mode warns", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger})
+		fill := newFill()
+
+		result := service.ValidateFillMessage(context.Background(), fill)
+
+		assert.True(t, result.IsValid)
+		assert.Contains(t, warningFields(result), "totalAmount")
+		assert.Contains(t, warningFields(result), "numberOfFills")
+		assert.Equal(t, 0.0, fill.TotalAmount)
+		assert.Equal(t, 0, fill.NumberOfFills)
+	})
+
+	t.Run("lenient mode computes defaults", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger, MissingFieldMode: MissingFieldLenient})
+		fill := newFill()
+
+		result := service.ValidateFillMessage(context.Background(), fill)
+
+		assert.True(t, result.IsValid)
+		assert.Empty(t, result.Warnings)
+		assert.InDelta(t, 190410.0, fill.TotalAmount, 0.001)
+		assert.Equal(t, 1, fill.NumberOfFills)
+	})
+
+	t.Run("lenient mode keeps provided values", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger, MissingFieldMode: MissingFieldLenient})
+		fill := newFill()
+		fill.TotalAmount = 190000.0
+		fill.NumberOfFills = 3
+
+		service.ValidateFillMessage(context.Background(), fill)
+
+		assert.Equal(t, 190000.0, fill.TotalAmount)
+		assert.Equal(t, 3, fill.NumberOfFills)
+	})
+}
+
+func TestValidationService_ValidateFillMessage_RecordsFindingMetrics(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	now := float64(time.Now().Unix())
+	newFill := func() *domain.Fill {
+		return &domain.Fill{
+			ID:                  123,
+			ExecutionServiceID:  0, // REQUIRED_FIELD error
+			ExecutionStatus:     "FULL",
+			TradeType:           "BUY",
+			Destination:         "ML",
+			SecurityID:          "SEC123",
+			Ticker:              "IBM",
+			Quantity:            1000,
+			ReceivedTimestamp:   now - 60,
+			SentTimestamp:       now - 50,
+			LastFilledTimestamp: now - 40,
+			QuantityFilled:      1000,
+			AveragePrice:        190.41,
+			NumberOfFills:       1,
+			TotalAmount:         1.0, // CALCULATION_MISMATCH warning
+			Version:             1,
+		}
+	}
+
+	t.Run("enabled metrics", func(t *testing.T) {
+		appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+		service := NewValidationService(ValidationConfig{Logger: appLogger, Metrics: appMetrics})
+
+		result := service.ValidateFillMessage(context.Background(), newFill())
+		require.False(t, result.IsValid)
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(appMetrics.ValidationErrorsTotal.WithLabelValues("REQUIRED_FIELD", "executionServiceId")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(appMetrics.ValidationWarningsTotal.WithLabelValues("CALCULATION_MISMATCH", "totalAmount")))
+		assert.Equal(t, len(result.Errors), testutil.CollectAndCount(&appMetrics.ValidationErrorsTotal))
+	})
+
+	t.Run("disabled metrics", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger, Metrics: metrics.New(metrics.Config{Enabled: false})})
+
+		assert.NotPanics(t, func() {
+			service.ValidateFillMessage(context.Background(), newFill())
+		})
+	})
+}
+
 func TestValidationService_ValidateFillMessage_FormatValidation(t *testing.T) {
 	appLogger, err := logger.New(logger.Config{
 		Level:       "info",
@@ -532,6 +1327,98 @@ func TestValidationService_ValidateFillMessage_FormatValidation(t *testing.T) {
 	}
 }
 
+func TestValidationService_ValidateFillMessage_CustomFormatPatterns(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	newFill := func(ticker string) *domain.Fill {
+		return &domain.Fill{
+			ID:                 123,
+			ExecutionServiceID: 456,
+			ExecutionStatus:    "FULL",
+			TradeType:          "BUY",
+			Destination:        "ML",
+			SecurityID:         "SEC123",
+			Ticker:             ticker,
+			Quantity:           1000,
+			QuantityFilled:     1000,
+			AveragePrice:       190.41,
+			Version:            1,
+		}
+	}
+	tickerWarnings := func(result *ValidationResult) []ValidationWarning {
+		var warnings []ValidationWarning
+		for _, warning := range result.Warnings {
+			if warning.Field == "ticker" {
+				warnings = append(warnings, warning)
+			}
+		}
+		return warnings
+	}
+
+	t.Run("default pattern rejects class shares", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{Logger: appLogger})
+
+		warnings := tickerWarnings(service.ValidateFillMessage(ctx, newFill("BRK.B")))
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "ticker 'BRK.B' does not match expected format (1-5 uppercase letters)", warnings[0].Message)
+	})
+
+	t.Run("configured copy of the default pattern is described in words", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:        appLogger,
+			TickerPattern: regexp.MustCompile(defaultTickerPattern.String()),
+		})
+
+		warnings := tickerWarnings(service.ValidateFillMessage(ctx, newFill("BRK.B")))
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "ticker 'BRK.B' does not match expected format (1-5 uppercase letters)", warnings[0].Message)
+	})
+
+	t.Run("custom pattern allows dots and longer symbols", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:        appLogger,
+			TickerPattern: regexp.MustCompile(`^[A-Z]{1,6}(\.[A-Z])?$`),
+		})
+
+		for _, ticker := range []string{"BRK.B", "ABCDEF", "IBM"} {
+			assert.Empty(t, tickerWarnings(service.ValidateFillMessage(ctx, newFill(ticker))), ticker)
+		}
+
+		warnings := tickerWarnings(service.ValidateFillMessage(ctx, newFill("BRK.BB")))
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "INVALID_FORMAT", warnings[0].Code)
+		assert.Equal(t, `ticker 'BRK.BB' does not match expected format (pattern ^[A-Z]{1,6}(\.[A-Z])?$)`, warnings[0].Message)
+	})
+
+	t.Run("custom destination and security ID patterns", func(t *testing.T) {
+		service := NewValidationService(ValidationConfig{
+			Logger:             appLogger,
+			SecurityIDPattern:  regexp.MustCompile(`^SEC[0-9]+$`),
+			DestinationPattern: regexp.MustCompile(`^[A-Z]{2,6}$`),
+		})
+
+		fill := newFill("IBM")
+		fill.Destination = "NASDAQ"
+		fill.SecurityID = "ABC123"
+		result := service.ValidateFillMessage(ctx, fill)
+
+		var fields []string
+		for _, warning := range result.Warnings {
+			if warning.Code == "INVALID_FORMAT" {
+				fields = append(fields, warning.Field)
+			}
+		}
+		assert.Equal(t, []string{"securityId"}, fields)
+	})
+}
+
 func TestValidationResult_GetErrorSummary(t *testing.T) {
 	result := &ValidationResult{
 		IsValid: false,
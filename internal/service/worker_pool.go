@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// workerPoolEnabled reports whether fetched messages are handled on a worker pool
+// rather than one at a time by the consume loop
+func (kcs *KafkaConsumerService) workerPoolEnabled() bool {
+	return len(kcs.workerQueues) > 0
+}
+
+// startWorkers launches one goroutine per worker queue
+func (kcs *KafkaConsumerService) startWorkers(ctx context.Context) {
+	if kcs.workerLimiter != nil && kcs.metrics != nil {
+		kcs.metrics.SetConsumerWorkerLimit(kcs.workerLimiter.Limit())
+	}
+
+	for _, queue := range kcs.workerQueues {
+		kcs.wg.Add(1)
+		go kcs.runWorker(ctx, queue)
+	}
+}
+
+// dispatchMessage queues a fetched message for the worker that owns its partition, so
+// messages of a partition are handled and committed in order. A message that cannot
+// be queued before the consumer stops is left uncommitted for redelivery.
+func (kcs *KafkaConsumerService) dispatchMessage(ctx context.Context, message kafka.Message) {
+	queue := kcs.workerQueues[workerIndex(message, len(kcs.workerQueues))]
+
+	select {
+	case queue <- message:
+	case <-kcs.stopCh:
+	case <-ctx.Done():
+	}
+}
+
+// workerIndex maps a message's topic and partition to a worker
+func workerIndex(message kafka.Message, workers int) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(deliveryPartition(message)))
+	return int(hash.Sum32() % uint32(workers))
+}
+
+// runWorker handles queued messages until the consumer stops. Messages still queued
+// at that point are skipped; the partition is redelivered from the last commit.
+func (kcs *KafkaConsumerService) runWorker(ctx context.Context, queue <-chan kafka.Message) {
+	defer kcs.wg.Done()
+
+	for {
+		select {
+		case <-kcs.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case message := <-queue:
+			select {
+			case <-kcs.stopCh:
+				return
+			default:
+			}
+
+			if err := kcs.handleQueuedMessage(ctx, message); err != nil {
+				kcs.logger.WithContext(ctx).Error("Error processing message", zap.Error(err))
+			}
+		}
+	}
+}
+
+// handleQueuedMessage handles a message once the adaptive limit lets another worker
+// become active. Slow messages and transient failures lower the limit, so fewer
+// workers press on a struggling Execution Service.
+func (kcs *KafkaConsumerService) handleQueuedMessage(ctx context.Context, message kafka.Message) error {
+	if err := kcs.workerLimiter.Acquire(ctx); err != nil {
+		// The consumer stopped; the message is redelivered
+		return nil
+	}
+
+	handleCtx, cancel := context.WithTimeout(ctx, kcs.config.FetchTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := kcs.handleInFlightMessage(handleCtx, message)
+
+	if kcs.workerLimiter != nil {
+		kcs.workerLimiter.Release(time.Since(start), isTransientFailure(err))
+		if kcs.metrics != nil {
+			kcs.metrics.SetConsumerWorkerLimit(kcs.workerLimiter.Limit())
+		}
+	}
+
+	return err
+}
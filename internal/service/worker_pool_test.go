@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/internal/utils"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyMessageHandler waits for up to wantActive fills to be handled at once
+// and records the highest number it saw
+type concurrencyMessageHandler struct {
+	mutex      sync.Mutex
+	active     int
+	maxActive  int
+	wantActive int
+	delay      time.Duration
+}
+
+func (h *concurrencyMessageHandler) HandleFillMessage(ctx context.Context, fill *domain.Fill) error {
+	h.mutex.Lock()
+	h.active++
+	if h.active > h.maxActive {
+		h.maxActive = h.active
+	}
+	h.mutex.Unlock()
+
+	deadline := time.Now().Add(h.delay)
+	for time.Now().Before(deadline) {
+		h.mutex.Lock()
+		reached := h.maxActive >= h.wantActive
+		h.mutex.Unlock()
+		if reached {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	h.mutex.Lock()
+	h.active--
+	h.mutex.Unlock()
+	return nil
+}
+
+func (h *concurrencyMessageHandler) highestActive() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.maxActive
+}
+
+// delayMessageHandler handles every fill after a configurable delay
+type delayMessageHandler struct {
+	mutex sync.Mutex
+	delay time.Duration
+}
+
+func (h *delayMessageHandler) HandleFillMessage(ctx context.Context, fill *domain.Fill) error {
+	h.mutex.Lock()
+	delay := h.delay
+	h.mutex.Unlock()
+
+	time.Sleep(delay)
+	return nil
+}
+
+func (h *delayMessageHandler) setDelay(delay time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.delay = delay
+}
+
+// enableWorkerPool gives a test consumer a worker pool of the given size
+func enableWorkerPool(consumer *KafkaConsumerService, size int, limiter *utils.AdaptiveConcurrencyLimiter) {
+	consumer.workerQueues = make([]chan kafka.Message, size)
+	for i := range consumer.workerQueues {
+		consumer.workerQueues[i] = make(chan kafka.Message, 1)
+	}
+	consumer.workerLimiter = limiter
+}
+
+func TestKafkaConsumerService_WorkerPool_HandlesPartitionsConcurrently(t *testing.T) {
+	messages := newTestFillMessages(t, 2)
+	// Pick a second partition owned by the other worker
+	for workerIndex(messages[1], 2) == workerIndex(messages[0], 2) {
+		messages[1].Partition++
+	}
+
+	handler := &concurrencyMessageHandler{wantActive: 2, delay: 2 * time.Second}
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second, messages...)
+	enableWorkerPool(consumer, 2, nil)
+
+	consumer.mutex.Lock()
+	consumer.startConsuming(context.Background())
+	consumer.mutex.Unlock()
+
+	assert.Eventually(t, func() bool { return reader.committedCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, consumer.Stop(context.Background()))
+
+	assert.Equal(t, 2, handler.highestActive())
+}
+
+func TestKafkaConsumerService_WorkerPool_KeepsPartitionOrder(t *testing.T) {
+	handler := &failingFillsMessageHandler{}
+	consumer, reader := newTestKafkaConsumer(t, handler, time.Second, newTestFillMessages(t, 10)...)
+	enableWorkerPool(consumer, 4, nil)
+
+	consumer.mutex.Lock()
+	consumer.startConsuming(context.Background())
+	consumer.mutex.Unlock()
+
+	assert.Eventually(t, func() bool { return reader.committedCount() == 10 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, consumer.Stop(context.Background()))
+
+	assert.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, committedOffsets(reader))
+}
+
+func TestKafkaConsumerService_WorkerPool_AdaptsActiveWorkersToLatency(t *testing.T) {
+	handler := &delayMessageHandler{delay: 50 * time.Millisecond}
+	consumer, _ := newTestKafkaConsumer(t, handler, time.Second)
+	limiter := utils.NewAdaptiveConcurrencyLimiter(utils.AdaptiveConcurrencyConfig{
+		MinLimit:      1,
+		MaxLimit:      4,
+		LatencyTarget: 20 * time.Millisecond,
+	})
+	enableWorkerPool(consumer, 4, limiter)
+
+	messages := newTestFillMessages(t, 20)
+	next := 0
+	handle := func() {
+		require.NoError(t, consumer.handleQueuedMessage(context.Background(), messages[next]))
+		next++
+	}
+
+	// Rising latency cuts the number of active workers
+	handle()
+	assert.Equal(t, 2, limiter.Limit())
+	handle()
+	assert.Equal(t, 1, limiter.Limit())
+	assert.Equal(t, float64(1), testutil.ToFloat64(consumer.metrics.ConsumerWorkerLimit))
+
+	// Recovery lets workers back in one at a time
+	handler.setDelay(0)
+	handle()
+	assert.Equal(t, 2, limiter.Limit())
+	handle()
+	handle()
+	assert.Equal(t, 3, limiter.Limit())
+	assert.Equal(t, float64(3), testutil.ToFloat64(consumer.metrics.ConsumerWorkerLimit))
+}
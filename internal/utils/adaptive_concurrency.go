@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AdaptiveConcurrencyConfig represents the configuration for an adaptive concurrency limiter
+type AdaptiveConcurrencyConfig struct {
+	MinLimit       int           // Lowest concurrency the limiter backs off to
+	MaxLimit       int           // Highest concurrency the limiter grows to. 0 disables the limiter
+	LatencyTarget  time.Duration // Calls slower than this count as congestion
+	DecreaseFactor float64       // Multiplier applied to the limit on congestion; defaults to 0.5
+}
+
+// AdaptiveConcurrencyStats represents adaptive concurrency limiter statistics
+type AdaptiveConcurrencyStats struct {
+	Limit         int    `json:"limit"`
+	MinLimit      int    `json:"min_limit"`
+	MaxLimit      int    `json:"max_limit"`
+	InFlight      int    `json:"in_flight"`
+	LatencyTarget string `json:"latency_target"`
+	Increases     int64  `json:"increases"`
+	Decreases     int64  `json:"decreases"`
+}
+
+// AdaptiveConcurrencyLimiter bounds concurrent downstream calls with an AIMD limit:
+// the limit grows by one after a limit's worth of fast, successful calls and is cut
+// multiplicatively when a call is slow or fails
+type AdaptiveConcurrencyLimiter struct {
+	mutex          sync.Mutex
+	limit          int
+	minLimit       int
+	maxLimit       int
+	latencyTarget  time.Duration
+	decreaseFactor float64
+	inFlight       int
+	goodSamples    int
+	released       chan struct{} // Closed and replaced whenever a slot may have opened
+
+	increases int64
+	decreases int64
+}
+
+// NewAdaptiveConcurrencyLimiter creates a limiter starting at the maximum limit,
+// or returns nil when the limiter is disabled
+func NewAdaptiveConcurrencyLimiter(config AdaptiveConcurrencyConfig) *AdaptiveConcurrencyLimiter {
+	if config.MaxLimit <= 0 {
+		return nil
+	}
+
+	minLimit := config.MinLimit
+	if minLimit < 1 {
+		minLimit = 1
+	}
+	if minLimit > config.MaxLimit {
+		minLimit = config.MaxLimit
+	}
+
+	decreaseFactor := config.DecreaseFactor
+	if decreaseFactor <= 0 || decreaseFactor >= 1 {
+		decreaseFactor = 0.5
+	}
+
+	return &AdaptiveConcurrencyLimiter{
+		limit:          config.MaxLimit,
+		minLimit:       minLimit,
+		maxLimit:       config.MaxLimit,
+		latencyTarget:  config.LatencyTarget,
+		decreaseFactor: decreaseFactor,
+		released:       make(chan struct{}),
+	}
+}
+
+// Acquire waits for a free slot under the current limit. A nil limiter never waits.
+func (l *AdaptiveConcurrencyLimiter) Acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mutex.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.mutex.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mutex.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release frees a slot and adjusts the limit from the call's latency and outcome
+func (l *AdaptiveConcurrencyLimiter) Release(latency time.Duration, failed bool) {
+	if l == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.inFlight--
+
+	if failed || (l.latencyTarget > 0 && latency > l.latencyTarget) {
+		l.goodSamples = 0
+		if l.limit > l.minLimit {
+			l.limit = int(float64(l.limit) * l.decreaseFactor)
+			if l.limit < l.minLimit {
+				l.limit = l.minLimit
+			}
+			l.decreases++
+		}
+	} else {
+		l.goodSamples++
+		if l.goodSamples >= l.limit && l.limit < l.maxLimit {
+			l.goodSamples = 0
+			l.limit++
+			l.increases++
+		}
+	}
+
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// Limit returns the current concurrency limit
+func (l *AdaptiveConcurrencyLimiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.limit
+}
+
+// GetStats returns adaptive concurrency limiter statistics
+func (l *AdaptiveConcurrencyLimiter) GetStats() AdaptiveConcurrencyStats {
+	if l == nil {
+		return AdaptiveConcurrencyStats{}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return AdaptiveConcurrencyStats{
+		Limit:         l.limit,
+		MinLimit:      l.minLimit,
+		MaxLimit:      l.maxLimit,
+		InFlight:      l.inFlight,
+		LatencyTarget: l.latencyTarget.String(),
+		Increases:     l.increases,
+		Decreases:     l.decreases,
+	}
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// observe runs one call through the limiter with the given latency and outcome
+func observe(t *testing.T, limiter *AdaptiveConcurrencyLimiter, latency time.Duration, failed bool) {
+	require.NoError(t, limiter.Acquire(context.Background()))
+	limiter.Release(latency, failed)
+}
+
+func TestNewAdaptiveConcurrencyLimiter_DisabledWithoutMax(t *testing.T) {
+	limiter := NewAdaptiveConcurrencyLimiter(AdaptiveConcurrencyConfig{MinLimit: 1})
+
+	assert.Nil(t, limiter)
+	assert.NoError(t, limiter.Acquire(context.Background()))
+	limiter.Release(time.Second, true)
+	assert.Equal(t, 0, limiter.Limit())
+	assert.Equal(t, AdaptiveConcurrencyStats{}, limiter.GetStats())
+}
+
+func TestAdaptiveConcurrencyLimiter_RisingLatencyReducesLimit(t *testing.T) {
+	limiter := NewAdaptiveConcurrencyLimiter(AdaptiveConcurrencyConfig{
+		MinLimit:      2,
+		MaxLimit:      16,
+		LatencyTarget: 100 * time.Millisecond,
+	})
+	assert.Equal(t, 16, limiter.Limit())
+
+	// Fast calls keep the limit at the max
+	observe(t, limiter, 10*time.Millisecond, false)
+	assert.Equal(t, 16, limiter.Limit())
+
+	// Each slow call halves the limit, down to the min
+	observe(t, limiter, 250*time.Millisecond, false)
+	assert.Equal(t, 8, limiter.Limit())
+	observe(t, limiter, 250*time.Millisecond, false)
+	assert.Equal(t, 4, limiter.Limit())
+	observe(t, limiter, 250*time.Millisecond, false)
+	observe(t, limiter, 250*time.Millisecond, false)
+	assert.Equal(t, 2, limiter.Limit())
+
+	// Failures back off too, regardless of latency
+	limiter = NewAdaptiveConcurrencyLimiter(AdaptiveConcurrencyConfig{MinLimit: 1, MaxLimit: 4, LatencyTarget: time.Second})
+	observe(t, limiter, time.Millisecond, true)
+	assert.Equal(t, 2, limiter.Limit())
+
+	stats := limiter.GetStats()
+	assert.Equal(t, int64(1), stats.Decreases)
+	assert.Equal(t, 0, stats.InFlight)
+}
+
+func TestAdaptiveConcurrencyLimiter_RecoveryIncreasesLimit(t *testing.T) {
+	limiter := NewAdaptiveConcurrencyLimiter(AdaptiveConcurrencyConfig{
+		MinLimit:      1,
+		MaxLimit:      4,
+		LatencyTarget: 100 * time.Millisecond,
+	})
+
+	observe(t, limiter, time.Second, false)
+	observe(t, limiter, time.Second, false)
+	require.Equal(t, 1, limiter.Limit())
+
+	// The limit grows by one after a limit's worth of fast calls
+	observe(t, limiter, 10*time.Millisecond, false)
+	assert.Equal(t, 2, limiter.Limit())
+	observe(t, limiter, 10*time.Millisecond, false)
+	assert.Equal(t, 2, limiter.Limit())
+	observe(t, limiter, 10*time.Millisecond, false)
+	assert.Equal(t, 3, limiter.Limit())
+
+	for i := 0; i < 10; i++ {
+		observe(t, limiter, 10*time.Millisecond, false)
+	}
+	assert.Equal(t, 4, limiter.Limit(), "limit must not exceed the max")
+	assert.Equal(t, int64(3), limiter.GetStats().Increases)
+}
+
+func TestAdaptiveConcurrencyLimiter_AcquireWaitsForSlot(t *testing.T) {
+	limiter := NewAdaptiveConcurrencyLimiter(AdaptiveConcurrencyConfig{MinLimit: 1, MaxLimit: 1, LatencyTarget: time.Second})
+	require.NoError(t, limiter.Acquire(context.Background()))
+
+	// A second caller gives up when its context ends
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Acquire(ctx), context.DeadlineExceeded)
+
+	// ...and proceeds once the slot is released
+	acquired := make(chan error, 1)
+	go func() {
+		acquired <- limiter.Acquire(context.Background())
+	}()
+
+	limiter.Release(time.Millisecond, false)
+
+	select {
+	case err := <-acquired:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("waiting caller was not released")
+	}
+	assert.Equal(t, 1, limiter.GetStats().InFlight)
+}
@@ -40,12 +40,14 @@ func (s CircuitBreakerState) String() string {
 
 // CircuitBreakerConfig represents circuit breaker configuration
 type CircuitBreakerConfig struct {
-	Name               string        // Name of the circuit breaker
-	FailureThreshold   int           // Number of failures before opening
-	SuccessThreshold   int           // Number of successes to close from half-open
-	Timeout            time.Duration // Time to wait before transitioning to half-open
-	MaxConcurrentCalls int           // Maximum concurrent calls in half-open state
-	ResetTimeout       time.Duration // Time to reset failure count in closed state
+	Name                 string        // Name of the circuit breaker
+	FailureThreshold     int           // Number of consecutive failures before opening
+	SuccessThreshold     int           // Number of successes to close from half-open
+	Timeout              time.Duration // Time to wait before transitioning to half-open
+	MaxConcurrentCalls   int           // Maximum concurrent calls in half-open state
+	ResetTimeout         time.Duration // Time to reset failure count in closed state
+	WindowSize           int           // Number of recent requests in the rolling window; 0 uses consecutive mode
+	FailureRateThreshold float64       // Failure rate (0-1] over a full window that opens the circuit
 }
 
 // CircuitBreakerStats represents circuit breaker statistics
@@ -61,6 +63,8 @@ type CircuitBreakerStats struct {
 	TotalSuccesses       int64
 	TotalFailures        int64
 	TotalRejections      int64
+	WindowFailureRate    float64       // Failure rate over the rolling window (window mode only)
+	TimeUntilHalfOpen    time.Duration // Time until an open circuit lets a probe through; zero unless open
 }
 
 // CircuitBreaker implements the circuit breaker pattern
@@ -76,6 +80,12 @@ type CircuitBreaker struct {
 	stateChangedAt time.Time
 	halfOpenCalls  int
 	lastResetTime  time.Time
+
+	// Rolling window of recent outcomes (true = failure), used when WindowSize > 0
+	window         []bool
+	windowPos      int
+	windowCount    int
+	windowFailures int
 }
 
 // NewCircuitBreaker creates a new circuit breaker
@@ -96,6 +106,12 @@ func NewCircuitBreaker(config CircuitBreakerConfig, appLogger *logger.Logger, ap
 	if config.ResetTimeout <= 0 {
 		config.ResetTimeout = 60 * time.Second
 	}
+	if config.WindowSize < 0 {
+		config.WindowSize = 0
+	}
+	if config.WindowSize > 0 && config.FailureRateThreshold <= 0 {
+		config.FailureRateThreshold = 0.5
+	}
 
 	cb := &CircuitBreaker{
 		config:         config,
@@ -106,6 +122,10 @@ func NewCircuitBreaker(config CircuitBreakerConfig, appLogger *logger.Logger, ap
 		metrics:        appMetrics,
 	}
 
+	if config.WindowSize > 0 {
+		cb.window = make([]bool, config.WindowSize)
+	}
+
 	// Initialize metrics
 	if appMetrics != nil {
 		appMetrics.SetCircuitBreakerState(config.Name, 0) // closed
@@ -127,11 +147,14 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(ctx context.Conte
 	// Execute the function
 	err := fn(ctx)
 
-	// Record the result
-	if err != nil {
-		cb.recordFailure(ctx, err)
-	} else {
+	// Record the result; a request a client-side limit held back never reached the
+	// service, so it counts as neither
+	switch {
+	case err == nil:
 		cb.recordSuccess(ctx)
+	case domain.IsNotSent(err):
+	default:
+		cb.recordFailure(ctx, err)
 	}
 
 	return err
@@ -209,6 +232,7 @@ func (cb *CircuitBreaker) recordSuccess(ctx context.Context) {
 	case StateClosed:
 		// Reset failure count on success
 		cb.stats.ConsecutiveFailures = 0
+		cb.recordOutcome(false)
 	}
 }
 
@@ -230,7 +254,8 @@ func (cb *CircuitBreaker) recordFailure(ctx context.Context, err error) {
 
 	switch cb.state {
 	case StateClosed:
-		if cb.stats.ConsecutiveFailures >= cb.config.FailureThreshold {
+		cb.recordOutcome(true)
+		if cb.shouldOpen() {
 			cb.transitionToOpen(ctx)
 		}
 
@@ -240,6 +265,54 @@ func (cb *CircuitBreaker) recordFailure(ctx context.Context, err error) {
 	}
 }
 
+// recordOutcome adds an outcome to the rolling window, evicting the oldest once full
+func (cb *CircuitBreaker) recordOutcome(failed bool) {
+	if cb.window == nil {
+		return
+	}
+
+	if cb.windowCount == len(cb.window) {
+		if cb.window[cb.windowPos] {
+			cb.windowFailures--
+		}
+	} else {
+		cb.windowCount++
+	}
+
+	cb.window[cb.windowPos] = failed
+	if failed {
+		cb.windowFailures++
+	}
+	cb.windowPos = (cb.windowPos + 1) % len(cb.window)
+	cb.stats.WindowFailureRate = float64(cb.windowFailures) / float64(cb.windowCount)
+}
+
+// resetWindow discards all outcomes in the rolling window
+func (cb *CircuitBreaker) resetWindow() {
+	if cb.window == nil {
+		return
+	}
+
+	for i := range cb.window {
+		cb.window[i] = false
+	}
+	cb.windowPos = 0
+	cb.windowCount = 0
+	cb.windowFailures = 0
+	cb.stats.WindowFailureRate = 0
+}
+
+// shouldOpen reports whether the closed circuit should trip. In window mode the
+// failure rate is only evaluated once the window holds WindowSize outcomes.
+func (cb *CircuitBreaker) shouldOpen() bool {
+	if cb.window == nil {
+		return cb.stats.ConsecutiveFailures >= cb.config.FailureThreshold
+	}
+
+	return cb.windowCount == len(cb.window) &&
+		cb.stats.WindowFailureRate >= cb.config.FailureRateThreshold
+}
+
 // recordRejection records a rejected request
 func (cb *CircuitBreaker) recordRejection() {
 	cb.mutex.Lock()
@@ -259,6 +332,7 @@ func (cb *CircuitBreaker) transitionToClosed(ctx context.Context) {
 	cb.lastResetTime = time.Now()
 	cb.halfOpenCalls = 0
 	cb.stats.ConsecutiveFailures = 0
+	cb.resetWindow()
 
 	if cb.metrics != nil {
 		cb.metrics.SetCircuitBreakerState(cb.config.Name, 0) // closed
@@ -286,6 +360,7 @@ func (cb *CircuitBreaker) transitionToOpen(ctx context.Context) {
 		zap.String("circuit_breaker", cb.config.Name),
 		zap.String("previous_state", previousState),
 		zap.Int("consecutive_failures", cb.stats.ConsecutiveFailures),
+		zap.Float64("window_failure_rate", cb.stats.WindowFailureRate),
 		zap.Duration("timeout", cb.config.Timeout),
 	)
 }
@@ -315,7 +390,15 @@ func (cb *CircuitBreaker) GetState() CircuitBreakerState {
 func (cb *CircuitBreaker) GetStats() CircuitBreakerStats {
 	cb.mutex.RLock()
 	defer cb.mutex.RUnlock()
-	return cb.stats
+
+	stats := cb.stats
+	stats.State = cb.state
+	if cb.state == StateOpen {
+		if remaining := time.Until(cb.stateChangedAt.Add(cb.config.Timeout)); remaining > 0 {
+			stats.TimeUntilHalfOpen = remaining
+		}
+	}
+	return stats
 }
 
 // Reset resets the circuit breaker to closed state
@@ -330,6 +413,7 @@ func (cb *CircuitBreaker) Reset(ctx context.Context) {
 	cb.halfOpenCalls = 0
 	cb.stats.ConsecutiveFailures = 0
 	cb.stats.ConsecutiveSuccesses = 0
+	cb.resetWindow()
 
 	if cb.metrics != nil {
 		cb.metrics.SetCircuitBreakerState(cb.config.Name, 0) // closed
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCircuitBreaker(t *testing.T, config CircuitBreakerConfig) *CircuitBreaker {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	return NewCircuitBreaker(config, appLogger, nil)
+}
+
+// executeAlternating runs n calls through the circuit breaker, failing every other one
+func executeAlternating(cb *CircuitBreaker, n int) {
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		cb.Execute(ctx, func(ctx context.Context) error {
+			if i%2 == 0 {
+				return errors.New("execution service failure")
+			}
+			return nil
+		})
+	}
+}
+
+func TestCircuitBreaker_ConsecutiveMode_IgnoresIntermittentFailures(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{
+		Name:             "test",
+		FailureThreshold: 3,
+		Timeout:          time.Minute,
+	})
+
+	executeAlternating(cb, 20)
+
+	assert.Equal(t, StateClosed, cb.GetState())
+	assert.Equal(t, 0.0, cb.GetStats().WindowFailureRate)
+}
+
+func TestCircuitBreaker_IgnoresRequestsThatWereNotSent(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{
+		Name:             "test",
+		FailureThreshold: 1,
+		Timeout:          time.Minute,
+	})
+
+	for i := 0; i < 3; i++ {
+		err := cb.Execute(context.Background(), func(ctx context.Context) error {
+			return domain.NewTimeoutError("execution-service rate limit", context.DeadlineExceeded).WithNotSent()
+		})
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, StateClosed, cb.GetState())
+	assert.Equal(t, int64(0), cb.GetStats().TotalFailures)
+}
+
+func TestCircuitBreaker_WindowMode_OpensOnFailureRate(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{
+		Name:                 "test",
+		FailureThreshold:     3,
+		Timeout:              time.Minute,
+		WindowSize:           10,
+		FailureRateThreshold: 0.5,
+	})
+
+	// The rate is not evaluated until the window is full
+	executeAlternating(cb, 9)
+	assert.Equal(t, StateClosed, cb.GetState())
+	assert.InDelta(t, 5.0/9.0, cb.GetStats().WindowFailureRate, 0.0001)
+
+	executeAlternating(cb, 1)
+	assert.Equal(t, StateOpen, cb.GetState())
+	assert.InDelta(t, 0.6, cb.GetStats().WindowFailureRate, 0.0001)
+}
+
+func TestCircuitBreaker_WindowMode_EvictsOldestOutcome(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{
+		Name:                 "test",
+		Timeout:              time.Minute,
+		WindowSize:           4,
+		FailureRateThreshold: 0.75,
+	})
+
+	ctx := context.Background()
+	fail := func(ctx context.Context) error { return errors.New("failure") }
+	succeed := func(ctx context.Context) error { return nil }
+
+	// Window: F S S S -> 25%
+	cb.Execute(ctx, fail)
+	cb.Execute(ctx, succeed)
+	cb.Execute(ctx, succeed)
+	cb.Execute(ctx, succeed)
+	assert.InDelta(t, 0.25, cb.GetStats().WindowFailureRate, 0.0001)
+
+	// Window: S S S S -> the initial failure has been evicted
+	cb.Execute(ctx, succeed)
+	assert.Equal(t, 0.0, cb.GetStats().WindowFailureRate)
+
+	// Window: S F F F -> 75% trips the breaker
+	cb.Execute(ctx, fail)
+	cb.Execute(ctx, fail)
+	assert.Equal(t, StateClosed, cb.GetState())
+	cb.Execute(ctx, fail)
+	assert.Equal(t, StateOpen, cb.GetState())
+
+	cb.Reset(ctx)
+	assert.Equal(t, StateClosed, cb.GetState())
+	assert.Equal(t, 0.0, cb.GetStats().WindowFailureRate)
+}
+
+func TestNewCircuitBreaker_WindowModeDefaults(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{Name: "test", WindowSize: 20})
+
+	assert.Equal(t, 0.5, cb.config.FailureRateThreshold)
+	assert.Len(t, cb.window, 20)
+
+	consecutive := newTestCircuitBreaker(t, CircuitBreakerConfig{Name: "test"})
+	assert.Equal(t, 0, consecutive.config.WindowSize)
+	assert.Nil(t, consecutive.window)
+}
+
+func TestCircuitBreaker_GetStats_TimeUntilHalfOpen(t *testing.T) {
+	cb := newTestCircuitBreaker(t, CircuitBreakerConfig{
+		Name:             "test",
+		FailureThreshold: 1,
+		SuccessThreshold: 2,
+		Timeout:          50 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	assert.Zero(t, cb.GetStats().TimeUntilHalfOpen, "closed")
+
+	cb.Execute(ctx, func(ctx context.Context) error { return errors.New("execution service failure") })
+	require.Equal(t, StateOpen, cb.GetState())
+	remaining := cb.GetStats().TimeUntilHalfOpen
+	assert.Positive(t, remaining, "open")
+	assert.LessOrEqual(t, remaining, 50*time.Millisecond)
+
+	// Once the timeout passes the next call probes the service in half-open state
+	time.Sleep(60 * time.Millisecond)
+	assert.Zero(t, cb.GetStats().TimeUntilHalfOpen, "open past its timeout")
+	require.NoError(t, cb.Execute(ctx, func(ctx context.Context) error { return nil }))
+	require.Equal(t, StateHalfOpen, cb.GetState())
+	assert.Zero(t, cb.GetStats().TimeUntilHalfOpen, "half-open")
+}
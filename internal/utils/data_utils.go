@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -29,23 +30,23 @@ func (du *DataUtils) CalculatePercentageChange(oldValue, newValue float64) float
 }
 
 // CalculateTotalAmount calculates the total amount from quantity and average price
-func (du *DataUtils) CalculateTotalAmount(quantity int64, averagePrice float64) float64 {
-	return float64(quantity) * averagePrice
+func (du *DataUtils) CalculateTotalAmount(quantity, averagePrice float64) float64 {
+	return quantity * averagePrice
 }
 
 // ValidateTotalAmount validates that the total amount matches the calculated value within tolerance
-func (du *DataUtils) ValidateTotalAmount(quantity int64, averagePrice, totalAmount, tolerance float64) bool {
+func (du *DataUtils) ValidateTotalAmount(quantity, averagePrice, totalAmount, tolerance float64) bool {
 	expectedTotal := du.CalculateTotalAmount(quantity, averagePrice)
 	diff := math.Abs(totalAmount - expectedTotal)
 	return diff <= tolerance
 }
 
 // CalculateAveragePrice calculates the average price from total amount and quantity
-func (du *DataUtils) CalculateAveragePrice(totalAmount float64, quantity int64) float64 {
+func (du *DataUtils) CalculateAveragePrice(totalAmount, quantity float64) float64 {
 	if quantity == 0 {
 		return 0
 	}
-	return totalAmount / float64(quantity)
+	return totalAmount / quantity
 }
 
 // RoundToDecimalPlaces rounds a float64 to the specified number of decimal places
@@ -60,11 +61,85 @@ func (du *DataUtils) FormatCurrency(amount float64, decimalPlaces int) string {
 	return fmt.Sprintf("$"+format, amount)
 }
 
-// ParseCurrency parses a currency string and returns the float64 value
+// currencyFormat describes how amounts in a currency are written
+type currencyFormat struct {
+	symbol        string
+	symbolAfter   bool // Symbol follows the amount, separated by a space
+	groupSep      string
+	decimalSep    string
+	decimalPlaces int
+}
+
+// currencyFormats holds the supported currencies by ISO 4217 code
+var currencyFormats = map[string]currencyFormat{
+	"USD": {symbol: "$", groupSep: ",", decimalSep: ".", decimalPlaces: 2},
+	"EUR": {symbol: "€", symbolAfter: true, groupSep: ".", decimalSep: ",", decimalPlaces: 2},
+	"GBP": {symbol: "£", groupSep: ",", decimalSep: ".", decimalPlaces: 2},
+	"JPY": {symbol: "¥", groupSep: ",", decimalSep: ".", decimalPlaces: 0},
+}
+
+// FormatCurrencyLocale formats an amount with the symbol, digit grouping and decimal
+// separator of the currency, e.g. $1,234,567.89, 1.234.567,89 €, £1,234,567.89 or
+// ¥1,234,568. A negative decimals uses the currency's usual number of decimal places.
+// Unsupported currencies are written with their code, e.g. CHF 1,234.50.
+func (du *DataUtils) FormatCurrencyLocale(amount float64, currencyCode string, decimals int) string {
+	code := strings.ToUpper(currencyCode)
+	format, ok := currencyFormats[code]
+	if !ok {
+		format = currencyFormat{symbol: code + " ", groupSep: ",", decimalSep: ".", decimalPlaces: 2}
+	}
+	if decimals < 0 {
+		decimals = format.decimalPlaces
+	}
+
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', decimals, 64)
+	integerPart, fractionPart, _ := strings.Cut(digits, ".")
+
+	var number strings.Builder
+	for i, digit := range integerPart {
+		if i > 0 && (len(integerPart)-i)%3 == 0 {
+			number.WriteString(format.groupSep)
+		}
+		number.WriteRune(digit)
+	}
+	if fractionPart != "" {
+		number.WriteString(format.decimalSep)
+		number.WriteString(fractionPart)
+	}
+
+	sign := ""
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		sign = "-"
+	}
+	if format.symbolAfter {
+		return sign + number.String() + " " + format.symbol
+	}
+	return sign + format.symbol + number.String()
+}
+
+// ParseCurrency parses a currency string and returns the float64 value. It accepts the
+// symbols of the currencies FormatCurrencyLocale supports. When a string has both
+// separators the last one is the decimal separator; a separator alone follows the
+// currency's convention, so "$1,234" is 1234 and "1,5 €" is 1.5, while dots in euro
+// amounts without a comma group digits, so "1.234 €" is 1234.
 func (du *DataUtils) ParseCurrency(currencyStr string) (float64, error) {
 	// Remove currency symbols and whitespace
 	cleaned := strings.TrimSpace(currencyStr)
-	cleaned = strings.ReplaceAll(cleaned, "$", "")
+	euro := strings.Contains(cleaned, "€")
+	for _, format := range currencyFormats {
+		cleaned = strings.ReplaceAll(cleaned, format.symbol, "")
+	}
+	cleaned = strings.ReplaceAll(cleaned, " ", "")
+
+	lastComma, lastDot := strings.LastIndex(cleaned, ","), strings.LastIndex(cleaned, ".")
+	switch {
+	case lastComma > lastDot && (lastDot >= 0 || euro):
+		// Decimal comma: dots group digits
+		cleaned = strings.ReplaceAll(cleaned[:lastComma], ".", "") + "." + cleaned[lastComma+1:]
+	case euro && lastComma < 0:
+		// A whole euro amount: dots group digits
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+	}
 	cleaned = strings.ReplaceAll(cleaned, ",", "")
 
 	return strconv.ParseFloat(cleaned, 64)
@@ -172,15 +247,7 @@ func (du *DataUtils) GetStatistics(values []float64) map[string]float64 {
 	// Calculate median
 	sortedValues := make([]float64, len(values))
 	copy(sortedValues, values)
-
-	// Simple bubble sort for median calculation
-	for i := 0; i < len(sortedValues); i++ {
-		for j := 0; j < len(sortedValues)-1-i; j++ {
-			if sortedValues[j] > sortedValues[j+1] {
-				sortedValues[j], sortedValues[j+1] = sortedValues[j+1], sortedValues[j]
-			}
-		}
-	}
+	sort.Float64s(sortedValues)
 
 	var median float64
 	n := len(sortedValues)
@@ -212,13 +279,16 @@ func (du *DataUtils) CalculatePercentile(values []float64, percentile float64) f
 	// Sort values
 	sortedValues := make([]float64, len(values))
 	copy(sortedValues, values)
+	sort.Float64s(sortedValues)
 
-	for i := 0; i < len(sortedValues); i++ {
-		for j := 0; j < len(sortedValues)-1-i; j++ {
-			if sortedValues[j] > sortedValues[j+1] {
-				sortedValues[j], sortedValues[j+1] = sortedValues[j+1], sortedValues[j]
-			}
-		}
+	return du.CalculateSortedPercentile(sortedValues, percentile)
+}
+
+// CalculateSortedPercentile calculates the specified percentile of values that are
+// already sorted in ascending order, so several percentiles can share one sort
+func (du *DataUtils) CalculateSortedPercentile(sortedValues []float64, percentile float64) float64 {
+	if len(sortedValues) == 0 || percentile < 0 || percentile > 100 {
+		return 0
 	}
 
 	// Calculate percentile index
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewDataUtils(t *testing.T) {
@@ -65,7 +66,7 @@ func TestDataUtils_CalculateTotalAmount(t *testing.T) {
 
 	tests := []struct {
 		name         string
-		quantity     int64
+		quantity     float64
 		averagePrice float64
 		expected     float64
 	}{
@@ -75,6 +76,12 @@ func TestDataUtils_CalculateTotalAmount(t *testing.T) {
 			averagePrice: 190.41,
 			expected:     190410,
 		},
+		{
+			name:         "fractional quantity",
+			quantity:     0.5,
+			averagePrice: 190.41,
+			expected:     95.205,
+		},
 		{
 			name:         "zero quantity",
 			quantity:     0,
@@ -102,7 +109,7 @@ func TestDataUtils_ValidateTotalAmount(t *testing.T) {
 
 	tests := []struct {
 		name         string
-		quantity     int64
+		quantity     float64
 		averagePrice float64
 		totalAmount  float64
 		tolerance    float64
@@ -148,7 +155,7 @@ func TestDataUtils_CalculateAveragePrice(t *testing.T) {
 	tests := []struct {
 		name        string
 		totalAmount float64
-		quantity    int64
+		quantity    float64
 		expected    float64
 	}{
 		{
@@ -307,6 +314,80 @@ func TestDataUtils_ParseCurrency(t *testing.T) {
 	}
 }
 
+func TestDataUtils_FormatCurrencyLocale(t *testing.T) {
+	du := NewDataUtils()
+
+	tests := []struct {
+		name     string
+		amount   float64
+		currency string
+		decimals int
+		expected string
+	}{
+		{"USD", 1234567.89, "USD", 2, "$1,234,567.89"},
+		{"USD default decimals", 190.4, "usd", -1, "$190.40"},
+		{"USD small amount", 12.5, "USD", 2, "$12.50"},
+		{"USD negative", -1234.5, "USD", 2, "-$1,234.50"},
+		{"EUR", 1234567.89, "EUR", 2, "1.234.567,89 €"},
+		{"EUR under a thousand", 999.99, "EUR", -1, "999,99 €"},
+		{"GBP", 1234567.89, "GBP", 2, "£1,234,567.89"},
+		{"GBP no decimals", 1000, "GBP", 0, "£1,000"},
+		{"JPY has no decimals", 1234567.89, "JPY", -1, "¥1,234,568"},
+		{"JPY explicit decimals", 1234.5, "JPY", 1, "¥1,234.5"},
+		{"unsupported currency", 1234.5, "CHF", -1, "CHF 1,234.50"},
+		{"zero", 0, "USD", 2, "$0.00"},
+		{"rounds to zero", -0.001, "USD", 2, "$0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, du.FormatCurrencyLocale(tt.amount, tt.currency, tt.decimals))
+		})
+	}
+}
+
+func TestDataUtils_ParseCurrency_Locale(t *testing.T) {
+	du := NewDataUtils()
+
+	tests := []struct {
+		currencyStr string
+		expected    float64
+	}{
+		{"$1,234,567.89", 1234567.89},
+		{"1.234.567,89 €", 1234567.89},
+		{"€1,234.56", 1234.56},
+		{"1,5 €", 1.5},
+		{"1.234.567 €", 1234567},
+		{"1.234 €", 1234},
+		{"£1,234,567.89", 1234567.89},
+		{"¥1,234,568", 1234568},
+		{"-$1,234.50", -1234.5},
+		{"$1,234", 1234},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currencyStr, func(t *testing.T) {
+			result, err := du.ParseCurrency(tt.currencyStr)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, result, 0.0001)
+		})
+	}
+
+	// Formatted amounts parse back to the same value, with or without decimals
+	for code := range currencyFormats {
+		result, err := du.ParseCurrency(du.FormatCurrencyLocale(-9876543.21, code, 2))
+		require.NoError(t, err, code)
+		assert.InDelta(t, -9876543.21, result, 0.0001, code)
+
+		for _, amount := range []float64{1234, 1234567, -9876543} {
+			formatted := du.FormatCurrencyLocale(amount, code, 0)
+			result, err := du.ParseCurrency(formatted)
+			require.NoError(t, err, formatted)
+			assert.Equal(t, amount, result, formatted)
+		}
+	}
+}
+
 func TestDataUtils_ValidateFormats(t *testing.T) {
 	du := NewDataUtils()
 
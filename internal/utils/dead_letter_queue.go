@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
 	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
 	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
 	"go.uber.org/zap"
@@ -26,16 +30,20 @@ type DeadLetterMessage struct {
 	Topic            string                 `json:"topic,omitempty"`
 	Partition        int                    `json:"partition,omitempty"`
 	Offset           int64                  `json:"offset,omitempty"`
+
+	// MessageKey identifies the failed message, so repeated failures of it fold into one entry
+	MessageKey string `json:"message_key,omitempty"`
 }
 
 // DeadLetterQueueConfig represents dead letter queue configuration
 type DeadLetterQueueConfig struct {
-	Enabled         bool          // Whether DLQ is enabled
-	MaxSize         int           // Maximum number of messages to store
-	RetentionPeriod time.Duration // How long to keep messages
-	FlushInterval   time.Duration // How often to flush old messages
-	PersistToDisk   bool          // Whether to persist messages to disk
-	FilePath        string        // File path for disk persistence
+	Enabled            bool          // Whether DLQ is enabled
+	MaxSize            int           // Maximum number of messages to store
+	RetentionPeriod    time.Duration // How long to keep messages
+	FlushInterval      time.Duration // How often to flush old messages
+	PersistToDisk      bool          // Whether to persist messages to disk
+	FilePath           string        // File path for disk persistence
+	CompactionInterval time.Duration // How often to rewrite the persistence file with only live messages
 }
 
 // DeadLetterQueueStats represents DLQ statistics
@@ -45,6 +53,21 @@ type DeadLetterQueueStats struct {
 	OldestMessageTime time.Time `json:"oldest_message_time"`
 	NewestMessageTime time.Time `json:"newest_message_time"`
 	LastFlushTime     time.Time `json:"last_flush_time"`
+	LastCompactTime   time.Time `json:"last_compact_time"`
+}
+
+// Persistence file record operations
+const (
+	dlqRecordAdd    = "add"
+	dlqRecordRemove = "remove"
+	dlqRecordClear  = "clear"
+)
+
+// deadLetterRecord is a single line in the append-only persistence file
+type deadLetterRecord struct {
+	Op      string             `json:"op"`
+	ID      string             `json:"id,omitempty"`
+	Message *DeadLetterMessage `json:"message,omitempty"`
 }
 
 // DeadLetterQueue handles failed messages
@@ -71,6 +94,9 @@ func NewDeadLetterQueue(config DeadLetterQueueConfig, appLogger *logger.Logger,
 	if config.FlushInterval <= 0 {
 		config.FlushInterval = 1 * time.Hour
 	}
+	if config.CompactionInterval <= 0 {
+		config.CompactionInterval = 10 * time.Minute
+	}
 
 	dlq := &DeadLetterQueue{
 		config:   config,
@@ -80,16 +106,33 @@ func NewDeadLetterQueue(config DeadLetterQueueConfig, appLogger *logger.Logger,
 		stopCh:   make(chan struct{}),
 	}
 
+	// Reload persisted messages before accepting new ones
+	if config.Enabled && config.PersistToDisk {
+		if err := dlq.loadFromDisk(); err != nil && appLogger != nil {
+			appLogger.Warn("Failed to load dead letter queue from disk",
+				zap.String("file_path", config.FilePath),
+				zap.Error(err),
+			)
+		}
+	}
+
 	// Start background cleanup if enabled
 	if config.Enabled {
 		dlq.wg.Add(1)
 		go dlq.cleanupWorker()
+
+		if config.PersistToDisk {
+			dlq.wg.Add(1)
+			go dlq.compactionWorker()
+		}
 	}
 
 	return dlq
 }
 
-// Add adds a message to the dead letter queue
+// Add adds a message to the dead letter queue. A message failing again with the same
+// failure reason replaces its earlier entry, keeping the ID and first failure time while
+// adding to the attempt count and error history; see messageKey for how it is matched.
 func (dlq *DeadLetterQueue) Add(ctx context.Context, originalMessage interface{}, failureReason string, errorHistory []error, attemptCount int, metadata map[string]interface{}) error {
 	if !dlq.config.Enabled {
 		return nil
@@ -107,6 +150,7 @@ func (dlq *DeadLetterQueue) Add(ctx context.Context, originalMessage interface{}
 	}
 
 	// Create dead letter message
+	now := time.Now()
 	dlMessage := DeadLetterMessage{
 		ID:               generateMessageID(),
 		CorrelationID:    logger.GetCorrelationID(ctx),
@@ -114,9 +158,31 @@ func (dlq *DeadLetterQueue) Add(ctx context.Context, originalMessage interface{}
 		FailureReason:    failureReason,
 		ErrorHistory:     errorStrings,
 		AttemptCount:     attemptCount,
-		FirstFailureTime: time.Now(), // This could be enhanced to track actual first failure
-		LastFailureTime:  time.Now(),
+		FirstFailureTime: now,
+		LastFailureTime:  now,
 		Metadata:         metadata,
+		MessageKey:       messageKey(originalMessage, metadata),
+	}
+
+	// Fold in an earlier entry for the same failure, moving it to the back of the queue
+	// so messages stay ordered by last failure time
+	if index := dlq.indexOfFailure(dlMessage.MessageKey, failureReason); index >= 0 {
+		previous := dlq.messages[index]
+		dlq.messages = append(dlq.messages[:index], dlq.messages[index+1:]...)
+
+		dlMessage.ID = previous.ID
+		dlMessage.FirstFailureTime = previous.FirstFailureTime
+		dlMessage.AttemptCount += previous.AttemptCount
+		dlMessage.ErrorHistory = append(previous.ErrorHistory, errorStrings...)
+
+		if dlq.config.PersistToDisk {
+			if err := dlq.appendRecord(deadLetterRecord{Op: dlqRecordRemove, ID: previous.ID}); err != nil {
+				dlq.logger.WithContext(ctx).Warn("Failed to persist dead letter removal to disk",
+					zap.String("message_id", previous.ID),
+					zap.Error(err),
+				)
+			}
+		}
 	}
 
 	// Add Kafka-specific metadata if available
@@ -144,7 +210,7 @@ func (dlq *DeadLetterQueue) Add(ctx context.Context, originalMessage interface{}
 	// Update statistics
 	dlq.stats.TotalMessages++
 	dlq.stats.CurrentSize = len(dlq.messages)
-	dlq.stats.NewestMessageTime = time.Now()
+	dlq.stats.NewestMessageTime = now
 	if dlq.stats.OldestMessageTime.IsZero() && len(dlq.messages) > 0 {
 		dlq.stats.OldestMessageTime = dlq.messages[0].FirstFailureTime
 	}
@@ -153,15 +219,17 @@ func (dlq *DeadLetterQueue) Add(ctx context.Context, originalMessage interface{}
 	dlq.logger.WithContext(ctx).Error("Message added to dead letter queue",
 		zap.String("message_id", dlMessage.ID),
 		zap.String("failure_reason", failureReason),
-		zap.Int("attempt_count", attemptCount),
+		zap.Int("attempt_count", dlMessage.AttemptCount),
+		zap.Time("first_failure_time", dlMessage.FirstFailureTime),
 		zap.Int("error_count", len(errorHistory)),
 		zap.Int("dlq_size", len(dlq.messages)),
 	)
 
 	// Record metrics
 	if dlq.metrics != nil {
-		// We could add a specific DLQ metric here if needed
+		dlq.metrics.RecordDLQMessageAdded(failureReason)
 	}
+	dlq.recordSizeMetrics()
 
 	// Persist to disk if configured
 	if dlq.config.PersistToDisk {
@@ -176,6 +244,42 @@ func (dlq *DeadLetterQueue) Add(ctx context.Context, originalMessage interface{}
 	return nil
 }
 
+// indexOfFailure returns the index of the entry for the same message and failure
+// reason, or -1 when there is none; the caller holds the mutex
+func (dlq *DeadLetterQueue) indexOfFailure(key, failureReason string) int {
+	if key == "" {
+		return -1
+	}
+	for i := len(dlq.messages) - 1; i >= 0; i-- {
+		if dlq.messages[i].MessageKey == key && dlq.messages[i].FailureReason == failureReason {
+			return i
+		}
+	}
+	return -1
+}
+
+// messageKey identifies a failed message by its Kafka position when the metadata has
+// one, otherwise by the fill ID and version or the allocation's idempotency key. It is
+// empty when the message cannot be identified.
+func messageKey(originalMessage interface{}, metadata map[string]interface{}) string {
+	if topic, ok := metadata["topic"].(string); ok && topic != "" {
+		partition, _ := metadata["partition"].(int)
+		if offset, ok := metadata["offset"].(int64); ok && offset >= 0 {
+			return fmt.Sprintf("%s/%d/%d", topic, partition, offset)
+		}
+	}
+
+	switch message := originalMessage.(type) {
+	case *domain.Fill:
+		return fmt.Sprintf("fill/%d/%d", message.ID, message.Version)
+	case *domain.AllocationServiceExecutionDTO:
+		if message.IdempotencyKey != "" {
+			return "allocation/" + message.IdempotencyKey
+		}
+	}
+	return ""
+}
+
 // GetMessages returns all messages in the dead letter queue
 func (dlq *DeadLetterQueue) GetMessages() []DeadLetterMessage {
 	dlq.mutex.RLock()
@@ -187,6 +291,28 @@ func (dlq *DeadLetterQueue) GetMessages() []DeadLetterMessage {
 	return messages
 }
 
+// GetMessagesFiltered returns the messages with the given failure reason whose last
+// failure falls within [since, until]. An empty reason or zero time leaves that filter open.
+func (dlq *DeadLetterQueue) GetMessagesFiltered(reason string, since, until time.Time) []DeadLetterMessage {
+	dlq.mutex.RLock()
+	defer dlq.mutex.RUnlock()
+
+	messages := make([]DeadLetterMessage, 0, len(dlq.messages))
+	for _, msg := range dlq.messages {
+		if reason != "" && msg.FailureReason != reason {
+			continue
+		}
+		if !since.IsZero() && msg.LastFailureTime.Before(since) {
+			continue
+		}
+		if !until.IsZero() && msg.LastFailureTime.After(until) {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
 // GetMessageByID returns a specific message by ID
 func (dlq *DeadLetterQueue) GetMessageByID(id string) (*DeadLetterMessage, bool) {
 	dlq.mutex.RLock()
@@ -210,6 +336,16 @@ func (dlq *DeadLetterQueue) RemoveMessage(ctx context.Context, id string) bool {
 			// Remove message
 			dlq.messages = append(dlq.messages[:i], dlq.messages[i+1:]...)
 			dlq.stats.CurrentSize = len(dlq.messages)
+			dlq.recordSizeMetrics()
+
+			if dlq.config.PersistToDisk {
+				if err := dlq.appendRecord(deadLetterRecord{Op: dlqRecordRemove, ID: id}); err != nil {
+					dlq.logger.WithContext(ctx).Warn("Failed to persist dead letter removal to disk",
+						zap.String("message_id", id),
+						zap.Error(err),
+					)
+				}
+			}
 
 			dlq.logger.WithContext(ctx).Info("Message removed from dead letter queue",
 				zap.String("message_id", id),
@@ -229,6 +365,13 @@ func (dlq *DeadLetterQueue) Clear(ctx context.Context) {
 	messageCount := len(dlq.messages)
 	dlq.messages = dlq.messages[:0]
 	dlq.stats.CurrentSize = 0
+	dlq.recordSizeMetrics()
+
+	if dlq.config.PersistToDisk {
+		if err := dlq.appendRecord(deadLetterRecord{Op: dlqRecordClear}); err != nil {
+			dlq.logger.WithContext(ctx).Warn("Failed to persist dead letter queue clear to disk", zap.Error(err))
+		}
+	}
 
 	dlq.logger.WithContext(ctx).Info("Dead letter queue cleared",
 		zap.Int("removed_messages", messageCount),
@@ -264,6 +407,9 @@ func (dlq *DeadLetterQueue) cleanup() {
 	dlq.mutex.Lock()
 	defer dlq.mutex.Unlock()
 
+	// Refresh the gauges on every pass so the oldest message age keeps growing
+	defer dlq.recordSizeMetrics()
+
 	if len(dlq.messages) == 0 {
 		return
 	}
@@ -271,8 +417,8 @@ func (dlq *DeadLetterQueue) cleanup() {
 	cutoff := time.Now().Add(-dlq.config.RetentionPeriod)
 	originalSize := len(dlq.messages)
 
-	// Find first message that should be kept
-	keepIndex := 0
+	// Find first message that should be kept; messages are ordered by last failure time
+	keepIndex := len(dlq.messages)
 	for i, msg := range dlq.messages {
 		if msg.LastFailureTime.After(cutoff) {
 			keepIndex = i
@@ -280,8 +426,21 @@ func (dlq *DeadLetterQueue) cleanup() {
 		}
 	}
 
-	// Remove old messages
+	// Remove old messages, recording the removals so a restart does not restore them
 	if keepIndex > 0 {
+		if dlq.config.PersistToDisk {
+			removals := make([]deadLetterRecord, keepIndex)
+			for i, msg := range dlq.messages[:keepIndex] {
+				removals[i] = deadLetterRecord{Op: dlqRecordRemove, ID: msg.ID}
+			}
+			if err := dlq.appendRecord(removals...); err != nil && dlq.logger != nil {
+				dlq.logger.Warn("Failed to persist expired dead letter removals to disk",
+					zap.Int("removed_messages", keepIndex),
+					zap.Error(err),
+				)
+			}
+		}
+
 		dlq.messages = dlq.messages[keepIndex:]
 		dlq.stats.CurrentSize = len(dlq.messages)
 		dlq.stats.LastFlushTime = time.Now()
@@ -304,30 +463,222 @@ func (dlq *DeadLetterQueue) cleanup() {
 	}
 }
 
-// persistMessage persists a message to disk (placeholder implementation)
+// persistMessage appends a message to the persistence file
 func (dlq *DeadLetterQueue) persistMessage(message DeadLetterMessage) error {
+	return dlq.appendRecord(deadLetterRecord{Op: dlqRecordAdd, ID: message.ID, Message: &message})
+}
+
+// appendRecord appends records to the persistence file in a single write
+func (dlq *DeadLetterQueue) appendRecord(records ...deadLetterRecord) error {
 	if dlq.config.FilePath == "" {
 		return fmt.Errorf("no file path configured for persistence")
 	}
 
-	// This is a simple implementation - in production you might want to use
-	// a more robust approach like appending to a log file or using a database
-	data, err := json.Marshal(message)
+	var data []byte
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal record: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	file, err := os.OpenFile(dlq.config.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return fmt.Errorf("failed to open persistence file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
 	}
 
-	// For now, just log that we would persist (actual file I/O omitted for simplicity)
-	_ = data
 	return nil
 }
 
-// Stop stops the dead letter queue and cleanup worker
+// loadFromDisk replays the persistence file to rebuild the in-memory queue
+func (dlq *DeadLetterQueue) loadFromDisk() error {
+	if dlq.config.FilePath == "" {
+		return fmt.Errorf("no file path configured for persistence")
+	}
+
+	file, err := os.Open(dlq.config.FilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open persistence file: %w", err)
+	}
+	defer file.Close()
+
+	dlq.mutex.Lock()
+	defer dlq.mutex.Unlock()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		var record deadLetterRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to parse persistence record: %w", err)
+		}
+
+		switch record.Op {
+		case dlqRecordAdd:
+			if record.Message == nil {
+				continue
+			}
+			if len(dlq.messages) >= dlq.config.MaxSize {
+				dlq.messages = dlq.messages[1:]
+			}
+			dlq.messages = append(dlq.messages, *record.Message)
+		case dlqRecordRemove:
+			for i, msg := range dlq.messages {
+				if msg.ID == record.ID {
+					dlq.messages = append(dlq.messages[:i], dlq.messages[i+1:]...)
+					break
+				}
+			}
+		case dlqRecordClear:
+			dlq.messages = dlq.messages[:0]
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read persistence file: %w", err)
+	}
+
+	// Drop messages that passed the retention period while the service was down
+	cutoff := time.Now().Add(-dlq.config.RetentionPeriod)
+	live := dlq.messages[:0]
+	for _, msg := range dlq.messages {
+		if msg.LastFailureTime.After(cutoff) {
+			live = append(live, msg)
+		}
+	}
+	dlq.messages = live
+
+	dlq.stats.CurrentSize = len(dlq.messages)
+	if len(dlq.messages) > 0 {
+		dlq.stats.OldestMessageTime = dlq.messages[0].FirstFailureTime
+		dlq.stats.NewestMessageTime = dlq.messages[len(dlq.messages)-1].LastFailureTime
+	}
+	dlq.recordSizeMetrics()
+
+	return nil
+}
+
+// recordSizeMetrics publishes the queue size and the age of the oldest message; the
+// caller holds the mutex
+func (dlq *DeadLetterQueue) recordSizeMetrics() {
+	if dlq.metrics == nil {
+		return
+	}
+
+	// Re-added messages move to the back, so the first failures are not in queue order
+	var oldestAge time.Duration
+	for _, msg := range dlq.messages {
+		if age := time.Since(msg.FirstFailureTime); age > oldestAge {
+			oldestAge = age
+		}
+	}
+	dlq.metrics.SetDLQState(len(dlq.messages), oldestAge)
+}
+
+// Compact atomically rewrites the persistence file so it contains only live messages
+func (dlq *DeadLetterQueue) Compact(ctx context.Context) error {
+	if !dlq.config.PersistToDisk {
+		return nil
+	}
+
+	dlq.mutex.Lock()
+	defer dlq.mutex.Unlock()
+
+	if dlq.config.FilePath == "" {
+		return fmt.Errorf("no file path configured for persistence")
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(dlq.config.FilePath), filepath.Base(dlq.config.FilePath)+".compact-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	writer := bufio.NewWriter(tmpFile)
+	for i := range dlq.messages {
+		data, err := json.Marshal(deadLetterRecord{Op: dlqRecordAdd, ID: dlq.messages[i].ID, Message: &dlq.messages[i]})
+		if err == nil {
+			_, err = writer.Write(append(data, '\n'))
+		}
+		if err != nil {
+			tmpFile.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to write compacted record: %w", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to flush compacted file: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to sync compacted file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close compacted file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, dlq.config.FilePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace persistence file: %w", err)
+	}
+
+	dlq.stats.LastCompactTime = time.Now()
+
+	if dlq.logger != nil {
+		dlq.logger.WithContext(ctx).Info("Dead letter queue persistence file compacted",
+			zap.String("file_path", dlq.config.FilePath),
+			zap.Int("live_messages", len(dlq.messages)),
+		)
+	}
+
+	return nil
+}
+
+// compactionWorker periodically compacts the persistence file
+func (dlq *DeadLetterQueue) compactionWorker() {
+	defer dlq.wg.Done()
+
+	ticker := time.NewTicker(dlq.config.CompactionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-dlq.stopCh:
+			return
+		case <-ticker.C:
+			if err := dlq.Compact(context.Background()); err != nil && dlq.logger != nil {
+				dlq.logger.Warn("Dead letter queue compaction failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Stop stops the dead letter queue and cleanup worker, compacting the
+// persisted file one last time so it holds exactly the remaining messages
 func (dlq *DeadLetterQueue) Stop(ctx context.Context) {
 	if dlq.config.Enabled {
 		close(dlq.stopCh)
 		dlq.wg.Wait()
 
+		if err := dlq.Compact(ctx); err != nil && dlq.logger != nil {
+			dlq.logger.Warn("Final dead letter queue compaction failed", zap.Error(err))
+		}
+
 		dlq.logger.WithContext(ctx).Info("Dead letter queue stopped",
 			zap.Int("final_message_count", len(dlq.messages)),
 		)
@@ -342,11 +693,12 @@ func generateMessageID() string {
 // GetDefaultDeadLetterQueueConfig returns a default DLQ configuration
 func GetDefaultDeadLetterQueueConfig() DeadLetterQueueConfig {
 	return DeadLetterQueueConfig{
-		Enabled:         true,
-		MaxSize:         1000,
-		RetentionPeriod: 24 * time.Hour,
-		FlushInterval:   1 * time.Hour,
-		PersistToDisk:   false,
-		FilePath:        "",
+		Enabled:            true,
+		MaxSize:            1000,
+		RetentionPeriod:    24 * time.Hour,
+		FlushInterval:      1 * time.Hour,
+		PersistToDisk:      false,
+		FilePath:           "",
+		CompactionInterval: 10 * time.Minute,
 	}
 }
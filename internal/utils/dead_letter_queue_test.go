@@ -0,0 +1,326 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/kasbench/globeco-confirmation-service/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDeadLetterQueue(t *testing.T, filePath string) *DeadLetterQueue {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	config := GetDefaultDeadLetterQueueConfig()
+	config.PersistToDisk = true
+	config.FilePath = filePath
+	config.CompactionInterval = time.Hour // Compaction is triggered manually in tests
+
+	dlq := NewDeadLetterQueue(config, appLogger, nil)
+	t.Cleanup(func() { dlq.Stop(context.Background()) })
+	return dlq
+}
+
+func readDeadLetterRecords(t *testing.T, filePath string) []deadLetterRecord {
+	file, err := os.Open(filePath)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []deadLetterRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record deadLetterRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestDeadLetterQueue_Compact(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "dlq.jsonl")
+	dlq := newTestDeadLetterQueue(t, filePath)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, dlq.Add(ctx, map[string]interface{}{"fill_id": i}, "test failure",
+			[]error{errors.New("boom")}, 1, nil))
+		time.Sleep(time.Microsecond) // Ensure unique message IDs
+	}
+
+	messages := dlq.GetMessages()
+	require.Len(t, messages, 5)
+
+	// Remove several messages; the append-only file still holds them
+	assert.True(t, dlq.RemoveMessage(ctx, messages[0].ID))
+	assert.True(t, dlq.RemoveMessage(ctx, messages[2].ID))
+	assert.True(t, dlq.RemoveMessage(ctx, messages[4].ID))
+	assert.Len(t, readDeadLetterRecords(t, filePath), 8)
+
+	require.NoError(t, dlq.Compact(ctx))
+
+	records := readDeadLetterRecords(t, filePath)
+	require.Len(t, records, 2)
+	for _, record := range records {
+		assert.Equal(t, dlqRecordAdd, record.Op)
+	}
+	assert.Equal(t, messages[1].ID, records[0].ID)
+	assert.Equal(t, messages[3].ID, records[1].ID)
+	assert.False(t, dlq.GetStats().LastCompactTime.IsZero())
+
+	// No temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Dir(filePath))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Reloading from the compacted file matches the live queue
+	reloaded := newTestDeadLetterQueue(t, filePath)
+	reloadedMessages := reloaded.GetMessages()
+	require.Len(t, reloadedMessages, 2)
+	assert.Equal(t, messages[1].ID, reloadedMessages[0].ID)
+	assert.Equal(t, messages[3].ID, reloadedMessages[1].ID)
+	assert.Equal(t, 2, reloaded.GetStats().CurrentSize)
+}
+
+func TestDeadLetterQueue_ReloadReplaysRemovals(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "dlq.jsonl")
+	dlq := newTestDeadLetterQueue(t, filePath)
+
+	require.NoError(t, dlq.Add(ctx, "first", "test failure", nil, 1, nil))
+	time.Sleep(time.Microsecond)
+	require.NoError(t, dlq.Add(ctx, "second", "test failure", nil, 1, nil))
+
+	messages := dlq.GetMessages()
+	require.Len(t, messages, 2)
+	assert.True(t, dlq.RemoveMessage(ctx, messages[0].ID))
+
+	reloaded := newTestDeadLetterQueue(t, filePath)
+	reloadedMessages := reloaded.GetMessages()
+	require.Len(t, reloadedMessages, 1)
+	assert.Equal(t, messages[1].ID, reloadedMessages[0].ID)
+}
+
+func TestDeadLetterQueue_ReloadKeepsExpiredMessagesRemoved(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "dlq.jsonl")
+	dlq := newTestDeadLetterQueue(t, filePath)
+
+	for _, message := range []string{"first", "second", "third"} {
+		require.NoError(t, dlq.Add(ctx, message, "test failure", nil, 1, nil))
+		time.Sleep(time.Microsecond)
+	}
+
+	// Age the first two messages past the retention period
+	dlq.mutex.Lock()
+	for i := 0; i < 2; i++ {
+		dlq.messages[i].LastFailureTime = time.Now().Add(-dlq.config.RetentionPeriod - time.Minute)
+	}
+	dlq.mutex.Unlock()
+	dlq.cleanup()
+
+	messages := dlq.GetMessages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "third", messages[0].OriginalMessage)
+
+	reloaded := newTestDeadLetterQueue(t, filePath)
+	reloadedMessages := reloaded.GetMessages()
+	require.Len(t, reloadedMessages, 1)
+	assert.Equal(t, messages[0].ID, reloadedMessages[0].ID)
+
+	// Expiring every message empties the queue
+	reloaded.mutex.Lock()
+	reloaded.messages[0].LastFailureTime = time.Now().Add(-reloaded.config.RetentionPeriod - time.Minute)
+	reloaded.mutex.Unlock()
+	reloaded.cleanup()
+	assert.Empty(t, reloaded.GetMessages())
+	assert.Empty(t, newTestDeadLetterQueue(t, filePath).GetMessages())
+}
+
+func TestDeadLetterQueue_LoadDropsMessagesPastRetention(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "dlq.jsonl")
+
+	// Messages that expired while the service was down have no removal record
+	var data []byte
+	for i, lastFailure := range []time.Time{time.Now().Add(-48 * time.Hour), time.Now()} {
+		message := DeadLetterMessage{ID: fmt.Sprintf("message-%d", i), FailureReason: "test failure", LastFailureTime: lastFailure}
+		line, err := json.Marshal(deadLetterRecord{Op: dlqRecordAdd, ID: message.ID, Message: &message})
+		require.NoError(t, err)
+		data = append(append(data, line...), '\n')
+	}
+	require.NoError(t, os.WriteFile(filePath, data, 0o644))
+
+	dlq := newTestDeadLetterQueue(t, filePath)
+	messages := dlq.GetMessages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "message-1", messages[0].ID)
+	assert.Equal(t, 1, dlq.GetStats().CurrentSize)
+}
+
+func TestDeadLetterQueue_Metrics(t *testing.T) {
+	ctx := context.Background()
+	appLogger, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	appMetrics := metrics.New(metrics.Config{Enabled: true, Namespace: "test"})
+
+	config := GetDefaultDeadLetterQueueConfig()
+	dlq := NewDeadLetterQueue(config, appLogger, appMetrics)
+	t.Cleanup(func() { dlq.Stop(context.Background()) })
+
+	require.NoError(t, dlq.Add(ctx, "first", "poison message", nil, 1, nil))
+	time.Sleep(time.Microsecond)
+	require.NoError(t, dlq.Add(ctx, "second", "poison message", nil, 1, nil))
+	time.Sleep(time.Microsecond)
+	require.NoError(t, dlq.Add(ctx, "third", "message too large", nil, 1, nil))
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(appMetrics.DLQCurrentSize))
+	assert.Equal(t, 2.0, testutil.ToFloat64(appMetrics.DLQMessagesAddedTotal.WithLabelValues("poison message")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(appMetrics.DLQMessagesAddedTotal.WithLabelValues("message too large")))
+
+	// The age gauge follows the oldest message on each cleanup pass
+	dlq.mutex.Lock()
+	dlq.messages[0].FirstFailureTime = time.Now().Add(-time.Minute)
+	dlq.mutex.Unlock()
+	dlq.cleanup()
+	assert.GreaterOrEqual(t, testutil.ToFloat64(appMetrics.DLQOldestMessageAgeSeconds), 60.0)
+
+	messages := dlq.GetMessages()
+	assert.True(t, dlq.RemoveMessage(ctx, messages[0].ID))
+	assert.Equal(t, 2.0, testutil.ToFloat64(appMetrics.DLQCurrentSize))
+	assert.Less(t, testutil.ToFloat64(appMetrics.DLQOldestMessageAgeSeconds), 60.0)
+
+	dlq.Clear(ctx)
+	assert.Equal(t, 0.0, testutil.ToFloat64(appMetrics.DLQCurrentSize))
+	assert.Equal(t, 0.0, testutil.ToFloat64(appMetrics.DLQOldestMessageAgeSeconds))
+}
+
+func TestDeadLetterQueue_GetMessagesFiltered(t *testing.T) {
+	ctx := context.Background()
+	dlq := newTestDeadLetterQueue(t, filepath.Join(t.TempDir(), "dlq.jsonl"))
+
+	reasons := []string{"poison message", "message too large", "poison message"}
+	for _, reason := range reasons {
+		require.NoError(t, dlq.Add(ctx, reason, reason, nil, 1, nil))
+		time.Sleep(time.Microsecond)
+	}
+
+	// Spread the failures an hour apart, oldest first
+	base := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	dlq.mutex.Lock()
+	for i := range dlq.messages {
+		dlq.messages[i].LastFailureTime = base.Add(time.Duration(i) * time.Hour)
+	}
+	dlq.mutex.Unlock()
+	messages := dlq.GetMessages()
+
+	t.Run("reason only", func(t *testing.T) {
+		filtered := dlq.GetMessagesFiltered("poison message", time.Time{}, time.Time{})
+		require.Len(t, filtered, 2)
+		assert.Equal(t, messages[0].ID, filtered[0].ID)
+		assert.Equal(t, messages[2].ID, filtered[1].ID)
+	})
+
+	t.Run("time only", func(t *testing.T) {
+		filtered := dlq.GetMessagesFiltered("", base.Add(time.Hour), time.Time{})
+		require.Len(t, filtered, 2)
+		assert.Equal(t, messages[1].ID, filtered[0].ID)
+
+		filtered = dlq.GetMessagesFiltered("", time.Time{}, base.Add(time.Hour))
+		require.Len(t, filtered, 2)
+		assert.Equal(t, messages[0].ID, filtered[0].ID)
+	})
+
+	t.Run("reason and time", func(t *testing.T) {
+		filtered := dlq.GetMessagesFiltered("poison message", base.Add(30*time.Minute), base.Add(3*time.Hour))
+		require.Len(t, filtered, 1)
+		assert.Equal(t, messages[2].ID, filtered[0].ID)
+	})
+
+	t.Run("no filters", func(t *testing.T) {
+		assert.Len(t, dlq.GetMessagesFiltered("", time.Time{}, time.Time{}), 3)
+		assert.Empty(t, dlq.GetMessagesFiltered("unknown", time.Time{}, time.Time{}))
+	})
+}
+
+func TestDeadLetterQueue_ReAddedMessageKeepsFirstFailure(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "dlq.jsonl")
+	dlq := newTestDeadLetterQueue(t, filePath)
+	fill := &domain.Fill{ID: 11, Version: 1}
+
+	require.NoError(t, dlq.Add(logger.WithCorrelationIDContext(ctx, "corr-1"), fill, "execution-service failure", []error{errors.New("first")}, 2, nil))
+	first := dlq.GetMessages()[0]
+
+	// An unrelated failure in between must not be merged
+	require.NoError(t, dlq.Add(ctx, fill, "allocation-service failure", []error{errors.New("other")}, 1, nil))
+
+	// Later failures of the same fill match it whatever their correlation ID
+	for _, cause := range []string{"second", "third"} {
+		time.Sleep(time.Millisecond)
+		require.NoError(t, dlq.Add(logger.WithCorrelationIDContext(ctx, "corr-"+cause), fill, "execution-service failure", []error{errors.New(cause)}, 1, nil))
+	}
+
+	messages := dlq.GetMessages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "allocation-service failure", messages[0].FailureReason)
+
+	readded := messages[1]
+	assert.Equal(t, first.ID, readded.ID)
+	assert.Equal(t, first.FirstFailureTime, readded.FirstFailureTime)
+	assert.True(t, readded.LastFailureTime.After(first.LastFailureTime))
+	assert.Equal(t, 4, readded.AttemptCount)
+	assert.Equal(t, []string{"first", "second", "third"}, readded.ErrorHistory)
+
+	// The persisted queue replays to the same single entry
+	reloaded := newTestDeadLetterQueue(t, filePath)
+	reloadedMessages := reloaded.GetMessages()
+	require.Len(t, reloadedMessages, 2)
+	assert.Equal(t, first.ID, reloadedMessages[1].ID)
+	assert.Equal(t, 4, reloadedMessages[1].AttemptCount)
+
+	// Another version of the fill is a different message
+	require.NoError(t, dlq.Add(ctx, &domain.Fill{ID: 11, Version: 2}, "execution-service failure", nil, 1, nil))
+	assert.Len(t, dlq.GetMessages(), 3)
+
+	// Messages that cannot be identified are never merged
+	require.NoError(t, dlq.Add(ctx, "fill", "poison message", nil, 1, nil))
+	time.Sleep(time.Microsecond)
+	require.NoError(t, dlq.Add(ctx, "fill", "poison message", nil, 1, nil))
+	assert.Len(t, dlq.GetMessages(), 5)
+}
+
+func TestDeadLetterQueue_ReAddedKafkaMessageMatchesByPosition(t *testing.T) {
+	dlq := newTestDeadLetterQueue(t, filepath.Join(t.TempDir(), "dlq.jsonl"))
+	position := func(offset int64) map[string]interface{} {
+		return map[string]interface{}{"topic": "fills", "partition": 2, "offset": offset}
+	}
+
+	// One correlation ID shared by different messages does not merge them
+	ctx := logger.WithCorrelationIDContext(context.Background(), "corr-1")
+	require.NoError(t, dlq.Add(ctx, "value", "poison message", nil, 1, position(7)))
+	require.NoError(t, dlq.Add(ctx, "value", "poison message", nil, 1, position(8)))
+	require.NoError(t, dlq.Add(context.Background(), "value", "poison message", nil, 1, position(7)))
+
+	messages := dlq.GetMessages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, int64(8), messages[0].Offset)
+	assert.Equal(t, int64(7), messages[1].Offset)
+	assert.Equal(t, 2, messages[1].AttemptCount)
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiterConfig represents the configuration for a request rate limiter
+type RateLimiterConfig struct {
+	Rate  float64 // Requests per second. 0 disables the limiter
+	Burst int     // Requests allowed at once after an idle period; defaults to 1
+}
+
+// RateLimiterStats represents rate limiter statistics
+type RateLimiterStats struct {
+	Rate            float64 `json:"rate"`
+	Burst           int     `json:"burst"`
+	AvailableTokens float64 `json:"available_tokens"`
+	Waits           int64   `json:"waits"` // Requests that had to wait for a token
+	TotalWait       string  `json:"total_wait"`
+}
+
+// RateLimiter is a token bucket that paces requests to Rate per second with bursts
+// of up to Burst, making callers wait for a token instead of failing them
+type RateLimiter struct {
+	mutex    sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	lastTick time.Time
+	now      func() time.Time
+
+	waits     int64
+	totalWait time.Duration
+}
+
+// NewRateLimiter creates a full rate limiter, or returns nil when the limiter is disabled
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	if config.Rate <= 0 {
+		return nil
+	}
+
+	burst := config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:     config.Rate,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		lastTick: time.Now(),
+		now:      time.Now,
+	}
+}
+
+// Wait takes a token, waiting until one is available or ctx is done, and returns how
+// long it waited. A token reserved by a cancelled wait is given back. A nil limiter
+// never waits.
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	l.mutex.Lock()
+	l.refill()
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mutex.Unlock()
+
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	start := time.Now()
+	select {
+	case <-timer.C:
+		waited := time.Since(start)
+		l.mutex.Lock()
+		l.waits++
+		l.totalWait += waited
+		l.mutex.Unlock()
+		return waited, nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		l.refill()
+		l.tokens++
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.mutex.Unlock()
+		return time.Since(start), ctx.Err()
+	}
+}
+
+// GetStats returns rate limiter statistics
+func (l *RateLimiter) GetStats() RateLimiterStats {
+	if l == nil {
+		return RateLimiterStats{}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.refill()
+
+	return RateLimiterStats{
+		Rate:            l.rate,
+		Burst:           int(l.burst),
+		AvailableTokens: l.tokens,
+		Waits:           l.waits,
+		TotalWait:       l.totalWait.String(),
+	}
+}
+
+// refill adds the tokens accrued since the last refill. The caller must hold the lock.
+func (l *RateLimiter) refill() {
+	now := l.now()
+	elapsed := now.Sub(l.lastTick).Seconds()
+	l.lastTick = now
+
+	if elapsed <= 0 {
+		return
+	}
+
+	l.tokens += elapsed * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
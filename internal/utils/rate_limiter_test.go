@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRateLimiter_DisabledWithoutRate(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{Burst: 5})
+
+	assert.Nil(t, limiter)
+	wait, err := limiter.Wait(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.Equal(t, RateLimiterStats{}, limiter.GetStats())
+}
+
+func TestRateLimiter_PacesRequestsAfterBurst(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{Rate: 50, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		_, err := limiter.Wait(context.Background())
+		require.NoError(t, err)
+	}
+
+	// The burst goes through at once; the other four wait 20ms each
+	assert.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+	stats := limiter.GetStats()
+	assert.Equal(t, int64(4), stats.Waits)
+	assert.Equal(t, 2, stats.Burst)
+}
+
+func TestRateLimiter_CancelledWaitGivesTokenBack(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{Rate: 1, Burst: 1})
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	limiter.lastTick = now
+
+	_, err := limiter.Wait(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = limiter.Wait(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "the wait is aborted rather than running to the next token")
+	assert.Equal(t, 0.0, limiter.GetStats().AvailableTokens)
+	assert.Equal(t, int64(0), limiter.GetStats().Waits)
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/kasbench/globeco-confirmation-service/internal/domain"
@@ -17,6 +18,7 @@ type ResilienceConfig struct {
 	CircuitBreakerConfig  CircuitBreakerConfig
 	DeadLetterQueueConfig DeadLetterQueueConfig
 	TimeoutConfig         TimeoutConfig
+	RetryBudget           RetryBudgetConfig
 }
 
 // TimeoutConfig represents timeout configuration
@@ -29,11 +31,17 @@ type TimeoutConfig struct {
 // ResilienceManager provides comprehensive error handling and resilience
 type ResilienceManager struct {
 	retryer         *Retryer
+	retryBudget     *RetryBudget
 	circuitBreaker  *CircuitBreaker
 	deadLetterQueue *DeadLetterQueue
 	timeoutConfig   TimeoutConfig
 	logger          *logger.Logger
 	metrics         *metrics.Metrics
+
+	// Named circuit breakers created on demand from the shared configuration
+	circuitBreakerConfig CircuitBreakerConfig
+	circuitBreakers      map[string]*CircuitBreaker
+	circuitBreakersMutex sync.Mutex
 }
 
 // NewResilienceManager creates a new resilience manager
@@ -49,33 +57,66 @@ func NewResilienceManager(config ResilienceConfig, appLogger *logger.Logger, app
 		config.TimeoutConfig.DefaultOperationTimeout = 5 * time.Second
 	}
 
+	retryBudget := NewRetryBudget(config.RetryBudget)
+
 	return &ResilienceManager{
-		retryer:         NewRetryer(config.RetryConfig, appLogger),
-		circuitBreaker:  NewCircuitBreaker(config.CircuitBreakerConfig, appLogger, appMetrics),
-		deadLetterQueue: NewDeadLetterQueue(config.DeadLetterQueueConfig, appLogger, appMetrics),
-		timeoutConfig:   config.TimeoutConfig,
-		logger:          appLogger,
-		metrics:         appMetrics,
+		retryer:              NewRetryer(config.RetryConfig, appLogger).WithRetryBudget(retryBudget),
+		retryBudget:          retryBudget,
+		circuitBreaker:       NewCircuitBreaker(config.CircuitBreakerConfig, appLogger, appMetrics),
+		deadLetterQueue:      NewDeadLetterQueue(config.DeadLetterQueueConfig, appLogger, appMetrics),
+		timeoutConfig:        config.TimeoutConfig,
+		logger:               appLogger,
+		metrics:              appMetrics,
+		circuitBreakerConfig: config.CircuitBreakerConfig,
+		circuitBreakers:      make(map[string]*CircuitBreaker),
 	}
 }
 
+// GetCircuitBreaker returns the circuit breaker with the given name, creating it
+// from the shared circuit breaker configuration on first use
+func (rm *ResilienceManager) GetCircuitBreaker(name string) *CircuitBreaker {
+	rm.circuitBreakersMutex.Lock()
+	defer rm.circuitBreakersMutex.Unlock()
+
+	if cb, exists := rm.circuitBreakers[name]; exists {
+		return cb
+	}
+
+	config := rm.circuitBreakerConfig
+	config.Name = name
+	cb := NewCircuitBreaker(config, rm.logger, rm.metrics)
+	rm.circuitBreakers[name] = cb
+
+	return cb
+}
+
 // ExecuteWithResilience executes an operation with full resilience (retry + circuit breaker + DLQ)
 func (rm *ResilienceManager) ExecuteWithResilience(ctx context.Context, operation string, fn func(ctx context.Context) error, metadata map[string]interface{}) error {
+	return rm.executeWithCircuitBreaker(ctx, rm.circuitBreaker, operation, RetryOptions{RetrySafe: true}, fn, metadata)
+}
+
+// executeWithCircuitBreaker executes an operation with retry, DLQ and the given circuit breaker
+func (rm *ResilienceManager) executeWithCircuitBreaker(ctx context.Context, circuitBreaker *CircuitBreaker, operation string, opts RetryOptions, fn func(ctx context.Context) error, metadata map[string]interface{}) error {
 	// Add timeout to context
 	timeoutCtx, cancel := rm.createTimeoutContext(ctx, operation)
 	defer cancel()
 
 	// Execute with circuit breaker protection
-	err := rm.circuitBreaker.Execute(timeoutCtx, func(ctx context.Context) error {
+	var attempted *RetryResult
+	err := circuitBreaker.Execute(timeoutCtx, func(ctx context.Context) error {
 		// Execute with retry logic
-		result := rm.retryer.Execute(ctx, operation, fn)
-		return result.LastError
+		attempted = rm.retryer.ExecuteWithOptions(ctx, operation, opts, fn)
+		return attempted.LastError
 	})
 
-	// If all retries failed, add to dead letter queue
+	// If all retries failed, add to dead letter queue. Non-idempotent operations are
+	// not run again; the attempts already made are recorded instead.
 	if err != nil {
-		retryResult := rm.retryer.Execute(timeoutCtx, operation, fn)
-		if !retryResult.Success {
+		retryResult := attempted
+		if opts.RetrySafe {
+			retryResult = rm.retryer.Execute(timeoutCtx, operation, fn)
+		}
+		if retryResult != nil && !retryResult.Success {
 			dlqErr := rm.deadLetterQueue.Add(
 				ctx,
 				metadata,
@@ -111,6 +152,18 @@ func (rm *ResilienceManager) ExecuteWithResilienceAndResult(ctx context.Context,
 
 // ExecuteAPICall executes an API call with appropriate resilience settings
 func (rm *ResilienceManager) ExecuteAPICall(ctx context.Context, method, url string, fn func(ctx context.Context) error) error {
+	return rm.ExecuteAPICallWithCircuitBreaker(ctx, rm.circuitBreaker, method, url, fn)
+}
+
+// ExecuteAPICallWithCircuitBreaker executes an idempotent API call protected by the given
+// circuit breaker instead of the shared one
+func (rm *ResilienceManager) ExecuteAPICallWithCircuitBreaker(ctx context.Context, circuitBreaker *CircuitBreaker, method, url string, fn func(ctx context.Context) error) error {
+	return rm.ExecuteAPICallWithOptions(ctx, circuitBreaker, method, url, RetryOptions{RetrySafe: true}, fn)
+}
+
+// ExecuteAPICallWithOptions executes an API call protected by the given circuit breaker,
+// retried according to opts
+func (rm *ResilienceManager) ExecuteAPICallWithOptions(ctx context.Context, circuitBreaker *CircuitBreaker, method, url string, opts RetryOptions, fn func(ctx context.Context) error) error {
 	metadata := map[string]interface{}{
 		"type":   "api_call",
 		"method": method,
@@ -125,7 +178,7 @@ func (rm *ResilienceManager) ExecuteAPICall(ctx context.Context, method, url str
 
 	startTime := time.Now()
 
-	err := rm.ExecuteWithResilience(timeoutCtx, operation, fn, metadata)
+	err := rm.executeWithCircuitBreaker(timeoutCtx, circuitBreaker, operation, opts, fn, metadata)
 
 	// Record API call metrics
 	duration := time.Since(startTime)
@@ -212,6 +265,11 @@ func (rm *ResilienceManager) extractStatusCodeFromError(err error) int {
 			return 409
 		case domain.ErrorTypeTimeout:
 			return 408
+		case domain.ErrorTypeAllocation:
+			if domainErr.Code == domain.AllocationBadRequestCode {
+				return 400
+			}
+			return 502
 		case domain.ErrorTypeExternal:
 			return 502
 		case domain.ErrorTypeCircuitBreaker:
@@ -235,11 +293,22 @@ func (rm *ResilienceManager) GetDeadLetterQueueStats() DeadLetterQueueStats {
 	return rm.deadLetterQueue.GetStats()
 }
 
+// GetRetryBudgetStats returns retry budget statistics; all zero when the budget is disabled
+func (rm *ResilienceManager) GetRetryBudgetStats() RetryBudgetStats {
+	return rm.retryBudget.GetStats()
+}
+
 // GetDeadLetterMessages returns all messages in the dead letter queue
 func (rm *ResilienceManager) GetDeadLetterMessages() []DeadLetterMessage {
 	return rm.deadLetterQueue.GetMessages()
 }
 
+// GetDeadLetterMessagesFiltered returns the dead letter queue messages matching the
+// failure reason and time range; see DeadLetterQueue.GetMessagesFiltered
+func (rm *ResilienceManager) GetDeadLetterMessagesFiltered(reason string, since, until time.Time) []DeadLetterMessage {
+	return rm.deadLetterQueue.GetMessagesFiltered(reason, since, until)
+}
+
 // RemoveDeadLetterMessage removes a message from the dead letter queue
 func (rm *ResilienceManager) RemoveDeadLetterMessage(ctx context.Context, messageID string) bool {
 	return rm.deadLetterQueue.RemoveMessage(ctx, messageID)
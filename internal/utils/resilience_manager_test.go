@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kasbench/globeco-confirmation-service/internal/domain"
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResilienceManager_GetCircuitBreaker(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	config := GetDefaultResilienceConfig()
+	config.CircuitBreakerConfig.FailureThreshold = 7
+
+	rm := NewResilienceManager(config, appLogger, nil)
+
+	get := rm.GetCircuitBreaker("get")
+	update := rm.GetCircuitBreaker("update")
+
+	// The same name always returns the same circuit breaker
+	assert.Same(t, get, rm.GetCircuitBreaker("get"))
+	assert.NotSame(t, get, update)
+	assert.NotSame(t, rm.circuitBreaker, get)
+
+	// Named circuit breakers inherit the shared configuration
+	assert.Equal(t, "get", get.config.Name)
+	assert.Equal(t, "update", update.config.Name)
+	assert.Equal(t, 7, get.config.FailureThreshold)
+}
+
+func TestResilienceManager_ExtractStatusCodeFromError(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	rm := NewResilienceManager(GetDefaultResilienceConfig(), appLogger, nil)
+
+	assert.Equal(t, 200, rm.extractStatusCodeFromError(nil))
+	assert.Equal(t, 502, rm.extractStatusCodeFromError(domain.NewAllocationError("server error: 503", nil, true)))
+	badRequest := domain.NewAllocationError("bad request", nil, false)
+	badRequest.Code = domain.AllocationBadRequestCode
+	assert.Equal(t, 400, rm.extractStatusCodeFromError(badRequest))
+	assert.Equal(t, 502, rm.extractStatusCodeFromError(domain.NewExternalError("execution-service", "request failed", nil, true)))
+	assert.Equal(t, 503, rm.extractStatusCodeFromError(domain.NewCircuitBreakerError("allocation-service")))
+	assert.Equal(t, 500, rm.extractStatusCodeFromError(errors.New("plain error")))
+}
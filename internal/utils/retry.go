@@ -2,9 +2,12 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"net"
+	"syscall"
 	"time"
 
 	"github.com/kasbench/globeco-confirmation-service/internal/domain"
@@ -12,19 +15,42 @@ import (
 	"go.uber.org/zap"
 )
 
+// JitterStrategy represents how random jitter is applied to retry delays
+type JitterStrategy string
+
+const (
+	// JitterNone - delays follow the exponential backoff exactly
+	JitterNone JitterStrategy = "none"
+	// JitterEqual - delays vary by ±10% around the exponential backoff
+	JitterEqual JitterStrategy = "equal"
+	// JitterFull - delays are drawn uniformly between zero and the exponential backoff
+	JitterFull JitterStrategy = "full"
+)
+
 // RetryConfig represents retry configuration
 type RetryConfig struct {
-	MaxAttempts     int           // Maximum number of retry attempts
-	InitialDelay    time.Duration // Initial delay before first retry
-	MaxDelay        time.Duration // Maximum delay between retries
-	BackoffFactor   float64       // Exponential backoff multiplier
-	JitterEnabled   bool          // Whether to add random jitter
-	RetryableErrors []string      // List of retryable error types
+	MaxAttempts     int            // Maximum number of retry attempts
+	InitialDelay    time.Duration  // Initial delay before first retry
+	MaxDelay        time.Duration  // Maximum delay between retries
+	BackoffFactor   float64        // Exponential backoff multiplier
+	JitterEnabled   bool           // Whether to add random jitter (equal jitter when JitterStrategy is unset)
+	JitterStrategy  JitterStrategy // How jitter is applied; takes precedence over JitterEnabled
+	RetryableErrors []string       // List of retryable error types
 }
 
 // RetryableFunc represents a function that can be retried
 type RetryableFunc func(ctx context.Context) error
 
+// RetryOptions adjusts how a single operation is retried
+type RetryOptions struct {
+	// RetrySafe reports whether the operation is idempotent. Operations that are not
+	// are only retried after errors that provably happened before the request was
+	// sent (see IsPreRequestError) or that the service rejected without acting on it
+	// (see domain.IsRequestRejected), so a write whose response was lost is not
+	// applied twice.
+	RetrySafe bool
+}
+
 // RetryResult represents the result of a retry operation
 type RetryResult struct {
 	Success      bool
@@ -38,6 +64,7 @@ type RetryResult struct {
 type Retryer struct {
 	config RetryConfig
 	logger *logger.Logger
+	budget *RetryBudget // Shared retry budget; nil allows every retry
 }
 
 // NewRetryer creates a new retryer instance
@@ -55,6 +82,14 @@ func NewRetryer(config RetryConfig, appLogger *logger.Logger) *Retryer {
 	if config.BackoffFactor <= 0 {
 		config.BackoffFactor = 2.0
 	}
+	if config.JitterStrategy == "" {
+		// Preserve the behavior of configurations that only set JitterEnabled
+		if config.JitterEnabled {
+			config.JitterStrategy = JitterEqual
+		} else {
+			config.JitterStrategy = JitterNone
+		}
+	}
 
 	return &Retryer{
 		config: config,
@@ -62,8 +97,19 @@ func NewRetryer(config RetryConfig, appLogger *logger.Logger) *Retryer {
 	}
 }
 
-// Execute executes a function with retry logic
+// WithRetryBudget makes the retryer draw each retry from the given shared budget
+func (r *Retryer) WithRetryBudget(budget *RetryBudget) *Retryer {
+	r.budget = budget
+	return r
+}
+
+// Execute executes an idempotent function with retry logic
 func (r *Retryer) Execute(ctx context.Context, operation string, fn RetryableFunc) *RetryResult {
+	return r.ExecuteWithOptions(ctx, operation, RetryOptions{RetrySafe: true}, fn)
+}
+
+// ExecuteWithOptions executes a function with retry logic adjusted by opts
+func (r *Retryer) ExecuteWithOptions(ctx context.Context, operation string, opts RetryOptions, fn RetryableFunc) *RetryResult {
 	startTime := time.Now()
 	result := &RetryResult{
 		ErrorHistory: make([]error, 0, r.config.MaxAttempts),
@@ -108,14 +154,41 @@ func (r *Retryer) Execute(ctx context.Context, operation string, fn RetryableFun
 			break
 		}
 
+		// The request may have reached the service, so repeating it could apply it twice,
+		// unless the service rejected it without acting on it
+		if !opts.RetrySafe && !IsPreRequestError(err) && !domain.IsRequestRejected(err) {
+			r.logger.WithContext(ctx).Warn("Non-idempotent operation failed after the request may have been sent, not retrying",
+				zap.String("operation", operation),
+				zap.Int("attempt", attempt),
+				zap.Error(err),
+			)
+			break
+		}
+
 		// Don't sleep after the last attempt
 		if attempt < r.config.MaxAttempts {
+			// Fail fast once the shared retry budget is spent
+			if !r.budget.TryAcquire() {
+				r.logger.WithContext(ctx).Warn("Retry budget exhausted, not retrying",
+					zap.String("operation", operation),
+					zap.Int("attempt", attempt),
+					zap.Error(err),
+				)
+				break
+			}
+
+			// A delay requested by the remote service is the minimum wait
 			delay := r.calculateDelay(attempt)
+			retryAfter := domain.RetryAfterOf(err)
+			if retryAfter > delay {
+				delay = retryAfter
+			}
 
 			r.logger.WithContext(ctx).Warn("Operation failed, retrying",
 				zap.String("operation", operation),
 				zap.Int("attempt", attempt),
 				zap.Duration("delay", delay),
+				zap.Duration("retry_after", retryAfter),
 				zap.Error(err),
 			)
 
@@ -141,9 +214,11 @@ func (r *Retryer) Execute(ctx context.Context, operation string, fn RetryableFun
 	return result
 }
 
-// ExecuteWithStringResult executes a function with retry logic and returns a string result
-func (r *Retryer) ExecuteWithStringResult(ctx context.Context, operation string, fn func(ctx context.Context) (string, error)) (string, *RetryResult) {
-	var result string
+// ExecuteWithResult executes a function with the retryer's retry logic and returns
+// the result of the last attempt. Go methods cannot take type parameters, so the
+// retryer is passed as an argument.
+func ExecuteWithResult[T any](ctx context.Context, r *Retryer, operation string, fn func(ctx context.Context) (T, error)) (T, *RetryResult) {
+	var result T
 
 	retryResult := r.Execute(ctx, operation, func(ctx context.Context) error {
 		var err error
@@ -154,17 +229,42 @@ func (r *Retryer) ExecuteWithStringResult(ctx context.Context, operation string,
 	return result, retryResult
 }
 
-// ExecuteWithInterfaceResult executes a function with retry logic and returns an interface{} result
+// ExecuteWithStringResult executes a function with retry logic and returns a string result
+func (r *Retryer) ExecuteWithStringResult(ctx context.Context, operation string, fn func(ctx context.Context) (string, error)) (string, *RetryResult) {
+	return ExecuteWithResult(ctx, r, operation, fn)
+}
+
+// ExecuteWithInterfaceResult executes a function with retry logic and returns an interface{} result.
+// Prefer ExecuteWithResult, which keeps the result's type.
 func (r *Retryer) ExecuteWithInterfaceResult(ctx context.Context, operation string, fn func(ctx context.Context) (interface{}, error)) (interface{}, *RetryResult) {
-	var result interface{}
+	return ExecuteWithResult(ctx, r, operation, fn)
+}
 
-	retryResult := r.Execute(ctx, operation, func(ctx context.Context) error {
-		var err error
-		result, err = fn(ctx)
-		return err
-	})
+// IsPreRequestError reports whether err provably happened before a request reached the
+// remote service: a client-side limit held it back, the host could not be resolved or
+// the connection was never established. Timeouts, resets and error responses may follow
+// a request the service processed, so they are not pre-request errors.
+func IsPreRequestError(err error) bool {
+	if err == nil {
+		return false
+	}
 
-	return result, retryResult
+	if domain.IsNotSent(err) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	// Nothing is sent until the connection is dialed
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
 }
 
 // calculateDelay calculates the delay for the next retry attempt
@@ -177,10 +277,13 @@ func (r *Retryer) calculateDelay(attempt int) time.Duration {
 		delay = float64(r.config.MaxDelay)
 	}
 
-	// Add jitter if enabled
-	if r.config.JitterEnabled {
+	// Apply jitter according to the configured strategy
+	switch r.config.JitterStrategy {
+	case JitterEqual:
 		jitter := delay * 0.1 * (rand.Float64()*2 - 1) // ±10% jitter
 		delay += jitter
+	case JitterFull:
+		delay = rand.Float64() * delay // Uniform in [0, backoff)
 	}
 
 	return time.Duration(delay)
@@ -253,11 +356,12 @@ func containsSubstring(s, substr string) bool {
 // GetDefaultRetryConfig returns a default retry configuration
 func GetDefaultRetryConfig() RetryConfig {
 	return RetryConfig{
-		MaxAttempts:   3,
-		InitialDelay:  100 * time.Millisecond,
-		MaxDelay:      5 * time.Second,
-		BackoffFactor: 2.0,
-		JitterEnabled: true,
+		MaxAttempts:    3,
+		InitialDelay:   100 * time.Millisecond,
+		MaxDelay:       5 * time.Second,
+		BackoffFactor:  2.0,
+		JitterEnabled:  true,
+		JitterStrategy: JitterEqual,
 		RetryableErrors: []string{
 			"*domain.ExternalError",
 			"*domain.TimeoutError",
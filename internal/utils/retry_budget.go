@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryBudgetConfig represents the configuration for the shared retry budget
+type RetryBudgetConfig struct {
+	Tokens     int     // Bucket capacity; each retry consumes one token. 0 disables the budget
+	RefillRate float64 // Tokens added per second
+}
+
+// RetryBudgetStats represents retry budget statistics
+type RetryBudgetStats struct {
+	Capacity          int     `json:"capacity"`
+	AvailableTokens   float64 `json:"available_tokens"`
+	RefillRate        float64 `json:"refill_rate"`
+	Utilization       float64 `json:"utilization"` // Fraction of the bucket currently spent
+	RetriesAllowed    int64   `json:"retries_allowed"`
+	RetriesSuppressed int64   `json:"retries_suppressed"`
+}
+
+// RetryBudget is a token bucket shared by retryers that caps the total number
+// of retries per unit time, so retries fail fast instead of amplifying an outage
+type RetryBudget struct {
+	mutex      sync.Mutex
+	capacity   float64
+	tokens     float64
+	refillRate float64
+	lastRefill time.Time
+	now        func() time.Time
+
+	retriesAllowed    int64
+	retriesSuppressed int64
+}
+
+// NewRetryBudget creates a full retry budget, or returns nil when the budget is disabled
+func NewRetryBudget(config RetryBudgetConfig) *RetryBudget {
+	if config.Tokens <= 0 {
+		return nil
+	}
+
+	return &RetryBudget{
+		capacity:   float64(config.Tokens),
+		tokens:     float64(config.Tokens),
+		refillRate: config.RefillRate,
+		lastRefill: time.Now(),
+		now:        time.Now,
+	}
+}
+
+// TryAcquire consumes a token for one retry, reporting false when the budget is exhausted.
+// A nil budget always allows the retry.
+func (rb *RetryBudget) TryAcquire() bool {
+	if rb == nil {
+		return true
+	}
+
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	rb.refill()
+
+	if rb.tokens < 1 {
+		rb.retriesSuppressed++
+		return false
+	}
+
+	rb.tokens--
+	rb.retriesAllowed++
+	return true
+}
+
+// GetStats returns retry budget statistics
+func (rb *RetryBudget) GetStats() RetryBudgetStats {
+	if rb == nil {
+		return RetryBudgetStats{}
+	}
+
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	rb.refill()
+
+	return RetryBudgetStats{
+		Capacity:          int(rb.capacity),
+		AvailableTokens:   rb.tokens,
+		RefillRate:        rb.refillRate,
+		Utilization:       1 - rb.tokens/rb.capacity,
+		RetriesAllowed:    rb.retriesAllowed,
+		RetriesSuppressed: rb.retriesSuppressed,
+	}
+}
+
+// refill adds the tokens accrued since the last refill. The caller must hold the lock.
+func (rb *RetryBudget) refill() {
+	now := rb.now()
+	elapsed := now.Sub(rb.lastRefill).Seconds()
+	rb.lastRefill = now
+
+	if elapsed <= 0 || rb.refillRate <= 0 {
+		return
+	}
+
+	rb.tokens += elapsed * rb.refillRate
+	if rb.tokens > rb.capacity {
+		rb.tokens = rb.capacity
+	}
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRetryBudget_DisabledWithoutTokens(t *testing.T) {
+	budget := NewRetryBudget(RetryBudgetConfig{})
+
+	assert.Nil(t, budget)
+	assert.True(t, budget.TryAcquire())
+	assert.Equal(t, RetryBudgetStats{}, budget.GetStats())
+}
+
+func TestRetryBudget_RefillsOverTime(t *testing.T) {
+	now := time.Now()
+	budget := NewRetryBudget(RetryBudgetConfig{Tokens: 2, RefillRate: 1})
+	budget.now = func() time.Time { return now }
+	budget.lastRefill = now
+
+	assert.True(t, budget.TryAcquire())
+	assert.True(t, budget.TryAcquire())
+	assert.False(t, budget.TryAcquire())
+
+	stats := budget.GetStats()
+	assert.Equal(t, 1.0, stats.Utilization)
+	assert.Equal(t, int64(2), stats.RetriesAllowed)
+	assert.Equal(t, int64(1), stats.RetriesSuppressed)
+
+	// One second refills one token
+	now = now.Add(time.Second)
+	assert.True(t, budget.TryAcquire())
+	assert.False(t, budget.TryAcquire())
+
+	// Refilling never exceeds the capacity
+	now = now.Add(time.Minute)
+	stats = budget.GetStats()
+	assert.Equal(t, 2.0, stats.AvailableTokens)
+	assert.Equal(t, 0.0, stats.Utilization)
+}
+
+func TestRetryer_Execute_SuppressesRetriesWhenBudgetExhausted(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	budget := NewRetryBudget(RetryBudgetConfig{Tokens: 3, RefillRate: 0.001})
+	retryer := NewRetryer(RetryConfig{
+		MaxAttempts:   3,
+		InitialDelay:  time.Millisecond,
+		MaxDelay:      time.Millisecond,
+		BackoffFactor: 1.0,
+	}, appLogger).WithRetryBudget(budget)
+
+	callCount := 0
+	fn := func(ctx context.Context) error {
+		callCount++
+		return errors.New("execution service unavailable")
+	}
+
+	// The first operation spends two tokens on its retries
+	result := retryer.Execute(context.Background(), "first", fn)
+	assert.False(t, result.Success)
+	assert.Equal(t, 3, result.Attempts)
+
+	// The second operation gets one retry before the budget runs out
+	result = retryer.Execute(context.Background(), "second", fn)
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, result.Attempts)
+
+	// Once the bucket is empty operations fail fast after a single attempt
+	callCount = 0
+	result = retryer.Execute(context.Background(), "third", fn)
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, 1, callCount)
+	assert.Error(t, result.LastError)
+
+	stats := budget.GetStats()
+	assert.Equal(t, int64(3), stats.RetriesAllowed)
+	assert.Equal(t, int64(2), stats.RetriesSuppressed)
+}
+
+func TestResilienceManager_GetRetryBudgetStats(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	config := GetDefaultResilienceConfig()
+	config.RetryBudget = RetryBudgetConfig{Tokens: 50, RefillRate: 5}
+
+	rm := NewResilienceManager(config, appLogger, nil)
+	defer rm.Stop(context.Background())
+
+	stats := rm.GetRetryBudgetStats()
+	assert.Equal(t, 50, stats.Capacity)
+	assert.Equal(t, 5.0, stats.RefillRate)
+	assert.InDelta(t, 0.0, stats.Utilization, 0.001)
+}
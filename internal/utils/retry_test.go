@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"syscall"
 	"testing"
 	"time"
 
@@ -139,6 +142,56 @@ func TestRetryer_Execute_SuccessAfterRetry(t *testing.T) {
 	assert.Len(t, result.ErrorHistory, 2) // Two failures before success
 }
 
+func TestRetryer_Execute_HonorsRetryAfter(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	retryer := NewRetryer(RetryConfig{
+		MaxAttempts:    2,
+		InitialDelay:   time.Millisecond,
+		MaxDelay:       time.Millisecond,
+		BackoffFactor:  2.0,
+		JitterStrategy: JitterNone,
+	}, appLogger)
+
+	t.Run("hint longer than the backoff", func(t *testing.T) {
+		var attempts []time.Time
+		result := retryer.Execute(context.Background(), "test-operation", func(ctx context.Context) error {
+			attempts = append(attempts, time.Now())
+			if len(attempts) == 1 {
+				return domain.NewExternalError("execution-service", "rate limit exceeded", nil, true).
+					WithRetryAfter(80 * time.Millisecond)
+			}
+			return nil
+		})
+
+		require.True(t, result.Success)
+		require.Len(t, attempts, 2)
+		assert.GreaterOrEqual(t, attempts[1].Sub(attempts[0]), 80*time.Millisecond)
+	})
+
+	t.Run("hint does not outlast the context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		calls := 0
+		result := retryer.Execute(ctx, "test-operation", func(ctx context.Context) error {
+			calls++
+			return domain.NewExternalError("execution-service", "server error: 503", nil, true).
+				WithRetryAfter(time.Hour)
+		})
+
+		assert.False(t, result.Success)
+		assert.Equal(t, 1, calls)
+		assert.ErrorIs(t, result.LastError, context.DeadlineExceeded)
+	})
+}
+
 func TestRetryer_Execute_AllAttemptsFailed(t *testing.T) {
 	appLogger, err := logger.New(logger.Config{
 		Level:       "info",
@@ -308,6 +361,136 @@ func TestRetryer_ExecuteWithInterfaceResult(t *testing.T) {
 	assert.Equal(t, 2, callCount)
 }
 
+func TestExecuteWithResult_ExecutionResponse(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	retryer := NewRetryer(RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond}, appLogger)
+	ctx := context.Background()
+
+	callCount := 0
+	fn := func(ctx context.Context) (*domain.ExecutionResponse, error) {
+		callCount++
+		if callCount < 2 {
+			return nil, errors.New("temporary failure")
+		}
+		return &domain.ExecutionResponse{ID: 27, Version: 3}, nil
+	}
+
+	execution, retryResult := ExecuteWithResult(ctx, retryer, "get-execution", fn)
+
+	require.NotNil(t, execution)
+	assert.Equal(t, int64(27), execution.ID)
+	assert.Equal(t, 3, execution.Version)
+	assert.True(t, retryResult.Success)
+	assert.Equal(t, 2, retryResult.Attempts)
+}
+
+func TestExecuteWithResult_Struct(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	type quote struct {
+		Ticker string
+		Price  float64
+	}
+
+	retryer := NewRetryer(RetryConfig{MaxAttempts: 2, InitialDelay: time.Millisecond}, appLogger)
+	ctx := context.Background()
+
+	// Success returns the value
+	result, retryResult := ExecuteWithResult(ctx, retryer, "get-quote", func(ctx context.Context) (quote, error) {
+		return quote{Ticker: "IBM", Price: 190.41}, nil
+	})
+	assert.Equal(t, quote{Ticker: "IBM", Price: 190.41}, result)
+	assert.True(t, retryResult.Success)
+
+	// Failure returns the last attempt's value and error
+	permanentErr := domain.NewValidationError("ticker", "unknown ticker")
+	result, retryResult = ExecuteWithResult(ctx, retryer, "get-quote", func(ctx context.Context) (quote, error) {
+		return quote{}, permanentErr
+	})
+	assert.Equal(t, quote{}, result)
+	assert.False(t, retryResult.Success)
+	assert.Equal(t, 1, retryResult.Attempts)
+	assert.Equal(t, permanentErr, retryResult.LastError)
+}
+
+func TestIsPreRequestError(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("i/o timeout")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "held back by a client-side limit", err: domain.NewTimeoutError("execution-service rate limit", context.DeadlineExceeded).WithNotSent(), want: true},
+		{name: "dns failure", err: &url.Error{Op: "Put", URL: "http://execution", Err: &net.DNSError{Err: "no such host", Name: "execution"}}, want: true},
+		{name: "connection refused", err: fmt.Errorf("request failed: %w", syscall.ECONNREFUSED), want: true},
+		{name: "dial error", err: &url.Error{Op: "Put", URL: "http://execution", Err: dialErr}, want: true},
+		{name: "dial error in domain error", err: domain.NewExternalError("execution-service", "request failed", dialErr, true), want: true},
+		{name: "read error after send", err: &url.Error{Op: "Put", URL: "http://execution", Err: readErr}, want: false},
+		{name: "deadline after send", err: domain.NewExternalError("execution-service", "request failed", context.DeadlineExceeded, true), want: false},
+		{name: "error response", err: domain.NewExternalError("execution-service", "HTTP 503", nil, true), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPreRequestError(tt.err))
+		})
+	}
+}
+
+func TestRetryer_ExecuteWithOptions_NotRetrySafe(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	retryer := NewRetryer(RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond}, appLogger)
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		opts         RetryOptions
+		err          error
+		wantAttempts int
+	}{
+		{name: "unsafe retries connection refused", opts: RetryOptions{RetrySafe: false}, err: syscall.ECONNREFUSED, wantAttempts: 3},
+		{name: "unsafe does not retry a timeout after send", opts: RetryOptions{RetrySafe: false}, err: domain.NewTimeoutError("update", context.DeadlineExceeded), wantAttempts: 1},
+		{name: "safe retries a timeout after send", opts: RetryOptions{RetrySafe: true}, err: domain.NewTimeoutError("update", context.DeadlineExceeded), wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callCount := 0
+			result := retryer.ExecuteWithOptions(ctx, "update", tt.opts, func(ctx context.Context) error {
+				callCount++
+				return tt.err
+			})
+
+			assert.False(t, result.Success)
+			assert.Equal(t, tt.wantAttempts, result.Attempts)
+			assert.Equal(t, tt.wantAttempts, callCount)
+		})
+	}
+}
+
 func TestRetryer_calculateDelay(t *testing.T) {
 	appLogger, err := logger.New(logger.Config{
 		Level:       "info",
@@ -394,6 +577,87 @@ func TestRetryer_calculateDelay_WithJitter(t *testing.T) {
 	}
 }
 
+func TestNewRetryer_JitterStrategy(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		config   RetryConfig
+		expected JitterStrategy
+	}{
+		{"jitter disabled maps to none", RetryConfig{JitterEnabled: false}, JitterNone},
+		{"jitter enabled maps to equal", RetryConfig{JitterEnabled: true}, JitterEqual},
+		{"explicit strategy preserved", RetryConfig{JitterEnabled: true, JitterStrategy: JitterFull}, JitterFull},
+		{"explicit none overrides enabled", RetryConfig{JitterEnabled: true, JitterStrategy: JitterNone}, JitterNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryer := NewRetryer(tt.config, appLogger)
+			assert.Equal(t, tt.expected, retryer.config.JitterStrategy)
+		})
+	}
+}
+
+func TestRetryer_calculateDelay_WithFullJitter(t *testing.T) {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	config := RetryConfig{
+		MaxAttempts:    5,
+		InitialDelay:   100 * time.Millisecond,
+		MaxDelay:       1 * time.Second,
+		BackoffFactor:  2.0,
+		JitterStrategy: JitterFull,
+	}
+
+	retryer := NewRetryer(config, appLogger)
+
+	tests := []struct {
+		attempt int
+		backoff time.Duration
+	}{
+		{2, 200 * time.Millisecond},
+		{5, 1 * time.Second}, // Capped at MaxDelay
+	}
+
+	const samples = 10000
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt_%d", tt.attempt), func(t *testing.T) {
+			var sum float64
+			var lowerHalf int
+			for i := 0; i < samples; i++ {
+				delay := retryer.calculateDelay(tt.attempt)
+				require.GreaterOrEqual(t, delay, time.Duration(0))
+				require.LessOrEqual(t, delay, tt.backoff)
+
+				sum += float64(delay)
+				if delay < tt.backoff/2 {
+					lowerHalf++
+				}
+			}
+
+			// A uniform distribution over [0, backoff] has mean backoff/2 and
+			// places half of the samples below the midpoint
+			mean := sum / samples
+			assert.InDelta(t, float64(tt.backoff)/2, mean, float64(tt.backoff)*0.05)
+			assert.InDelta(t, samples/2, lowerHalf, samples*0.05)
+		})
+	}
+}
+
 func TestRetryer_isRetryableError(t *testing.T) {
 	appLogger, err := logger.New(logger.Config{
 		Level:       "info",
@@ -464,6 +728,7 @@ func TestGetDefaultRetryConfig(t *testing.T) {
 	assert.Equal(t, 5*time.Second, config.MaxDelay)
 	assert.Equal(t, 2.0, config.BackoffFactor)
 	assert.True(t, config.JitterEnabled)
+	assert.Equal(t, JitterEqual, config.JitterStrategy)
 	assert.Contains(t, config.RetryableErrors, "*domain.ExternalError")
 	assert.Contains(t, config.RetryableErrors, "*domain.TimeoutError")
 }
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ShutdownStage orders the steps of a graceful shutdown; lower stages stop first
+type ShutdownStage int
+
+const (
+	// ShutdownStageConsumer stops fetching messages and drains the one in flight
+	ShutdownStageConsumer ShutdownStage = iota
+	// ShutdownStageDeadLetterQueue stops the dead letter queue workers and persists its contents
+	ShutdownStageDeadLetterQueue
+	// ShutdownStageDuplicateDetection stops duplicate detection and closes its store
+	ShutdownStageDuplicateDetection
+	// ShutdownStageHTTPServer stops serving health checks, metrics and the admin API
+	ShutdownStageHTTPServer
+	// ShutdownStageTelemetry flushes and shuts down tracing and metrics exporters
+	ShutdownStageTelemetry
+)
+
+// String returns the stage name used in logs
+func (s ShutdownStage) String() string {
+	switch s {
+	case ShutdownStageConsumer:
+		return "consumer"
+	case ShutdownStageDeadLetterQueue:
+		return "dead_letter_queue"
+	case ShutdownStageDuplicateDetection:
+		return "duplicate_detection"
+	case ShutdownStageHTTPServer:
+		return "http_server"
+	case ShutdownStageTelemetry:
+		return "telemetry"
+	default:
+		return fmt.Sprintf("stage_%d", int(s))
+	}
+}
+
+// ShutdownFunc stops one component
+type ShutdownFunc func(ctx context.Context) error
+
+type shutdownStep struct {
+	stage ShutdownStage
+	name  string
+	fn    ShutdownFunc
+}
+
+// ShutdownSequence stops registered components in stage order, so in-flight
+// messages finish before the components they depend on are torn down.
+// Steps within a stage run in registration order.
+type ShutdownSequence struct {
+	steps  []shutdownStep
+	logger *logger.Logger
+}
+
+// NewShutdownSequence creates an empty shutdown sequence
+func NewShutdownSequence(appLogger *logger.Logger) *ShutdownSequence {
+	return &ShutdownSequence{logger: appLogger}
+}
+
+// Register adds a component to stop during the given stage
+func (s *ShutdownSequence) Register(stage ShutdownStage, name string, fn ShutdownFunc) {
+	s.steps = append(s.steps, shutdownStep{stage: stage, name: name, fn: fn})
+}
+
+// Run stops every registered component in stage order. A failing step does not
+// prevent later steps from running; all failures are returned together.
+func (s *ShutdownSequence) Run(ctx context.Context) error {
+	steps := make([]shutdownStep, len(s.steps))
+	copy(steps, s.steps)
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].stage < steps[j].stage
+	})
+
+	var errs []error
+	for _, step := range steps {
+		startTime := time.Now()
+		err := step.fn(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
+		}
+
+		if s.logger != nil {
+			if err != nil {
+				s.logger.WithContext(ctx).Error("Shutdown step failed",
+					zap.String("stage", step.stage.String()),
+					zap.String("component", step.name),
+					zap.Duration("duration", time.Since(startTime)),
+					zap.Error(err),
+				)
+			} else {
+				s.logger.WithContext(ctx).Info("Shutdown step completed",
+					zap.String("stage", step.stage.String()),
+					zap.String("component", step.name),
+					zap.Duration("duration", time.Since(startTime)),
+				)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
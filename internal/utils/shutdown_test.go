@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kasbench/globeco-confirmation-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestShutdownSequence(t *testing.T) *ShutdownSequence {
+	appLogger, err := logger.New(logger.Config{
+		Level:       "info",
+		Format:      "json",
+		Output:      "stdout",
+		ServiceName: "test",
+	})
+	require.NoError(t, err)
+
+	return NewShutdownSequence(appLogger)
+}
+
+func TestShutdownSequence_RunsStagesInDocumentedOrder(t *testing.T) {
+	sequence := newTestShutdownSequence(t)
+
+	var stopped []string
+	stop := func(name string) ShutdownFunc {
+		return func(ctx context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		}
+	}
+
+	// Registered out of order; the stages decide the order
+	sequence.Register(ShutdownStageTelemetry, "opentelemetry", stop("opentelemetry"))
+	sequence.Register(ShutdownStageHTTPServer, "http_server", stop("http_server"))
+	sequence.Register(ShutdownStageDuplicateDetection, "duplicate_detection", stop("duplicate_detection"))
+	sequence.Register(ShutdownStageDeadLetterQueue, "resilience_manager", stop("resilience_manager"))
+	sequence.Register(ShutdownStageConsumer, "kafka_consumer", stop("kafka_consumer"))
+	sequence.Register(ShutdownStageTelemetry, "metrics", stop("metrics"))
+
+	require.NoError(t, sequence.Run(context.Background()))
+	assert.Equal(t, []string{
+		"kafka_consumer",
+		"resilience_manager",
+		"duplicate_detection",
+		"http_server",
+		"opentelemetry",
+		"metrics",
+	}, stopped)
+}
+
+func TestShutdownSequence_ContinuesAfterFailedStep(t *testing.T) {
+	sequence := newTestShutdownSequence(t)
+
+	consumerErr := errors.New("drain deadline exceeded")
+	telemetryStopped := false
+	sequence.Register(ShutdownStageConsumer, "kafka_consumer", func(ctx context.Context) error {
+		return consumerErr
+	})
+	sequence.Register(ShutdownStageTelemetry, "opentelemetry", func(ctx context.Context) error {
+		telemetryStopped = true
+		return nil
+	})
+
+	err := sequence.Run(context.Background())
+	assert.ErrorIs(t, err, consumerErr)
+	assert.ErrorContains(t, err, "kafka_consumer")
+	assert.True(t, telemetryStopped)
+}
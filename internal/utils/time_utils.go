@@ -6,11 +6,22 @@ import (
 )
 
 // TimeUtils provides utility functions for time calculations and formatting
-type TimeUtils struct{}
+type TimeUtils struct {
+	clock Clock
+}
 
-// NewTimeUtils creates a new TimeUtils instance
+// NewTimeUtils creates a new TimeUtils instance that uses the system clock
 func NewTimeUtils() *TimeUtils {
-	return &TimeUtils{}
+	return NewTimeUtilsWithClock(RealClock{})
+}
+
+// NewTimeUtilsWithClock creates a new TimeUtils instance that tells the time with the
+// given clock; a nil clock uses the system clock
+func NewTimeUtilsWithClock(clock Clock) *TimeUtils {
+	if clock == nil {
+		clock = RealClock{}
+	}
+	return &TimeUtils{clock: clock}
 }
 
 // UnixFloatToTime converts a Unix timestamp with fractional seconds to time.Time
@@ -66,7 +77,7 @@ func (tu *TimeUtils) IsTimestampInFuture(timestamp float64, toleranceSeconds int
 		return false
 	}
 
-	now := time.Now().Unix()
+	now := tu.clock.Now().Unix()
 	return timestamp > float64(now+toleranceSeconds)
 }
 
@@ -77,7 +88,7 @@ func (tu *TimeUtils) IsTimestampTooOld(timestamp float64, maxAge time.Duration)
 	}
 
 	timestampTime := tu.UnixFloatToTime(timestamp)
-	return time.Since(timestampTime) > maxAge
+	return tu.clock.Now().Sub(timestampTime) > maxAge
 }
 
 // FormatDuration formats a duration in a human-readable way
@@ -270,6 +270,25 @@ func TestTimeUtils_IsTimestampTooOld(t *testing.T) {
 	}
 }
 
+func TestTimeUtils_WithClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	tu := NewTimeUtilsWithClock(clock)
+	now := clock.Now()
+
+	assert.False(t, tu.IsTimestampInFuture(float64(now.Add(time.Hour).Unix()), 3600))
+	assert.True(t, tu.IsTimestampInFuture(float64(now.Add(time.Hour+time.Second).Unix()), 3600))
+	assert.False(t, tu.IsTimestampTooOld(float64(now.Add(-24*time.Hour).Unix()), 24*time.Hour))
+	assert.True(t, tu.IsTimestampTooOld(float64(now.Add(-24*time.Hour-time.Second).Unix()), 24*time.Hour))
+
+	// The checks follow the clock, not the wall clock
+	clock.Advance(time.Hour)
+	assert.False(t, tu.IsTimestampInFuture(float64(now.Add(time.Hour+time.Second).Unix()), 3600))
+	assert.True(t, tu.IsTimestampTooOld(float64(now.Add(-24*time.Hour).Unix()), 24*time.Hour))
+
+	clock.Set(now)
+	assert.Equal(t, now, clock.Now())
+}
+
 func TestTimeUtils_FormatDuration(t *testing.T) {
 	tu := NewTimeUtils()
 
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var logfmtBufferPool = buffer.NewPool()
+
+// logfmtEncoder writes entries as logfmt key=value pairs. It encodes each entry with
+// the JSON encoder and transcodes the result, so field handling matches the JSON
+// format exactly; nested objects and arrays are written as quoted JSON.
+type logfmtEncoder struct {
+	zapcore.Encoder
+}
+
+func newLogfmtEncoder(encoderConfig zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{Encoder: zapcore.NewJSONEncoder(encoderConfig)}
+}
+
+// Clone copies the encoder, including any fields added with With
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	return &logfmtEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// EncodeEntry encodes an entry and its fields as a single logfmt line
+func (e *logfmtEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	encoded, err := e.Encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer encoded.Free()
+
+	line := logfmtBufferPool.Get()
+	if err := transcodeJSONToLogfmt(encoded.Bytes(), line); err != nil {
+		line.Free()
+		return nil, err
+	}
+	line.AppendByte('\n')
+	return line, nil
+}
+
+// transcodeJSONToLogfmt writes the members of a JSON object as logfmt pairs, in order
+func transcodeJSONToLogfmt(data []byte, out *buffer.Buffer) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return fmt.Errorf("logfmt: expected a JSON object")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("logfmt: %w", err)
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("logfmt: expected an object key")
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return fmt.Errorf("logfmt: %w", err)
+		}
+
+		if out.Len() > 0 {
+			out.AppendByte(' ')
+		}
+		out.AppendString(key)
+		out.AppendByte('=')
+		appendLogfmtValue(out, raw)
+	}
+
+	return nil
+}
+
+// appendLogfmtValue writes a JSON value, unwrapping strings and quoting when needed
+func appendLogfmtValue(out *buffer.Buffer, raw json.RawMessage) {
+	switch raw[0] {
+	case '"':
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil {
+			appendLogfmtString(out, value)
+			return
+		}
+		appendLogfmtString(out, string(raw))
+	case '{', '[':
+		appendLogfmtString(out, string(raw))
+	default:
+		// Numbers, booleans and null are written as they are
+		out.Write(raw)
+	}
+}
+
+// appendLogfmtString writes a string value, quoting it when it is empty or contains
+// spaces, quotes, equals signs or control characters
+func appendLogfmtString(out *buffer.Buffer, value string) {
+	if needsLogfmtQuoting(value) {
+		out.AppendString(strconv.Quote(value))
+		return
+	}
+	out.AppendString(value)
+}
+
+func needsLogfmtQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r == ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
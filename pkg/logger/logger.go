@@ -2,8 +2,10 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"syscall"
 	"time"
 
 	"go.uber.org/zap"
@@ -22,18 +24,40 @@ const (
 type Logger struct {
 	*zap.Logger
 	serviceName string
+	level       *zap.AtomicLevel // Shared by loggers derived from this one; nil when not adjustable
 }
 
 // Config represents logger configuration
 type Config struct {
 	Level       string // debug, info, warn, error
-	Format      string // json, console
+	Format      string // json (default), console, logfmt
 	Output      string // stdout, stderr, file
 	ServiceName string
+
+	// Sampling of debug and info entries: each second the first SamplingInitial entries
+	// with the same level and message are logged, then every SamplingThereafter-th one.
+	// Warnings and errors are never sampled. Zero SamplingInitial disables sampling.
+	SamplingInitial    int
+	SamplingThereafter int
 }
 
+// samplingTick is the interval over which sampled entries are counted
+const samplingTick = time.Second
+
+// Log output formats
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console" // zap's human-readable development format, colored
+	FormatLogfmt  = "logfmt"
+)
+
 // New creates a new logger instance
 func New(config Config) (*Logger, error) {
+	return newLogger(config, getWriter(config.Output))
+}
+
+// newLogger creates a logger writing to the given writer
+func newLogger(config Config, writer zapcore.WriteSyncer) (*Logger, error) {
 	// Parse log level
 	level, err := zapcore.ParseLevel(config.Level)
 	if err != nil {
@@ -58,14 +82,25 @@ func New(config Config) (*Logger, error) {
 
 	// Create encoder based on format
 	var encoder zapcore.Encoder
-	if config.Format == "console" {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	} else {
+	switch config.Format {
+	case FormatJSON, "":
 		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case FormatConsole:
+		developmentConfig := zap.NewDevelopmentEncoderConfig()
+		developmentConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(developmentConfig)
+	case FormatLogfmt:
+		encoder = newLogfmtEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("invalid log format %s: must be one of %s, %s, %s", config.Format, FormatJSON, FormatConsole, FormatLogfmt)
 	}
 
-	// Create core
-	core := zapcore.NewCore(encoder, zapcore.AddSync(getWriter(config.Output)), level)
+	// Create core with a level that can be changed while running
+	atomicLevel := zap.NewAtomicLevelAt(level)
+	core := zapcore.NewCore(encoder, writer, atomicLevel)
+	if config.SamplingInitial > 0 {
+		core = newSampledCore(encoder, writer, atomicLevel, config.SamplingInitial, config.SamplingThereafter)
+	}
 
 	// Create logger with caller information
 	zapLogger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
@@ -76,9 +111,59 @@ func New(config Config) (*Logger, error) {
 	return &Logger{
 		Logger:      zapLogger,
 		serviceName: config.ServiceName,
+		level:       &atomicLevel,
 	}, nil
 }
 
+// newSampledCore returns a core that samples debug and info entries and passes warnings
+// and errors through. Both halves follow the adjustable level.
+func newSampledCore(encoder zapcore.Encoder, writer zapcore.WriteSyncer, level zap.AtomicLevel, initial, thereafter int) zapcore.Core {
+	sampledLevels := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l < zapcore.WarnLevel && level.Enabled(l)
+	})
+	unsampledLevels := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= zapcore.WarnLevel && level.Enabled(l)
+	})
+
+	return zapcore.NewTee(
+		zapcore.NewSamplerWithOptions(zapcore.NewCore(encoder, writer, sampledLevels), samplingTick, initial, thereafter),
+		zapcore.NewCore(encoder.Clone(), writer, unsampledLevels),
+	)
+}
+
+// SetLevel changes the minimum level logged by this logger and every logger derived from it
+func (l *Logger) SetLevel(level string) error {
+	if l.level == nil {
+		return fmt.Errorf("log level is not adjustable")
+	}
+
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %s: %w", level, err)
+	}
+
+	l.level.SetLevel(parsed)
+	return nil
+}
+
+// GetLevel returns the minimum level logged, or an empty string when it is not adjustable
+func (l *Logger) GetLevel() string {
+	if l.level == nil {
+		return ""
+	}
+	return l.level.String()
+}
+
+// Close flushes buffered log entries. Syncing a terminal or pipe on stdout or stderr
+// fails with EINVAL or ENOTTY on some platforms; those errors are ignored since there
+// is nothing to flush.
+func (l *Logger) Close() error {
+	if err := l.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTTY) {
+		return fmt.Errorf("failed to flush logger: %w", err)
+	}
+	return nil
+}
+
 // getWriter returns the appropriate writer based on output configuration
 func getWriter(output string) zapcore.WriteSyncer {
 	switch output {
@@ -97,6 +182,7 @@ func (l *Logger) WithCorrelationID(correlationID string) *Logger {
 	return &Logger{
 		Logger:      l.Logger.With(zap.String("correlationId", correlationID)),
 		serviceName: l.serviceName,
+		level:       l.level,
 	}
 }
 
@@ -113,6 +199,7 @@ func (l *Logger) WithFields(fields ...zap.Field) *Logger {
 	return &Logger{
 		Logger:      l.Logger.With(fields...),
 		serviceName: l.serviceName,
+		level:       l.level,
 	}
 }
 
@@ -1,14 +1,40 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
+func TestLogger_SetLevel(t *testing.T) {
+	logger, err := New(Config{Level: "info", Format: "json", Output: "stdout", ServiceName: "test"})
+	require.NoError(t, err)
+	derived := logger.WithCorrelationID("test-correlation-id")
+
+	assert.Equal(t, "info", logger.GetLevel())
+	assert.False(t, derived.Core().Enabled(zapcore.DebugLevel))
+
+	require.NoError(t, logger.SetLevel("debug"))
+	assert.Equal(t, "debug", derived.GetLevel())
+	assert.True(t, derived.Core().Enabled(zapcore.DebugLevel))
+
+	assert.Error(t, logger.SetLevel("verbose"))
+	assert.Equal(t, "debug", logger.GetLevel())
+
+	// Loggers not built by New have no adjustable level
+	assert.Error(t, (&Logger{Logger: zap.NewNop()}).SetLevel("debug"))
+}
+
 func TestNew(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -35,6 +61,26 @@ func TestNew(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid logfmt config",
+			config: Config{
+				Level:       "info",
+				Format:      "logfmt",
+				Output:      "stdout",
+				ServiceName: "test-service",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid log format",
+			config: Config{
+				Level:       "info",
+				Format:      "xml",
+				Output:      "stdout",
+				ServiceName: "test-service",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid log level",
 			config: Config{
@@ -62,6 +108,133 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNew_Formats(t *testing.T) {
+	logWith := func(t *testing.T, format string) string {
+		var out bytes.Buffer
+		logger, err := newLogger(Config{Level: "info", Format: format, ServiceName: "test-service"}, zapcore.AddSync(&out))
+		require.NoError(t, err)
+
+		logger.WithCorrelationID("corr-1").Info("Fill processed",
+			zap.Int64("fill_id", 7),
+			zap.String("ticker", "IBM"),
+			zap.String("note", "two words"),
+			zap.Any("tags", map[string]string{"desk": "eq"}),
+		)
+		return out.String()
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(logWith(t, FormatJSON)), &entry))
+
+		assert.Equal(t, "Fill processed", entry["message"])
+		assert.Equal(t, "INFO", entry["level"])
+		assert.Equal(t, "test-service", entry["service"])
+		assert.Equal(t, "corr-1", entry["correlationId"])
+		assert.Equal(t, float64(7), entry["fill_id"])
+	})
+
+	t.Run("logfmt", func(t *testing.T) {
+		output := logWith(t, FormatLogfmt)
+		require.True(t, strings.HasSuffix(output, "\n"))
+		assert.Equal(t, 1, strings.Count(output, "\n"))
+
+		assert.True(t, strings.HasPrefix(output, "level=INFO timestamp="))
+		assert.Contains(t, output, `message="Fill processed"`)
+		assert.Contains(t, output, "service=test-service ")
+		assert.Contains(t, output, "correlationId=corr-1 ")
+		assert.Contains(t, output, "fill_id=7 ")
+		assert.Contains(t, output, "ticker=IBM ")
+		assert.Contains(t, output, `note="two words"`)
+		assert.Contains(t, output, `tags="{\"desk\":\"eq\"}"`)
+	})
+
+	t.Run("console", func(t *testing.T) {
+		output := logWith(t, FormatConsole)
+
+		// Tab-separated time, colored level, caller and message, then the fields as JSON
+		columns := strings.Split(strings.TrimSuffix(output, "\n"), "\t")
+		require.Len(t, columns, 5)
+		assert.Contains(t, columns[1], "INFO")
+		assert.Contains(t, columns[1], "\x1b[")
+		assert.Equal(t, "Fill processed", columns[3])
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(columns[4]), &fields))
+		assert.Equal(t, "IBM", fields["ticker"])
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := newLogger(Config{Level: "info", Format: "xml"}, zapcore.AddSync(&bytes.Buffer{}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid log format xml")
+	})
+}
+
+func TestNew_Sampling(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := newLogger(Config{Level: "debug", Format: FormatJSON, SamplingInitial: 2, SamplingThereafter: 5}, zapcore.AddSync(&out))
+	require.NoError(t, err)
+	derived := logger.WithCorrelationID("corr-1")
+
+	// Within one second: the first two, then every fifth after them
+	for i := 0; i < 10; i++ {
+		derived.Debug("Processing Kafka message", zap.Int("i", i))
+	}
+	for i := 0; i < 10; i++ {
+		derived.Warn("Execution Service slow", zap.Int("i", i))
+	}
+
+	var debugIndexes, warnIndexes []float64
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		switch entry["level"] {
+		case "DEBUG":
+			debugIndexes = append(debugIndexes, entry["i"].(float64))
+		case "WARN":
+			warnIndexes = append(warnIndexes, entry["i"].(float64))
+		}
+	}
+	assert.Equal(t, []float64{0, 1, 6}, debugIndexes)
+	assert.Len(t, warnIndexes, 10, "warnings are never sampled")
+
+	// The level stays adjustable
+	require.NoError(t, logger.SetLevel("info"))
+	assert.False(t, derived.Core().Enabled(zapcore.DebugLevel))
+	assert.True(t, derived.Core().Enabled(zapcore.InfoLevel))
+}
+
+// syncErrorWriter discards writes and fails every sync with err
+type syncErrorWriter struct {
+	err error
+}
+
+func (w syncErrorWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w syncErrorWriter) Sync() error                 { return w.err }
+
+func TestLogger_Close(t *testing.T) {
+	for _, output := range []string{"stdout", "stderr"} {
+		logger, err := New(Config{Level: "info", Format: FormatJSON, Output: output, ServiceName: "test"})
+		require.NoError(t, err)
+		logger.Info("Shutting down")
+		assert.NoError(t, logger.Close(), output)
+	}
+
+	// Terminals and pipes cannot be synced; that is not a failure
+	for _, syncErr := range []error{syscall.EINVAL, syscall.ENOTTY, &os.PathError{Op: "sync", Path: "/dev/stdout", Err: syscall.EINVAL}} {
+		logger, err := newLogger(Config{Level: "info"}, syncErrorWriter{err: syncErr})
+		require.NoError(t, err)
+		assert.NoError(t, logger.Close())
+	}
+
+	logger, err := newLogger(Config{Level: "info"}, syncErrorWriter{err: syscall.EIO})
+	require.NoError(t, err)
+	err = logger.Close()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, syscall.EIO)
+}
+
 func TestLogger_WithCorrelationID(t *testing.T) {
 	config := Config{
 		Level:       "info",
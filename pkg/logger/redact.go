@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// RedactedValue replaces the values of redacted fields
+const RedactedValue = "[REDACTED]"
+
+// Redact returns a copy of value for logging with the named top-level fields masked.
+// Fields are matched by their JSON names, ignoring case. Values that do not encode
+// as a JSON object are returned unchanged.
+func Redact(value interface{}, fields []string) interface{} {
+	if len(fields) == 0 || value == nil {
+		return value
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(encoded, &object); err != nil || object == nil {
+		return value
+	}
+
+	for name := range object {
+		for _, field := range fields {
+			if strings.EqualFold(name, field) {
+				object[name] = RedactedValue
+				break
+			}
+		}
+	}
+
+	return object
+}
+
+// RedactedAny is zap.Any for a value with the named fields masked
+func RedactedAny(key string, value interface{}, fields []string) zap.Field {
+	return zap.Any(key, Redact(value, fields))
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type redactTestFill struct {
+	ID         int64  `json:"id"`
+	SecurityID string `json:"securityId"`
+	Ticker     string `json:"ticker"`
+}
+
+func TestRedact(t *testing.T) {
+	fill := &redactTestFill{ID: 7, SecurityID: "SEC123", Ticker: "IBM"}
+
+	redacted, ok := Redact(fill, []string{"securityid", "unknownField"}).(map[string]interface{})
+	require.True(t, ok)
+
+	assert.Equal(t, RedactedValue, redacted["securityId"])
+	assert.Equal(t, "IBM", redacted["ticker"])
+	assert.Equal(t, float64(7), redacted["id"])
+	assert.NotContains(t, redacted, "unknownField")
+
+	// The original is left untouched
+	assert.Equal(t, "SEC123", fill.SecurityID)
+}
+
+func TestRedact_PassesThroughUnredactableValues(t *testing.T) {
+	fill := &redactTestFill{ID: 7, SecurityID: "SEC123"}
+
+	assert.Same(t, fill, Redact(fill, nil))
+	assert.Equal(t, "plain", Redact("plain", []string{"securityId"}))
+	assert.Nil(t, Redact(nil, []string{"securityId"}))
+}
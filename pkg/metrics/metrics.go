@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,18 +13,89 @@ type Metrics struct {
 	// Message processing metrics
 	MessagesProcessedTotal prometheus.Counter
 	MessagesFailedTotal    prometheus.Counter
+	OversizedMessagesTotal prometheus.Counter
+	PoisonMessagesTotal    prometheus.Counter
 	MessageProcessingTime  prometheus.Histogram
 	MessageProcessingGauge prometheus.Gauge
 
+	// Kafka reader internal counters, exported periodically from the reader's stats
+	KafkaReaderErrorsTotal     prometheus.Counter
+	KafkaReaderTimeoutsTotal   prometheus.Counter
+	KafkaReaderRebalancesTotal prometheus.Counter
+
+	// Message size, and processing time by size class so large messages can be compared
+	// with small ones. Size classes come from Config.MessageSizeClasses to bound cardinality.
+	MessageSizeBytes                 prometheus.Histogram
+	MessageProcessingTimeBySizeClass prometheus.HistogramVec
+	messageSizeClasses               []int
+
+	// Failed fraction of the most recently handled messages
+	MessageErrorRate prometheus.Gauge
+
+	// Messages skipped because their execution is excluded from canary processing
+	MessagesSkippedCanaryTotal prometheus.Counter
+
+	// Messages delivered again after a previous delivery failed
+	MessagesRedeliveredTotal prometheus.Counter
+
+	// Processed messages by venue. The ticker variant is only registered when high
+	// cardinality metrics are enabled, since every traded security adds a series.
+	MessagesProcessedByDestinationTotal prometheus.CounterVec
+	MessagesProcessedByTickerTotal      prometheus.CounterVec
+
+	// Validation findings by code and field
+	ValidationErrorsTotal   prometheus.CounterVec
+	ValidationWarningsTotal prometheus.CounterVec
+
+	// Shadow mode: messages handled without calling downstream services
+	MessagesShadowProcessedTotal prometheus.Counter
+	ShadowCallsSkippedTotal      prometheus.CounterVec
+
+	// Correlation ID metrics
+	CorrelationIDGeneratedTotal prometheus.Counter
+	CorrelationIDInheritedTotal prometheus.Counter
+
 	// API call metrics
 	APICallsTotal    prometheus.CounterVec
 	APICallDuration  prometheus.HistogramVec
 	APICallsInFlight prometheus.Gauge
 
+	// Requests served by the HTTP server, labeled by route template rather than raw path
+	// so path parameters do not add series
+	HTTPRequestsTotal   prometheus.CounterVec
+	HTTPRequestDuration prometheus.HistogramVec
+	HTTPPanicsTotal     prometheus.Counter
+
+	// Execution Service HTTP latency by operation and status code, excluding parsing and validation
+	ExecutionAPILatency prometheus.HistogramVec
+
+	// Current adaptive limit on concurrent Execution Service requests
+	ExecutionServiceConcurrencyLimit prometheus.Gauge
+
+	// Current adaptive limit on active Kafka consumer workers
+	ConsumerWorkerLimit prometheus.Gauge
+
+	// Time Execution Service requests waited for the client-side rate limit
+	ExecutionRateLimitWait prometheus.HistogramVec
+
+	// Execution updates that left the execution unchanged
+	ExecutionUpdateNoOpsTotal prometheus.Counter
+
+	// Allocation Service posts by result, and the latency of posts that were sent
+	AllocationPostsTotal          prometheus.CounterVec
+	AllocationPostDurationSeconds prometheus.Histogram
+
 	// Kafka metrics
 	KafkaMessagesConsumed prometheus.Counter
-	KafkaConsumerLag      prometheus.Gauge
+	KafkaConsumerLag      prometheus.GaugeVec
 	KafkaConnectionErrors prometheus.Counter
+	KafkaConsumerPaused   prometheus.Gauge
+	KafkaConsumerPauses   prometheus.Counter
+
+	// Dead letter queue metrics
+	DLQCurrentSize             prometheus.Gauge
+	DLQOldestMessageAgeSeconds prometheus.Gauge
+	DLQMessagesAddedTotal      prometheus.CounterVec
 
 	// Circuit breaker metrics
 	CircuitBreakerState      prometheus.GaugeVec
@@ -37,14 +109,26 @@ type Metrics struct {
 	ActiveGoroutines prometheus.Gauge
 	MemoryUsage      prometheus.Gauge
 	CPUUsage         prometheus.Gauge
+
+	registry *prometheus.Registry
 }
 
 // Config represents metrics configuration
 type Config struct {
-	Namespace string
-	Enabled   bool
+	Namespace   string
+	Enabled     bool
+	ConstLabels map[string]string // Labels added to every metric, e.g. environment and service_version
+
+	// Registers metrics labeled by ticker, which add a series per traded security
+	HighCardinalityMetricsEnabled bool
+
+	// Ascending byte thresholds splitting messages into size classes; defaults to 1KiB and 16KiB
+	MessageSizeClasses []int
 }
 
+// DefaultMessageSizeClasses are the byte thresholds used when Config.MessageSizeClasses is empty
+var DefaultMessageSizeClasses = []int{1024, 16384}
+
 // New creates a new metrics instance
 func New(config Config) *Metrics {
 	if !config.Enabled {
@@ -58,9 +142,25 @@ func New(config Config) *Metrics {
 
 	// Create a new registry for testing to avoid conflicts
 	registry := prometheus.NewRegistry()
-	factory := promauto.With(registry)
 
-	return &Metrics{
+	// Attach the constant labels to everything registered through the factory
+	constLabels := prometheus.Labels{}
+	for name, value := range config.ConstLabels {
+		if value != "" {
+			constLabels[name] = value
+		}
+	}
+	factory := promauto.With(prometheus.WrapRegistererWith(constLabels, registry))
+
+	messageSizeClasses := config.MessageSizeClasses
+	if len(messageSizeClasses) == 0 {
+		messageSizeClasses = DefaultMessageSizeClasses
+	}
+
+	m := &Metrics{
+		registry:           registry,
+		messageSizeClasses: messageSizeClasses,
+
 		// Message processing metrics
 		MessagesProcessedTotal: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
@@ -72,17 +172,106 @@ func New(config Config) *Metrics {
 			Name:      "messages_failed_total",
 			Help:      "Total number of messages that failed processing",
 		}),
+		OversizedMessagesTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "kafka_oversized_messages_total",
+			Help:      "Total number of Kafka messages rejected for exceeding the maximum message size",
+		}),
+		PoisonMessagesTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "kafka_poison_messages_total",
+			Help:      "Total number of Kafka messages dead-lettered after repeatedly failing processing",
+		}),
+		KafkaReaderErrorsTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "kafka_reader_errors_total",
+			Help:      "Total number of errors reported by the Kafka reader",
+		}),
+		KafkaReaderTimeoutsTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "kafka_reader_timeouts_total",
+			Help:      "Total number of timeouts reported by the Kafka reader",
+		}),
+		KafkaReaderRebalancesTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "kafka_reader_rebalances_total",
+			Help:      "Total number of consumer group rebalances reported by the Kafka reader",
+		}),
+		MessagesSkippedCanaryTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_skipped_canary_total",
+			Help:      "Total number of messages skipped because their execution is excluded from canary processing",
+		}),
+		MessagesRedeliveredTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_redelivered_total",
+			Help:      "Total number of messages received again after a previous delivery failed",
+		}),
+		MessagesProcessedByDestinationTotal: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_processed_by_destination_total",
+			Help:      "Total number of messages processed by destination and trade type. Bounded cardinality; see messages_processed_by_ticker_total for the per-security breakdown",
+		}, []string{"destination", "trade_type"}),
+		ValidationErrorsTotal: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validation_errors_total",
+			Help:      "Total number of fill validation errors by code and field",
+		}, []string{"code", "field"}),
+		ValidationWarningsTotal: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validation_warnings_total",
+			Help:      "Total number of fill validation warnings by code and field",
+		}, []string{"code", "field"}),
+		MessagesShadowProcessedTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_shadow_processed_total",
+			Help:      "Total number of messages processed in shadow mode without calling downstream services",
+		}),
+		ShadowCallsSkippedTotal: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "shadow_calls_skipped_total",
+			Help:      "Total number of downstream service calls skipped in shadow mode",
+		}, []string{"service"}),
 		MessageProcessingTime: factory.NewHistogram(prometheus.HistogramOpts{
 			Namespace: namespace,
 			Name:      "message_processing_duration_seconds",
 			Help:      "Time spent processing messages",
 			Buckets:   prometheus.DefBuckets,
 		}),
+		MessageSizeBytes: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "message_size_bytes",
+			Help:      "Size of consumed message values in bytes",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8), // 256B to 4MiB
+		}),
+		MessageProcessingTimeBySizeClass: *factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "message_processing_duration_by_size_seconds",
+			Help:      "Time spent processing messages by message size class, for correlating latency with size",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"size_class"}),
 		MessageProcessingGauge: factory.NewGauge(prometheus.GaugeOpts{
 			Namespace: namespace,
 			Name:      "messages_processing_current",
 			Help:      "Current number of messages being processed",
 		}),
+		MessageErrorRate: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "message_error_rate",
+			Help:      "Fraction of recently handled messages that failed, over a rolling window",
+		}),
+
+		// Correlation ID metrics
+		CorrelationIDGeneratedTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "correlation_id_generated_total",
+			Help:      "Total number of messages for which a new correlation ID was generated",
+		}),
+		CorrelationIDInheritedTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "correlation_id_inherited_total",
+			Help:      "Total number of messages whose correlation ID was inherited from the producer",
+		}),
 
 		// API call metrics
 		APICallsTotal: *factory.NewCounterVec(prometheus.CounterOpts{
@@ -101,6 +290,60 @@ func New(config Config) *Metrics {
 			Name:      "api_calls_in_flight",
 			Help:      "Current number of API calls in flight",
 		}),
+		HTTPRequestsTotal: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests served, by method, route template and status code",
+		}, []string{"method", "path", "status"}),
+		HTTPRequestDuration: *factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests served, by method and route template",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"method", "path"}),
+		HTTPPanicsTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_panics_total",
+			Help:      "Total number of panics recovered in HTTP handlers",
+		}),
+		ExecutionAPILatency: *factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "execution_api_latency_seconds",
+			Help:      "Latency of Execution Service HTTP calls by operation and status code (\"error\" when no response was received)",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"operation", "status_code"}),
+		ExecutionServiceConcurrencyLimit: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "execution_service_concurrency_limit",
+			Help:      "Current adaptive limit on concurrent Execution Service requests",
+		}),
+		ConsumerWorkerLimit: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "consumer_worker_limit",
+			Help:      "Current adaptive limit on Kafka consumer workers handling messages at once",
+		}),
+		ExecutionRateLimitWait: *factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "execution_rate_limit_wait_seconds",
+			Help:      "Time Execution Service calls waited for the client-side rate limit by operation",
+			Buckets:   []float64{0, 0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		}, []string{"operation"}),
+		ExecutionUpdateNoOpsTotal: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "execution_update_noops_total",
+			Help:      "Total number of execution updates that did not change the execution",
+		}),
+		AllocationPostsTotal: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "allocation_posts_total",
+			Help:      "Total number of completed fills considered for the Allocation Service, by result (success, failure, circuit_open, duplicate, skipped)",
+		}, []string{"result"}),
+		AllocationPostDurationSeconds: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "allocation_post_duration_seconds",
+			Help:      "Duration of Allocation Service posts, including retries",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}),
 
 		// Kafka metrics
 		KafkaMessagesConsumed: factory.NewCounter(prometheus.CounterOpts{
@@ -108,16 +351,43 @@ func New(config Config) *Metrics {
 			Name:      "kafka_messages_consumed_total",
 			Help:      "Total number of Kafka messages consumed",
 		}),
-		KafkaConsumerLag: factory.NewGauge(prometheus.GaugeOpts{
+		KafkaConsumerLag: *factory.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: namespace,
 			Name:      "kafka_consumer_lag",
-			Help:      "Current Kafka consumer lag",
-		}),
+			Help:      "Messages the consumer group is behind on each partition",
+		}, []string{"topic", "partition"}),
 		KafkaConnectionErrors: factory.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "kafka_connection_errors_total",
 			Help:      "Total number of Kafka connection errors",
 		}),
+		KafkaConsumerPaused: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "kafka_consumer_paused",
+			Help:      "Whether Kafka consumption is paused by dead letter queue backpressure (1=paused)",
+		}),
+		KafkaConsumerPauses: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "kafka_consumer_pauses_total",
+			Help:      "Total number of times Kafka consumption was paused by dead letter queue backpressure",
+		}),
+
+		// Dead letter queue metrics
+		DLQCurrentSize: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "dlq_current_size",
+			Help:      "Number of messages currently held in the dead letter queue",
+		}),
+		DLQOldestMessageAgeSeconds: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "dlq_oldest_message_age_seconds",
+			Help:      "Age of the oldest message in the dead letter queue (0 when empty), refreshed when the queue changes and on each cleanup pass",
+		}),
+		DLQMessagesAddedTotal: *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dlq_messages_added_total",
+			Help:      "Total number of messages added to the dead letter queue by failure reason",
+		}, []string{"reason"}),
 
 		// Circuit breaker metrics
 		CircuitBreakerState: *factory.NewGaugeVec(prometheus.GaugeOpts{
@@ -161,6 +431,24 @@ func New(config Config) *Metrics {
 			Help:      "Current CPU usage percentage",
 		}),
 	}
+
+	if config.HighCardinalityMetricsEnabled {
+		m.MessagesProcessedByTickerTotal = *factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_processed_by_ticker_total",
+			Help:      "Total number of messages processed by ticker and destination. High cardinality: adds a series per traded security",
+		}, []string{"ticker", "destination"})
+	}
+
+	return m
+}
+
+// Gatherer returns the registry holding the application metrics
+func (m *Metrics) Gatherer() prometheus.Gatherer {
+	if m.registry == nil {
+		return prometheus.NewRegistry()
+	}
+	return m.registry
 }
 
 // RecordMessageProcessed increments the processed messages counter
@@ -177,6 +465,122 @@ func (m *Metrics) RecordMessageFailed() {
 	}
 }
 
+// RecordOversizedMessage increments the oversized Kafka messages counter
+func (m *Metrics) RecordOversizedMessage() {
+	if m.OversizedMessagesTotal != nil {
+		m.OversizedMessagesTotal.Inc()
+	}
+}
+
+// RecordPoisonMessage increments the poison Kafka messages counter
+func (m *Metrics) RecordPoisonMessage() {
+	if m.PoisonMessagesTotal != nil {
+		m.PoisonMessagesTotal.Inc()
+	}
+}
+
+// RecordKafkaReaderStats adds the Kafka reader's error, timeout and rebalance counts
+// since the previous call
+func (m *Metrics) RecordKafkaReaderStats(errors, timeouts, rebalances int64) {
+	if m.KafkaReaderErrorsTotal != nil && errors > 0 {
+		m.KafkaReaderErrorsTotal.Add(float64(errors))
+	}
+	if m.KafkaReaderTimeoutsTotal != nil && timeouts > 0 {
+		m.KafkaReaderTimeoutsTotal.Add(float64(timeouts))
+	}
+	if m.KafkaReaderRebalancesTotal != nil && rebalances > 0 {
+		m.KafkaReaderRebalancesTotal.Add(float64(rebalances))
+	}
+}
+
+// RecordMessageSkippedCanary increments the canary skipped messages counter
+func (m *Metrics) RecordMessageSkippedCanary() {
+	if m.MessagesSkippedCanaryTotal != nil {
+		m.MessagesSkippedCanaryTotal.Inc()
+	}
+}
+
+// RecordMessageRedelivered increments the redelivered messages counter
+func (m *Metrics) RecordMessageRedelivered() {
+	if m.MessagesRedeliveredTotal != nil {
+		m.MessagesRedeliveredTotal.Inc()
+	}
+}
+
+// RecordMessageProcessedFor increments the processed messages counters for a fill's
+// destination and trade type, and for its ticker when high cardinality metrics are enabled
+func (m *Metrics) RecordMessageProcessedFor(destination, tradeType, ticker string) {
+	if m.MessagesProcessedByDestinationTotal.MetricVec != nil {
+		m.MessagesProcessedByDestinationTotal.WithLabelValues(destination, tradeType).Inc()
+	}
+	if m.MessagesProcessedByTickerTotal.MetricVec != nil {
+		m.MessagesProcessedByTickerTotal.WithLabelValues(ticker, destination).Inc()
+	}
+}
+
+// RecordValidationError increments the validation errors counter for a code and field
+func (m *Metrics) RecordValidationError(code, field string) {
+	if m.ValidationErrorsTotal.MetricVec != nil {
+		m.ValidationErrorsTotal.WithLabelValues(code, field).Inc()
+	}
+}
+
+// RecordValidationWarning increments the validation warnings counter for a code and field
+func (m *Metrics) RecordValidationWarning(code, field string) {
+	if m.ValidationWarningsTotal.MetricVec != nil {
+		m.ValidationWarningsTotal.WithLabelValues(code, field).Inc()
+	}
+}
+
+// RecordMessageShadowProcessed increments the shadow processed messages counter
+func (m *Metrics) RecordMessageShadowProcessed() {
+	if m.MessagesShadowProcessedTotal != nil {
+		m.MessagesShadowProcessedTotal.Inc()
+	}
+}
+
+// RecordShadowCallSkipped increments the skipped downstream calls counter for a service
+func (m *Metrics) RecordShadowCallSkipped(service string) {
+	if m.ShadowCallsSkippedTotal.MetricVec != nil {
+		m.ShadowCallsSkippedTotal.WithLabelValues(service).Inc()
+	}
+}
+
+// SetMessageErrorRate sets the rolling message error rate
+func (m *Metrics) SetMessageErrorRate(rate float64) {
+	if m.MessageErrorRate != nil {
+		m.MessageErrorRate.Set(rate)
+	}
+}
+
+// RecordExecutionAPILatency records the latency of one Execution Service HTTP call
+func (m *Metrics) RecordExecutionAPILatency(operation, statusCode string, duration time.Duration) {
+	if m.ExecutionAPILatency.MetricVec != nil {
+		m.ExecutionAPILatency.WithLabelValues(operation, statusCode).Observe(duration.Seconds())
+	}
+}
+
+// RecordExecutionRateLimitWait records how long an Execution Service call waited for the rate limit
+func (m *Metrics) RecordExecutionRateLimitWait(operation string, wait time.Duration) {
+	if m.ExecutionRateLimitWait.MetricVec != nil {
+		m.ExecutionRateLimitWait.WithLabelValues(operation).Observe(wait.Seconds())
+	}
+}
+
+// SetExecutionServiceConcurrencyLimit sets the adaptive Execution Service concurrency limit gauge
+func (m *Metrics) SetExecutionServiceConcurrencyLimit(limit int) {
+	if m.ExecutionServiceConcurrencyLimit != nil {
+		m.ExecutionServiceConcurrencyLimit.Set(float64(limit))
+	}
+}
+
+// SetConsumerWorkerLimit sets the adaptive Kafka consumer worker limit gauge
+func (m *Metrics) SetConsumerWorkerLimit(limit int) {
+	if m.ConsumerWorkerLimit != nil {
+		m.ConsumerWorkerLimit.Set(float64(limit))
+	}
+}
+
 // RecordMessageProcessingTime records the time taken to process a message
 func (m *Metrics) RecordMessageProcessingTime(duration time.Duration) {
 	if m.MessageProcessingTime != nil {
@@ -184,6 +588,34 @@ func (m *Metrics) RecordMessageProcessingTime(duration time.Duration) {
 	}
 }
 
+// RecordMessageSize records the size of a consumed message value
+func (m *Metrics) RecordMessageSize(bytes int) {
+	if m.MessageSizeBytes != nil {
+		m.MessageSizeBytes.Observe(float64(bytes))
+	}
+}
+
+// RecordMessageProcessingTimeBySize records processing time under the message's size class
+func (m *Metrics) RecordMessageProcessingTimeBySize(bytes int, duration time.Duration) {
+	if m.MessageProcessingTimeBySizeClass.MetricVec != nil {
+		m.MessageProcessingTimeBySizeClass.WithLabelValues(MessageSizeClass(bytes, m.messageSizeClasses)).Observe(duration.Seconds())
+	}
+}
+
+// MessageSizeClass returns the size class label for a message: "lt_<threshold>" for the
+// first threshold the size is under, or "ge_<largest threshold>"
+func MessageSizeClass(bytes int, thresholds []int) string {
+	for _, threshold := range thresholds {
+		if bytes < threshold {
+			return "lt_" + strconv.Itoa(threshold)
+		}
+	}
+	if len(thresholds) == 0 {
+		return "all"
+	}
+	return "ge_" + strconv.Itoa(thresholds[len(thresholds)-1])
+}
+
 // SetMessagesProcessing sets the current number of messages being processed
 func (m *Metrics) SetMessagesProcessing(count float64) {
 	if m.MessageProcessingGauge != nil {
@@ -191,6 +623,20 @@ func (m *Metrics) SetMessagesProcessing(count float64) {
 	}
 }
 
+// RecordCorrelationIDGenerated increments the generated correlation IDs counter
+func (m *Metrics) RecordCorrelationIDGenerated() {
+	if m.CorrelationIDGeneratedTotal != nil {
+		m.CorrelationIDGeneratedTotal.Inc()
+	}
+}
+
+// RecordCorrelationIDInherited increments the inherited correlation IDs counter
+func (m *Metrics) RecordCorrelationIDInherited() {
+	if m.CorrelationIDInheritedTotal != nil {
+		m.CorrelationIDInheritedTotal.Inc()
+	}
+}
+
 // RecordAPICall records an API call with method, endpoint, and status code
 func (m *Metrics) RecordAPICall(method, endpoint, statusCode string, duration time.Duration) {
 	if m.APICallsTotal.MetricVec != nil {
@@ -201,6 +647,24 @@ func (m *Metrics) RecordAPICall(method, endpoint, statusCode string, duration ti
 	}
 }
 
+// RecordHTTPRequest records a request served by the HTTP server; path must be the
+// matched route template, not the raw request path
+func (m *Metrics) RecordHTTPRequest(method, path, status string, duration time.Duration) {
+	if m.HTTPRequestsTotal.MetricVec != nil {
+		m.HTTPRequestsTotal.WithLabelValues(method, path, status).Inc()
+	}
+	if m.HTTPRequestDuration.MetricVec != nil {
+		m.HTTPRequestDuration.WithLabelValues(method, path).Observe(duration.Seconds())
+	}
+}
+
+// RecordHTTPPanic increments the recovered HTTP handler panics counter
+func (m *Metrics) RecordHTTPPanic() {
+	if m.HTTPPanicsTotal != nil {
+		m.HTTPPanicsTotal.Inc()
+	}
+}
+
 // IncAPICallsInFlight increments the in-flight API calls gauge
 func (m *Metrics) IncAPICallsInFlight() {
 	if m.APICallsInFlight != nil {
@@ -215,6 +679,27 @@ func (m *Metrics) DecAPICallsInFlight() {
 	}
 }
 
+// RecordExecutionUpdateNoOp increments the no-op execution updates counter
+func (m *Metrics) RecordExecutionUpdateNoOp() {
+	if m.ExecutionUpdateNoOpsTotal != nil {
+		m.ExecutionUpdateNoOpsTotal.Inc()
+	}
+}
+
+// RecordAllocationPost counts a fill considered for the Allocation Service by result
+func (m *Metrics) RecordAllocationPost(result string) {
+	if m.AllocationPostsTotal.MetricVec != nil {
+		m.AllocationPostsTotal.WithLabelValues(result).Inc()
+	}
+}
+
+// RecordAllocationPostDuration records how long an Allocation Service post took
+func (m *Metrics) RecordAllocationPostDuration(duration time.Duration) {
+	if m.AllocationPostDurationSeconds != nil {
+		m.AllocationPostDurationSeconds.Observe(duration.Seconds())
+	}
+}
+
 // RecordKafkaMessage increments the Kafka messages consumed counter
 func (m *Metrics) RecordKafkaMessage() {
 	if m.KafkaMessagesConsumed != nil {
@@ -222,10 +707,24 @@ func (m *Metrics) RecordKafkaMessage() {
 	}
 }
 
-// SetKafkaConsumerLag sets the current Kafka consumer lag
-func (m *Metrics) SetKafkaConsumerLag(lag float64) {
-	if m.KafkaConsumerLag != nil {
-		m.KafkaConsumerLag.Set(lag)
+// SetKafkaConsumerLag sets the consumer group's lag on one partition
+func (m *Metrics) SetKafkaConsumerLag(topic string, partition int, lag float64) {
+	if m.KafkaConsumerLag.MetricVec != nil {
+		m.KafkaConsumerLag.WithLabelValues(topic, strconv.Itoa(partition)).Set(lag)
+	}
+}
+
+// SetKafkaConsumerPaused records whether Kafka consumption is paused, counting each pause
+func (m *Metrics) SetKafkaConsumerPaused(paused bool) {
+	if m.KafkaConsumerPaused != nil {
+		if paused {
+			m.KafkaConsumerPaused.Set(1)
+		} else {
+			m.KafkaConsumerPaused.Set(0)
+		}
+	}
+	if paused && m.KafkaConsumerPauses != nil {
+		m.KafkaConsumerPauses.Inc()
 	}
 }
 
@@ -236,6 +735,23 @@ func (m *Metrics) RecordKafkaConnectionError() {
 	}
 }
 
+// SetDLQState records the dead letter queue size and the age of its oldest message
+func (m *Metrics) SetDLQState(size int, oldestAge time.Duration) {
+	if m.DLQCurrentSize != nil {
+		m.DLQCurrentSize.Set(float64(size))
+	}
+	if m.DLQOldestMessageAgeSeconds != nil {
+		m.DLQOldestMessageAgeSeconds.Set(oldestAge.Seconds())
+	}
+}
+
+// RecordDLQMessageAdded counts a message added to the dead letter queue by failure reason
+func (m *Metrics) RecordDLQMessageAdded(reason string) {
+	if m.DLQMessagesAddedTotal.MetricVec != nil {
+		m.DLQMessagesAddedTotal.WithLabelValues(reason).Inc()
+	}
+}
+
 // SetCircuitBreakerState sets the circuit breaker state
 func (m *Metrics) SetCircuitBreakerState(name string, state float64) {
 	if m.CircuitBreakerState.MetricVec != nil {
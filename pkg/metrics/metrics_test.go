@@ -4,7 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -55,6 +57,46 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNew_ConstLabels(t *testing.T) {
+	metrics := New(Config{
+		Namespace: "test",
+		Enabled:   true,
+		ConstLabels: map[string]string{
+			"environment":     "staging",
+			"service_version": "1.2.3",
+			"empty":           "",
+		},
+	})
+
+	metrics.RecordMessageProcessed()
+	metrics.RecordAPICall("GET", "/api/v1/execution/1", "200", 10*time.Millisecond)
+
+	families, err := metrics.Gatherer().Gather()
+	require.NoError(t, err)
+	require.NotEmpty(t, families)
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			assert.Equal(t, "staging", labels["environment"], family.GetName())
+			assert.Equal(t, "1.2.3", labels["service_version"], family.GetName())
+			assert.NotContains(t, labels, "empty", family.GetName())
+		}
+	}
+}
+
+func TestMetrics_Gatherer_Disabled(t *testing.T) {
+	metrics := New(Config{Enabled: false})
+
+	families, err := metrics.Gatherer().Gather()
+	require.NoError(t, err)
+	assert.Empty(t, families)
+}
+
 func TestMetrics_RecordMessageProcessed(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -170,6 +212,117 @@ func TestMetrics_RecordAPICall(t *testing.T) {
 	}
 }
 
+func TestMetrics_RecordHTTPRequest(t *testing.T) {
+	m := New(Config{Namespace: "test_http_requests", Enabled: true})
+
+	m.RecordHTTPRequest("GET", "/api/v1/duplicates", "200", 25*time.Millisecond)
+	m.RecordHTTPRequest("GET", "/api/v1/duplicates", "200", 5*time.Millisecond)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.HTTPRequestsTotal.WithLabelValues("GET", "/api/v1/duplicates", "200")))
+	assert.Equal(t, 1, testutil.CollectAndCount(&m.HTTPRequestDuration))
+
+	// Disabled metrics ignore requests
+	New(Config{Enabled: false}).RecordHTTPRequest("GET", "/", "200", time.Millisecond)
+}
+
+func TestMetrics_RecordAllocationPost(t *testing.T) {
+	m := New(Config{Namespace: "test_allocation_posts", Enabled: true})
+
+	m.RecordAllocationPost("success")
+	m.RecordAllocationPost("success")
+	m.RecordAllocationPost("skipped")
+	m.RecordAllocationPostDuration(40 * time.Millisecond)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.AllocationPostsTotal.WithLabelValues("success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.AllocationPostsTotal.WithLabelValues("skipped")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.AllocationPostDurationSeconds))
+
+	// Disabled metrics ignore posts
+	disabled := New(Config{Enabled: false})
+	disabled.RecordAllocationPost("success")
+	disabled.RecordAllocationPostDuration(time.Millisecond)
+}
+
+func TestMetrics_DLQ(t *testing.T) {
+	m := New(Config{Namespace: "test_dlq", Enabled: true})
+
+	m.SetDLQState(3, 90*time.Second)
+	m.RecordDLQMessageAdded("poison message")
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.DLQCurrentSize))
+	assert.Equal(t, float64(90), testutil.ToFloat64(m.DLQOldestMessageAgeSeconds))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.DLQMessagesAddedTotal.WithLabelValues("poison message")))
+
+	// Disabled metrics ignore updates
+	disabled := New(Config{Enabled: false})
+	disabled.SetDLQState(1, time.Second)
+	disabled.RecordDLQMessageAdded("poison message")
+}
+
+func TestMetrics_RecordMessageProcessedFor(t *testing.T) {
+	t.Run("destination only by default", func(t *testing.T) {
+		metrics := New(Config{Namespace: "test", Enabled: true})
+
+		metrics.RecordMessageProcessedFor("ML", "BUY", "IBM")
+		metrics.RecordMessageProcessedFor("ML", "BUY", "AAPL")
+
+		assert.Equal(t, 2.0, testutil.ToFloat64(metrics.MessagesProcessedByDestinationTotal.WithLabelValues("ML", "BUY")))
+		assert.Nil(t, metrics.MessagesProcessedByTickerTotal.MetricVec)
+	})
+
+	t.Run("ticker with high cardinality metrics", func(t *testing.T) {
+		metrics := New(Config{Namespace: "test", Enabled: true, HighCardinalityMetricsEnabled: true})
+
+		metrics.RecordMessageProcessedFor("ML", "BUY", "IBM")
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.MessagesProcessedByTickerTotal.WithLabelValues("IBM", "ML")))
+	})
+
+	t.Run("disabled metrics", func(t *testing.T) {
+		metrics := New(Config{Namespace: "test", Enabled: false, HighCardinalityMetricsEnabled: true})
+
+		// Should not panic
+		metrics.RecordMessageProcessedFor("ML", "BUY", "IBM")
+	})
+}
+
+func TestMetrics_RecordMessageSize(t *testing.T) {
+	metrics := New(Config{Namespace: "test", Enabled: true, MessageSizeClasses: []int{1024, 4096}})
+
+	for _, size := range []int{200, 800, 3000, 10000} {
+		metrics.RecordMessageSize(size)
+		metrics.RecordMessageProcessingTimeBySize(size, 10*time.Millisecond)
+	}
+
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.MessageSizeBytes))
+	assert.Equal(t, 3, testutil.CollectAndCount(&metrics.MessageProcessingTimeBySizeClass))
+
+	families, err := metrics.Gatherer().Gather()
+	require.NoError(t, err)
+
+	sampleCounts := map[string]uint64{}
+	for _, family := range families {
+		switch family.GetName() {
+		case "test_message_size_bytes":
+			sampleCounts["size"] = family.GetMetric()[0].GetHistogram().GetSampleCount()
+		case "test_message_processing_duration_by_size_seconds":
+			for _, metric := range family.GetMetric() {
+				sampleCounts[metric.GetLabel()[0].GetValue()] = metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	assert.Equal(t, map[string]uint64{"size": 4, "lt_1024": 2, "lt_4096": 1, "ge_4096": 1}, sampleCounts)
+}
+
+func TestMessageSizeClass(t *testing.T) {
+	thresholds := []int{1024, 16384}
+
+	assert.Equal(t, "lt_1024", MessageSizeClass(0, thresholds))
+	assert.Equal(t, "lt_16384", MessageSizeClass(1024, thresholds))
+	assert.Equal(t, "ge_16384", MessageSizeClass(16384, thresholds))
+	assert.Equal(t, "all", MessageSizeClass(100, nil))
+}
+
 func TestMetrics_APICallsInFlight(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -235,7 +388,7 @@ func TestMetrics_SetKafkaConsumerLag(t *testing.T) {
 			metrics := New(config)
 
 			// Should not panic regardless of enabled state
-			metrics.SetKafkaConsumerLag(100.0)
+			metrics.SetKafkaConsumerLag("fills", 0, 100.0)
 		})
 	}
 }
@@ -359,7 +512,7 @@ func TestMetrics_AllMethods(t *testing.T) {
 
 	// Kafka
 	metrics.RecordKafkaMessage()
-	metrics.SetKafkaConsumerLag(5.0)
+	metrics.SetKafkaConsumerLag("fills", 0, 5.0)
 	metrics.RecordKafkaConnectionError()
 
 	// Circuit breaker
@@ -97,12 +97,12 @@ func (a *Adapter) RecordKafkaMessage() {
 }
 
 // SetKafkaConsumerLag sets Kafka consumer lag in both systems
-func (a *Adapter) SetKafkaConsumerLag(lag float64) {
+func (a *Adapter) SetKafkaConsumerLag(topic string, partition int, lag float64) {
 	if a.promMetrics != nil {
-		a.promMetrics.SetKafkaConsumerLag(lag)
+		a.promMetrics.SetKafkaConsumerLag(topic, partition, lag)
 	}
 	if a.otelMetrics != nil {
-		a.otelMetrics.SetKafkaConsumerLag(a.ctx, lag)
+		a.otelMetrics.SetKafkaConsumerLag(a.ctx, topic, partition, lag)
 	}
 }
 
@@ -104,7 +104,7 @@ func New(config Config) *Metrics {
 
 	kafkaConsumerLag, _ := meter.Float64Gauge(
 		"kafka_consumer_lag",
-		metric.WithDescription("Current Kafka consumer lag"),
+		metric.WithDescription("Messages the consumer group is behind on each partition"),
 	)
 
 	kafkaConnectionErrors, _ := meter.Int64Counter(
@@ -254,12 +254,16 @@ func (m *Metrics) RecordKafkaMessage(ctx context.Context) {
 	m.kafkaMessagesConsumed.Add(ctx, 1)
 }
 
-// SetKafkaConsumerLag sets the current Kafka consumer lag
-func (m *Metrics) SetKafkaConsumerLag(ctx context.Context, lag float64) {
+// SetKafkaConsumerLag sets the consumer group's lag on one partition
+func (m *Metrics) SetKafkaConsumerLag(ctx context.Context, topic string, partition int, lag float64) {
 	if !m.enabled {
 		return
 	}
-	m.kafkaConsumerLag.Record(ctx, lag)
+	attrs := []attribute.KeyValue{
+		attribute.String("topic", topic),
+		attribute.Int("partition", partition),
+	}
+	m.kafkaConsumerLag.Record(ctx, lag, metric.WithAttributes(attrs...))
 }
 
 // RecordKafkaConnectionError increments the Kafka connection errors counter